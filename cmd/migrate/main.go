@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/config"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/migration"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"os"
+	"strconv"
+)
+
+const usage = `Usage: migrate <command> [steps]
+
+Commands:
+  up [n]     apply pending migrations, all of them unless n is given
+  down [n]   revert the last n applied migrations, defaults to 1
+  redo       revert the last applied migration and apply it again
+  status     list every migration and when it was applied
+`
+
+func main() {
+	log.SetFormatter(&log.TextFormatter{
+		DisableColors: true,
+		FullTimestamp: true,
+	})
+
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		fmt.Fprint(flag.CommandLine.Output(), "\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	config := config.MustLoad(configFlags)
+
+	if err := logging.Setup(config.Log, log.InfoLevel); err != nil {
+		log.Panicf("Failed to set up logging: %s", err)
+	}
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	steps := 0
+	if flag.NArg() == 2 {
+		n, err := strconv.Atoi(flag.Arg(1))
+		if err != nil || n < 1 {
+			log.Fatalf("Steps must be a positive number, got %q", flag.Arg(1))
+		}
+		steps = n
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", config.Database.Host, config.Database.Port, config.Database.Username, config.Database.Password, config.Database.Name)
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  dsn,
+		PreferSimpleProtocol: true,
+	}), &gorm.Config{})
+	if err != nil {
+		log.Panicf("Failed to connect to database: %s", err)
+	}
+
+	migrator, err := migration.New(db, config)
+	if err != nil {
+		log.Panicf("Failed to load migrations: %s", err)
+	}
+
+	ctx := context.WithValue(context.Background(), "requestid", "migrate")
+
+	switch flag.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx, steps)
+		if err != nil {
+			log.Fatalf("Failed to migrate up: %s", err)
+		}
+		log.Infof("Applied %d migrations", applied)
+	case "down":
+		if steps == 0 {
+			steps = 1
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Failed to migrate down: %s", err)
+		}
+		log.Infof("Reverted %d migrations", reverted)
+	case "redo":
+		if err := migrator.Redo(ctx); err != nil {
+			log.Fatalf("Failed to redo migration: %s", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to get migration status: %s", err)
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -1,48 +1,32 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/go-faker/faker/v4"
-	"github.com/go-playground/validator/v10"
-	"github.com/goccy/go-json"
 	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/config"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"math/rand"
-	"os"
 )
 
 func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
 	log.SetFormatter(&log.TextFormatter{
 		DisableColors: true,
 		FullTimestamp: true,
 	})
 
-	log.SetLevel(log.DebugLevel)
-
-	currentDir, err := os.Getwd()
-	if err != nil {
-		log.Panicf("Failed to get current directory path: %s", err)
-	}
+	config := config.MustLoad(configFlags)
 
-	configPath := fmt.Sprintf("%s/config.json", currentDir)
-	configFile, err := os.ReadFile(configPath)
-	if err != nil {
-		log.Panicf("Failed to read config file: %s", err)
-	}
-
-	var config *domain.Config
-	err = json.Unmarshal(configFile, &config)
-	if err != nil {
-		log.Panicf("Failed to unmarshal config file: %s", err)
-	}
-
-	validate := validator.New()
-	err = validate.Struct(config)
-	if err != nil {
-		log.Panicf("Failed to validate config file: %s", err)
+	if err := logging.Setup(config.Log, log.DebugLevel); err != nil {
+		log.Panicf("Failed to set up logging: %s", err)
 	}
 
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", config.Database.Host, config.Database.Port, config.Database.Username, config.Database.Password, config.Database.Name)
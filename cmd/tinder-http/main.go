@@ -1,58 +1,86 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"github.com/go-playground/validator/v10"
 	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/config"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/handler/job"
 	"github.com/zombozo12/tinder-dealls/handler/resthttp"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/analytics"
+	"github.com/zombozo12/tinder-dealls/repository/audit"
 	"github.com/zombozo12/tinder-dealls/repository/auth"
+	"github.com/zombozo12/tinder-dealls/repository/block"
+	"github.com/zombozo12/tinder-dealls/repository/chat"
+	"github.com/zombozo12/tinder-dealls/repository/contentfilter"
+	"github.com/zombozo12/tinder-dealls/repository/contentflag"
+	"github.com/zombozo12/tinder-dealls/repository/device"
+	"github.com/zombozo12/tinder-dealls/repository/eventbus"
+	"github.com/zombozo12/tinder-dealls/repository/health"
 	"github.com/zombozo12/tinder-dealls/repository/inventory"
 	"github.com/zombozo12/tinder-dealls/repository/matched"
+	"github.com/zombozo12/tinder-dealls/repository/metrics"
+	"github.com/zombozo12/tinder-dealls/repository/migration"
 	"github.com/zombozo12/tinder-dealls/repository/notification"
+	"github.com/zombozo12/tinder-dealls/repository/oidc"
+	"github.com/zombozo12/tinder-dealls/repository/outbox"
+	"github.com/zombozo12/tinder-dealls/repository/presence"
 	"github.com/zombozo12/tinder-dealls/repository/profile"
+	"github.com/zombozo12/tinder-dealls/repository/push"
 	"github.com/zombozo12/tinder-dealls/repository/rds"
+	"github.com/zombozo12/tinder-dealls/repository/realtime"
+	"github.com/zombozo12/tinder-dealls/repository/report"
+	"github.com/zombozo12/tinder-dealls/repository/settings"
+	"github.com/zombozo12/tinder-dealls/repository/sms"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
 	"github.com/zombozo12/tinder-dealls/services"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
+)
+
+// Set at build time, for example -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse HEAD)".
+var (
+	version = "dev"
+	commit  = ""
 )
 
 func main() {
+	autoMigrate := flag.Bool("migrate", false, "apply pending database migrations before starting")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
 	log.SetFormatter(&log.TextFormatter{
 		DisableColors: true,
 		FullTimestamp: true,
 	})
 
-	log.SetLevel(log.DebugLevel)
-
-	currentDir, err := os.Getwd()
-	if err != nil {
-		log.Panicf("Failed to get current directory path: %s", err)
-	}
+	config := config.MustLoad(configFlags)
 
-	configPath := fmt.Sprintf("%s/config.json", currentDir)
-	configFile, err := os.ReadFile(configPath)
-	if err != nil {
-		log.Panicf("Failed to read config file: %s", err)
+	if err := logging.Setup(config.Log, log.DebugLevel); err != nil {
+		log.Panicf("Failed to set up logging: %s", err)
 	}
 
-	var config *domain.Config
-	err = json.Unmarshal(configFile, &config)
-	if err != nil {
-		log.Panicf("Failed to unmarshal config file: %s", err)
-	}
+	// Call latencies are observed as the span of every call ends.
+	metricsCollector := metrics.New(config)
+	tracing.SetObserver(metricsCollector)
 
-	validate := validator.New()
-	err = validate.Struct(config)
+	shutdownTracing, err := tracing.Setup(config, "tinder-http")
 	if err != nil {
-		log.Panicf("Failed to validate config file: %s", err)
+		log.Panicf("Failed to set up tracing: %s", err)
 	}
 
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", config.Database.Host, config.Database.Port, config.Database.Username, config.Database.Password, config.Database.Name)
@@ -64,6 +92,30 @@ func main() {
 		log.Panicf("Failed to connect to database: %s", err)
 	}
 
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		log.Panicf("Failed to trace database: %s", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Panicf("Failed to get database pool: %s", err)
+	}
+
+	if err := metricsCollector.RegisterDB(sqlDB, config.Database.Name); err != nil {
+		log.Panicf("Failed to register database metrics: %s", err)
+	}
+
+	if *autoMigrate {
+		migrator, err := migration.New(db, config)
+		if err != nil {
+			log.Panicf("Failed to load migrations: %s", err)
+		}
+
+		if _, err := migrator.Up(context.WithValue(context.Background(), "requestid", "migrate"), 0); err != nil {
+			log.Panicf("Failed to migrate database: %s", err)
+		}
+	}
+
 	app := fiber.New(fiber.Config{
 		Prefork:       false,
 		ServerHeader:  "tinder",
@@ -79,6 +131,9 @@ func main() {
 		Password: config.Redis.Password,
 		DB:       0,
 	})
+	redisClient.AddHook(tracing.RedisHook())
+
+	healthModule := health.New(config, health.Build(version, commit), health.Database(sqlDB), health.Redis(redisClient))
 
 	authRepo := auth.New(db, config)
 	profileRepo := profile.New(db, config)
@@ -86,44 +141,208 @@ func main() {
 	notificationRepo := notification.New(db, config)
 	redisRepo := rds.New(redisClient, config)
 	matchedRepo := matched.New(db, config)
+	smsSender := sms.NewLogSender(config)
+	auditRepo := audit.New(db, config)
+	reportRepo := report.New(db, config)
+	blockRepo := block.New(db, config)
+	chatRepo := chat.New(db, config)
+	realtimeHub := realtime.NewHub(config)
+	presenceRepo := presence.New(redisClient, config)
+	contentFlagRepo := contentflag.New(db, config)
+	contentFilter := contentfilter.New(config, redisRepo)
+	deviceRepo := device.New(db, config)
+	outboxRepo := outbox.New(db, config)
+	unitOfWork := uow.New(db, config)
+	settingsStore := settings.New(db, redisClient, config)
+	if err := settingsStore.Refresh(context.WithValue(context.Background(), "requestid", "settings")); err != nil {
+		log.Warnf("Failed to load runtime settings, using defaults: %s", err)
+	}
+	var eventTransport eventbus.Transport = eventbus.NewMemory()
+	if config.Events.Transport == "redis" {
+		eventTransport = eventbus.NewRedis(redisClient, config)
+	}
+	eventBus := eventbus.New(config, eventTransport)
+	pushGateway, err := push.New(config)
+	if err != nil {
+		log.Panicf("Failed to setup push gateway: %s", err)
+	}
+
+	identityProviders := make(map[string]services.IdentityProviderInterface)
+	for name, provider := range config.OAuth {
+		identityProviders[name] = oidc.New(name, provider)
+	}
 
 	// Setting up services
-	authService, err := services.NewAuthService(config, db, authRepo, inventoryRepo, profileRepo)
+	otpService, err := services.NewOTPService(config, redisRepo, smsSender)
+	if err != nil {
+		log.Panicf("Failed to setup otp service: %s", err)
+	}
+
+	authService, err := services.NewAuthService(config, db, authRepo, inventoryRepo, profileRepo, redisRepo,
+		otpService, identityProviders, eventBus, settingsStore)
 	if err != nil {
 		log.Panicf("Failed to setup auth service: %s", err)
 	}
 
-	profileService, err := services.NewProfileService(config, db, profileRepo)
+	profileService, err := services.NewProfileService(config, db, profileRepo, blockRepo, presenceRepo,
+		contentFilter, contentFlagRepo, eventBus)
 	if err != nil {
 		log.Panicf("Failed to setup profile service: %s", err)
 	}
 
+	experimentService, err := services.NewExperimentService(config, settingsStore, eventBus)
+	if err != nil {
+		log.Panicf("Failed to setup experiment service: %s", err)
+	}
+
 	matcherService, err := services.NewMatcherService(config,
-		profileRepo, inventoryRepo, matchedRepo, notificationRepo)
+		profileRepo, inventoryRepo, matchedRepo, unitOfWork, outboxRepo, eventBus, experimentService)
 	if err != nil {
 		log.Panicf("Failed to setup matcher service: %s", err)
 	}
 
-	recommendationService, err := services.NewRecommendationService(config, db, redisRepo, profileRepo)
+	recommendationService, err := services.NewRecommendationService(config, db, redisRepo, profileRepo, blockRepo,
+		presenceRepo, settingsStore, experimentService)
 	if err != nil {
 		log.Panicf("Failed to setup recommendation service: %s", err)
 	}
 
+	accountService, err := services.NewAccountService(config, authRepo, profileRepo, inventoryRepo,
+		matchedRepo, notificationRepo, redisRepo, blockRepo, chatRepo, contentFlagRepo, deviceRepo, reportRepo, unitOfWork)
+	if err != nil {
+		log.Panicf("Failed to setup account service: %s", err)
+	}
+
+	adminService, err := services.NewAdminService(config, authRepo, profileRepo, inventoryRepo,
+		matchedRepo, notificationRepo, auditRepo, reportRepo, contentFlagRepo, settingsStore, unitOfWork)
+	if err != nil {
+		log.Panicf("Failed to setup admin service: %s", err)
+	}
+
+	reportService, err := services.NewReportService(config, authRepo, profileRepo, reportRepo, blockRepo, unitOfWork)
+	if err != nil {
+		log.Panicf("Failed to setup report service: %s", err)
+	}
+
+	chatService, err := services.NewChatService(config, chatRepo, matchedRepo, blockRepo, realtimeHub,
+		contentFilter, contentFlagRepo, profileRepo, presenceRepo, outboxRepo)
+	if err != nil {
+		log.Panicf("Failed to setup chat service: %s", err)
+	}
+
+	presenceService, err := services.NewPresenceService(config, presenceRepo)
+	if err != nil {
+		log.Panicf("Failed to setup presence service: %s", err)
+	}
+
+	notificationService, err := services.NewNotificationService(config, notificationRepo, deviceRepo, pushGateway,
+		settingsStore)
+	if err != nil {
+		log.Panicf("Failed to setup notification service: %s", err)
+	}
+
+	outboxService, err := services.NewOutboxService(config, outboxRepo, notificationRepo)
+	if err != nil {
+		log.Panicf("Failed to setup outbox service: %s", err)
+	}
+
+	notificationSubscriber, err := services.NewNotificationSubscriber(config, outboxRepo)
+	if err != nil {
+		log.Panicf("Failed to setup notification subscriber: %s", err)
+	}
+
+	// Setting up event subscribers
+	eventBus.Subscribe(domain.EventSwiped, "notification", notificationSubscriber.OnSwiped)
+	eventBus.Subscribe(domain.EventMatched, "notification", notificationSubscriber.OnMatched)
+
+	for _, event := range []string{domain.EventUserRegistered, domain.EventSwiped, domain.EventMatched} {
+		eventBus.SubscribeAsync(event, "metrics", metricsCollector.Track)
+	}
+
+	var analyticsSink *analytics.Sink
+	if config.Analytics.Enabled {
+		analyticsSink, err = analytics.New(config)
+		if err != nil {
+			log.Panicf("Failed to setup analytics sink: %s", err)
+		}
+
+		for _, event := range []string{domain.EventUserRegistered, domain.EventUserLoggedIn, domain.EventSwiped,
+			domain.EventMatched, domain.EventExperimentExposed} {
+			eventBus.SubscribeAsync(event, "analytics", analyticsSink.Track)
+		}
+		analyticsSink.Start()
+	}
+	eventBus.Start()
+
+	// Setting up background jobs
+	jobRunner := job.NewRunner(config)
+	jobRunner.Every("account_purge", time.Hour, func(ctx context.Context) error {
+		_, err := accountService.PurgeDeleted(ctx)
+		return err
+	})
+	jobRunner.Every("match_expiry", time.Hour, func(ctx context.Context) error {
+		_, err := matcherService.ExpireStaleMatches(ctx)
+		return err
+	})
+	jobRunner.Every("match_reminders", 15*time.Minute, func(ctx context.Context) error {
+		_, err := matcherService.SendMatchReminders(ctx)
+		return err
+	})
+	jobRunner.Every("outbox_relay", 5*time.Second, func(ctx context.Context) error {
+		_, err := outboxService.Relay(ctx)
+		return err
+	})
+	jobRunner.Every("push_delivery", 10*time.Second, func(ctx context.Context) error {
+		_, err := notificationService.DeliverPush(ctx)
+		return err
+	})
+	jobRunner.Every("settings_refresh", 5*time.Second, settingsStore.Refresh)
+
 	// Setting up router
 	resthttp.NewRouter(app, resthttp.RouteDependencies{
 		Cfg:            config,
 		Auth:           authService,
+		OTP:            otpService,
 		Profile:        profileService,
 		Matcher:        matcherService,
 		Recommendation: recommendationService,
+		Account:        accountService,
+		Admin:          adminService,
+		Report:         reportService,
+		Chat:           chatService,
+		Realtime:       realtimeHub,
+		Presence:       presenceService,
+		Notification:   notificationService,
+		Metrics:        metricsCollector,
+		Health:         healthModule,
 	})
 
+	// Metrics are served apart from the public API, on a port that is kept private.
+	var metricsServer *http.Server
+	if config.Metrics.Port > 0 {
+		metricsServer = &http.Server{
+			Addr:              fmt.Sprintf(":%d", config.Metrics.Port),
+			Handler:           metricsCollector.Handler(),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Panicf("Failed to start metrics server: %s", err)
+			}
+		}()
+	}
+
 	// Setting up graceful shutdown
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		_ = <-c
 		log.Info("Gracefully shutting down...")
+
+		// Load balancers see the instance is not ready and stop sending requests before it stops serving.
+		healthModule.Drain()
+		time.Sleep(time.Duration(config.Health.DrainDelay) * time.Second)
+
 		_ = app.Shutdown()
 	}()
 
@@ -132,4 +351,16 @@ func main() {
 	}
 
 	log.Info("Running cleanup tasks...")
+	if metricsServer != nil {
+		_ = metricsServer.Shutdown(context.Background())
+	}
+	jobRunner.Stop()
+	eventBus.Stop()
+	if analyticsSink != nil {
+		analyticsSink.Stop()
+	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Warnf("Failed to flush traces: %s", err)
+	}
 }
@@ -0,0 +1,371 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the environment variable of every setting, database.password is read from
+// TINDER_DATABASE_PASSWORD.
+const EnvPrefix = "TINDER"
+
+// defaultPath is read when -config is not given, it may be missing.
+const defaultPath = "config.json"
+
+const redacted = "[redacted]"
+
+// Flags are the command line options shared by every command.
+type Flags struct {
+	// Path is the JSON or YAML file to read, chosen by its extension.
+	Path string
+	// Print asks to print the effective config with secrets redacted and exit.
+	Print bool
+	// Sets override single settings by their dotted name, for example database.host=db.
+	Sets overrides
+}
+
+type overrides map[string]string
+
+func (o overrides) String() string {
+	return fmt.Sprint(map[string]string(o))
+}
+
+func (o overrides) Set(value string) error {
+	key, raw, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+
+	o[key] = raw
+	return nil
+}
+
+// RegisterFlags adds -config, -print-config and -set to fs, call it before fs.Parse.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{Sets: overrides{}}
+	fs.StringVar(&flags.Path, "config", "", "config file, JSON or YAML (default \"config.json\" if present)")
+	fs.BoolVar(&flags.Print, "print-config", false, "print the effective config with secrets redacted and exit")
+	fs.Var(flags.Sets, "set", "override a setting, for example -set database.host=db (repeatable)")
+	return flags
+}
+
+// MustLoad loads and validates the config of a command. With -print-config it prints the redacted
+// config and exits instead, with a non-zero status if the config is invalid.
+func MustLoad(flags *Flags) *domain.Config {
+	config, err := Load(flags)
+	if err != nil {
+		log.Panicf("Failed to load config: %s", err)
+	}
+
+	invalid := Validate(config)
+	if flags.Print {
+		if err := Print(os.Stdout, config); err != nil {
+			log.Fatalf("Failed to print config: %s", err)
+		}
+		if invalid != nil {
+			log.Fatalf("Invalid config: %s", invalid)
+		}
+		os.Exit(0)
+	}
+
+	if invalid != nil {
+		log.Panicf("Failed to validate config: %s", invalid)
+	}
+
+	return config
+}
+
+// Load layers the config, later sources win: defaults, the config file, EnvPrefix environment
+// variables and finally -set flags.
+func Load(flags *Flags) (*domain.Config, error) {
+	config := Defaults()
+
+	if err := loadFile(flags.Path, config); err != nil {
+		return nil, err
+	}
+
+	if err := loadEnv(config); err != nil {
+		return nil, err
+	}
+
+	if err := loadOverrides(flags.Sets, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Defaults are the settings of a local setup, everything else has to be configured.
+func Defaults() *domain.Config {
+	return &domain.Config{
+		Server: domain.Server{
+			Port: 3000,
+		},
+		Health: domain.Health{
+			DrainDelay: 5,
+		},
+		Database: domain.Database{
+			Host: "localhost",
+			Port: 5432,
+		},
+		JWT: domain.JWT{
+			ExpireTime: 48,
+		},
+		Redis: domain.Redis{
+			Host: "localhost",
+			Port: 6379,
+		},
+		Metrics: domain.Metrics{
+			Port: 9090,
+		},
+	}
+}
+
+func Validate(config *domain.Config) error {
+	validate := validator.New()
+	return validate.Struct(config)
+}
+
+// Print writes config as indented JSON, fields tagged secret are replaced unless they are empty.
+func Print(w io.Writer, config *domain.Config) error {
+	clone, err := redact(config)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(clone, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", out)
+	return err
+}
+
+// redact returns a copy of config with the secret fields replaced.
+func redact(config *domain.Config) (*domain.Config, error) {
+	out, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var clone *domain.Config
+	if err := json.Unmarshal(out, &clone); err != nil {
+		return nil, err
+	}
+
+	_, err = walk(reflect.ValueOf(clone).Elem(), "", false, func(_ string, v reflect.Value, secret bool) (bool, error) {
+		if !secret || v.Kind() != reflect.String || v.String() == "" {
+			return false, nil
+		}
+
+		v.SetString(redacted)
+		return true, nil
+	})
+
+	return clone, err
+}
+
+func loadFile(path string, config *domain.Config) error {
+	explicit := path != ""
+	if !explicit {
+		path = defaultPath
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed reading %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		// Decoding through a map keeps the json tags of domain.Config the only names to maintain.
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return fmt.Errorf("failed parsing %s: %w", path, err)
+		}
+
+		content, err = json.Marshal(values)
+		if err != nil {
+			return fmt.Errorf("failed parsing %s: %w", path, err)
+		}
+	}
+
+	if err := json.Unmarshal(content, config); err != nil {
+		return fmt.Errorf("failed parsing %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func loadEnv(config *domain.Config) error {
+	_, err := walk(reflect.ValueOf(config).Elem(), "", false, func(path string, v reflect.Value, _ bool) (bool, error) {
+		name := EnvName(path)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			return false, nil
+		}
+
+		if err := setValue(v, raw); err != nil {
+			return false, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return true, nil
+	})
+
+	return err
+}
+
+func loadOverrides(sets overrides, config *domain.Config) error {
+	pending := make(map[string]string, len(sets))
+	for key, raw := range sets {
+		pending[key] = raw
+	}
+
+	_, err := walk(reflect.ValueOf(config).Elem(), "", false, func(path string, v reflect.Value, _ bool) (bool, error) {
+		raw, ok := pending[path]
+		if !ok {
+			return false, nil
+		}
+		delete(pending, path)
+
+		if err := setValue(v, raw); err != nil {
+			return false, fmt.Errorf("invalid %s: %w", path, err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for key := range pending {
+		return fmt.Errorf("unknown setting %s", key)
+	}
+
+	return nil
+}
+
+// EnvName is the environment variable of a dotted setting name.
+func EnvName(path string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(path))
+}
+
+// walk calls visit with the dotted json name of every setting below v and reports whether visit changed
+// any. Optional sections are only allocated once something is set in them, and maps are only walked
+// for the keys they already have, so a new OAuth provider has to be declared in the config file.
+func walk(v reflect.Value, path string, secret bool, visit func(path string, v reflect.Value, secret bool) (bool, error)) (bool, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		changed := false
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			ok, err := walk(v.Field(i), join(path, name), field.Tag.Get("secret") == "true", visit)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || ok
+		}
+		return changed, nil
+	case reflect.Pointer:
+		target := v
+		if v.IsNil() {
+			target = reflect.New(v.Type().Elem())
+		}
+
+		changed, err := walk(target.Elem(), path, secret, visit)
+		if err != nil {
+			return false, err
+		}
+
+		if changed && v.IsNil() {
+			v.Set(target)
+		}
+		return changed, nil
+	case reflect.Map:
+		changed := false
+		for _, key := range v.MapKeys() {
+			// Map values are not addressable, so walk a copy and store it back.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+
+			ok, err := walk(elem, join(path, key.String()), secret, visit)
+			if err != nil {
+				return false, err
+			}
+
+			if ok {
+				v.SetMapIndex(key, elem)
+				changed = true
+			}
+		}
+		return changed, nil
+	default:
+		return visit(path, v, secret)
+	}
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// setValue parses raw into v, lists are comma separated.
+func setValue(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			item := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(item, part); err != nil {
+				return err
+			}
+			items = reflect.Append(items, item)
+		}
+		v.Set(items)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const yamlConfig = `
+server:
+  port: 8080
+database:
+  host: db
+  user: tinder
+  password: file-password
+  name: tinder
+jwt:
+  key: file-key
+oauth:
+  google:
+    issuer: https://accounts.google.com
+    client_id: id
+    redirect_url: https://tinder.local/callback
+moderation:
+  banned_words: [spam]
+`
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("TINDER_DATABASE_PASSWORD", "env-password")
+	t.Setenv("TINDER_SERVER_PORT", "9090")
+	t.Setenv("TINDER_OAUTH_GOOGLE_CLIENT_SECRET", "env-secret")
+	t.Setenv("TINDER_ANALYTICS_S3_BUCKET", "events")
+
+	flags := &Flags{
+		Path: writeConfig(t, "config.yaml", yamlConfig),
+		Sets: overrides{"server.port": "7070", "matches.reminder_days": "1, 2"},
+	}
+
+	config, err := Load(flags)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if config.Redis.Host != "localhost" || config.Redis.Port != 6379 {
+		t.Errorf("Load() redis = %+v, want defaults", config.Redis)
+	}
+	if config.Database.Host != "db" || config.Database.Port != 5432 {
+		t.Errorf("Load() database = %+v, want host from file and default port", config.Database)
+	}
+	if config.Database.Password != "env-password" {
+		t.Errorf("Load() database.password = %q, want env over file", config.Database.Password)
+	}
+	if config.Server.Port != 7070 {
+		t.Errorf("Load() server.port = %d, want flag over env", config.Server.Port)
+	}
+	if config.Health.DrainDelay != 5 {
+		t.Errorf("Load() health.drain_delay = %d, want default 5", config.Health.DrainDelay)
+	}
+	if config.OAuth["google"].ClientSecret != "env-secret" || config.OAuth["google"].ClientID != "id" {
+		t.Errorf("Load() oauth.google = %+v, want file merged with env", config.OAuth["google"])
+	}
+	if config.Analytics.S3 == nil || config.Analytics.S3.Bucket != "events" {
+		t.Errorf("Load() analytics.s3 = %+v, want allocated from env", config.Analytics.S3)
+	}
+	if config.Push.FCM != nil {
+		t.Errorf("Load() push.fcm = %+v, want nil", config.Push.FCM)
+	}
+	if len(config.Matches.ReminderDays) != 2 || config.Matches.ReminderDays[1] != 2 {
+		t.Errorf("Load() matches.reminder_days = %v, want [1 2]", config.Matches.ReminderDays)
+	}
+	if len(config.Moderation.BannedWords) != 1 {
+		t.Errorf("Load() moderation.banned_words = %v, want [spam]", config.Moderation.BannedWords)
+	}
+
+	// Setting one field of an optional section makes its required fields mandatory.
+	if err := Validate(config); err == nil || !strings.Contains(err.Error(), "S3.Endpoint") {
+		t.Errorf("Validate() error = %v, want analytics.s3.endpoint required", err)
+	}
+}
+
+func TestLoad_failed(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags *Flags
+		env   map[string]string
+	}{
+		{
+			name:  "missing explicit file",
+			flags: &Flags{Path: filepath.Join(t.TempDir(), "missing.json")},
+		},
+		{
+			name:  "unknown flag setting",
+			flags: &Flags{Sets: overrides{"database.hostname": "db"}},
+		},
+		{
+			name:  "invalid env number",
+			flags: &Flags{},
+			env:   map[string]string{"TINDER_REDIS_PORT": "redis"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			if _, err := Load(tt.flags); err == nil {
+				t.Errorf("Load() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	flags := &Flags{Path: writeConfig(t, "config.json", `{"jwt": {"key": "file-key"}, "redis": {"password": ""}}`)}
+
+	config, err := Load(flags)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, config); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	if strings.Contains(out.String(), "file-key") || !strings.Contains(out.String(), redacted) {
+		t.Errorf("Print() = %s, want jwt.key redacted", out.String())
+	}
+	if config.JWT.Key != "file-key" {
+		t.Errorf("Print() changed jwt.key to %q", config.JWT.Key)
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type AccountDeletionResponse struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// UserRecord is everything stored about a single user. It backs both the
+// GDPR export and the admin user view.
+type UserRecord struct {
+	User          *User            `json:"user"`
+	Profile       *Profile         `json:"profile,omitempty"`
+	Inventory     *Inventory       `json:"inventory,omitempty"`
+	Identities    []UserIdentity   `json:"identities"`
+	TwoFactor     *TwoFactorStatus `json:"two_factor,omitempty"`
+	Matches       []Matched        `json:"matches"`
+	Notifications []Notification   `json:"notifications"`
+}
+
+type AccountExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	UserRecord
+	Messages                []Message               `json:"messages"`
+	Reports                 []Report                `json:"reports"`
+	Blocks                  []UserBlock             `json:"blocks"`
+	Devices                 []DeviceToken           `json:"devices"`
+	NotificationPreferences NotificationPreferences `json:"notification_preferences"`
+	ContentFlags            []ContentFlag           `json:"content_flags"`
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+const (
+	AuditActionAdjustInventory    = "adjust_inventory"
+	AuditActionBanUser            = "ban_user"
+	AuditActionUnbanUser          = "unban_user"
+	AuditActionDeleteProfile      = "delete_profile"
+	AuditActionResendNotification = "resend_notification"
+	AuditActionSearchUsers        = "search_users"
+	AuditActionViewUser           = "view_user"
+	AuditActionReviewReport       = "review_report"
+	AuditActionUpdateSetting      = "update_setting"
+)
+
+type UserSearchRequest struct {
+	Query  string `query:"q" validate:"omitempty,max=255"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `query:"offset" validate:"omitempty,min=0"`
+}
+
+// AdjustInventoryRequest sets the given counters; nil fields are left as they are.
+type AdjustInventoryRequest struct {
+	Likes      *int `json:"likes" validate:"omitempty,min=0"`
+	SuperLikes *int `json:"super_likes" validate:"omitempty,min=0"`
+	Swipes     *int `json:"swipes" validate:"omitempty,min=0"`
+}
+
+type BanRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type AuditLog struct {
+	ID           int64     `gorm:"primaryKey" json:"id"`
+	AdminID      int64     `gorm:"not null" json:"admin_id"`
+	Action       string    `gorm:"not null" json:"action"`
+	TargetUserID int64     `gorm:"default:null" json:"target_user_id,omitempty"`
+	Payload      string    `gorm:"type:jsonb;default:'{}'" json:"payload"`
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+}
+
+type AuditLogRequest struct {
+	AdminID      int64       `validate:"required"`
+	Action       string      `validate:"required"`
+	TargetUserID int64       `validate:"omitempty"`
+	Payload      interface{} `validate:"omitempty"`
+}
+
+type AuditLogFilter struct {
+	AdminID      int64  `query:"admin_id"`
+	TargetUserID int64  `query:"target_user_id"`
+	Action       string `query:"action"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset       int    `query:"offset" validate:"omitempty,min=0"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// AnalyticsSchemaVersion is bumped whenever a field of AnalyticsRecord changes meaning or is removed.
+const AnalyticsSchemaVersion = 1
+
+// AnalyticsRecord is one line of an exported analytics file. Delivery is at least once, consumers
+// deduplicate on EventID.
+type AnalyticsRecord struct {
+	SchemaVersion int               `json:"schema_version"`
+	EventID       string            `json:"event_id"`
+	Event         string            `json:"event"`
+	UserID        int64             `json:"user_id"`
+	TargetUserID  int64             `json:"target_user_id,omitempty"`
+	Properties    map[string]string `json:"properties,omitempty"`
+	RequestID     string            `json:"request_id"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+}
@@ -1,10 +1,17 @@
 package domain
 
+// AuthRequest accepts either email + password or phone + OTP code.
 type AuthRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=32"`
+	Email    string `json:"email,omitempty" validate:"required_without=Phone,omitempty,email"`
+	Password string `json:"password,omitempty" validate:"required_with=Email,omitempty,min=8,max=32"`
+	Phone    string `json:"phone,omitempty" validate:"required_without=Email,omitempty,e164"`
+	Code     string `json:"code,omitempty" validate:"required_with=Phone,omitempty,len=6,numeric"`
 }
 
+// AuthResponse carries either the access token, or a short-lived MFA token when the account has
+// two-factor authentication enabled and the second step is still pending.
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token       string `json:"token,omitempty"`
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
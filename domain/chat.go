@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+)
+
+// Conversation exists once per mutually matched pair, UserAID is always the lower user id.
+type Conversation struct {
+	ID            int64      `gorm:"primaryKey" json:"id"`
+	UserAID       int64      `gorm:"not null" json:"user_a_id"`
+	UserBID       int64      `gorm:"not null" json:"user_b_id"`
+	LastMessageAt *time.Time `gorm:"default:null" json:"last_message_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
+}
+
+// Peer returns the other participant, or 0 when userID is not part of the conversation.
+func (c Conversation) Peer(userID int64) int64 {
+	switch userID {
+	case c.UserAID:
+		return c.UserBID
+	case c.UserBID:
+		return c.UserAID
+	default:
+		return 0
+	}
+}
+
+type Message struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	ConversationID int64      `gorm:"not null" json:"conversation_id"`
+	SenderID       int64      `gorm:"not null" json:"sender_id"`
+	Body           string     `gorm:"not null" json:"body"`
+	Status         string     `gorm:"not null;default:sent" json:"status"`
+	DeliveredAt    *time.Time `gorm:"default:null" json:"delivered_at,omitempty"`
+	ReadAt         *time.Time `gorm:"default:null" json:"read_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+}
+
+type OpenConversationRequest struct {
+	TargetUserID int64 `json:"target_user_id" validate:"required"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+type CreateMessageRequest struct {
+	ConversationID int64
+	SenderID       int64
+	Body           string
+}
+
+// MessageHistoryRequest pages backwards through history, Before is the id of the oldest message
+// already loaded.
+type MessageHistoryRequest struct {
+	Before int64 `query:"before" validate:"omitempty,min=1"`
+	Limit  int   `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type MessagePage struct {
+	Messages   []Message `json:"messages"`
+	NextCursor int64     `json:"next_cursor,omitempty"`
+}
+
+type ReceiptRequest struct {
+	Status    string `json:"status" validate:"required,oneof=delivered read"`
+	MessageID int64  `json:"message_id" validate:"required"`
+}
+
+type Receipt struct {
+	ConversationID int64  `json:"conversation_id"`
+	UserID         int64  `json:"user_id"`
+	Status         string `json:"status"`
+	MessageID      int64  `json:"message_id"`
+}
@@ -1,11 +1,22 @@
 package domain
 
+// Config is loaded by the config package, fields tagged secret are redacted when it is printed.
 type Config struct {
-	Server   Server   `json:"server" validate:"required"`
-	Key      string   `json:"key"`
-	Database Database `json:"database" validate:"required"`
-	JWT      JWT      `json:"jwt" validate:"required"`
-	Redis    Redis    `json:"redis" validate:"required"`
+	Server     Server                   `json:"server" validate:"required"`
+	Key        string                   `json:"key" secret:"true"`
+	Database   Database                 `json:"database" validate:"required"`
+	JWT        JWT                      `json:"jwt" validate:"required"`
+	Redis      Redis                    `json:"redis" validate:"required"`
+	OAuth      map[string]OAuthProvider `json:"oauth" validate:"omitempty,dive"`
+	Moderation Moderation               `json:"moderation"`
+	Matches    Matches                  `json:"matches"`
+	Push       Push                     `json:"push"`
+	Events     Events                   `json:"events"`
+	Analytics  Analytics                `json:"analytics"`
+	Metrics    Metrics                  `json:"metrics"`
+	Tracing    Tracing                  `json:"tracing"`
+	Log        Log                      `json:"log"`
+	Health     Health                   `json:"health"`
 }
 
 type Server struct {
@@ -16,17 +27,143 @@ type Database struct {
 	Host     string `json:"host" validate:"required"`
 	Port     int    `json:"port" validate:"required"`
 	Username string `json:"user" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Password string `json:"password" validate:"required" secret:"true"`
 	Name     string `json:"name" validate:"required"`
 }
 
 type JWT struct {
-	Key        string `json:"key" validate:"required"`
+	Key        string `json:"key" validate:"required" secret:"true"`
 	ExpireTime int    `json:"expire_time" validate:"required"` // in hours
 }
 
 type Redis struct {
 	Host     string `json:"host" validate:"required"`
 	Port     int    `json:"port" validate:"required"`
-	Password string `json:"password"`
+	Password string `json:"password" secret:"true"`
+}
+
+type OAuthProvider struct {
+	Issuer       string   `json:"issuer" validate:"required,url"`
+	ClientID     string   `json:"client_id" validate:"required"`
+	ClientSecret string   `json:"client_secret" secret:"true"`
+	RedirectURL  string   `json:"redirect_url" validate:"required,url"`
+	Scopes       []string `json:"scopes"`
+}
+
+type Moderation struct {
+	// Profiles reported by at least this many distinct users are hidden until reviewed, defaults to 3.
+	ReportThreshold int `json:"report_threshold" validate:"omitempty,min=1"`
+	// BannedWords are blocked in names, bios and chat messages, matching ignores case and common leetspeak.
+	BannedWords []string `json:"banned_words"`
+	// MessageRateLimit is how many chat messages a user may send per MessageRateWindow seconds before
+	// being flagged, twice as many are blocked. Defaults to 20 messages per 60 seconds.
+	MessageRateLimit  int `json:"message_rate_limit" validate:"omitempty,min=1"`
+	MessageRateWindow int `json:"message_rate_window" validate:"omitempty,min=1"`
+}
+
+type Matches struct {
+	// ExpiryDays is how long a mutual match may go without a message before it is expired, defaults to 7.
+	ExpiryDays int `json:"expiry_days" validate:"omitempty,min=1"`
+	// ReminderDays are the days after matching a "your move" reminder is sent, defaults to 1 and 3.
+	ReminderDays []int `json:"reminder_days" validate:"omitempty,dive,min=1"`
+}
+
+type Push struct {
+	// MaxAttempts is how often delivering a notification is tried before it is marked failed, defaults to 5.
+	MaxAttempts int `json:"max_attempts" validate:"omitempty,min=1"`
+	// FCM delivers to android devices, APNs to ios devices. Platforms left out only log the push.
+	FCM  *FCM  `json:"fcm"`
+	APNs *APNs `json:"apns"`
+}
+
+type Events struct {
+	// Transport carries events to async subscribers. "memory", the default, keeps them on this instance,
+	// "redis" shares them between instances through a Redis stream.
+	Transport string `json:"transport" validate:"omitempty,oneof=memory redis"`
+	// Stream and Group name the Redis stream and consumer group, default to "events" and "tinder".
+	Stream string `json:"stream"`
+	Group  string `json:"group"`
+}
+
+type Analytics struct {
+	// Enabled exports swipe, match, login and registration events as gzipped newline-delimited JSON.
+	Enabled bool `json:"enabled"`
+	// Directory receives the files unless S3 is set, its .spool folder holds batches until they are stored.
+	// Defaults to "analytics".
+	Directory string `json:"directory"`
+	S3        *S3    `json:"s3"`
+	// BatchSize and FlushInterval (seconds) bound how long events wait before they are written, default to
+	// 1000 events and 60 seconds. BufferSize is how many events may queue up before new ones are dropped,
+	// defaults to 10000.
+	BatchSize     int `json:"batch_size" validate:"omitempty,min=1"`
+	FlushInterval int `json:"flush_interval" validate:"omitempty,min=1"`
+	BufferSize    int `json:"buffer_size" validate:"omitempty,min=1"`
+}
+
+type Metrics struct {
+	// Port serves /metrics on a listener of its own, apart from the public API. 0 serves no metrics.
+	Port int `json:"port" validate:"omitempty,min=1,max=65535"`
+	// Token protects /metrics, scrapers send it as a bearer token. Empty leaves it to the network to keep
+	// Port private.
+	Token string `json:"token" secret:"true"`
+}
+
+type Tracing struct {
+	// Exporter sends spans to an OpenTelemetry collector with "otlp" or prints them with "stdout" for local
+	// runs, empty turns tracing off.
+	Exporter string `json:"exporter" validate:"omitempty,oneof=otlp stdout"`
+	// Endpoint is the host:port of the collector's OTLP/HTTP receiver, defaults to localhost:4318 or the
+	// OTEL_EXPORTER_OTLP_ENDPOINT variable. Insecure sends spans over plain HTTP.
+	Endpoint string `json:"endpoint"`
+	Insecure bool   `json:"insecure"`
+	// ServiceName names this process in traces, defaults to the name of the command.
+	ServiceName string `json:"service_name"`
+	// SampleRate is the percentage of new traces recorded, defaults to 100. Traces a caller already
+	// sampled are always recorded.
+	SampleRate int `json:"sample_rate" validate:"omitempty,min=1,max=100"`
+}
+
+type Log struct {
+	// Level is the most verbose level written, such as "info", defaults to the level of the command.
+	Level string `json:"level" validate:"omitempty,oneof=trace debug info warn error"`
+	// Format writes lines as "text" or as one "json" object each, defaults to text.
+	Format string `json:"format" validate:"omitempty,oneof=text json"`
+	// DebugSampleRate is the percentage of requests whose debug lines are written, defaults to 100. The
+	// lines of a request are kept or dropped together.
+	DebugSampleRate int `json:"debug_sample_rate" validate:"omitempty,min=1,max=100"`
+}
+
+type Health struct {
+	// Timeout (seconds) bounds every dependency check of /readyz, defaults to 2.
+	Timeout int `json:"timeout" validate:"omitempty,min=1"`
+	// DrainDelay (seconds) is how long the server keeps serving after a shutdown signal while /readyz
+	// reports draining, so load balancers stop sending requests first. Defaults to 5, 0 stops right away.
+	DrainDelay int `json:"drain_delay" validate:"omitempty,min=0"`
+}
+
+// S3 is any storage speaking the S3 API, objects are written path-style to Endpoint/Bucket/Prefix.
+type S3 struct {
+	Endpoint        string `json:"endpoint" validate:"required,url"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket" validate:"required"`
+	Prefix          string `json:"prefix"`
+	AccessKeyID     string `json:"access_key_id" validate:"required"`
+	SecretAccessKey string `json:"secret_access_key" validate:"required" secret:"true"`
+}
+
+type FCM struct {
+	ProjectID   string `json:"project_id" validate:"required"`
+	ClientEmail string `json:"client_email" validate:"required"`
+	PrivateKey  string `json:"private_key" validate:"required" secret:"true"` // PEM encoded service account key
+	TokenURL    string `json:"token_url" validate:"omitempty,url"`
+	Endpoint    string `json:"endpoint" validate:"omitempty,url"`
+}
+
+type APNs struct {
+	KeyID      string `json:"key_id" validate:"required"`
+	TeamID     string `json:"team_id" validate:"required"`
+	BundleID   string `json:"bundle_id" validate:"required"`
+	PrivateKey string `json:"private_key" validate:"required" secret:"true"` // PEM encoded .p8 signing key
+	Sandbox    bool   `json:"sandbox"`
+	Endpoint   string `json:"endpoint" validate:"omitempty,url"`
 }
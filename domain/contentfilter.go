@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+const (
+	FilterVerdictAllow = "allow"
+	FilterVerdictFlag  = "flag"
+	FilterVerdictBlock = "block"
+)
+
+const (
+	ContentKindName    = "name"
+	ContentKindBio     = "bio"
+	ContentKindMessage = "message"
+)
+
+// FilterContent is a piece of user generated text run through the content filter chain.
+type FilterContent struct {
+	UserID int64
+	Kind   string
+	Text   string
+}
+
+// FilterResult is the verdict of a single filter, Filter and Reason are empty when the content is allowed.
+type FilterResult struct {
+	Verdict string `json:"verdict"`
+	Filter  string `json:"filter,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+func (r FilterResult) Blocked() bool {
+	return r.Verdict == FilterVerdictBlock
+}
+
+// ContentFlag records content that was flagged or blocked so moderators can review it.
+type ContentFlag struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	UserID    int64     `gorm:"not null" json:"user_id"`
+	Kind      string    `gorm:"not null" json:"kind"`
+	Content   string    `gorm:"not null" json:"content"`
+	Verdict   string    `gorm:"not null" json:"verdict"`
+	Filter    string    `gorm:"not null" json:"filter"`
+	Reason    string    `gorm:"not null" json:"reason"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+}
+
+type ContentFlagFilter struct {
+	UserID  int64  `query:"user_id"`
+	Kind    string `query:"kind" validate:"omitempty,oneof=name bio message"`
+	Verdict string `query:"verdict" validate:"omitempty,oneof=flag block"`
+	Limit   int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset  int    `query:"offset" validate:"omitempty,min=0"`
+}
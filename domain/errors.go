@@ -0,0 +1,114 @@
+package domain
+
+import "errors"
+
+// Error kinds, every catalogued Error wraps one of them. The kind decides how a failure is reported to
+// clients, for example the HTTP status, so callers check it with errors.Is.
+var (
+	ErrInvalid       = errors.New("invalid request")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	ErrForbidden     = errors.New("forbidden")
+	// ErrNotFound is also returned by repositories when a write refers to or targets a row that does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is also returned by repositories when a write would duplicate a row that must be unique.
+	ErrAlreadyExists = errors.New("already exists")
+	ErrConflict      = errors.New("conflict")
+	ErrRateLimited   = errors.New("rate limited")
+)
+
+// Error is a failure the client can act on. Code is stable and machine readable, Message is meant for
+// people and Details carries optional context such as the offending field.
+type Error struct {
+	Code    string
+	Message string
+	Kind    error
+	Details map[string]string
+}
+
+func NewError(kind error, code string, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Kind:    kind,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Is matches errors of the same code, so a copy carrying details still matches its catalogue entry.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of the error carrying details.
+func (e *Error) WithDetails(details map[string]string) *Error {
+	err := *e
+	err.Details = details
+	return &err
+}
+
+// Accounts and sign in.
+var (
+	ErrUserNotFound            = NewError(ErrNotFound, "user_not_found", "user not found")
+	ErrUserBanned              = NewError(ErrForbidden, "user_banned", "user is banned")
+	ErrInvalidCredentials      = NewError(ErrUnauthorized, "invalid_credentials", "invalid email or password")
+	ErrEmailRegistered         = NewError(ErrAlreadyExists, "email_registered", "email already registered")
+	ErrPhoneRegistered         = NewError(ErrAlreadyExists, "phone_registered", "phone already registered")
+	ErrUnknownIdentityProvider = NewError(ErrNotFound, "unknown_identity_provider", "unknown identity provider")
+	ErrInvalidOAuthState       = NewError(ErrInvalid, "invalid_oauth_state", "invalid oauth state")
+	ErrEmailNotVerified        = NewError(ErrForbidden, "email_not_verified", "email not verified by identity provider")
+	ErrIdentityRejected        = NewError(ErrUnauthorized, "identity_rejected", "identity provider rejected the sign in")
+	ErrTokenRevoked            = NewError(ErrUnauthorized, "token_revoked", "token revoked")
+)
+
+// One-time passwords and two-factor authentication.
+var (
+	ErrOTPResendThrottled = NewError(ErrRateLimited, "otp_resend_throttled", "otp resend throttled")
+	ErrOTPQuotaExceeded   = NewError(ErrRateLimited, "otp_quota_exceeded", "otp send quota exceeded")
+	ErrOTPExpired         = NewError(ErrUnauthorized, "otp_expired", "otp expired or not requested")
+	ErrOTPTooManyAttempts = NewError(ErrRateLimited, "otp_too_many_attempts", "too many otp attempts")
+	ErrInvalidOTP         = NewError(ErrUnauthorized, "invalid_otp", "invalid otp")
+	ErrTOTPAlreadyEnabled = NewError(ErrConflict, "totp_already_enabled", "totp already enabled")
+	ErrTOTPNotEnrolled    = NewError(ErrConflict, "totp_not_enrolled", "totp not enrolled")
+	ErrTOTPNotEnabled     = NewError(ErrConflict, "totp_not_enabled", "totp not enabled")
+	ErrInvalidTOTPCode    = NewError(ErrInvalid, "invalid_totp_code", "invalid totp code")
+	ErrInvalidMFAToken    = NewError(ErrUnauthorized, "invalid_mfa_token", "invalid mfa token")
+	ErrInvalidMFACode     = NewError(ErrUnauthorized, "invalid_mfa_code", "invalid mfa code")
+	ErrMFATooManyAttempts = NewError(ErrRateLimited, "mfa_too_many_attempts", "too many mfa attempts")
+)
+
+// Profiles, swipes and matches.
+var (
+	ErrProfileNotFound        = NewError(ErrNotFound, "profile_not_found", "profile not found")
+	ErrInventoryNotFound      = NewError(ErrNotFound, "inventory_not_found", "inventory not found")
+	ErrSwipeSelf              = NewError(ErrInvalid, "swipe_self", "cannot swipe yourself")
+	ErrInsufficientSwipes     = NewError(ErrQuotaExceeded, "insufficient_swipes", "insufficient swipes")
+	ErrInsufficientLikes      = NewError(ErrQuotaExceeded, "insufficient_likes", "insufficient likes")
+	ErrInsufficientSuperLikes = NewError(ErrQuotaExceeded, "insufficient_super_likes", "insufficient super likes")
+	ErrAlreadyMatched         = NewError(ErrAlreadyExists, "already_matched", "already matched")
+	ErrNotMatched             = NewError(ErrNotFound, "not_matched", "not matched")
+	ErrContentRejected        = NewError(ErrInvalid, "content_rejected", "content rejected")
+)
+
+// Chat, notifications, reports, administration and settings.
+var (
+	ErrChatSelf             = NewError(ErrInvalid, "chat_self", "cannot chat with yourself")
+	ErrConversationNotFound = NewError(ErrNotFound, "conversation_not_found", "conversation not found")
+	ErrMatchNotActive       = NewError(ErrConflict, "match_not_active", "match is not active")
+	ErrNotificationNotFound = NewError(ErrNotFound, "notification_not_found", "notification not found")
+	ErrReportSelf           = NewError(ErrInvalid, "report_self", "cannot report yourself")
+	ErrReportNotFound       = NewError(ErrNotFound, "report_not_found", "report not found")
+	ErrReportClosed         = NewError(ErrConflict, "report_closed", "report already closed")
+	ErrBanSelf              = NewError(ErrInvalid, "ban_self", "cannot ban yourself")
+	ErrNothingToAdjust      = NewError(ErrInvalid, "nothing_to_adjust", "nothing to adjust")
+	ErrSettingNotFound      = NewError(ErrNotFound, "setting_not_found", "setting not found")
+	ErrInvalidSettingValue  = NewError(ErrInvalid, "invalid_setting_value", "invalid setting value")
+)
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/goccy/go-json"
+	"time"
+)
+
+const (
+	EventUserRegistered = "user.registered"
+	EventUserLoggedIn   = "user.logged_in"
+	EventProfileUpdated = "profile.updated"
+	EventSwiped         = "swiped"
+	EventMatched        = "matched"
+	EventUnmatched      = "unmatched"
+	// EventExperimentExposed is published whenever a participant is served a variant of an experiment.
+	EventExperimentExposed = "experiment.exposed"
+)
+
+const (
+	SwipeActionLike      = "like"
+	SwipeActionSuperLike = "super_like"
+	SwipeActionDislike   = "dislike"
+)
+
+const (
+	UnmatchReasonUser    = "user"
+	UnmatchReasonExpired = "expired"
+)
+
+// Event is something that happened in the domain, published on the event bus under its EventName.
+type Event interface {
+	EventName() string
+}
+
+type UserRegisteredEvent struct {
+	UserID int64 `json:"user_id"`
+	// Method is email, phone or the name of the identity provider.
+	Method string `json:"method"`
+}
+
+// UserLoggedInEvent is published once a user got an access token, MFA is set when a second factor was checked.
+type UserLoggedInEvent struct {
+	UserID int64 `json:"user_id"`
+	MFA    bool  `json:"mfa"`
+}
+
+type ProfileUpdatedEvent struct {
+	UserID int64 `json:"user_id"`
+}
+
+// SwipedEvent is published for every swipe, Mutual is set when it completed a match.
+type SwipedEvent struct {
+	UserID       int64  `json:"user_id"`
+	TargetUserID int64  `json:"target_user_id"`
+	UserName     string `json:"user_name"`
+	Action       string `json:"action"`
+	Mutual       bool   `json:"mutual"`
+}
+
+// MatchedEvent is published once per match, UserID is the one whose swipe completed it.
+type MatchedEvent struct {
+	MatchID      int64  `json:"match_id"`
+	UserID       int64  `json:"user_id"`
+	TargetUserID int64  `json:"target_user_id"`
+	UserName     string `json:"user_name"`
+}
+
+type UnmatchedEvent struct {
+	UserID       int64  `json:"user_id"`
+	TargetUserID int64  `json:"target_user_id"`
+	Reason       string `json:"reason"`
+}
+
+type ExperimentExposedEvent struct {
+	UserID     int64  `json:"user_id"`
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+}
+
+func (UserRegisteredEvent) EventName() string { return EventUserRegistered }
+func (UserLoggedInEvent) EventName() string   { return EventUserLoggedIn }
+func (ProfileUpdatedEvent) EventName() string { return EventProfileUpdated }
+func (SwipedEvent) EventName() string         { return EventSwiped }
+func (MatchedEvent) EventName() string        { return EventMatched }
+func (UnmatchedEvent) EventName() string      { return EventUnmatched }
+
+func (ExperimentExposedEvent) EventName() string { return EventExperimentExposed }
+
+// EventEnvelope is how an event travels to async subscribers.
+type EventEnvelope struct {
+	Name       string          `json:"name"`
+	RequestID  string          `json:"request_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+	// Trace carries the trace of the publisher, so async subscribers continue it.
+	Trace map[string]string `json:"trace,omitempty"`
+}
+
+// DecodeEvent turns the payload of an envelope back into its typed event.
+func DecodeEvent(envelope EventEnvelope) (Event, error) {
+	var event Event
+	switch envelope.Name {
+	case EventUserRegistered:
+		event = &UserRegisteredEvent{}
+	case EventUserLoggedIn:
+		event = &UserLoggedInEvent{}
+	case EventProfileUpdated:
+		event = &ProfileUpdatedEvent{}
+	case EventSwiped:
+		event = &SwipedEvent{}
+	case EventMatched:
+		event = &MatchedEvent{}
+	case EventUnmatched:
+		event = &UnmatchedEvent{}
+	case EventExperimentExposed:
+		event = &ExperimentExposedEvent{}
+	default:
+		return nil, fmt.Errorf("unknown event %q", envelope.Name)
+	}
+
+	if err := json.Unmarshal(envelope.Payload, event); err != nil {
+		return nil, err
+	}
+
+	// Subscribers switch on the value types that are published.
+	switch e := event.(type) {
+	case *UserRegisteredEvent:
+		return *e, nil
+	case *UserLoggedInEvent:
+		return *e, nil
+	case *ProfileUpdatedEvent:
+		return *e, nil
+	case *SwipedEvent:
+		return *e, nil
+	case *MatchedEvent:
+		return *e, nil
+	case *ExperimentExposedEvent:
+		return *e, nil
+	default:
+		return *event.(*UnmatchedEvent), nil
+	}
+}
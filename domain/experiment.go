@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ExperimentRecommendationRanking tries ordering recommendations by the newest profiles instead of activity.
+	ExperimentRecommendationRanking = "recommendation_ranking"
+	// FlagFreeDislikes stops dislikes from spending a swipe.
+	FlagFreeDislikes = "free_dislikes"
+)
+
+const (
+	VariantControl       = "control"
+	VariantRankingNewest = "newest"
+	// Flags are experiments with an off and an on variant.
+	VariantOff = "off"
+	VariantOn  = "on"
+)
+
+// ExperimentSubjectKey holds the ExperimentSubject of a request in its context.
+const ExperimentSubjectKey = "experiment_subject"
+
+const (
+	ExperimentAttributePlatform = "platform"
+	ExperimentAttributeCountry  = "country"
+	ExperimentAttributePlan     = "plan"
+)
+
+// PlanFree is the plan of every user until paid plans exist.
+const PlanFree = "free"
+
+// experimentVariants lists the variants the code knows for every experiment, the first one is the control
+// served to everyone outside the experiment.
+var experimentVariants = map[string][]string{
+	ExperimentRecommendationRanking: {VariantControl, VariantRankingNewest},
+	FlagFreeDislikes:                {VariantOff, VariantOn},
+}
+
+// bucketCount is the resolution of the rollout, a rollout percentage covers Rollout*100 buckets.
+const bucketCount = 10000
+
+// ExperimentSubject is who an experiment is evaluated for. Attributes are matched against targeting rules.
+type ExperimentSubject struct {
+	UserID     int64
+	Attributes map[string]string
+}
+
+// ExperimentConfig decides who takes part in an experiment and how participants are split. It is stored as
+// the JSON value of the experiment's runtime setting, experiments without one are off.
+type ExperimentConfig struct {
+	Enabled bool `json:"enabled"`
+	// Rollout is the percentage of targeted users that take part, the others get the control variant.
+	Rollout int `json:"rollout"`
+	// Weights split participants between variants, variants left out get none. Defaults to an even split.
+	Weights map[string]int `json:"weights,omitempty"`
+	// Targeting limits the experiment to users whose attributes match one of the listed values, for example
+	// {"platform": ["ios"], "country": ["ID", "SG"]}. Attributes not listed are not checked.
+	Targeting map[string][]string `json:"targeting,omitempty"`
+}
+
+// ExperimentSubjectFrom returns the subject the request context carries, background jobs have none.
+func ExperimentSubjectFrom(ctx context.Context) (ExperimentSubject, bool) {
+	subject, ok := ctx.Value(ExperimentSubjectKey).(ExperimentSubject)
+	return subject, ok
+}
+
+const experimentSettingPrefix = "experiment."
+
+// ExperimentSetting is the key of the runtime setting configuring an experiment.
+func ExperimentSetting(experiment string) string {
+	return experimentSettingPrefix + experiment
+}
+
+func ExperimentVariants(experiment string) []string {
+	return experimentVariants[experiment]
+}
+
+// ParseExperimentConfig reads a stored config and checks it against the variants of the experiment.
+func ParseExperimentConfig(experiment string, value string) (ExperimentConfig, error) {
+	var config ExperimentConfig
+	if err := json.Unmarshal([]byte(value), &config); err != nil {
+		return config, fmt.Errorf("must be an experiment config object")
+	}
+
+	if config.Rollout < 0 || config.Rollout > 100 {
+		return config, fmt.Errorf("rollout must be between 0 and 100")
+	}
+
+	variants := ExperimentVariants(experiment)
+	total := 0
+	for variant, weight := range config.Weights {
+		if !contains(variants, variant) {
+			return config, fmt.Errorf("unknown variant %s, expected one of %s", variant, strings.Join(variants, ", "))
+		}
+		if weight < 0 {
+			return config, fmt.Errorf("weight of %s must not be negative", variant)
+		}
+		total += weight
+	}
+
+	if len(config.Weights) > 0 && total == 0 {
+		return config, fmt.Errorf("weights must not all be zero")
+	}
+
+	return config, nil
+}
+
+// Assign picks the variant of subject. Assignment is deterministic: the same user always lands in the same
+// bucket of an experiment, so raising the rollout only adds users and never moves the ones already in.
+// participating is false for users outside the experiment, they get the control variant.
+func (c ExperimentConfig) Assign(experiment string, subject ExperimentSubject) (variant string, participating bool) {
+	variants := ExperimentVariants(experiment)
+	if len(variants) == 0 {
+		return "", false
+	}
+
+	control := variants[0]
+	if !c.Enabled || subject.UserID == 0 || !c.targets(subject) {
+		return control, false
+	}
+
+	if experimentHash(experiment, "rollout", subject.UserID)%bucketCount >= uint32(c.Rollout*bucketCount/100) {
+		return control, false
+	}
+
+	// A second, independent hash splits participants, so who takes part does not decide their variant.
+	total := 0
+	for _, v := range variants {
+		total += c.weight(v)
+	}
+
+	point := int(experimentHash(experiment, "variant", subject.UserID) % uint32(total))
+	for _, v := range variants {
+		if point < c.weight(v) {
+			return v, true
+		}
+		point -= c.weight(v)
+	}
+
+	return control, true
+}
+
+func (c ExperimentConfig) weight(variant string) int {
+	if len(c.Weights) == 0 {
+		return 1
+	}
+
+	return c.Weights[variant]
+}
+
+func (c ExperimentConfig) targets(subject ExperimentSubject) bool {
+	for attribute, allowed := range c.Targeting {
+		value := subject.Attributes[attribute]
+		if !containsFold(allowed, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func experimentHash(experiment string, purpose string, userID int64) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(experiment + ":" + purpose + ":" + strconv.FormatInt(userID, 10)))
+	return h.Sum32()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
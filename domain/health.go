@@ -0,0 +1,30 @@
+package domain
+
+const (
+	HealthStatusUp       = "up"
+	HealthStatusDown     = "down"
+	HealthStatusDraining = "draining"
+)
+
+// Readiness tells whether the instance can serve requests, it is ready while it is not shutting down and
+// every dependency answers.
+type Readiness struct {
+	Ready        bool                        `json:"ready"`
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyHealth `json:"dependencies,omitempty"`
+}
+
+type DependencyHealth struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BuildInfo identifies the binary serving a request. Version and Commit are set at build time with
+// -ldflags, Commit falls back to the revision go build recorded.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+type UserIdentity struct {
+	ID        int64      `gorm:"primaryKey" json:"id"`
+	UserID    int64      `gorm:"not null" json:"user_id"`
+	Provider  string     `gorm:"not null" json:"provider"`
+	Subject   string     `gorm:"not null" json:"subject"`
+	Email     string     `json:"email"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
+	DeletedAt *time.Time `gorm:"default:null" json:"deleted_at,omitempty"`
+}
+
+type CreateIdentityRequest struct {
+	UserID   int64  `json:"user_id" validate:"required"`
+	Provider string `json:"provider" validate:"required"`
+	Subject  string `json:"subject" validate:"required"`
+	Email    string `json:"email"`
+}
+
+// IdentityClaims is the subset of a validated ID token the auth service cares about.
+type IdentityClaims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// OAuthState is stored in redis between the authorization redirect and the callback.
+type OAuthState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
+
+type OAuthURLResponse struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
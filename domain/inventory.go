@@ -14,6 +14,7 @@ type Inventory struct {
 
 type CreateInventoryRequest struct {
 	UserID     int64 `json:"user_id"`
+	Swipes     int64 `json:"swipes"`
 	Likes      int64 `json:"likes"`
 	SuperLikes int64 `json:"super_likes"`
 }
@@ -21,3 +21,29 @@ type MatchResponse struct {
 	UserID       int64 `json:"user_id"`
 	TargetUserID int64 `json:"target_user_id"`
 }
+
+// StaleMatch is a mutual match nobody has written in yet, UserAID is the lower user id of the pair.
+type StaleMatch struct {
+	UserAID   int64     `json:"user_a_id"`
+	UserBID   int64     `json:"user_b_id"`
+	UserAName string    `json:"user_a_name"`
+	UserBName string    `json:"user_b_name"`
+	MatchedAt time.Time `json:"matched_at"`
+}
+
+type StaleMatchFilter struct {
+	MatchedBefore time.Time
+	// MatchedAfter is ignored when zero.
+	MatchedAfter time.Time
+	// WithoutReminder leaves out matches that already got the reminder for this stage, ignored when zero.
+	WithoutReminder int
+	Limit           int
+}
+
+type MatchReminder struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	UserAID   int64     `gorm:"not null" json:"user_a_id"`
+	UserBID   int64     `gorm:"not null" json:"user_b_id"`
+	Stage     int       `gorm:"not null" json:"stage"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+}
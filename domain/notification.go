@@ -2,25 +2,72 @@ package domain
 
 import "time"
 
+const (
+	NotificationTypeMatch     = "match"
+	NotificationTypeSuperLike = "super_like"
+	NotificationTypeMessage   = "message"
+	NotificationTypeSystem    = "system"
+	NotificationTypeMarketing = "marketing"
+)
+
+const (
+	NotificationTemplateMatchNew          = "match.new"
+	NotificationTemplateMatchReminder     = "match.reminder"
+	NotificationTemplateSuperLikeReceived = "super_like.received"
+	NotificationTemplateMessageNew        = "message.new"
+	NotificationTemplateSystemText        = "system.text"
+)
+
+// NotificationPayload holds the structured data a notification template is
+// rendered from. ActorName is a snapshot taken when the notification is
+// created so a renamed or purged actor does not break older rows.
+type NotificationPayload struct {
+	ActorUserID    int64  `json:"actor_user_id,omitempty"`
+	ActorName      string `json:"actor_name,omitempty"`
+	MatchID        int64  `json:"match_id,omitempty"`
+	ConversationID int64  `json:"conversation_id,omitempty"`
+	Days           int    `json:"days,omitempty"`
+	Text           string `json:"text,omitempty"`
+}
+
 type Notification struct {
-	ID        int64      `json:"id"`
-	UserID    int64      `json:"user_id"`
-	Message   string     `json:"message"`
-	IsRead    bool       `json:"is_read"`
-	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
-	UpdatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
-	DeletedAt *time.Time `gorm:"default:null" json:"deleted_at,omitempty"`
+	ID          int64               `json:"id"`
+	UserID      int64               `json:"user_id"`
+	Type        string              `json:"type"`
+	TemplateKey string              `json:"template_key"`
+	Payload     NotificationPayload `gorm:"type:jsonb;serializer:json" json:"payload"`
+	Message     string              `gorm:"-" json:"message"`
+	IsRead      bool                `json:"is_read"`
+	OutboxID    *int64              `gorm:"default:null" json:"-"`
+	CreatedAt   time.Time           `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+	UpdatedAt   time.Time           `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
+	DeletedAt   *time.Time          `gorm:"default:null" json:"deleted_at,omitempty"`
 }
 
 type NotificationRequest struct {
-	UserID  int64  `json:"user_id" validate:"required"`
-	Message string `json:"message" validate:"required"`
+	UserID      int64               `json:"user_id" validate:"required"`
+	Type        string              `json:"type" validate:"required,oneof=match super_like message system marketing"`
+	TemplateKey string              `json:"template_key" validate:"required"`
+	Payload     NotificationPayload `json:"payload"`
+	// OutboxID is the outbox message the notification is stored for, a message published twice only
+	// stores it once. Zero for notifications stored directly.
+	OutboxID int64 `json:"-"`
+}
+
+type NotificationFilter struct {
+	Locale string `query:"locale"`
+	Unread bool   `query:"unread"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `query:"offset" validate:"omitempty,min=0"`
 }
 
 type NotificationResponse struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	Message   string    `json:"message"`
-	IsRead    bool      `json:"is_read"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int64               `json:"id"`
+	UserID      int64               `json:"user_id"`
+	Type        string              `json:"type"`
+	TemplateKey string              `json:"template_key"`
+	Payload     NotificationPayload `json:"payload"`
+	Message     string              `json:"message"`
+	IsRead      bool                `json:"is_read"`
+	CreatedAt   time.Time           `json:"created_at"`
 }
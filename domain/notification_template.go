@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const DefaultLocale = "en"
+
+type notificationTemplate func(p NotificationPayload) string
+
+// notificationTemplates maps locale -> template key -> renderer. Every key
+// must exist in DefaultLocale, other locales may be partial and fall back.
+var notificationTemplates = map[string]map[string]notificationTemplate{
+	"en": {
+		NotificationTemplateMatchNew: func(p NotificationPayload) string {
+			return fmt.Sprintf("You have a new match with %s", p.ActorName)
+		},
+		NotificationTemplateMatchReminder: func(p NotificationPayload) string {
+			switch p.Days {
+			case 0:
+				return fmt.Sprintf("You matched with %s today — say hi", p.ActorName)
+			case 1:
+				return fmt.Sprintf("You matched with %s 1 day ago — say hi", p.ActorName)
+			}
+			return fmt.Sprintf("You matched with %s %d days ago — say hi", p.ActorName, p.Days)
+		},
+		NotificationTemplateSuperLikeReceived: func(p NotificationPayload) string {
+			return fmt.Sprintf("%s super liked you", p.ActorName)
+		},
+		NotificationTemplateMessageNew: func(p NotificationPayload) string {
+			return fmt.Sprintf("New message from %s", p.ActorName)
+		},
+		NotificationTemplateSystemText: func(p NotificationPayload) string {
+			return p.Text
+		},
+	},
+	"id": {
+		NotificationTemplateMatchNew: func(p NotificationPayload) string {
+			return fmt.Sprintf("Kamu punya match baru dengan %s", p.ActorName)
+		},
+		NotificationTemplateMatchReminder: func(p NotificationPayload) string {
+			if p.Days == 0 {
+				return fmt.Sprintf("Kamu match dengan %s hari ini — sapa dia", p.ActorName)
+			}
+			return fmt.Sprintf("Kamu match dengan %s %d hari yang lalu — sapa dia", p.ActorName, p.Days)
+		},
+		NotificationTemplateSuperLikeReceived: func(p NotificationPayload) string {
+			return fmt.Sprintf("%s memberimu super like", p.ActorName)
+		},
+		NotificationTemplateMessageNew: func(p NotificationPayload) string {
+			return fmt.Sprintf("Pesan baru dari %s", p.ActorName)
+		},
+	},
+}
+
+// SupportedLocales lists the locales notifications can be rendered in, with
+// DefaultLocale first.
+func SupportedLocales() []string {
+	var others []string
+	for locale := range notificationTemplates {
+		if locale != DefaultLocale {
+			others = append(others, locale)
+		}
+	}
+	sort.Strings(others)
+
+	return append([]string{DefaultLocale}, others...)
+}
+
+// NormalizeLocale reduces a tag such as "id-ID" to its primary language and
+// falls back to DefaultLocale when it is not supported.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locale = locale[:i]
+	}
+
+	if _, ok := notificationTemplates[locale]; !ok {
+		return DefaultLocale
+	}
+
+	return locale
+}
+
+// RenderNotification renders the notification text for the given locale.
+// Unknown template keys render the raw payload text so a newer writer never
+// produces a blank notification for an older reader.
+func RenderNotification(n Notification, locale string) string {
+	if tmpl, ok := notificationTemplates[NormalizeLocale(locale)][n.TemplateKey]; ok {
+		return tmpl(n.Payload)
+	}
+
+	if tmpl, ok := notificationTemplates[DefaultLocale][n.TemplateKey]; ok {
+		return tmpl(n.Payload)
+	}
+
+	return n.Payload.Text
+}
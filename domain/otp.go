@@ -0,0 +1,10 @@
+package domain
+
+type OTPRequest struct {
+	Phone string `json:"phone" validate:"required,e164"`
+}
+
+type OTPResponse struct {
+	ExpiresIn int `json:"expires_in"` // in seconds
+	ResendIn  int `json:"resend_in"`  // in seconds
+}
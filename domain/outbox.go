@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+const (
+	// OutboxKindNotification carries a NotificationRequest to store once the writing transaction committed.
+	OutboxKindNotification = "notification"
+)
+
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+	OutboxStatusFailed    = "failed"
+)
+
+// OutboxMessage is a side effect recorded in the same transaction as the
+// write that caused it and published by the relay after commit.
+type OutboxMessage struct {
+	ID          int64      `json:"id"`
+	Kind        string     `json:"kind"`
+	Payload     string     `gorm:"type:jsonb" json:"payload"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	AvailableAt time.Time  `json:"available_at"`
+	LastError   string     `json:"last_error"`
+	CreatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+	PublishedAt *time.Time `gorm:"default:null" json:"published_at,omitempty"`
+}
+
+type OutboxUpdate struct {
+	Status      string
+	Attempts    int
+	AvailableAt time.Time
+	LastError   string
+}
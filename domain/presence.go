@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+const (
+	// PresenceOnlineWindow is how long after the last request or heartbeat a user still counts as online.
+	PresenceOnlineWindow = 5 * time.Minute
+	// PresenceRetention is how long the last active timestamp is kept in Redis.
+	PresenceRetention = 30 * 24 * time.Hour
+)
+
+type Presence struct {
+	Online       bool       `json:"online"`
+	LastActiveAt *time.Time `json:"last_active_at,omitempty"`
+}
+
+// NewPresence turns a last active timestamp into a presence, a zero time means we have not seen the user recently.
+func NewPresence(lastActiveAt time.Time) Presence {
+	if lastActiveAt.IsZero() {
+		return Presence{}
+	}
+
+	return Presence{
+		Online:       time.Since(lastActiveAt) <= PresenceOnlineWindow,
+		LastActiveAt: &lastActiveAt,
+	}
+}
+
+type PrivacyRequest struct {
+	ShowLastActive *bool `json:"show_last_active" validate:"required"`
+}
+
+type TypingRequest struct {
+	Typing bool `json:"typing"`
+}
+
+type TypingEvent struct {
+	ConversationID int64 `json:"conversation_id"`
+	UserID         int64 `json:"user_id"`
+	Typing         bool  `json:"typing"`
+}
@@ -3,19 +3,23 @@ package domain
 import "time"
 
 type Profile struct {
-	ID         int64      `gorm:"primaryKey" json:"id" faker:"-"`
-	UserID     int64      `gorm:"not null" json:"user_id" faker:"-"`
-	Name       string     `gorm:"not null" json:"name" faker:"name"`
-	Pic        string     `json:"pic" faker:"url"`
-	Gender     string     `json:"gender" faker:"-"`
-	InterestIn string     `json:"interest_in" faker:"-"`
-	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at" faker:"-"`
-	UpdatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at" faker:"-"`
-	DeletedAt  *time.Time `gorm:"default:null" json:"deleted_at,omitempty" faker:"-"`
+	ID             int64      `gorm:"primaryKey" json:"id" faker:"-"`
+	UserID         int64      `gorm:"not null" json:"user_id" faker:"-"`
+	Name           string     `gorm:"not null" json:"name" faker:"name"`
+	Pic            string     `json:"pic" faker:"url"`
+	Bio            string     `json:"bio" faker:"sentence"`
+	Gender         string     `json:"gender" faker:"-"`
+	InterestIn     string     `json:"interest_in" faker:"-"`
+	HiddenAt       *time.Time `gorm:"default:null" json:"hidden_at,omitempty" faker:"-"`
+	ShowLastActive bool       `gorm:"default:true" json:"show_last_active" faker:"-"`
+	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at" faker:"-"`
+	UpdatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at" faker:"-"`
+	DeletedAt      *time.Time `gorm:"default:null" json:"deleted_at,omitempty" faker:"-"`
 }
 
 type ProfileRequest struct {
 	Name       string `json:"name" validate:"required"`
+	Bio        string `json:"bio" validate:"max=500"`
 	Gender     string `json:"gender" validate:"required"`
 	InterestIn string `json:"interest_in" validate:"required"`
 }
@@ -25,9 +29,12 @@ type UpdateProfilePicRequest struct {
 }
 
 type ProfileResponse struct {
-	UserID     int64  `json:"user_id"`
-	Name       string `json:"name"`
-	Pic        string `json:"pic"`
-	Gender     string `json:"gender"`
-	InterestIn string `json:"interest_in"`
+	UserID       int64      `json:"user_id"`
+	Name         string     `json:"name"`
+	Pic          string     `json:"pic"`
+	Bio          string     `json:"bio"`
+	Gender       string     `json:"gender"`
+	InterestIn   string     `json:"interest_in"`
+	Online       bool       `json:"online,omitempty"`
+	LastActiveAt *time.Time `json:"last_active_at,omitempty"`
 }
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	PushPlatformAndroid = "android" // delivered through FCM
+	PushPlatformIOS     = "ios"     // delivered through APNs
+)
+
+const (
+	PushOutboxStatusPending = "pending"
+	PushOutboxStatusSent    = "sent"
+	PushOutboxStatusSkipped = "skipped"
+	PushOutboxStatusFailed  = "failed"
+)
+
+// ErrInvalidPushToken is returned by push senders when the gateway reports the device token as
+// unregistered or malformed, the token should be dropped instead of retried.
+var ErrInvalidPushToken = errors.New("invalid push token")
+
+type DeviceToken struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Platform  string    `json:"platform"`
+	Token     string    `json:"token"`
+	Locale    string    `json:"locale"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
+}
+
+type DeviceTokenRequest struct {
+	Platform string `json:"platform" validate:"required,oneof=android ios"`
+	Token    string `json:"token" validate:"required,max=4096"`
+	Locale   string `json:"locale" validate:"omitempty,max=35"`
+}
+
+type NotificationPreferences struct {
+	UserID     int64     `gorm:"primaryKey" json:"-"`
+	Matches    bool      `json:"matches"`
+	Messages   bool      `json:"messages"`
+	SuperLikes bool      `json:"super_likes"`
+	Marketing  bool      `json:"marketing"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
+}
+
+// DefaultNotificationPreferences applies to users who never changed them, everything but marketing is on.
+func DefaultNotificationPreferences(userID int64) NotificationPreferences {
+	return NotificationPreferences{
+		UserID:     userID,
+		Matches:    true,
+		Messages:   true,
+		SuperLikes: true,
+	}
+}
+
+// Allows tells whether a notification of the given type may be pushed. System notifications are
+// always pushed, they cover account and safety notices.
+func (p NotificationPreferences) Allows(notificationType string) bool {
+	switch notificationType {
+	case NotificationTypeMatch:
+		return p.Matches
+	case NotificationTypeMessage:
+		return p.Messages
+	case NotificationTypeSuperLike:
+		return p.SuperLikes
+	case NotificationTypeMarketing:
+		return p.Marketing
+	}
+
+	return true
+}
+
+type NotificationPreferencesRequest struct {
+	Matches    *bool `json:"matches"`
+	Messages   *bool `json:"messages"`
+	SuperLikes *bool `json:"super_likes"`
+	Marketing  *bool `json:"marketing"`
+}
+
+type PushMessage struct {
+	Platform string            `json:"platform"`
+	Token    string            `json:"token"`
+	Body     string            `json:"body"`
+	Data     map[string]string `json:"data,omitempty"`
+	// CollapseKey lets the gateway replace an undelivered push with a newer one from the same thread.
+	CollapseKey string `json:"collapse_key,omitempty"`
+}
+
+type PushOutbox struct {
+	ID             int64     `json:"id"`
+	NotificationID int64     `json:"notification_id"`
+	UserID         int64     `json:"user_id"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+	LastError      string    `json:"last_error"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
+}
+
+type PushOutboxUpdate struct {
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
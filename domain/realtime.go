@@ -0,0 +1,20 @@
+package domain
+
+const (
+	RealtimeEventMessage = "message"
+	RealtimeEventReceipt = "receipt"
+	RealtimeEventTyping  = "typing"
+)
+
+// RealtimeEvent is a frame pushed to connected clients over WebSocket.
+type RealtimeEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// RealtimeCommand is a frame sent by clients over WebSocket, only typing is understood for now.
+type RealtimeCommand struct {
+	Type           string `json:"type"`
+	ConversationID int64  `json:"conversation_id"`
+	Typing         bool   `json:"typing"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+const (
+	ReportReasonSpam                 = "spam"
+	ReportReasonHarassment           = "harassment"
+	ReportReasonFakeProfile          = "fake_profile"
+	ReportReasonInappropriateContent = "inappropriate_content"
+	ReportReasonUnderage             = "underage"
+	ReportReasonOther                = "other"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusTriaged   = "triaged"
+	ReportStatusActioned  = "actioned"
+	ReportStatusDismissed = "dismissed"
+)
+
+type Report struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	ReporterID     int64      `gorm:"not null" json:"reporter_id"`
+	ReportedUserID int64      `gorm:"not null" json:"reported_user_id"`
+	Reason         string     `gorm:"not null" json:"reason"`
+	Description    string     `json:"description,omitempty"`
+	PhotoRef       string     `gorm:"default:null" json:"photo_ref,omitempty"`
+	Status         string     `gorm:"not null;default:open" json:"status"`
+	ReviewedBy     int64      `gorm:"default:null" json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `gorm:"default:null" json:"reviewed_at,omitempty"`
+	ReviewNote     string     `gorm:"default:null" json:"review_note,omitempty"`
+	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at"`
+}
+
+type ReportRequest struct {
+	ReportedUserID int64  `json:"reported_user_id" validate:"required"`
+	Reason         string `json:"reason" validate:"required,oneof=spam harassment fake_profile inappropriate_content underage other"`
+	Description    string `json:"description" validate:"required_if=Reason other,max=1000"`
+	PhotoRef       string `json:"photo_ref" validate:"omitempty,max=255"`
+}
+
+type ReportFilter struct {
+	Status         string `query:"status" validate:"omitempty,oneof=open triaged actioned dismissed"`
+	ReportedUserID int64  `query:"reported_user_id"`
+	Limit          int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset         int    `query:"offset" validate:"omitempty,min=0"`
+}
+
+type ReviewReportRequest struct {
+	Status string `json:"status" validate:"required,oneof=triaged actioned dismissed"`
+	Note   string `json:"note" validate:"max=1000"`
+}
+
+type UserBlock struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	BlockerID int64     `gorm:"not null" json:"blocker_id"`
+	BlockedID int64     `gorm:"not null" json:"blocked_id"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SettingTypeInt    = "int"
+	SettingTypeBool   = "bool"
+	SettingTypeString = "string"
+	// SettingTypeExperiment holds an ExperimentConfig as JSON.
+	SettingTypeExperiment = "experiment"
+)
+
+const (
+	SettingRecommendationPageSize       = "recommendation.page_size"
+	SettingRecommendationFrozenTTL      = "recommendation.frozen_ttl"
+	SettingRecommendationRankByActivity = "recommendation.rank_by_activity"
+	SettingQuotaSwipes                  = "quota.swipes"
+	SettingQuotaLikes                   = "quota.likes"
+	SettingQuotaSuperLikes              = "quota.super_likes"
+)
+
+// SettingDefinition describes a runtime setting. Values are stored as text, Min and Max bound int settings.
+type SettingDefinition struct {
+	Key         string
+	Type        string
+	Default     string
+	Min         int
+	Max         int
+	Description string
+}
+
+// Setting is a runtime setting with its current value. UpdatedBy and UpdatedAt stay empty while the
+// setting has its default.
+type Setting struct {
+	Key         string     `gorm:"primaryKey" json:"key"`
+	Value       string     `json:"value"`
+	UpdatedBy   *int64     `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Type        string     `gorm:"-" json:"type"`
+	Default     string     `gorm:"-" json:"default"`
+	Description string     `gorm:"-" json:"description"`
+}
+
+type UpdateSettingRequest struct {
+	// Value is the new value as text, an empty string resets the setting to its default.
+	Value string `json:"value" validate:"max=2000"`
+}
+
+var settingDefinitions = buildSettingDefinitions()
+
+func buildSettingDefinitions() map[string]SettingDefinition {
+	definitions := []SettingDefinition{
+		{
+			Key:         SettingRecommendationPageSize,
+			Type:        SettingTypeInt,
+			Default:     "10",
+			Min:         1,
+			Max:         100,
+			Description: "profiles returned per recommendation request",
+		},
+		{
+			Key:         SettingRecommendationFrozenTTL,
+			Type:        SettingTypeInt,
+			Default:     "86400",
+			Min:         60,
+			Max:         60 * 60 * 24 * 30,
+			Description: "seconds a recommended profile is kept out of later recommendations",
+		},
+		{
+			Key:         SettingRecommendationRankByActivity,
+			Type:        SettingTypeBool,
+			Default:     "true",
+			Description: "show recently active users first",
+		},
+		{
+			Key:         SettingQuotaSwipes,
+			Type:        SettingTypeInt,
+			Default:     "10",
+			Max:         10000,
+			Description: "swipes given to a new account",
+		},
+		{
+			Key:         SettingQuotaLikes,
+			Type:        SettingTypeInt,
+			Default:     "10",
+			Max:         10000,
+			Description: "likes given to a new account",
+		},
+		{
+			Key:         SettingQuotaSuperLikes,
+			Type:        SettingTypeInt,
+			Default:     "1",
+			Max:         10000,
+			Description: "super likes given to a new account",
+		},
+	}
+
+	for locale, templates := range notificationTemplates {
+		for templateKey := range templates {
+			if templateKey == NotificationTemplateSystemText {
+				continue
+			}
+
+			definitions = append(definitions, SettingDefinition{
+				Key:  NotificationTemplateSetting(locale, templateKey),
+				Type: SettingTypeString,
+				Description: fmt.Sprintf("replaces the built-in %s text in %s, {actor_name}, {days} and {text} "+
+					"are filled in, empty uses the built-in text", templateKey, locale),
+			})
+		}
+	}
+
+	for experiment, variants := range experimentVariants {
+		definitions = append(definitions, SettingDefinition{
+			Key:  ExperimentSetting(experiment),
+			Type: SettingTypeExperiment,
+			Description: fmt.Sprintf(`rollout of the %s experiment as {"enabled", "rollout", "weights", "targeting"}, `+
+				"variants are %s, empty turns it off", experiment, strings.Join(variants, ", ")),
+		})
+	}
+
+	byKey := make(map[string]SettingDefinition, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+	}
+
+	return byKey
+}
+
+// NotificationTemplateSetting is the key of the setting overriding a notification template in a locale.
+func NotificationTemplateSetting(locale string, templateKey string) string {
+	return fmt.Sprintf("notification.template.%s.%s", locale, templateKey)
+}
+
+// RenderNotificationTemplate fills the placeholders of a template set at runtime.
+func RenderNotificationTemplate(text string, p NotificationPayload) string {
+	return strings.NewReplacer(
+		"{actor_name}", p.ActorName,
+		"{days}", strconv.Itoa(p.Days),
+		"{text}", p.Text,
+	).Replace(text)
+}
+
+func GetSettingDefinition(key string) (SettingDefinition, bool) {
+	definition, ok := settingDefinitions[key]
+	return definition, ok
+}
+
+// SettingDefinitions lists every runtime setting ordered by key.
+func SettingDefinitions() []SettingDefinition {
+	definitions := make([]SettingDefinition, 0, len(settingDefinitions))
+	for _, definition := range settingDefinitions {
+		definitions = append(definitions, definition)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Key < definitions[j].Key
+	})
+
+	return definitions
+}
+
+// Validate reports why value cannot be stored for the setting, an empty value always resets it.
+func (d SettingDefinition) Validate(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch d.Type {
+	case SettingTypeInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		if n < d.Min || (d.Max > 0 && n > d.Max) {
+			return fmt.Errorf("must be between %d and %d", d.Min, d.Max)
+		}
+	case SettingTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+	case SettingTypeExperiment:
+		if _, err := ParseExperimentConfig(strings.TrimPrefix(d.Key, experimentSettingPrefix), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+type UserTOTP struct {
+	UserID      int64      `json:"user_id"`
+	Secret      string     `gorm:"column:totp_secret" json:"-"` // encrypted with the jwt key
+	Enabled     bool       `gorm:"column:totp_enabled" json:"enabled"`
+	ConfirmedAt *time.Time `gorm:"column:totp_confirmed_at" json:"confirmed_at,omitempty"`
+}
+
+type RecoveryCode struct {
+	ID        int64      `gorm:"primaryKey" json:"id"`
+	UserID    int64      `gorm:"not null" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `gorm:"default:null" json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at"`
+}
+
+type TOTPEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TOTPConfirmRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type TOTPConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAVerifyRequest completes a login, code is either a TOTP code or one of the recovery codes.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorStatus struct {
+	UserID                 int64      `json:"user_id"`
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
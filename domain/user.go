@@ -2,12 +2,21 @@ package domain
 
 import "time"
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID             int64      `gorm:"primaryKey" json:"id" faker:"-"`
-	Email          string     `gorm:"unique;not null" json:"email" faker:"email"`
+	Email          string     `gorm:"unique" json:"email,omitempty" faker:"email"`
+	Phone          string     `gorm:"unique;default:null" json:"phone,omitempty" faker:"-"`
 	Password       string     `gorm:"not null" json:"password,omitempty" faker:"-"`
 	AccessToken    string     `json:"access_token,omitempty" faker:"-"`
 	TokenExpiredAt *time.Time `json:"token_expired_at,omitempty" faker:"-"`
+	TOTPEnabled    bool       `gorm:"column:totp_enabled" json:"totp_enabled,omitempty" faker:"-"`
+	Role           string     `gorm:"default:user" json:"role,omitempty" faker:"-"`
+	BannedAt       *time.Time `gorm:"default:null" json:"banned_at,omitempty" faker:"-"`
 	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"created_at" faker:"-"`
 	UpdatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP()" json:"updated_at" faker:"-"`
 	DeletedAt      *time.Time `gorm:"default:null" json:"deleted_at,omitempty" faker:"-"`
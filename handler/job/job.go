@@ -0,0 +1,83 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"sync"
+	"time"
+)
+
+type Func func(ctx context.Context) error
+
+type Runner struct {
+	cfg  *domain.Config
+	wg   sync.WaitGroup
+	stop chan struct{}
+}
+
+func NewRunner(cfg *domain.Config) *Runner {
+	return &Runner{
+		cfg:  cfg,
+		stop: make(chan struct{}),
+	}
+}
+
+// Every runs fn once straight away and then on every tick of interval until
+// Stop is called.
+func (r *Runner) Every(name string, interval time.Duration, fn Func) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for run := 1; ; run++ {
+			r.run(name, run, fn)
+
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r *Runner) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+func (r *Runner) run(name string, run int, fn Func) {
+	startTime := time.Now()
+	tags := make(log.Fields)
+	requestID := fmt.Sprintf("job-%s-%d-%d", name, startTime.Unix(), run)
+	ctx, span := tracing.Start(context.WithValue(context.Background(), "requestid", requestID),
+		fmt.Sprintf("handler.job.%s", name))
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			tags["error"] = fmt.Sprintf("panic: %v", rec)
+			tags["status"] = "error"
+		}
+
+		tags["name"] = fmt.Sprintf("handler.job.%s", name)
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = requestID
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if err := fn(ctx); err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return
+	}
+
+	tags["status"] = "success"
+}
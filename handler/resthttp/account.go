@@ -0,0 +1,85 @@
+package resthttp
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"time"
+)
+
+type AccountHandlerModule struct {
+	cfg            *domain.Config
+	accountService AccountService
+}
+
+func NewAccountHandlerModule(cfg *domain.Config, accountService AccountService) *AccountHandlerModule {
+	return &AccountHandlerModule{
+		cfg:            cfg,
+		accountService: accountService,
+	}
+}
+
+func (m AccountHandlerModule) delete(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.account.delete"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	res, err := m.accountService.Delete(ctx.Context(), jwtUser.ID)
+	if err != nil {
+		tags["error"] = "failed deleting account"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed deleting account")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AccountHandlerModule) export(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.account.export"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	archive, err := m.accountService.Export(ctx.Context(), jwtUser.ID)
+	if err != nil {
+		tags["error"] = "failed exporting account"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed exporting account")
+	}
+
+	tags["status"] = "success"
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="account-%d-%s.zip"`, jwtUser.ID, startTime.Format("20060102")))
+	return ctx.Status(fiber.StatusOK).Send(archive)
+}
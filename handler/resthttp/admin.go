@@ -0,0 +1,530 @@
+package resthttp
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"time"
+)
+
+type AdminHandlerModule struct {
+	cfg          *domain.Config
+	adminService AdminService
+}
+
+func NewAdminHandlerModule(cfg *domain.Config, adminService AdminService) *AdminHandlerModule {
+	return &AdminHandlerModule{
+		cfg:          cfg,
+		adminService: adminService,
+	}
+}
+
+func (m AdminHandlerModule) searchUsers(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.search_users"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.UserSearchRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.adminService.SearchUsers(ctx.Context(), jwtUser.ID, req)
+	if err != nil {
+		tags["error"] = "failed searching users"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed searching users")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AdminHandlerModule) getUser(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.get_user"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	res, err := m.adminService.GetUser(ctx.Context(), jwtUser.ID, int64(userID))
+	if err != nil {
+		tags["error"] = "failed getting user"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting user")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AdminHandlerModule) adjustInventory(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.adjust_inventory"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.AdjustInventoryRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	err = m.adminService.AdjustInventory(ctx.Context(), jwtUser.ID, int64(userID), req)
+	if err != nil {
+		tags["error"] = "failed adjusting inventory"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed adjusting inventory")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "inventory adjusted successfully"})
+}
+
+func (m AdminHandlerModule) banUser(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.ban_user"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.BanRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	err = m.adminService.BanUser(ctx.Context(), jwtUser.ID, int64(userID), req)
+	if err != nil {
+		tags["error"] = "failed banning user"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed banning user")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "user banned successfully"})
+}
+
+func (m AdminHandlerModule) unbanUser(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.unban_user"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	err = m.adminService.UnbanUser(ctx.Context(), jwtUser.ID, int64(userID))
+	if err != nil {
+		tags["error"] = "failed unbanning user"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed unbanning user")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "user unbanned successfully"})
+}
+
+func (m AdminHandlerModule) deleteProfile(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.delete_profile"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	err = m.adminService.DeleteProfile(ctx.Context(), jwtUser.ID, int64(userID))
+	if err != nil {
+		tags["error"] = "failed deleting profile"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed deleting profile")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "profile deleted successfully"})
+}
+
+func (m AdminHandlerModule) resendNotification(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.resend_notification"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	notificationID, err := ctx.ParamsInt("id")
+	if err != nil || notificationID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	err = m.adminService.ResendNotification(ctx.Context(), jwtUser.ID, int64(notificationID))
+	if err != nil {
+		tags["error"] = "failed resending notification"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed resending notification")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "notification resent successfully"})
+}
+
+func (m AdminHandlerModule) getAuditLogs(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.get_audit_logs"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	var req domain.AuditLogFilter
+	if err := ctx.QueryParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.adminService.GetAuditLogs(ctx.Context(), req)
+	if err != nil {
+		tags["error"] = "failed getting audit logs"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting audit logs")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AdminHandlerModule) getReports(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.get_reports"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	var req domain.ReportFilter
+	if err := ctx.QueryParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.adminService.GetReports(ctx.Context(), req)
+	if err != nil {
+		tags["error"] = "failed getting reports"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting reports")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AdminHandlerModule) getContentFlags(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.get_content_flags"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	var req domain.ContentFlagFilter
+	if err := ctx.QueryParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.adminService.GetContentFlags(ctx.Context(), req)
+	if err != nil {
+		tags["error"] = "failed getting content flags"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting content flags")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AdminHandlerModule) reviewReport(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.review_report"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	reportID, err := ctx.ParamsInt("id")
+	if err != nil || reportID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.ReviewReportRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	err = m.adminService.ReviewReport(ctx.Context(), jwtUser.ID, int64(reportID), req)
+	if err != nil {
+		tags["error"] = "failed reviewing report"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed reviewing report")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "report reviewed successfully"})
+}
+
+func (m AdminHandlerModule) getSettings(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.get_settings"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	res, err := m.adminService.GetSettings(ctx.Context())
+	if err != nil {
+		tags["error"] = "failed getting settings"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting settings")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AdminHandlerModule) updateSetting(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.admin.update_setting"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.UpdateSettingRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	err = m.adminService.UpdateSetting(ctx.Context(), jwtUser.ID, ctx.Params("key"), req)
+	if err != nil {
+		tags["error"] = "failed updating setting"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed updating setting")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "setting updated successfully"})
+}
@@ -5,16 +5,21 @@ import (
 	"github.com/gofiber/fiber/v2"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
 	"time"
 )
 
 type AuthHandlerModule struct {
+	cfg         *domain.Config
 	authService AuthService
+	otpService  OTPService
 }
 
-func NewAuthHandlerModule(authService AuthService) *AuthHandlerModule {
+func NewAuthHandlerModule(cfg *domain.Config, authService AuthService, otpService OTPService) *AuthHandlerModule {
 	return &AuthHandlerModule{
+		cfg:         cfg,
 		authService: authService,
+		otpService:  otpService,
 	}
 }
 
@@ -26,8 +31,8 @@ func (m AuthHandlerModule) login(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.auth.login"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	var req domain.AuthRequest
@@ -46,7 +51,7 @@ func (m AuthHandlerModule) login(ctx *fiber.Ctx) error {
 	res, err := m.authService.Login(ctx.Context(), req)
 	if err != nil {
 		tags["error"] = "failed login"
-		return response.setErrorResponse(fiber.StatusUnauthorized, "failed login")
+		return response.setServiceErrorResponse(err, "failed login")
 	}
 
 	tags["status"] = "success"
@@ -60,8 +65,8 @@ func (m AuthHandlerModule) register(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.auth.register"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	var req domain.AuthRequest
@@ -80,7 +85,7 @@ func (m AuthHandlerModule) register(ctx *fiber.Ctx) error {
 	err = m.authService.Register(ctx.Context(), req)
 	if err != nil {
 		tags["error"] = "failed register"
-		return response.setErrorResponse(fiber.StatusUnauthorized, "failed register")
+		return response.setServiceErrorResponse(err, "failed register")
 	}
 
 	tags["status"] = "success"
@@ -88,3 +93,228 @@ func (m AuthHandlerModule) register(ctx *fiber.Ctx) error {
 		"message": "success register",
 	})
 }
+
+func (m AuthHandlerModule) sendOTP(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "handler.http.auth.send_otp"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	var req domain.OTPRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		return response.setErrorResponse(fiber.StatusUnprocessableEntity, "failed parsing request")
+	}
+
+	validate := validator.New()
+	err := validate.Struct(req)
+	if err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.otpService.Send(ctx.Context(), req)
+	if err != nil {
+		tags["error"] = "failed sending otp"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed sending otp")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AuthHandlerModule) oauthURL(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "handler.http.auth.oauth_url"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	res, err := m.authService.OAuthURL(ctx.Context(), ctx.Params("provider"))
+	if err != nil {
+		tags["error"] = "failed building oauth url"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed building oauth url")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AuthHandlerModule) oauthCallback(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "handler.http.auth.oauth_callback"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	var req domain.OAuthCallbackRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		return response.setErrorResponse(fiber.StatusUnprocessableEntity, "failed parsing request")
+	}
+
+	validate := validator.New()
+	err := validate.Struct(req)
+	if err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.authService.OAuthCallback(ctx.Context(), ctx.Params("provider"), req)
+	if err != nil {
+		tags["error"] = "failed oauth login"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed oauth login")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AuthHandlerModule) enrollTOTP(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "handler.http.auth.enroll_totp"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	res, err := m.authService.EnrollTOTP(ctx.Context(), jwtUser.ID)
+	if err != nil {
+		tags["error"] = "failed enrolling totp"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed enrolling totp")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AuthHandlerModule) confirmTOTP(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "handler.http.auth.confirm_totp"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.TOTPConfirmRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		return response.setErrorResponse(fiber.StatusUnprocessableEntity, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.authService.ConfirmTOTP(ctx.Context(), jwtUser.ID, req)
+	if err != nil {
+		tags["error"] = "failed confirming totp"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed confirming totp")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AuthHandlerModule) verifyMFA(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "handler.http.auth.verify_mfa"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	var req domain.MFAVerifyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		return response.setErrorResponse(fiber.StatusUnprocessableEntity, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.authService.VerifyMFA(ctx.Context(), req)
+	if err != nil {
+		tags["error"] = "failed verifying mfa"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed verifying mfa")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m AuthHandlerModule) twoFactorStatus(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "handler.http.auth.two_factor_status"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	res, err := m.authService.TwoFactorStatus(ctx.Context(), jwtUser.ID)
+	if err != nil {
+		tags["error"] = "failed getting two factor status"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting two factor status")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
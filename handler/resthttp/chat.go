@@ -0,0 +1,427 @@
+package resthttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fasthttp/websocket"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"time"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+type ChatHandlerModule struct {
+	cfg         *domain.Config
+	chatService ChatService
+	hub         RealtimeHub
+	presence    PresenceService
+	upgrader    websocket.FastHTTPUpgrader
+}
+
+func NewChatHandlerModule(cfg *domain.Config, chatService ChatService, hub RealtimeHub,
+	presence PresenceService) *ChatHandlerModule {
+	return &ChatHandlerModule{
+		cfg:         cfg,
+		chatService: chatService,
+		hub:         hub,
+		presence:    presence,
+		upgrader: websocket.FastHTTPUpgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+}
+
+func (m ChatHandlerModule) openConversation(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.chat.open_conversation"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.OpenConversationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.chatService.OpenConversation(ctx.Context(), jwtUser.ID, req)
+	if err != nil {
+		tags["error"] = "failed opening conversation"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed opening conversation")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m ChatHandlerModule) getConversations(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.chat.get_conversations"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	res, err := m.chatService.GetConversations(ctx.Context(), jwtUser.ID)
+	if err != nil {
+		tags["error"] = "failed getting conversations"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting conversations")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m ChatHandlerModule) sendMessage(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.chat.send_message"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	conversationID, err := ctx.ParamsInt("id")
+	if err != nil || conversationID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.SendMessageRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.chatService.SendMessage(ctx.Context(), jwtUser.ID, int64(conversationID), req)
+	if err != nil {
+		tags["error"] = "failed sending message"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed sending message")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m ChatHandlerModule) getMessages(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.chat.get_messages"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	conversationID, err := ctx.ParamsInt("id")
+	if err != nil || conversationID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.MessageHistoryRequest
+	if err := ctx.QueryParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.chatService.GetMessages(ctx.Context(), jwtUser.ID, int64(conversationID), req)
+	if err != nil {
+		tags["error"] = "failed getting messages"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting messages")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m ChatHandlerModule) updateReceipt(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.chat.update_receipt"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	conversationID, err := ctx.ParamsInt("id")
+	if err != nil || conversationID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.ReceiptRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	if err := m.chatService.UpdateReceipt(ctx.Context(), jwtUser.ID, int64(conversationID), req); err != nil {
+		tags["error"] = "failed updating receipt"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed updating receipt")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "receipt updated successfully"})
+}
+
+func (m ChatHandlerModule) setTyping(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.chat.set_typing"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	conversationID, err := ctx.ParamsInt("id")
+	if err != nil || conversationID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req domain.TypingRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	if err := m.chatService.SetTyping(ctx.Context(), jwtUser.ID, int64(conversationID), req); err != nil {
+		tags["error"] = "failed setting typing"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed setting typing")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "typing updated successfully"})
+}
+
+// stream upgrades to a WebSocket and pushes every realtime event for the user until either side
+// closes the connection.
+func (m ChatHandlerModule) stream(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+	requestID := logging.RequestID(ctx.Context())
+
+	defer func() {
+		tags["name"] = "handler.http.chat.stream"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = requestID
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	if !websocket.FastHTTPIsWebSocketUpgrade(ctx.Context()) {
+		tags["error"] = "not a websocket upgrade"
+		return response.setErrorResponse(fiber.StatusUpgradeRequired, "websocket upgrade required")
+	}
+
+	userID := jwtUser.ID
+	if err := m.upgrader.Upgrade(ctx.Context(), func(conn *websocket.Conn) {
+		m.serveConnection(conn, userID, requestID)
+	}); err != nil {
+		tags["error"] = "failed upgrading connection"
+		tags["actual_error"] = err.Error()
+		return nil
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (m ChatHandlerModule) serveConnection(conn *websocket.Conn, userID int64, requestID string) {
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.ws.chat.connection"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = requestID
+		tags["user_id"] = userID
+		log.WithFields(tags).Debug()
+	}()
+
+	events, unsubscribe := m.hub.Subscribe(userID)
+	defer unsubscribe()
+	defer conn.Close()
+
+	// The upgrade request is gone by now, connCtx only carries its request id for logging.
+	connCtx := context.WithValue(context.Background(), "requestid", requestID)
+	_ = m.presence.Touch(connCtx, userID)
+
+	// The read loop keeps the deadline fresh on pongs, relays typing frames and notices when the client goes away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+
+		conn.SetReadLimit(4096)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			_ = m.presence.Touch(connCtx, userID)
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+
+		for {
+			var command domain.RealtimeCommand
+			if err := conn.ReadJSON(&command); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+					continue
+				}
+
+				return
+			}
+
+			_ = m.presence.Touch(connCtx, userID)
+
+			if command.Type == domain.RealtimeEventTyping {
+				_ = m.chatService.SetTyping(connCtx, userID, command.ConversationID,
+					domain.TypingRequest{Typing: command.Typing})
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			tags["status"] = "closed"
+			return
+		case event, ok := <-events:
+			if !ok {
+				tags["status"] = "unsubscribed"
+				return
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				tags["error"] = "failed writing event"
+				tags["actual_error"] = err.Error()
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				tags["error"] = "failed writing ping"
+				tags["actual_error"] = err.Error()
+				return
+			}
+		}
+	}
+}
@@ -3,25 +3,100 @@ package resthttp
 import (
 	"context"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"time"
 )
 
 type AuthService interface {
 	Login(ctx context.Context, req domain.AuthRequest) (*domain.AuthResponse, error)
 	Register(ctx context.Context, req domain.AuthRequest) error
+	Authenticate(ctx context.Context, userID int64, token string) error
+	OAuthURL(ctx context.Context, provider string) (*domain.OAuthURLResponse, error)
+	OAuthCallback(ctx context.Context, provider string, req domain.OAuthCallbackRequest) (*domain.AuthResponse, error)
+	EnrollTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollResponse, error)
+	ConfirmTOTP(ctx context.Context, userID int64, req domain.TOTPConfirmRequest) (*domain.TOTPConfirmResponse, error)
+	VerifyMFA(ctx context.Context, req domain.MFAVerifyRequest) (*domain.AuthResponse, error)
+	TwoFactorStatus(ctx context.Context, userID int64) (*domain.TwoFactorStatus, error)
+}
+
+type OTPService interface {
+	Send(ctx context.Context, req domain.OTPRequest) (*domain.OTPResponse, error)
 }
 
 type ProfileService interface {
 	Create(ctx context.Context, userID int64, req domain.ProfileRequest) error
 	UpdateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error
 	UpdateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error
+	GetProfile(ctx context.Context, viewerID int64, userID int64) (*domain.ProfileResponse, error)
+	UpdatePrivacy(ctx context.Context, userID int64, req domain.PrivacyRequest) error
 }
 
 type MatcherService interface {
 	Like(ctx context.Context, userID int64, targetUserID int64) error
 	SuperLike(ctx context.Context, userID int64, targetUserID int64) error
 	Dislike(ctx context.Context, userID int64, targetUserID int64) error
+	Unmatch(ctx context.Context, userID int64, targetUserID int64) error
 }
 
 type RecommendationService interface {
 	GetRecommendation(ctx context.Context, userID int64) (recommendation []domain.Profile, err error)
 }
+
+type AccountService interface {
+	Delete(ctx context.Context, userID int64) (*domain.AccountDeletionResponse, error)
+	Export(ctx context.Context, userID int64) ([]byte, error)
+}
+
+type AdminService interface {
+	SearchUsers(ctx context.Context, adminID int64, req domain.UserSearchRequest) ([]domain.User, error)
+	GetUser(ctx context.Context, adminID int64, userID int64) (*domain.UserRecord, error)
+	AdjustInventory(ctx context.Context, adminID int64, userID int64, req domain.AdjustInventoryRequest) error
+	BanUser(ctx context.Context, adminID int64, userID int64, req domain.BanRequest) error
+	UnbanUser(ctx context.Context, adminID int64, userID int64) error
+	DeleteProfile(ctx context.Context, adminID int64, userID int64) error
+	ResendNotification(ctx context.Context, adminID int64, notificationID int64) error
+	GetAuditLogs(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error)
+	GetReports(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error)
+	GetContentFlags(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error)
+	ReviewReport(ctx context.Context, adminID int64, reportID int64, req domain.ReviewReportRequest) error
+	GetSettings(ctx context.Context) ([]domain.Setting, error)
+	UpdateSetting(ctx context.Context, adminID int64, key string, req domain.UpdateSettingRequest) error
+}
+
+type ReportService interface {
+	Report(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error)
+}
+
+type ChatService interface {
+	OpenConversation(ctx context.Context, userID int64, req domain.OpenConversationRequest) (*domain.Conversation, error)
+	GetConversations(ctx context.Context, userID int64) ([]domain.Conversation, error)
+	SendMessage(ctx context.Context, userID int64, conversationID int64, req domain.SendMessageRequest) (*domain.Message, error)
+	GetMessages(ctx context.Context, userID int64, conversationID int64, req domain.MessageHistoryRequest) (*domain.MessagePage, error)
+	UpdateReceipt(ctx context.Context, userID int64, conversationID int64, req domain.ReceiptRequest) error
+	SetTyping(ctx context.Context, userID int64, conversationID int64, req domain.TypingRequest) error
+}
+
+type RealtimeHub interface {
+	Subscribe(userID int64) (<-chan domain.RealtimeEvent, func())
+}
+
+type PresenceService interface {
+	Touch(ctx context.Context, userID int64) error
+}
+
+type NotificationService interface {
+	GetNotifications(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.NotificationResponse, error)
+	MarkRead(ctx context.Context, userID int64, notificationID int64) error
+	RegisterDevice(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error
+	UnregisterDevice(ctx context.Context, userID int64, token string) error
+	GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID int64, req domain.NotificationPreferencesRequest) (*domain.NotificationPreferences, error)
+}
+
+type Metrics interface {
+	ObserveRequest(method string, route string, status int, elapsed time.Duration)
+}
+
+type Health interface {
+	Ready(ctx context.Context) domain.Readiness
+	Build() domain.BuildInfo
+}
@@ -0,0 +1,54 @@
+package resthttp
+
+import (
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"time"
+)
+
+type HealthHandlerModule struct {
+	cfg    *domain.Config
+	health Health
+}
+
+func NewHealthHandlerModule(cfg *domain.Config, health Health) *HealthHandlerModule {
+	return &HealthHandlerModule{
+		cfg:    cfg,
+		health: health,
+	}
+}
+
+// live answers as long as the process serves requests, it checks no dependency.
+func (m HealthHandlerModule) live(ctx *fiber.Ctx) error {
+	response := newResponse(ctx, time.Now())
+	return response.setOKResponse(map[string]interface{}{"status": domain.HealthStatusUp})
+}
+
+// ready answers 503 while a dependency is down or the instance is shutting down.
+func (m HealthHandlerModule) ready(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.health.ready"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	readiness := m.health.Ready(ctx.Context())
+	tags["status"] = readiness.Status
+	if !readiness.Ready {
+		return response.setStatusResponse(fiber.StatusServiceUnavailable, readiness)
+	}
+
+	return response.setOKResponse(readiness)
+}
+
+func (m HealthHandlerModule) version(ctx *fiber.Ctx) error {
+	response := newResponse(ctx, time.Now())
+	return response.setOKResponse(m.health.Build())
+}
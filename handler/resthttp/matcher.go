@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
 	"time"
 )
 
@@ -27,8 +28,8 @@ func (h *MatcherHandlerModule) like(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.matcher.like"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	jwtUser, err := domain.ExtractUserClaims(ctx, h.cfg.JWT.Key)
@@ -48,7 +49,7 @@ func (h *MatcherHandlerModule) like(ctx *fiber.Ctx) error {
 	if err := h.matcherService.Like(ctx.Context(), jwtUser.ID, req.TargetUserID); err != nil {
 		tags["error"] = "failed liking"
 		tags["actual_error"] = err.Error()
-		return response.setErrorResponse(fiber.StatusInternalServerError, "failed liking")
+		return response.setServiceErrorResponse(err, "failed liking")
 	}
 
 	tags["status"] = "success"
@@ -63,8 +64,8 @@ func (h *MatcherHandlerModule) superLike(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.matcher.superLike"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	jwtUser, err := domain.ExtractUserClaims(ctx, h.cfg.JWT.Key)
@@ -84,7 +85,7 @@ func (h *MatcherHandlerModule) superLike(ctx *fiber.Ctx) error {
 	if err := h.matcherService.SuperLike(ctx.Context(), jwtUser.ID, req.TargetUserID); err != nil {
 		tags["error"] = "failed super liking"
 		tags["actual_error"] = err.Error()
-		return response.setErrorResponse(fiber.StatusInternalServerError, "failed super liking")
+		return response.setServiceErrorResponse(err, "failed super liking")
 	}
 
 	tags["status"] = "success"
@@ -99,8 +100,8 @@ func (h *MatcherHandlerModule) dislike(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.matcher.dislike"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	jwtUser, err := domain.ExtractUserClaims(ctx, h.cfg.JWT.Key)
@@ -120,9 +121,45 @@ func (h *MatcherHandlerModule) dislike(ctx *fiber.Ctx) error {
 	if err := h.matcherService.Dislike(ctx.Context(), jwtUser.ID, req.TargetUserID); err != nil {
 		tags["error"] = "failed disliking"
 		tags["actual_error"] = err.Error()
-		return response.setErrorResponse(fiber.StatusInternalServerError, "failed disliking")
+		return response.setServiceErrorResponse(err, "failed disliking")
 	}
 
 	tags["status"] = "success"
 	return response.setOKResponse(map[string]interface{}{"message": "disliked successfully"})
 }
+
+func (h *MatcherHandlerModule) unmatch(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.matcher.unmatch"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, h.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.MatchRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	if err := h.matcherService.Unmatch(ctx.Context(), jwtUser.ID, req.TargetUserID); err != nil {
+		tags["error"] = "failed unmatching"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed unmatching")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "unmatched successfully"})
+}
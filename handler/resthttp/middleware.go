@@ -1,14 +1,53 @@
 package resthttp
 
 import (
+	"errors"
 	"github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
 	"time"
 )
 
-func authenticate(key string) fiber.Handler {
+// authenticate verifies the access token is signed and not revoked, and marks its owner as active. Presence
+// failures never block a request.
+func authenticate(cfg *domain.Config, auth AuthService, presence PresenceService) fiber.Handler {
 	return jwtware.New(jwtware.Config{
 		SuccessHandler: func(ctx *fiber.Ctx) error {
+			response := newResponse(ctx, time.Now())
+
+			// Tokens waiting for the second factor are only good for /api/auth/2fa/verify.
+			token := ctx.Locals("user").(*jwt.Token)
+			claims := token.Claims.(jwt.MapClaims)
+			if scope, _ := claims["scope"].(string); scope == "mfa_pending" {
+				return response.setErrorResponse(fiber.StatusUnauthorized, "unauthorized")
+			}
+
+			user, err := domain.ExtractUserClaims(ctx, cfg.JWT.Key)
+			if err != nil {
+				return response.setErrorResponse(fiber.StatusUnauthorized, "unauthorized")
+			}
+
+			if err := auth.Authenticate(ctx.Context(), user.ID, token.Raw); err != nil {
+				if errors.Is(err, domain.ErrUnauthorized) {
+					return response.setErrorResponse(fiber.StatusUnauthorized, "unauthorized")
+				}
+				return response.setServiceErrorResponse(err, "failed authenticating")
+			}
+
+			ctx.Locals(logging.Key, logging.From(ctx.Context()).WithField("user_id", user.ID))
+			_ = presence.Touch(ctx.Context(), user.ID)
+			ctx.Locals(domain.ExperimentSubjectKey, experimentSubject(ctx, user.ID))
+
 			return ctx.Next()
 		},
 		ErrorHandler: func(ctx *fiber.Ctx, err error) error {
@@ -16,7 +55,112 @@ func authenticate(key string) fiber.Handler {
 			return response.setErrorResponse(fiber.StatusUnauthorized, "unauthorized")
 		},
 		SigningKey: jwtware.SigningKey{
-			Key: []byte(key),
+			Key: []byte(cfg.Key),
 		},
 	})
 }
+
+// contextLogger must run after requestid, it gives the request a logger carrying its ID, method and path.
+// authenticate adds the user.
+func contextLogger() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		ctx.Locals(logging.Key, log.WithFields(log.Fields{
+			"request_id": logging.RequestID(ctx.Context()),
+			"method":     ctx.Method(),
+			"path":       ctx.Path(),
+		}))
+
+		return ctx.Next()
+	}
+}
+
+// authorize must run after authenticate, it only lets through users holding one of the given roles.
+func authorize(key string, roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		user, err := domain.ExtractUserClaims(ctx, key)
+		if err != nil || !lo.Contains(roles, user.Role) {
+			response := newResponse(ctx, time.Now())
+			return response.setErrorResponse(fiber.StatusForbidden, "forbidden")
+		}
+
+		return ctx.Next()
+	}
+}
+
+// observe records the duration and status of every request by the route it matched.
+func observe(metrics Metrics) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		startTime := time.Now()
+		err := ctx.Next()
+
+		metrics.ObserveRequest(ctx.Method(), ctx.Route().Path, responseStatus(ctx, err), time.Since(startTime))
+		return err
+	}
+}
+
+// traceRequest starts the server span of a request, continuing the trace of the caller. Services find it
+// through tracing.SpanKey in the request context handlers pass on.
+func traceRequest() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		carrier := make(map[string]string)
+		for _, header := range tracing.Headers() {
+			if value := ctx.Get(header); value != "" {
+				carrier[header] = value
+			}
+		}
+
+		_, span := tracing.Start(tracing.Extract(ctx.Context(), carrier), ctx.Method(),
+			trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		ctx.Locals(tracing.SpanKey, span)
+		err := ctx.Next()
+
+		status := responseStatus(ctx, err)
+		span.SetName(ctx.Method() + " " + ctx.Route().Path)
+		span.SetAttributes(
+			attribute.String("http.request.method", ctx.Method()),
+			attribute.String("http.route", ctx.Route().Path),
+			attribute.Int("http.response.status_code", status),
+		)
+		if requestID, ok := ctx.Locals("requestid").(string); ok {
+			span.SetAttributes(attribute.String("request_id", requestID))
+		}
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+
+		return err
+	}
+}
+
+// responseStatus is the status the request is answered with. Errors returned by handlers only become a
+// response in the error handler, after the middlewares.
+func responseStatus(ctx *fiber.Ctx, err error) int {
+	if err == nil {
+		return ctx.Response().StatusCode()
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// experimentSubject describes the caller for experiment targeting. Services read it from the request context.
+func experimentSubject(ctx *fiber.Ctx, userID int64) domain.ExperimentSubject {
+	country := ctx.Get("X-Country")
+	if country == "" {
+		country = ctx.Get("CF-IPCountry")
+	}
+
+	return domain.ExperimentSubject{
+		UserID: userID,
+		Attributes: map[string]string{
+			domain.ExperimentAttributePlatform: strings.ToLower(ctx.Get("X-Platform")),
+			domain.ExperimentAttributeCountry:  strings.ToUpper(country),
+			domain.ExperimentAttributePlan:     domain.PlanFree,
+		},
+	}
+}
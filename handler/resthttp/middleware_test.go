@@ -0,0 +1,113 @@
+package resthttp
+
+import (
+	"context"
+	"errors"
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type authStub struct {
+	AuthService
+	err error
+}
+
+func (a authStub) Authenticate(ctx context.Context, userID int64, token string) error {
+	return a.err
+}
+
+type presenceStub struct{}
+
+func (presenceStub) Touch(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func signToken(t *testing.T, cfg *domain.Config, scope string) string {
+	user, err := json.Marshal(domain.User{ID: 1, Role: domain.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := domain.EncryptAESWithGCM(string(user), cfg.JWT.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.MapClaims{"sub": sub, "exp": time.Now().Add(time.Hour).Unix()}
+	if scope != "" {
+		claims["scope"] = scope
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func Test_authenticate(t *testing.T) {
+	cfg := &domain.Config{Key: "signing-key"}
+	cfg.JWT.Key = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name       string
+		token      string
+		err        error
+		wantStatus int
+	}{
+		{
+			name:       "failed without token",
+			wantStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:       "failed mfa pending token",
+			token:      signToken(t, cfg, "mfa_pending"),
+			wantStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:       "failed token revoked",
+			token:      signToken(t, cfg, ""),
+			err:        domain.ErrTokenRevoked,
+			wantStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:       "failed checking token",
+			token:      signToken(t, cfg, ""),
+			err:        errors.New("test"),
+			wantStatus: fiber.StatusInternalServerError,
+		},
+		{
+			name:       "success",
+			token:      signToken(t, cfg, ""),
+			wantStatus: fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", authenticate(cfg, authStub{err: tt.err}, presenceStub{}), func(ctx *fiber.Ctx) error {
+				return ctx.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("authenticate() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
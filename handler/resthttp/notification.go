@@ -0,0 +1,249 @@
+package resthttp
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"time"
+)
+
+type NotificationHandlerModule struct {
+	cfg                 *domain.Config
+	notificationService NotificationService
+}
+
+func NewNotificationHandlerModule(cfg *domain.Config, notificationService NotificationService) *NotificationHandlerModule {
+	return &NotificationHandlerModule{
+		cfg:                 cfg,
+		notificationService: notificationService,
+	}
+}
+
+func (m NotificationHandlerModule) getNotifications(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.notification.get_notifications"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.NotificationFilter
+	if err := ctx.QueryParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	// An explicit ?locale= wins over the Accept-Language header.
+	if req.Locale == "" {
+		req.Locale = ctx.AcceptsLanguages(domain.SupportedLocales()...)
+	}
+
+	res, err := m.notificationService.GetNotifications(ctx.Context(), jwtUser.ID, req)
+	if err != nil {
+		tags["error"] = "failed getting notifications"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting notifications")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m NotificationHandlerModule) markRead(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.notification.mark_read"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	notificationID, err := ctx.ParamsInt("id")
+	if err != nil || notificationID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	if err := m.notificationService.MarkRead(ctx.Context(), jwtUser.ID, int64(notificationID)); err != nil {
+		tags["error"] = "failed marking notification read"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed marking notification read")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "notification marked as read"})
+}
+
+func (m NotificationHandlerModule) registerDevice(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.notification.register_device"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.DeviceTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	if err := m.notificationService.RegisterDevice(ctx.Context(), jwtUser.ID, req); err != nil {
+		tags["error"] = "failed registering device"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed registering device")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "device registered successfully"})
+}
+
+func (m NotificationHandlerModule) unregisterDevice(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.notification.unregister_device"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	token := ctx.Params("token")
+	if token == "" {
+		tags["error"] = "invalid token"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid token")
+	}
+
+	if err := m.notificationService.UnregisterDevice(ctx.Context(), jwtUser.ID, token); err != nil {
+		tags["error"] = "failed unregistering device"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed unregistering device")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "device unregistered successfully"})
+}
+
+func (m NotificationHandlerModule) getPreferences(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.notification.get_preferences"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	res, err := m.notificationService.GetPreferences(ctx.Context(), jwtUser.ID)
+	if err != nil {
+		tags["error"] = "failed getting preferences"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting preferences")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m NotificationHandlerModule) updatePreferences(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.notification.update_preferences"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.NotificationPreferencesRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	res, err := m.notificationService.UpdatePreferences(ctx.Context(), jwtUser.ID, req)
+	if err != nil {
+		tags["error"] = "failed updating preferences"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed updating preferences")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
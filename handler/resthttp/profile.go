@@ -1,9 +1,11 @@
 package resthttp
 
 import (
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
 	"time"
 )
 
@@ -27,8 +29,8 @@ func (m ProfileHandlerModule) create(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.profile.create"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
@@ -48,7 +50,7 @@ func (m ProfileHandlerModule) create(ctx *fiber.Ctx) error {
 	if err := m.profileService.Create(ctx.Context(), jwtUser.ID, req); err != nil {
 		tags["error"] = "failed creating profile"
 		tags["actual_error"] = err.Error()
-		return response.setErrorResponse(fiber.StatusInternalServerError, "failed creating profile")
+		return response.setServiceErrorResponse(err, "failed creating profile")
 	}
 
 	tags["status"] = "success"
@@ -63,8 +65,8 @@ func (m ProfileHandlerModule) updateProfilePic(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.profile.update_profile_pic"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
@@ -84,7 +86,7 @@ func (m ProfileHandlerModule) updateProfilePic(ctx *fiber.Ctx) error {
 	if err := m.profileService.UpdateProfilePic(ctx.Context(), jwtUser.ID, req); err != nil {
 		tags["error"] = "failed updating profile pic"
 		tags["actual_error"] = err.Error()
-		return response.setErrorResponse(fiber.StatusInternalServerError, "failed updating profile pic")
+		return response.setServiceErrorResponse(err, "failed updating profile pic")
 	}
 
 	tags["status"] = "success"
@@ -99,8 +101,8 @@ func (m ProfileHandlerModule) updateProfile(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.profile.update_profile"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
@@ -120,9 +122,87 @@ func (m ProfileHandlerModule) updateProfile(ctx *fiber.Ctx) error {
 	if err := m.profileService.UpdateProfile(ctx.Context(), jwtUser.ID, req); err != nil {
 		tags["error"] = "failed updating profile"
 		tags["actual_error"] = err.Error()
-		return response.setErrorResponse(fiber.StatusInternalServerError, "failed updating profile")
+		return response.setServiceErrorResponse(err, "failed updating profile")
 	}
 
 	tags["status"] = "success"
 	return response.setOKResponse(map[string]interface{}{"message": "profile updated successfully"})
 }
+
+func (m ProfileHandlerModule) getProfile(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.profile.get_profile"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	userID, err := ctx.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		tags["error"] = "invalid id"
+		return response.setErrorResponse(fiber.StatusBadRequest, "invalid id")
+	}
+
+	res, err := m.profileService.GetProfile(ctx.Context(), jwtUser.ID, int64(userID))
+	if err != nil {
+		tags["error"] = "failed getting profile"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed getting profile")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
+
+func (m ProfileHandlerModule) updatePrivacy(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.profile.update_privacy"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.PrivacyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	if err := m.profileService.UpdatePrivacy(ctx.Context(), jwtUser.ID, req); err != nil {
+		tags["error"] = "failed updating privacy"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed updating privacy")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(map[string]interface{}{"message": "privacy updated successfully"})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
 	"time"
 )
 
@@ -26,8 +27,8 @@ func (h *RecommendationHandlerModule) getRecommendation(ctx *fiber.Ctx) error {
 	defer func() {
 		tags["name"] = "handler.http.recommendation.getRecommendation"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Locals("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
 	}()
 
 	jwtUser, err := domain.ExtractUserClaims(ctx, h.cfg.JWT.Key)
@@ -40,7 +41,7 @@ func (h *RecommendationHandlerModule) getRecommendation(ctx *fiber.Ctx) error {
 	recommendation, err := h.recommendationService.GetRecommendation(ctx.Context(), jwtUser.ID)
 	if err != nil {
 		tags["error"] = "failed getting recommendation"
-		return response.setErrorResponse(fiber.StatusInternalServerError, "failed getting recommendation")
+		return response.setServiceErrorResponse(err, "failed getting recommendation")
 	}
 
 	tags["status"] = "success"
@@ -0,0 +1,65 @@
+package resthttp
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"time"
+)
+
+type ReportHandlerModule struct {
+	cfg           *domain.Config
+	reportService ReportService
+}
+
+func NewReportHandlerModule(cfg *domain.Config, reportService ReportService) *ReportHandlerModule {
+	return &ReportHandlerModule{
+		cfg:           cfg,
+		reportService: reportService,
+	}
+}
+
+func (m ReportHandlerModule) report(ctx *fiber.Ctx) error {
+	startTime := time.Now()
+	response := newResponse(ctx, startTime)
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "handler.http.report.report"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx.Context())
+		logging.From(ctx.Context()).WithFields(tags).Debug()
+	}()
+
+	jwtUser, err := domain.ExtractUserClaims(ctx, m.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed extracting user claims"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusInternalServerError, "failed extracting user claims")
+	}
+
+	var req domain.ReportRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		tags["error"] = "failed parsing request"
+		tags["actual_error"] = err.Error()
+		return response.setErrorResponse(fiber.StatusBadRequest, "failed parsing request")
+	}
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		return response.setErrorValidationResponse(err)
+	}
+
+	res, err := m.reportService.Report(ctx.Context(), jwtUser.ID, req)
+	if err != nil {
+		tags["error"] = "failed reporting user"
+		tags["actual_error"] = err.Error()
+		return response.setServiceErrorResponse(err, "failed reporting user")
+	}
+
+	tags["status"] = "success"
+	return response.setOKResponse(res)
+}
@@ -1,13 +1,38 @@
 package resthttp
 
 import (
+	"errors"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
 	"regexp"
 	"strings"
 	"time"
 )
 
+// errorStatuses maps every domain error kind to the status it is reported with.
+var errorStatuses = []struct {
+	kind   error
+	status int
+}{
+	{kind: domain.ErrInvalid, status: fiber.StatusBadRequest},
+	{kind: domain.ErrUnauthorized, status: fiber.StatusUnauthorized},
+	{kind: domain.ErrQuotaExceeded, status: fiber.StatusPaymentRequired},
+	{kind: domain.ErrForbidden, status: fiber.StatusForbidden},
+	{kind: domain.ErrNotFound, status: fiber.StatusNotFound},
+	{kind: domain.ErrAlreadyExists, status: fiber.StatusConflict},
+	{kind: domain.ErrConflict, status: fiber.StatusConflict},
+	{kind: domain.ErrRateLimited, status: fiber.StatusTooManyRequests},
+}
+
+type errorBody struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
 type response struct {
 	Context   *fiber.Ctx  `json:"-"`
 	IsError   bool        `json:"is_error"`
@@ -21,7 +46,7 @@ func newResponse(ctx *fiber.Ctx, start time.Time) response {
 	return response{
 		Context:   ctx,
 		Start:     start,
-		RequestID: ctx.Locals("requestid").(string),
+		RequestID: logging.RequestID(ctx.Context()),
 	}
 }
 
@@ -33,25 +58,95 @@ func (r *response) setOKResponse(data interface{}) error {
 	return r.Context.Status(fiber.StatusOK).JSON(r)
 }
 
+// setStatusResponse answers with data like setOKResponse, under a status of its own such as 503.
+func (r *response) setStatusResponse(statusCode int, data interface{}) error {
+	r.Data = data
+	r.Elapsed = time.Since(r.Start).String()
+	r.IsError = statusCode >= fiber.StatusBadRequest
+
+	return r.Context.Status(statusCode).JSON(r)
+}
+
+// setErrorResponse reports a failure found by the handler itself, the code is derived from the status.
 func (r *response) setErrorResponse(statusCode int, err string) error {
-	r.Data = map[string]string{
-		"error": err,
+	return r.setError(statusCode, errorBody{
+		Code:    snakeCase(utils.StatusMessage(statusCode)),
+		Message: err,
+	})
+}
+
+// setServiceErrorResponse reports an error returned by a service. Catalogued domain errors and
+// validation errors are shown as they are with the status of their kind, anything else is an internal
+// error the client only sees message for.
+func (r *response) setServiceErrorResponse(err error, message string) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return r.setErrorValidationResponse(validationErrors)
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return r.setError(errorStatus(domainErr.Kind), errorBody{
+			Code:    domainErr.Code,
+			Message: domainErr.Message,
+			Details: domainErr.Details,
+		})
+	}
+
+	// Repositories return bare kinds, for example a unique violation as domain.ErrAlreadyExists.
+	for _, s := range errorStatuses {
+		if errors.Is(err, s.kind) {
+			return r.setError(s.status, errorBody{
+				Code:    snakeCase(s.kind.Error()),
+				Message: s.kind.Error(),
+			})
+		}
 	}
+
+	return r.setError(fiber.StatusInternalServerError, errorBody{
+		Code:    "internal_error",
+		Message: message,
+	})
+}
+
+func (r *response) setError(statusCode int, body errorBody) error {
+	r.Data = body
 	r.Elapsed = time.Since(r.Start).String()
 	r.IsError = true
 
 	return r.Context.Status(statusCode).JSON(r)
 }
 
-func (r *response) setErrorValidationResponse(err error) error {
-	r.Elapsed = time.Since(r.Start).String()
-	r.IsError = true
+func errorStatus(kind error) int {
+	for _, s := range errorStatuses {
+		if errors.Is(kind, s.kind) {
+			return s.status
+		}
+	}
 
+	return fiber.StatusInternalServerError
+}
+
+func snakeCase(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), " ", "_")
+}
+
+// setErrorValidationResponse reports the failed fields of validation errors. Anything else validate returns,
+// such as an invalid validation of a nil struct, is only reported as an invalid request.
+func (r *response) setErrorValidationResponse(err error) error {
 	var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
 	var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
 
-	errors := make(map[string]string)
-	for _, v := range err.(validator.ValidationErrors) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return r.setError(fiber.StatusBadRequest, errorBody{
+			Code:    "invalid_request",
+			Message: "invalid request",
+		})
+	}
+
+	details := make(map[string]string)
+	for _, v := range validationErrors {
 		var sb strings.Builder
 
 		snake := matchFirstCap.ReplaceAllString(v.Field(), "${1}_${2}")
@@ -64,12 +159,12 @@ func (r *response) setErrorValidationResponse(err error) error {
 			sb.WriteString(v.Param())
 		}
 
-		errors[field] = strings.TrimSpace(sb.String())
-	}
-
-	r.Data = map[string]map[string]string{
-		"error": errors,
+		details[field] = strings.TrimSpace(sb.String())
 	}
 
-	return r.Context.Status(fiber.StatusBadRequest).JSON(r)
+	return r.setError(fiber.StatusBadRequest, errorBody{
+		Code:    "invalid_request",
+		Message: "invalid request",
+		Details: details,
+	})
 }
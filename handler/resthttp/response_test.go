@@ -0,0 +1,147 @@
+package resthttp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_response_setServiceErrorResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       errorBody
+	}{
+		{
+			name:       "catalogued error",
+			err:        domain.ErrInsufficientLikes,
+			wantStatus: fiber.StatusPaymentRequired,
+			want:       errorBody{Code: "insufficient_likes", Message: "insufficient likes"},
+		},
+		{
+			name:       "wrapped catalogued error with details",
+			err:        fmt.Errorf("check: %w", domain.ErrContentRejected.WithDetails(map[string]string{"reason": "contact"})),
+			wantStatus: fiber.StatusBadRequest,
+			want:       errorBody{Code: "content_rejected", Message: "content rejected", Details: map[string]string{"reason": "contact"}},
+		},
+		{
+			name:       "bare kind from repository",
+			err:        fmt.Errorf("%w: profile_user_id_key", domain.ErrAlreadyExists),
+			wantStatus: fiber.StatusConflict,
+			want:       errorBody{Code: "already_exists", Message: "already exists"},
+		},
+		{
+			name:       "rate limited",
+			err:        domain.ErrOTPResendThrottled,
+			wantStatus: fiber.StatusTooManyRequests,
+			want:       errorBody{Code: "otp_resend_throttled", Message: "otp resend throttled"},
+		},
+		{
+			name:       "unknown error stays internal",
+			err:        errors.New("dial tcp: connection refused"),
+			wantStatus: fiber.StatusInternalServerError,
+			want:       errorBody{Code: "internal_error", Message: "failed liking"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				ctx.Locals("requestid", "test")
+				response := newResponse(ctx, time.Now())
+				return response.setServiceErrorResponse(tt.err, "failed liking")
+			})
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+
+			body, _ := io.ReadAll(res.Body)
+			var got struct {
+				IsError bool      `json:"is_error"`
+				Data    errorBody `json:"data"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("failed decoding body %s: %s", body, err)
+			}
+
+			if !got.IsError || !reflect.DeepEqual(got.Data, tt.want) {
+				t.Errorf("body = %+v, want %+v", got.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_response_setErrorValidationResponse(t *testing.T) {
+	type request struct {
+		Name string `validate:"required"`
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want errorBody
+	}{
+		{
+			name: "validation errors",
+			err:  validator.New().Struct(request{}),
+			want: errorBody{Code: "invalid_request", Message: "invalid request", Details: map[string]string{"name": "name should be required"}},
+		},
+		{
+			name: "invalid validation",
+			err:  validator.New().Struct(nil),
+			want: errorBody{Code: "invalid_request", Message: "invalid request"},
+		},
+		{
+			name: "other error",
+			err:  errors.New("unexpected"),
+			want: errorBody{Code: "invalid_request", Message: "invalid request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				response := newResponse(ctx, time.Now())
+				return response.setErrorValidationResponse(tt.err)
+			})
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+
+			if res.StatusCode != fiber.StatusBadRequest {
+				t.Errorf("status = %d, want %d", res.StatusCode, fiber.StatusBadRequest)
+			}
+
+			body, _ := io.ReadAll(res.Body)
+			var got struct {
+				Data errorBody `json:"data"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("failed decoding body %s: %s", body, err)
+			}
+
+			if !reflect.DeepEqual(got.Data, tt.want) {
+				t.Errorf("body = %+v, want %+v", got.Data, tt.want)
+			}
+		})
+	}
+}
@@ -11,22 +11,48 @@ import (
 type RouteDependencies struct {
 	Cfg            *domain.Config
 	Auth           AuthService
+	OTP            OTPService
 	Profile        ProfileService
 	Recommendation RecommendationService
 	Matcher        MatcherService
+	Account        AccountService
+	Admin          AdminService
+	Report         ReportService
+	Chat           ChatService
+	Realtime       RealtimeHub
+	Presence       PresenceService
+	Notification   NotificationService
+	Metrics        Metrics
+	Health         Health
 }
 
 func NewRouter(app *fiber.App, dep RouteDependencies) {
 	app.Use(recover.New())
+
+	// Probes are answered before the access log, metrics and tracing, they would drown out real traffic.
+	healthHandler := NewHealthHandlerModule(dep.Cfg, dep.Health)
+	app.Get("/healthz", healthHandler.live)
+	app.Get("/readyz", healthHandler.ready)
+	app.Get("/version", healthHandler.version)
+
 	app.Use(logger.New())
 	app.Use(requestid.New())
+	app.Use(contextLogger())
+	app.Use(observe(dep.Metrics))
+	app.Use(traceRequest())
 
-	authMiddleware := authenticate(dep.Cfg.Key)
+	authMiddleware := authenticate(dep.Cfg, dep.Auth, dep.Presence)
+	adminMiddleware := authorize(dep.Cfg.JWT.Key, domain.RoleAdmin)
 
-	authHandler := NewAuthHandlerModule(dep.Auth)
+	authHandler := NewAuthHandlerModule(dep.Cfg, dep.Auth, dep.OTP)
 	profileHandler := NewProfileHandlerModule(dep.Cfg, dep.Profile)
 	recommendationHandler := NewRecommendationHandlerModule(dep.Cfg, dep.Recommendation)
 	matcherHandler := NewMatcherHandlerModule(dep.Cfg, dep.Matcher)
+	accountHandler := NewAccountHandlerModule(dep.Cfg, dep.Account)
+	adminHandler := NewAdminHandlerModule(dep.Cfg, dep.Admin)
+	reportHandler := NewReportHandlerModule(dep.Cfg, dep.Report)
+	chatHandler := NewChatHandlerModule(dep.Cfg, dep.Chat, dep.Realtime, dep.Presence)
+	notificationHandler := NewNotificationHandlerModule(dep.Cfg, dep.Notification)
 
 	// Set global prefix to /api
 	api := app.Group("/api")
@@ -35,12 +61,21 @@ func NewRouter(app *fiber.App, dep RouteDependencies) {
 	auth := api.Group("/auth")
 	auth.Post("/in", authHandler.login)
 	auth.Post("/up", authHandler.register)
+	auth.Post("/otp", authHandler.sendOTP)
+	auth.Get("/oauth/:provider", authHandler.oauthURL)
+	auth.Post("/oauth/:provider/callback", authHandler.oauthCallback)
+	auth.Post("/2fa/verify", authHandler.verifyMFA)
+	auth.Post("/2fa/enroll", authMiddleware, authHandler.enrollTOTP)
+	auth.Post("/2fa/confirm", authMiddleware, authHandler.confirmTOTP)
+	auth.Get("/2fa/status", authMiddleware, authHandler.twoFactorStatus)
 
 	// Set prefix to /api/profile
 	profile := api.Group("/profile").Use(authMiddleware)
 	profile.Post("/create", profileHandler.create)
 	profile.Put("/pic", profileHandler.updateProfilePic)
 	profile.Put("/update", profileHandler.updateProfile)
+	profile.Put("/privacy", profileHandler.updatePrivacy)
+	profile.Get("/:id", profileHandler.getProfile)
 
 	// Set prefix to /api/recommendation
 	recommendation := api.Group("/recommendation").Use(authMiddleware)
@@ -51,4 +86,48 @@ func NewRouter(app *fiber.App, dep RouteDependencies) {
 	matcher.Post("/like", matcherHandler.like)
 	matcher.Post("/superlike", matcherHandler.superLike)
 	matcher.Post("/dislike", matcherHandler.dislike)
+	matcher.Post("/unmatch", matcherHandler.unmatch)
+
+	// Set prefix to /api/chat
+	chat := api.Group("/chat").Use(authMiddleware)
+	chat.Get("/conversations", chatHandler.getConversations)
+	chat.Post("/conversations", chatHandler.openConversation)
+	chat.Get("/conversations/:id/messages", chatHandler.getMessages)
+	chat.Post("/conversations/:id/messages", chatHandler.sendMessage)
+	chat.Post("/conversations/:id/receipts", chatHandler.updateReceipt)
+	chat.Post("/conversations/:id/typing", chatHandler.setTyping)
+	chat.Get("/ws", chatHandler.stream)
+
+	// Set prefix to /api/notifications
+	notifications := api.Group("/notifications").Use(authMiddleware)
+	notifications.Get("", notificationHandler.getNotifications)
+	notifications.Put("/:id/read", notificationHandler.markRead)
+	notifications.Post("/devices", notificationHandler.registerDevice)
+	notifications.Delete("/devices/:token", notificationHandler.unregisterDevice)
+	notifications.Get("/preferences", notificationHandler.getPreferences)
+	notifications.Put("/preferences", notificationHandler.updatePreferences)
+
+	// Set prefix to /api/account
+	account := api.Group("/account").Use(authMiddleware)
+	account.Delete("", accountHandler.delete)
+	account.Get("/export", accountHandler.export)
+
+	// Set prefix to /api/report
+	api.Post("/report", authMiddleware, reportHandler.report)
+
+	// Set prefix to /api/admin
+	admin := api.Group("/admin").Use(authMiddleware, adminMiddleware)
+	admin.Get("/users", adminHandler.searchUsers)
+	admin.Get("/users/:id", adminHandler.getUser)
+	admin.Put("/users/:id/inventory", adminHandler.adjustInventory)
+	admin.Post("/users/:id/ban", adminHandler.banUser)
+	admin.Post("/users/:id/unban", adminHandler.unbanUser)
+	admin.Delete("/users/:id/profile", adminHandler.deleteProfile)
+	admin.Post("/notifications/:id/resend", adminHandler.resendNotification)
+	admin.Get("/audit", adminHandler.getAuditLogs)
+	admin.Get("/reports", adminHandler.getReports)
+	admin.Put("/reports/:id", adminHandler.reviewReport)
+	admin.Get("/content-flags", adminHandler.getContentFlags)
+	admin.Get("/settings", adminHandler.getSettings)
+	admin.Put("/settings/:key", adminHandler.updateSetting)
 }
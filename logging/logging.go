@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"hash/fnv"
+	"math/rand"
+	"os"
+)
+
+// Key holds the logger of a request in its context, middlewares set it with the fields every line of the
+// request carries.
+const Key = "logger"
+
+// RequestID is the ID of the request or job ctx belongs to, empty when it has none.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value("requestid").(string)
+	return requestID
+}
+
+// From returns the logger of ctx. Contexts without one, such as jobs and async subscribers, get a logger
+// carrying their request ID.
+func From(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(Key).(*log.Entry); ok {
+		return entry
+	}
+
+	entry := log.NewEntry(log.StandardLogger())
+	if requestID := RequestID(ctx); requestID != "" {
+		return entry.WithField("request_id", requestID)
+	}
+	return entry
+}
+
+// Setup configures the standard logger, fallback is the level of commands that configure none.
+func Setup(cfg domain.Log, fallback log.Level) error {
+	level := fallback
+	if cfg.Level != "" {
+		parsed, err := log.ParseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+
+	var formatter log.Formatter = &log.TextFormatter{
+		DisableColors: true,
+		FullTimestamp: true,
+	}
+	if cfg.Format == "json" {
+		formatter = &log.JSONFormatter{}
+	}
+
+	if cfg.DebugSampleRate > 0 && cfg.DebugSampleRate < 100 {
+		formatter = sampledFormatter{formatter: formatter, sampleRate: uint32(cfg.DebugSampleRate)}
+	}
+
+	logger := log.StandardLogger()
+	logger.SetLevel(level)
+	logger.SetFormatter(formatter)
+	logger.SetOutput(os.Stderr)
+	return nil
+}
+
+// sampledFormatter only formats the debug lines of sampled requests, the others are written as nothing.
+type sampledFormatter struct {
+	formatter  log.Formatter
+	sampleRate uint32
+}
+
+func (f sampledFormatter) Format(entry *log.Entry) ([]byte, error) {
+	if entry.Level >= log.DebugLevel && !f.sampled(entry) {
+		return nil, nil
+	}
+
+	return f.formatter.Format(entry)
+}
+
+// sampled buckets by request ID, so a request written is written with all of its lines.
+func (f sampledFormatter) sampled(entry *log.Entry) bool {
+	requestID, _ := entry.Data["request_id"].(string)
+	if requestID == "" {
+		return uint32(rand.Intn(100)) < f.sampleRate
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(requestID))
+	return hash.Sum32()%100 < f.sampleRate
+}
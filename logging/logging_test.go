@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		wantRequestID interface{}
+	}{
+		{
+			name:          "without request id",
+			ctx:           context.Background(),
+			wantRequestID: nil,
+		},
+		{
+			name:          "with request id",
+			ctx:           context.WithValue(context.Background(), "requestid", "1"),
+			wantRequestID: "1",
+		},
+		{
+			name:          "with logger",
+			ctx:           context.WithValue(context.Background(), Key, log.WithField("request_id", "2")),
+			wantRequestID: "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := From(tt.ctx).Data["request_id"]; got != tt.wantRequestID {
+				t.Errorf("From() request_id = %v, want %v", got, tt.wantRequestID)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if got := RequestID(context.Background()); got != "" {
+		t.Errorf("RequestID() = %q, want empty", got)
+	}
+	if got := RequestID(context.WithValue(context.Background(), "requestid", "1")); got != "1" {
+		t.Errorf("RequestID() = %q, want 1", got)
+	}
+}
+
+func TestSampledFormatter(t *testing.T) {
+	f := sampledFormatter{formatter: &log.JSONFormatter{}, sampleRate: 50}
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		entry := &log.Entry{Level: log.DebugLevel, Data: log.Fields{"request_id": fmt.Sprintf("request-%d", i)}}
+		line, err := f.Format(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		again, _ := f.Format(entry)
+		if (len(line) == 0) != (len(again) == 0) {
+			t.Fatalf("Format() sampled the same request differently")
+		}
+		if len(line) > 0 {
+			kept++
+		}
+	}
+
+	if kept < 400 || kept > 600 {
+		t.Errorf("Format() kept %d of 1000 requests, want about 500", kept)
+	}
+
+	line, err := f.Format(&log.Entry{Level: log.InfoLevel, Data: log.Fields{"request_id": "request-1"}})
+	if err != nil || len(line) == 0 {
+		t.Errorf("Format() dropped an info line")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	logger := log.StandardLogger()
+	level, formatter, out, hooks := logger.GetLevel(), logger.Formatter, logger.Out, logger.ReplaceHooks(make(log.LevelHooks))
+	t.Cleanup(func() {
+		logger.SetLevel(level)
+		logger.SetFormatter(formatter)
+		logger.SetOutput(out)
+		logger.ReplaceHooks(hooks)
+	})
+
+	if err := Setup(domain.Log{Level: "warn", Format: "json"}, log.DebugLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	if logger.IsLevelEnabled(log.InfoLevel) {
+		t.Errorf("Setup() left info lines enabled at level warn")
+	}
+
+	if err := Setup(domain.Log{Level: "verbose"}, log.DebugLevel); err == nil {
+		t.Errorf("Setup() accepted an unknown level")
+	}
+}
@@ -0,0 +1,341 @@
+package analytics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/eventbus"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultDirectory     = "analytics"
+	defaultBatchSize     = 1000
+	defaultFlushInterval = 60
+	defaultBufferSize    = 10000
+	spoolFolder          = ".spool"
+	fileSuffix           = ".ndjson.gz"
+)
+
+// Sink exports analytics events in batches. Track only queues the event, so a
+// slow store never holds up the publisher, events that do not fit in the
+// queue are dropped and counted. Every batch is written to the spool directory
+// first and removed from there once the store accepted it. Batches left over
+// by a crash or a store outage are retried, so an event may be stored twice
+// but is never lost once it was spooled.
+type Sink struct {
+	cfg           *domain.Config
+	store         Store
+	spool         string
+	batchSize     int
+	flushInterval time.Duration
+	records       chan domain.AnalyticsRecord
+	dropped       int64
+	wg            sync.WaitGroup
+	stop          chan struct{}
+}
+
+// New writes to S3 when it is configured and to the local directory otherwise.
+func New(cfg *domain.Config) (*Sink, error) {
+	dir := cfg.Analytics.Directory
+	if dir == "" {
+		dir = defaultDirectory
+	}
+
+	var store Store = NewLocal(dir)
+	if cfg.Analytics.S3 != nil {
+		s3, err := NewS3(*cfg.Analytics.S3, &http.Client{Timeout: 30 * time.Second})
+		if err != nil {
+			return nil, fmt.Errorf("s3: %w", err)
+		}
+		store = s3
+	}
+
+	return NewSink(cfg, store, filepath.Join(dir, spoolFolder)), nil
+}
+
+func NewSink(cfg *domain.Config, store Store, spool string) *Sink {
+	batchSize := cfg.Analytics.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+
+	flushInterval := cfg.Analytics.FlushInterval
+	if flushInterval == 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	bufferSize := cfg.Analytics.BufferSize
+	if bufferSize == 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Sink{
+		cfg:           cfg,
+		store:         store,
+		spool:         spool,
+		batchSize:     batchSize,
+		flushInterval: time.Duration(flushInterval) * time.Second,
+		records:       make(chan domain.AnalyticsRecord, bufferSize),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Track queues an event for export, events that are not analysed are ignored.
+func (s *Sink) Track(ctx context.Context, event domain.Event) error {
+	record, ok := newRecord(ctx, event)
+	if !ok {
+		return nil
+	}
+
+	select {
+	case s.records <- record:
+		return nil
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+		return errors.New("analytics buffer full")
+	}
+}
+
+// Start writes batches in the background until Stop is called, beginning with
+// whatever a previous run left in the spool.
+func (s *Sink) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run()
+	}()
+}
+
+// Stop writes the events still queued before it returns.
+func (s *Sink) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Sink) run() {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	s.flush(nil)
+
+	batch := make([]domain.AnalyticsRecord, 0, s.batchSize)
+	for {
+		select {
+		case record := <-s.records:
+			batch = append(batch, record)
+			if len(batch) >= s.batchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		case <-s.stop:
+			for len(s.records) > 0 {
+				batch = append(batch, <-s.records)
+			}
+			s.flush(batch)
+			return
+		}
+	}
+}
+
+// flush spools batch, one file per hourly partition, and hands every spooled
+// file to the store.
+func (s *Sink) flush(batch []domain.AnalyticsRecord) {
+	startTime := time.Now()
+	tags := make(log.Fields)
+	ctx := context.WithValue(context.Background(), "requestid", fmt.Sprintf("analytics-%d", startTime.UnixNano()))
+	ctx, span := tracing.Start(ctx, "repo.analytics.flush")
+
+	defer func() {
+		tags["name"] = "repo.analytics.flush"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	tags["records"] = len(batch)
+	if dropped := atomic.SwapInt64(&s.dropped, 0); dropped > 0 {
+		tags["dropped"] = dropped
+	}
+
+	if err := s.spoolBatch(batch); err != nil {
+		tags["error"] = "failed spool batch"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return
+	}
+
+	stored, err := s.upload(ctx)
+	tags["stored"] = stored
+	if err != nil {
+		tags["error"] = "failed store batch"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return
+	}
+
+	tags["status"] = "success"
+}
+
+func (s *Sink) spoolBatch(batch []domain.AnalyticsRecord) error {
+	partitions := make(map[string][]domain.AnalyticsRecord)
+	for _, record := range batch {
+		partition := record.OccurredAt.UTC().Format("dt=2006-01-02/hour=15")
+		partitions[partition] = append(partitions[partition], record)
+	}
+
+	for partition, records := range partitions {
+		data, err := encode(records)
+		if err != nil {
+			return err
+		}
+
+		id, err := randomHex(6)
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("%d-%s%s", time.Now().UnixNano(), id, fileSuffix)
+		if err := writeFile(filepath.Join(s.spool, filepath.FromSlash(partition), name), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upload stores spooled files oldest first and stops at the first failure, the
+// rest is retried on the next flush.
+func (s *Sink) upload(ctx context.Context) (int, error) {
+	var keys []string
+	err := filepath.WalkDir(s.spool, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(path, fileSuffix) {
+			return nil
+		}
+
+		key, err := filepath.Rel(s.spool, path)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, filepath.ToSlash(key))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// File names start with the time they were spooled.
+	sort.Slice(keys, func(i, j int) bool {
+		return filepath.Base(keys[i]) < filepath.Base(keys[j])
+	})
+
+	stored := 0
+	for _, key := range keys {
+		path := filepath.Join(s.spool, filepath.FromSlash(key))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return stored, err
+		}
+
+		if err := s.store.Put(ctx, key, data); err != nil {
+			return stored, err
+		}
+
+		if err := os.Remove(path); err != nil {
+			return stored, err
+		}
+		stored++
+	}
+
+	return stored, nil
+}
+
+func encode(records []domain.AnalyticsRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newRecord(ctx context.Context, event domain.Event) (domain.AnalyticsRecord, bool) {
+	record := domain.AnalyticsRecord{
+		SchemaVersion: domain.AnalyticsSchemaVersion,
+		Event:         event.EventName(),
+		OccurredAt:    eventbus.OccurredAt(ctx).UTC(),
+	}
+
+	switch e := event.(type) {
+	case domain.UserRegisteredEvent:
+		record.UserID = e.UserID
+		record.Properties = map[string]string{"method": e.Method}
+	case domain.UserLoggedInEvent:
+		record.UserID = e.UserID
+		record.Properties = map[string]string{"mfa": strconv.FormatBool(e.MFA)}
+	case domain.SwipedEvent:
+		record.UserID = e.UserID
+		record.TargetUserID = e.TargetUserID
+		record.Properties = map[string]string{"action": e.Action, "mutual": strconv.FormatBool(e.Mutual)}
+	case domain.MatchedEvent:
+		record.UserID = e.UserID
+		record.TargetUserID = e.TargetUserID
+		record.Properties = map[string]string{"match_id": strconv.FormatInt(e.MatchID, 10)}
+	case domain.ExperimentExposedEvent:
+		record.UserID = e.UserID
+		record.Properties = map[string]string{"experiment": e.Experiment, "variant": e.Variant}
+	default:
+		return domain.AnalyticsRecord{}, false
+	}
+
+	record.RequestID = logging.RequestID(ctx)
+	record.EventID, _ = randomHex(16)
+	return record, true
+}
+
+func randomHex(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,190 @@
+package analytics
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/goccy/go-json"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failingStore struct {
+	err error
+}
+
+func (f *failingStore) Put(context.Context, string, []byte) error {
+	return f.err
+}
+
+func readRecords(t *testing.T, dir string) map[string][]domain.AnalyticsRecord {
+	files := make(map[string][]domain.AnalyticsRecord)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, fileSuffix) {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+
+		key, _ := filepath.Rel(dir, path)
+		scanner := bufio.NewScanner(zr)
+		for scanner.Scan() {
+			var record domain.AnalyticsRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				return err
+			}
+			files[filepath.ToSlash(key)] = append(files[filepath.ToSlash(key)], record)
+		}
+
+		return scanner.Err()
+	})
+	if err != nil {
+		t.Fatalf("failed reading records: %s", err)
+	}
+
+	return files
+}
+
+func TestSink_Track(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	dir := t.TempDir()
+	spool := filepath.Join(dir, spoolFolder)
+
+	sink := NewSink(&domain.Config{}, NewLocal(dir), spool)
+	sink.Start()
+
+	events := []domain.Event{
+		domain.UserRegisteredEvent{UserID: 1, Method: "google"},
+		domain.UserLoggedInEvent{UserID: 1, MFA: true},
+		domain.SwipedEvent{UserID: 1, TargetUserID: 2, Action: domain.SwipeActionLike, Mutual: true},
+		domain.MatchedEvent{MatchID: 10, UserID: 1, TargetUserID: 2},
+		domain.ProfileUpdatedEvent{UserID: 1},
+	}
+	for _, event := range events {
+		if err := sink.Track(ctx, event); err != nil {
+			t.Fatalf("Track() error = %v", err)
+		}
+	}
+	sink.Stop()
+
+	files := readRecords(t, dir)
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+
+	for key, records := range files {
+		if !strings.HasPrefix(key, "dt=") || !strings.Contains(key, "/hour=") {
+			t.Errorf("key = %s, want partitioned by day and hour", key)
+		}
+
+		if len(records) != 4 {
+			t.Fatalf("got %d records, want 4", len(records))
+		}
+
+		swiped := records[2]
+		if swiped.Event != domain.EventSwiped || swiped.TargetUserID != 2 || swiped.RequestID != "test" ||
+			swiped.Properties["action"] != domain.SwipeActionLike || swiped.Properties["mutual"] != "true" ||
+			swiped.SchemaVersion != domain.AnalyticsSchemaVersion || swiped.EventID == "" {
+			t.Errorf("swiped record = %+v", swiped)
+		}
+	}
+
+	if left := readRecords(t, spool); len(left) != 0 {
+		t.Errorf("spool still holds %d files", len(left))
+	}
+}
+
+func TestSink_Track_BufferFull(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	sink := NewSink(&domain.Config{Analytics: domain.Analytics{BufferSize: 1}}, NewLocal(t.TempDir()), t.TempDir())
+
+	if err := sink.Track(ctx, domain.UserLoggedInEvent{UserID: 1}); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+
+	if err := sink.Track(ctx, domain.UserLoggedInEvent{UserID: 1}); err == nil {
+		t.Errorf("Track() error = nil, want buffer full")
+	}
+}
+
+func TestSink_RetriesSpool(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	dir := t.TempDir()
+	spool := filepath.Join(t.TempDir(), spoolFolder)
+
+	sink := NewSink(&domain.Config{}, &failingStore{err: errors.New("unavailable")}, spool)
+	sink.Start()
+	if err := sink.Track(ctx, domain.MatchedEvent{MatchID: 10, UserID: 1, TargetUserID: 2}); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	sink.Stop()
+
+	if left := readRecords(t, spool); len(left) != 1 {
+		t.Fatalf("spool holds %d files, want 1", len(left))
+	}
+
+	// The next run uploads what the failed one left behind.
+	sink = NewSink(&domain.Config{}, NewLocal(dir), spool)
+	sink.Start()
+	sink.Stop()
+
+	if files := readRecords(t, dir); len(files) != 1 {
+		t.Errorf("got %d files, want 1", len(files))
+	}
+
+	if left := readRecords(t, spool); len(left) != 0 {
+		t.Errorf("spool still holds %d files", len(left))
+	}
+}
+
+func TestS3_Put(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.EscapedPath() != "/events/tinder/dt%3D2024-01-31/hour%3D09/batch.ndjson.gz" {
+			t.Errorf("request = %s %s", r.Method, r.URL.EscapedPath())
+		}
+
+		if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex([]byte("data")) {
+			t.Errorf("payload hash = %s", r.Header.Get("X-Amz-Content-Sha256"))
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request") {
+			t.Errorf("authorization = %s", r.Header.Get("Authorization"))
+		}
+	}))
+	defer server.Close()
+
+	s3, err := NewS3(domain.S3{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "events",
+		Prefix:          "tinder",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	if err := s3.Put(ctx, "dt=2024-01-31/hour=09/batch.ndjson.gz", []byte("data")); err != nil {
+		t.Errorf("Put() error = %v", err)
+	}
+}
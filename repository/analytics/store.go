@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// Store persists one finished file under key, a slash separated path such as
+// "dt=2024-01-31/hour=09/<batch>.ndjson.gz".
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// Local writes files below a directory.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) *Local {
+	return &Local{
+		dir: dir,
+	}
+}
+
+func (l *Local) Put(_ context.Context, key string, data []byte) error {
+	return writeFile(filepath.Join(l.dir, filepath.FromSlash(key)), data)
+}
+
+// writeFile renames a complete temporary file into place, so readers never see
+// half a file.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
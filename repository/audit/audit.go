@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	cfg *domain.Config
+	dbs dbInterface
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+	}
+}
+
+func (m Module) Create(ctx context.Context, req domain.AuditLogRequest) error {
+	return m.dbs.create(ctx, req)
+}
+
+func (m Module) GetAll(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	return m.dbs.getAll(ctx, filter)
+}
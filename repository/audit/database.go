@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"context"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"time"
+)
+
+const defaultLimit = 50
+
+type dbModule struct {
+	db  *gorm.DB
+	cfg *domain.Config
+}
+
+// The audit log is append only, there is deliberately no update or delete.
+type dbInterface interface {
+	create(ctx context.Context, req domain.AuditLogRequest) error
+	getAll(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error)
+}
+
+func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
+	return &dbModule{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+func (d dbModule) create(ctx context.Context, req domain.AuditLogRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.audit.create")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.audit.create"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	payload := []byte("{}")
+	if req.Payload != nil {
+		var err error
+		if payload, err = json.Marshal(req.Payload); err != nil {
+			tags["error"] = err.Error()
+			tags["status"] = "error"
+			return err
+		}
+	}
+
+	auditLog := domain.AuditLog{
+		AdminID:      req.AdminID,
+		Action:       req.Action,
+		TargetUserID: req.TargetUserID,
+		Payload:      string(payload),
+	}
+
+	result := d.conn(ctx).Table("admin_audit_log").Create(&auditLog)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (d dbModule) getAll(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.audit.get_all")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.audit.get_all"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	query := d.conn(ctx).Table("admin_audit_log")
+	if filter.AdminID != 0 {
+		query = query.Where("admin_id = ?", filter.AdminID)
+	}
+
+	if filter.TargetUserID != 0 {
+		query = query.Where("target_user_id = ?", filter.TargetUserID)
+	}
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	var logs []domain.AuditLog
+	result := query.Order("id DESC").Limit(limit).Offset(filter.Offset).Find(&logs)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return logs, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/zombozo12/tinder-dealls/domain"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Module struct {
@@ -29,3 +30,80 @@ func (m Module) UpdateToken(ctx context.Context, userID int64, req domain.Update
 func (m Module) Register(ctx context.Context, req domain.AuthRequest) (domain.User, error) {
 	return m.dbs.register(ctx, req)
 }
+
+func (m Module) GetByID(ctx context.Context, userID int64) (*domain.User, error) {
+	return m.dbs.getByID(ctx, userID)
+}
+
+// GetAccessToken is the token the user last signed in with, empty when the user is deleted or has none.
+func (m Module) GetAccessToken(ctx context.Context, userID int64) (string, error) {
+	return m.dbs.getAccessToken(ctx, userID)
+}
+
+func (m Module) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return m.dbs.getByEmail(ctx, email)
+}
+
+func (m Module) GetByPhone(ctx context.Context, phone string) (*domain.User, error) {
+	return m.dbs.getByPhone(ctx, phone)
+}
+
+func (m Module) RegisterPhone(ctx context.Context, phone string) (domain.User, error) {
+	return m.dbs.registerPhone(ctx, phone)
+}
+
+func (m Module) GetIdentity(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	return m.dbs.getIdentity(ctx, provider, subject)
+}
+
+func (m Module) CreateIdentity(ctx context.Context, req domain.CreateIdentityRequest) error {
+	return m.dbs.createIdentity(ctx, req)
+}
+
+func (m Module) GetTOTP(ctx context.Context, userID int64) (*domain.UserTOTP, error) {
+	return m.dbs.getTOTP(ctx, userID)
+}
+
+func (m Module) SetTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	return m.dbs.setTOTPSecret(ctx, userID, secret)
+}
+
+func (m Module) EnableTOTP(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	return m.dbs.enableTOTP(ctx, userID, recoveryCodeHashes)
+}
+
+func (m Module) UseRecoveryCode(ctx context.Context, userID int64, code string) (bool, error) {
+	return m.dbs.useRecoveryCode(ctx, userID, code)
+}
+
+func (m Module) UseTOTPCounter(ctx context.Context, userID int64, counter int64) (bool, error) {
+	return m.dbs.useTOTPCounter(ctx, userID, counter)
+}
+
+func (m Module) GetTwoFactorStatus(ctx context.Context, userID int64) (*domain.TwoFactorStatus, error) {
+	return m.dbs.getTwoFactorStatus(ctx, userID)
+}
+
+func (m Module) GetIdentitiesByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error) {
+	return m.dbs.getIdentitiesByUserID(ctx, userID)
+}
+
+func (m Module) SoftDelete(ctx context.Context, userID int64) error {
+	return m.dbs.softDelete(ctx, userID)
+}
+
+func (m Module) GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.User, error) {
+	return m.dbs.getDeletedBefore(ctx, before, limit)
+}
+
+func (m Module) Purge(ctx context.Context, userID int64) error {
+	return m.dbs.purge(ctx, userID)
+}
+
+func (m Module) Search(ctx context.Context, req domain.UserSearchRequest) ([]domain.User, error) {
+	return m.dbs.search(ctx, req)
+}
+
+func (m Module) SetBanned(ctx context.Context, userID int64, bannedAt *time.Time) error {
+	return m.dbs.setBanned(ctx, userID, bannedAt)
+}
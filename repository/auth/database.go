@@ -6,8 +6,13 @@ import (
 	"github.com/go-playground/validator/v10"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"strconv"
 	"time"
 )
 
@@ -20,6 +25,25 @@ type dbInterface interface {
 	login(ctx context.Context, req domain.AuthRequest) (user domain.User, err error)
 	register(ctx context.Context, req domain.AuthRequest) (domain.User, error)
 	updateToken(ctx context.Context, userID int64, req domain.UpdateTokenRequest) error
+	getByID(ctx context.Context, userID int64) (user *domain.User, err error)
+	getAccessToken(ctx context.Context, userID int64) (string, error)
+	getByEmail(ctx context.Context, email string) (user *domain.User, err error)
+	getByPhone(ctx context.Context, phone string) (user *domain.User, err error)
+	registerPhone(ctx context.Context, phone string) (domain.User, error)
+	getIdentity(ctx context.Context, provider, subject string) (identity *domain.UserIdentity, err error)
+	createIdentity(ctx context.Context, req domain.CreateIdentityRequest) error
+	getTOTP(ctx context.Context, userID int64) (totp *domain.UserTOTP, err error)
+	setTOTPSecret(ctx context.Context, userID int64, secret string) error
+	enableTOTP(ctx context.Context, userID int64, recoveryCodeHashes []string) error
+	useRecoveryCode(ctx context.Context, userID int64, code string) (bool, error)
+	useTOTPCounter(ctx context.Context, userID int64, counter int64) (bool, error)
+	getTwoFactorStatus(ctx context.Context, userID int64) (status *domain.TwoFactorStatus, err error)
+	getIdentitiesByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error)
+	softDelete(ctx context.Context, userID int64) error
+	getDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.User, error)
+	purge(ctx context.Context, userID int64) error
+	search(ctx context.Context, req domain.UserSearchRequest) ([]domain.User, error)
+	setBanned(ctx context.Context, userID int64, bannedAt *time.Time) error
 }
 
 func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
@@ -29,20 +53,30 @@ func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
 	}
 }
 
+// conn joins the unit of work running on ctx, if any.
+func (m module) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, m.db)
+}
+
 func (m module) login(ctx context.Context, req domain.AuthRequest) (user domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.login")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.auth.login"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	if result := m.db.Table("users").Where("email = ?", req.Email).First(&user); result.Error != nil {
+	if result := m.conn(ctx).Table("users").Where("email = ? AND deleted_at IS NULL", req.Email).First(&user); result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return user, domain.ErrInvalidCredentials
+		}
 		return user, result.Error
 	}
 
@@ -50,14 +84,17 @@ func (m module) login(ctx context.Context, req domain.AuthRequest) (user domain.
 	if err != nil {
 		tags["error"] = err.Error()
 		tags["status"] = "error"
-		return user, err
+		return user, domain.ErrInvalidCredentials
 	}
 
 	user = domain.User{
-		ID:        user.ID,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:          user.ID,
+		Email:       user.Email,
+		TOTPEnabled: user.TOTPEnabled,
+		Role:        user.Role,
+		BannedAt:    user.BannedAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	}
 
 	tags["status"] = "success"
@@ -65,25 +102,27 @@ func (m module) login(ctx context.Context, req domain.AuthRequest) (user domain.
 }
 
 func (m module) register(ctx context.Context, req domain.AuthRequest) (domain.User, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.register")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.auth.register"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	user := domain.User{
 		Email:    req.Email,
 		Password: req.Password,
 	}
-	result := m.db.Table("users").Create(&user)
+	result := m.conn(ctx).Table("users").Create(&user)
 	if result.Error != nil {
 		tags["error"] = "failed creating user"
 		tags["status"] = "error"
-		return user, result.Error
+		return user, dberr.Translate(result.Error)
 	}
 
 	tags["status"] = "success"
@@ -91,14 +130,16 @@ func (m module) register(ctx context.Context, req domain.AuthRequest) (domain.Us
 }
 
 func (m module) updateToken(ctx context.Context, userID int64, req domain.UpdateTokenRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.update_token")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.auth.update_token"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	if userID == 0 {
@@ -116,7 +157,7 @@ func (m module) updateToken(ctx context.Context, userID int64, req domain.Update
 
 	req.UpdatedAt = time.Now()
 
-	result := m.db.Table("users").Where("id = ?", userID).
+	result := m.conn(ctx).Table("users").Where("id = ?", userID).
 		Updates(req)
 	if result.Error != nil {
 		tags["error"] = result.Error.Error()
@@ -127,3 +168,664 @@ func (m module) updateToken(ctx context.Context, userID int64, req domain.Update
 	tags["status"] = "success"
 	return nil
 }
+
+func (m module) getByID(ctx context.Context, userID int64) (user *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_by_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_by_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("users").Where("id = ? AND deleted_at IS NULL", userID).First(&user); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	user.Password = ""
+	user.AccessToken = ""
+
+	tags["status"] = "success"
+	return user, nil
+}
+
+func (m module) getAccessToken(ctx context.Context, userID int64) (string, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_access_token")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_access_token"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var user struct {
+		AccessToken *string
+	}
+	if result := m.conn(ctx).Table("users").Select("access_token").
+		Where("id = ? AND deleted_at IS NULL", userID).Take(&user); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return "", nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return "", result.Error
+	}
+
+	tags["status"] = "success"
+	if user.AccessToken == nil {
+		return "", nil
+	}
+	return *user.AccessToken, nil
+}
+
+func (m module) getByEmail(ctx context.Context, email string) (user *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_by_email")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_by_email"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("users").Where("email = ? AND deleted_at IS NULL", email).First(&user); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	user.Password = ""
+	user.AccessToken = ""
+
+	tags["status"] = "success"
+	return user, nil
+}
+
+func (m module) getByPhone(ctx context.Context, phone string) (user *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_by_phone")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_by_phone"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("users").Where("phone = ? AND deleted_at IS NULL", phone).First(&user); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	user.Password = ""
+	user.AccessToken = ""
+
+	tags["status"] = "success"
+	return user, nil
+}
+
+func (m module) registerPhone(ctx context.Context, phone string) (domain.User, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.register_phone")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.register_phone"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	user := domain.User{
+		Phone: phone,
+	}
+
+	// Phone accounts have no email or password, leave both columns NULL instead of inserting
+	// empty strings that would collide on the unique email index.
+	result := m.conn(ctx).Table("users").Omit("email", "password").Create(&user)
+	if result.Error != nil {
+		tags["error"] = "failed creating user"
+		tags["status"] = "error"
+		return user, dberr.Translate(result.Error)
+	}
+
+	tags["status"] = "success"
+	return user, nil
+}
+
+func (m module) getIdentity(ctx context.Context, provider, subject string) (identity *domain.UserIdentity, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_identity")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_identity"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("user_identity").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return identity, nil
+}
+
+func (m module) createIdentity(ctx context.Context, req domain.CreateIdentityRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.create_identity")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.create_identity"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	identity := domain.UserIdentity{
+		UserID:   req.UserID,
+		Provider: req.Provider,
+		Subject:  req.Subject,
+		Email:    req.Email,
+	}
+
+	if result := m.conn(ctx).Table("user_identity").Create(&identity); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return dberr.Translate(result.Error)
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (m module) getTOTP(ctx context.Context, userID int64) (totp *domain.UserTOTP, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_totp")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_totp"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("users").
+		Select("id AS user_id, totp_secret, totp_enabled, totp_confirmed_at").
+		Where("id = ?", userID).
+		First(&totp); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return totp, nil
+}
+
+func (m module) setTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.set_totp_secret")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.set_totp_secret"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := m.conn(ctx).Table("users").Where("id = ? AND totp_enabled = ?", userID, false).
+		Updates(map[string]interface{}{
+			"totp_secret": secret,
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tags["error"] = "totp already enabled"
+		tags["status"] = "error"
+		return domain.ErrTOTPAlreadyEnabled
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// enableTOTP flips the flag and replaces any previous recovery codes in one transaction.
+func (m module) enableTOTP(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.enable_totp")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.enable_totp"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err := m.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if result := tx.Table("users").Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_enabled":      true,
+				"totp_confirmed_at": now,
+				"updated_at":        now,
+			}); result.Error != nil {
+			return result.Error
+		}
+
+		if result := tx.Table("user_recovery_code").Where("user_id = ?", userID).
+			Delete(&domain.RecoveryCode{}); result.Error != nil {
+			return result.Error
+		}
+
+		codes := make([]domain.RecoveryCode, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			codes = append(codes, domain.RecoveryCode{
+				UserID:   userID,
+				CodeHash: hash,
+			})
+		}
+
+		return dberr.Translate(tx.Table("user_recovery_code").Create(&codes).Error)
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// useRecoveryCode burns the matching unused recovery code, it reports false when none matches.
+func (m module) useRecoveryCode(ctx context.Context, userID int64, code string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.use_recovery_code")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.use_recovery_code"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var codes []domain.RecoveryCode
+	if result := m.conn(ctx).Table("user_recovery_code").
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Find(&codes); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return false, result.Error
+	}
+
+	for _, v := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(v.CodeHash), []byte(code)) != nil {
+			continue
+		}
+
+		// The used_at guard keeps two concurrent logins from burning the same code twice.
+		result := m.conn(ctx).Table("user_recovery_code").
+			Where("id = ? AND used_at IS NULL", v.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			tags["error"] = result.Error.Error()
+			tags["status"] = "error"
+			return false, result.Error
+		}
+
+		tags["status"] = "success"
+		return result.RowsAffected == 1, nil
+	}
+
+	tags["status"] = "not_found"
+	return false, nil
+}
+
+// useTOTPCounter records the period of an accepted authenticator code, it reports false when a code of the same
+// or a later period was accepted before.
+func (m module) useTOTPCounter(ctx context.Context, userID int64, counter int64) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.use_totp_counter")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.use_totp_counter"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := m.conn(ctx).Table("users").
+		Where("id = ? AND (totp_last_counter IS NULL OR totp_last_counter < ?)", userID, counter).
+		UpdateColumn("totp_last_counter", counter)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tags["status"] = "warning"
+		tags["warning"] = "code already used"
+		return false, nil
+	}
+
+	tags["status"] = "success"
+	return true, nil
+}
+
+func (m module) getTwoFactorStatus(ctx context.Context, userID int64) (status *domain.TwoFactorStatus, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_two_factor_status")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_two_factor_status"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("users").
+		Select("users.id AS user_id, users.totp_enabled AS enabled, users.totp_confirmed_at AS confirmed_at, "+
+			"(SELECT COUNT(*) FROM user_recovery_code WHERE user_id = users.id AND used_at IS NULL) AS recovery_codes_remaining").
+		Where("users.id = ?", userID).
+		First(&status); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return status, nil
+}
+
+func (m module) getIdentitiesByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_identities_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_identities_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var identities []domain.UserIdentity
+	if result := m.conn(ctx).Table("user_identity").Where("user_id = ?", userID).Find(&identities); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return identities, nil
+}
+
+func (m module) softDelete(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.soft_delete")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.soft_delete"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	now := time.Now()
+	result := m.conn(ctx).Table("users").Where("id = ? AND deleted_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"access_token":     nil,
+			"token_expired_at": nil,
+			"deleted_at":       now,
+			"updated_at":       now,
+		})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tags["error"] = "user not found"
+		tags["status"] = "error"
+		return domain.ErrUserNotFound
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (m module) getDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.User, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.get_deleted_before")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.get_deleted_before"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var users []domain.User
+	if result := m.conn(ctx).Table("users").
+		Select("id, email, phone, deleted_at").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&users); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["count"] = len(users)
+	tags["status"] = "success"
+	return users, nil
+}
+
+func (m module) purge(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.purge")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.purge"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err := m.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Table("user_recovery_code").Where("user_id = ?", userID).
+			Delete(&domain.RecoveryCode{}); result.Error != nil {
+			return result.Error
+		}
+
+		if result := tx.Table("user_identity").Where("user_id = ?", userID).
+			Delete(&domain.UserIdentity{}); result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Table("users").Where("id = ?", userID).Delete(&domain.User{}).Error
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (m module) search(ctx context.Context, req domain.UserSearchRequest) ([]domain.User, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.search")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.search"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	query := m.conn(ctx).Table("users").
+		Select("id, email, phone, totp_enabled, role, banned_at, created_at, updated_at, deleted_at")
+	if req.Query != "" {
+		pattern := "%" + req.Query + "%"
+		if id, err := strconv.ParseInt(req.Query, 10, 64); err == nil {
+			query = query.Where("id = ? OR email ILIKE ? OR phone ILIKE ?", id, pattern, pattern)
+		} else {
+			query = query.Where("email ILIKE ? OR phone ILIKE ?", pattern, pattern)
+		}
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	var users []domain.User
+	if result := query.Order("id").Limit(limit).Offset(req.Offset).Find(&users); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["count"] = len(users)
+	tags["status"] = "success"
+	return users, nil
+}
+
+func (m module) setBanned(ctx context.Context, userID int64, bannedAt *time.Time) error {
+	ctx, span := tracing.Start(ctx, "repo.database.auth.set_banned")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.auth.set_banned"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	updates := map[string]interface{}{
+		"banned_at":  bannedAt,
+		"updated_at": time.Now(),
+	}
+
+	// Banning also drops the stored token so the user has to sign in again.
+	if bannedAt != nil {
+		updates["access_token"] = nil
+		updates["token_expired_at"] = nil
+	}
+
+	result := m.conn(ctx).Table("users").Where("id = ? AND deleted_at IS NULL", userID).Updates(updates)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tags["error"] = "user not found"
+		tags["status"] = "error"
+		return domain.ErrUserNotFound
+	}
+
+	tags["status"] = "success"
+	return nil
+}
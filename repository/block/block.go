@@ -0,0 +1,35 @@
+package block
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	cfg *domain.Config
+	dbs dbInterface
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+	}
+}
+
+func (m Module) Create(ctx context.Context, blockerID int64, blockedID int64) error {
+	return m.dbs.create(ctx, blockerID, blockedID)
+}
+
+func (m Module) GetBlockedUserIDs(ctx context.Context, userID int64) ([]int64, error) {
+	return m.dbs.getBlockedUserIDs(ctx, userID)
+}
+
+func (m Module) GetByBlockerID(ctx context.Context, blockerID int64) ([]domain.UserBlock, error) {
+	return m.dbs.getByBlockerID(ctx, blockerID)
+}
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
@@ -0,0 +1,148 @@
+package block
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type dbModule struct {
+	db  *gorm.DB
+	cfg *domain.Config
+}
+
+type dbInterface interface {
+	create(ctx context.Context, blockerID int64, blockedID int64) error
+	getBlockedUserIDs(ctx context.Context, userID int64) ([]int64, error)
+	getByBlockerID(ctx context.Context, blockerID int64) ([]domain.UserBlock, error)
+	purgeByUserID(ctx context.Context, userID int64) error
+}
+
+func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
+	return &dbModule{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+func (d dbModule) create(ctx context.Context, blockerID int64, blockedID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.block.create")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.block.create"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	block := domain.UserBlock{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+	}
+
+	result := d.conn(ctx).Table("user_block").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&block)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return dberr.Translate(result.Error)
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// getBlockedUserIDs returns everyone on either side of a block with userID.
+func (d dbModule) getBlockedUserIDs(ctx context.Context, userID int64) ([]int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.block.get_blocked_user_ids")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.block.get_blocked_user_ids"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var ids []int64
+	result := d.conn(ctx).Raw("SELECT blocked_id FROM user_block WHERE blocker_id = ? "+
+		"UNION SELECT blocker_id FROM user_block WHERE blocked_id = ?", userID, userID).
+		Scan(&ids)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return ids, nil
+}
+
+func (d dbModule) getByBlockerID(ctx context.Context, blockerID int64) ([]domain.UserBlock, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.block.get_by_blocker_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.block.get_by_blocker_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var blocks []domain.UserBlock
+	result := d.conn(ctx).Table("user_block").Where("blocker_id = ?", blockerID).Order("id").Find(&blocks)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return blocks, nil
+}
+
+func (d dbModule) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.block.purge_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.block.purge_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("user_block").
+		Where("blocker_id = ? OR blocked_id = ?", userID, userID).
+		Delete(&domain.UserBlock{})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
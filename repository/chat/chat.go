@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	cfg *domain.Config
+	dbs dbInterface
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+	}
+}
+
+func (m Module) GetOrCreateConversation(ctx context.Context, userID int64, targetUserID int64) (*domain.Conversation, error) {
+	return m.dbs.getOrCreateConversation(ctx, userID, targetUserID)
+}
+
+func (m Module) GetConversation(ctx context.Context, conversationID int64) (*domain.Conversation, error) {
+	return m.dbs.getConversation(ctx, conversationID)
+}
+
+func (m Module) GetConversationsByUserID(ctx context.Context, userID int64) ([]domain.Conversation, error) {
+	return m.dbs.getConversationsByUserID(ctx, userID)
+}
+
+func (m Module) CreateMessage(ctx context.Context, req domain.CreateMessageRequest) (*domain.Message, error) {
+	return m.dbs.createMessage(ctx, req)
+}
+
+func (m Module) GetMessages(ctx context.Context, conversationID int64, before int64, limit int) ([]domain.Message, error) {
+	return m.dbs.getMessages(ctx, conversationID, before, limit)
+}
+
+func (m Module) UpdateReceipts(ctx context.Context, conversationID int64, recipientID int64, upToMessageID int64,
+	status string) (int64, error) {
+	return m.dbs.updateReceipts(ctx, conversationID, recipientID, upToMessageID, status)
+}
+
+func (m Module) GetMessagesBySenderID(ctx context.Context, senderID int64) ([]domain.Message, error) {
+	return m.dbs.getMessagesBySenderID(ctx, senderID)
+}
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
@@ -0,0 +1,316 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type dbModule struct {
+	db  *gorm.DB
+	cfg *domain.Config
+}
+
+type dbInterface interface {
+	getOrCreateConversation(ctx context.Context, userID int64, targetUserID int64) (*domain.Conversation, error)
+	getConversation(ctx context.Context, conversationID int64) (*domain.Conversation, error)
+	getConversationsByUserID(ctx context.Context, userID int64) ([]domain.Conversation, error)
+	createMessage(ctx context.Context, req domain.CreateMessageRequest) (*domain.Message, error)
+	getMessages(ctx context.Context, conversationID int64, before int64, limit int) ([]domain.Message, error)
+	updateReceipts(ctx context.Context, conversationID int64, recipientID int64, upToMessageID int64, status string) (int64, error)
+	getMessagesBySenderID(ctx context.Context, senderID int64) ([]domain.Message, error)
+	purgeByUserID(ctx context.Context, userID int64) error
+}
+
+func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
+	return &dbModule{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func (d dbModule) getOrCreateConversation(ctx context.Context, userID int64, targetUserID int64) (*domain.Conversation, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.get_or_create_conversation")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.get_or_create_conversation"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	userAID, userBID := userID, targetUserID
+	if userAID > userBID {
+		userAID, userBID = userBID, userAID
+	}
+
+	conversation := domain.Conversation{
+		UserAID: userAID,
+		UserBID: userBID,
+	}
+
+	if result := uow.Bind(ctx, d.db).Table("conversation").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&conversation); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, dberr.Translate(result.Error)
+	}
+
+	if result := uow.Bind(ctx, d.db).Table("conversation").
+		Where("user_a_id = ? AND user_b_id = ?", userAID, userBID).
+		First(&conversation); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return &conversation, nil
+}
+
+func (d dbModule) getConversation(ctx context.Context, conversationID int64) (*domain.Conversation, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.get_conversation")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.get_conversation"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var conversation domain.Conversation
+	if result := uow.Bind(ctx, d.db).Table("conversation").Where("id = ?", conversationID).First(&conversation); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return &conversation, nil
+}
+
+func (d dbModule) getConversationsByUserID(ctx context.Context, userID int64) ([]domain.Conversation, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.get_conversations_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.get_conversations_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var conversations []domain.Conversation
+	if result := uow.Bind(ctx, d.db).Table("conversation").
+		Where("user_a_id = ? OR user_b_id = ?", userID, userID).
+		Order("last_message_at DESC NULLS LAST, id DESC").
+		Find(&conversations); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return conversations, nil
+}
+
+func (d dbModule) createMessage(ctx context.Context, req domain.CreateMessageRequest) (*domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.create_message")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.create_message"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	message := domain.Message{
+		ConversationID: req.ConversationID,
+		SenderID:       req.SenderID,
+		Body:           req.Body,
+		Status:         domain.MessageStatusSent,
+		CreatedAt:      time.Now(),
+	}
+
+	err := uow.Bind(ctx, d.db).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Table("message").Create(&message); result.Error != nil {
+			return dberr.Translate(result.Error)
+		}
+
+		return tx.Table("conversation").Where("id = ?", req.ConversationID).
+			Updates(map[string]interface{}{
+				"last_message_at": message.CreatedAt,
+				"updated_at":      message.CreatedAt,
+			}).Error
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return &message, nil
+}
+
+// getMessages returns up to limit messages older than before, newest first. A zero before starts
+// from the latest message.
+func (d dbModule) getMessages(ctx context.Context, conversationID int64, before int64, limit int) ([]domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.get_messages")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.get_messages"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	query := uow.Bind(ctx, d.db).Table("message").Where("conversation_id = ?", conversationID)
+	if before > 0 {
+		query = query.Where("id < ?", before)
+	}
+
+	var messages []domain.Message
+	if result := query.Order("id DESC").Limit(limit).Find(&messages); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return messages, nil
+}
+
+// updateReceipts moves every message addressed to recipientID up to and including upToMessageID
+// forward to status. Receipts never move backwards.
+func (d dbModule) updateReceipts(ctx context.Context, conversationID int64, recipientID int64, upToMessageID int64,
+	status string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.update_receipts")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.update_receipts"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	now := time.Now()
+	query := uow.Bind(ctx, d.db).Table("message").
+		Where("conversation_id = ? AND sender_id <> ? AND id <= ?", conversationID, recipientID, upToMessageID)
+
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case domain.MessageStatusDelivered:
+		query = query.Where("status = ?", domain.MessageStatusSent)
+		updates["delivered_at"] = now
+	case domain.MessageStatusRead:
+		query = query.Where("status IN (?)", []string{domain.MessageStatusSent, domain.MessageStatusDelivered})
+		updates["delivered_at"] = gorm.Expr("COALESCE(delivered_at, ?)", now)
+		updates["read_at"] = now
+	default:
+		tags["error"] = "invalid status"
+		tags["status"] = "error"
+		return 0, errors.New("invalid status")
+	}
+
+	result := query.Updates(updates)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return 0, result.Error
+	}
+
+	tags["updated"] = result.RowsAffected
+	tags["status"] = "success"
+	return result.RowsAffected, nil
+}
+
+func (d dbModule) getMessagesBySenderID(ctx context.Context, senderID int64) ([]domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.get_messages_by_sender_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.get_messages_by_sender_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var messages []domain.Message
+	if result := uow.Bind(ctx, d.db).Table("message").Where("sender_id = ?", senderID).Order("id").Find(&messages); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return messages, nil
+}
+
+func (d dbModule) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.chat.purge_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.chat.purge_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err := uow.Bind(ctx, d.db).Transaction(func(tx *gorm.DB) error {
+		conversations := tx.Table("conversation").Select("id").
+			Where("user_a_id = ? OR user_b_id = ?", userID, userID)
+
+		if result := tx.Table("message").Where("conversation_id IN (?)", conversations).
+			Delete(&domain.Message{}); result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Table("conversation").Where("user_a_id = ? OR user_b_id = ?", userID, userID).
+			Delete(&domain.Conversation{}).Error
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
@@ -0,0 +1,58 @@
+package contentfilter
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"regexp"
+	"unicode"
+)
+
+var (
+	urlPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+|\b[a-z0-9-]+\s?(\.|\(dot\)|\[dot\])\s?(com|net|org|io|me|co|id|ly|gg|app|link|xyz)\b`)
+	// Digits with the usual separators in between, checked for length afterwards.
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s().-]{6,}\d`)
+)
+
+const minPhoneDigits = 8
+
+// ContactDetails blocks links and phone numbers so users are not pulled off the platform.
+type ContactDetails struct{}
+
+func NewContactDetails() *ContactDetails {
+	return &ContactDetails{}
+}
+
+func (c ContactDetails) Name() string {
+	return "contact_details"
+}
+
+func (c ContactDetails) Check(_ context.Context, content domain.FilterContent) (domain.FilterResult, error) {
+	if urlPattern.MatchString(content.Text) {
+		return domain.FilterResult{
+			Verdict: domain.FilterVerdictBlock,
+			Reason:  "contains a link",
+		}, nil
+	}
+
+	for _, match := range phonePattern.FindAllString(content.Text, -1) {
+		if countDigits(match) >= minPhoneDigits {
+			return domain.FilterResult{
+				Verdict: domain.FilterVerdictBlock,
+				Reason:  "contains a phone number",
+			}, nil
+		}
+	}
+
+	return domain.FilterResult{Verdict: domain.FilterVerdictAllow}, nil
+}
+
+func countDigits(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsDigit(r) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,78 @@
+package contentfilter
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+// Filter screens a piece of content, it returns an allow verdict when it has nothing to say.
+type Filter interface {
+	Name() string
+	Check(ctx context.Context, content domain.FilterContent) (domain.FilterResult, error)
+}
+
+// Chain runs filters in order. It stops at the first block, otherwise the first flag wins.
+type Chain struct {
+	filters []Filter
+}
+
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+// New builds the default chain: banned words, contact details and the message rate heuristic.
+func New(cfg *domain.Config, counter Counter) *Chain {
+	return NewChain(
+		NewBannedWords(cfg.Moderation.BannedWords),
+		NewContactDetails(),
+		NewMessageRate(cfg, counter),
+	)
+}
+
+func (c Chain) Check(ctx context.Context, content domain.FilterContent) (domain.FilterResult, error) {
+	ctx, span := tracing.Start(ctx, "repo.contentfilter.check")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.contentfilter.check"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		tags["kind"] = content.Kind
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	verdict := domain.FilterResult{Verdict: domain.FilterVerdictAllow}
+	for _, filter := range c.filters {
+		result, err := filter.Check(ctx, content)
+		if err != nil {
+			tags["error"] = err.Error()
+			tags["filter"] = filter.Name()
+			tags["status"] = "error"
+			return domain.FilterResult{}, err
+		}
+
+		if result.Verdict == domain.FilterVerdictAllow {
+			continue
+		}
+
+		result.Filter = filter.Name()
+		if result.Blocked() {
+			verdict = result
+			break
+		}
+
+		if verdict.Verdict == domain.FilterVerdictAllow {
+			verdict = result
+		}
+	}
+
+	tags["verdict"] = verdict.Verdict
+	tags["status"] = "success"
+	return verdict, nil
+}
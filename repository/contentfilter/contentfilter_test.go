@@ -0,0 +1,139 @@
+package contentfilter
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+type fakeCounter struct {
+	counts map[string]int64
+}
+
+func (f *fakeCounter) Incr(_ context.Context, key string) (int64, error) {
+	f.counts[key]++
+	return f.counts[key], nil
+}
+
+func (f *fakeCounter) Expire(_ context.Context, _ string, _ int) error {
+	return nil
+}
+
+func TestBannedWords_Check(t *testing.T) {
+	filter := NewBannedWords([]string{"scam", "Bad Word"})
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "clean", text: "hello there, fancy a coffee?", want: domain.FilterVerdictAllow},
+		{name: "plain", text: "this is a scam", want: domain.FilterVerdictBlock},
+		{name: "case", text: "SCAM alert", want: domain.FilterVerdictBlock},
+		{name: "leetspeak", text: "total $c4m", want: domain.FilterVerdictBlock},
+		{name: "spaced out", text: "s c a m", want: domain.FilterVerdictBlock},
+		{name: "dotted", text: "s.c.4.m", want: domain.FilterVerdictBlock},
+		{name: "inside a longer word", text: "scampi for dinner", want: domain.FilterVerdictAllow},
+		{name: "multi word entry", text: "badword", want: domain.FilterVerdictBlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.Check(context.Background(), domain.FilterContent{Text: tt.text})
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if got.Verdict != tt.want {
+				t.Errorf("Check(%q) verdict = %v, want %v", tt.text, got.Verdict, tt.want)
+			}
+		})
+	}
+}
+
+func TestContactDetails_Check(t *testing.T) {
+	filter := NewContactDetails()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "clean", text: "I have 2 cats and 1 dog", want: domain.FilterVerdictAllow},
+		{name: "year and age", text: "born in 1995, 28 years old", want: domain.FilterVerdictAllow},
+		{name: "url", text: "see https://example.com/me", want: domain.FilterVerdictBlock},
+		{name: "www", text: "www.example.org", want: domain.FilterVerdictBlock},
+		{name: "bare domain", text: "add me at mysite.com", want: domain.FilterVerdictBlock},
+		{name: "obfuscated domain", text: "mysite (dot) com", want: domain.FilterVerdictBlock},
+		{name: "phone", text: "call me +62 812-3456-7890", want: domain.FilterVerdictBlock},
+		{name: "phone without separators", text: "081234567890", want: domain.FilterVerdictBlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.Check(context.Background(), domain.FilterContent{Text: tt.text})
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if got.Verdict != tt.want {
+				t.Errorf("Check(%q) verdict = %v, want %v", tt.text, got.Verdict, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageRate_Check(t *testing.T) {
+	ctx := context.Background()
+	cfg := &domain.Config{Moderation: domain.Moderation{MessageRateLimit: 2}}
+	filter := NewMessageRate(cfg, &fakeCounter{counts: make(map[string]int64)})
+
+	message := domain.FilterContent{UserID: 1, Kind: domain.ContentKindMessage, Text: "hi"}
+	want := []string{
+		domain.FilterVerdictAllow,
+		domain.FilterVerdictAllow,
+		domain.FilterVerdictFlag,
+		domain.FilterVerdictFlag,
+		domain.FilterVerdictBlock,
+	}
+
+	for i, verdict := range want {
+		got, err := filter.Check(ctx, message)
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+
+		if got.Verdict != verdict {
+			t.Errorf("message %d verdict = %v, want %v", i+1, got.Verdict, verdict)
+		}
+	}
+
+	bio := domain.FilterContent{UserID: 1, Kind: domain.ContentKindBio, Text: "hi"}
+	if got, _ := filter.Check(ctx, bio); got.Verdict != domain.FilterVerdictAllow {
+		t.Errorf("bio verdict = %v, want %v", got.Verdict, domain.FilterVerdictAllow)
+	}
+}
+
+func TestChain_Check(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	cfg := &domain.Config{Moderation: domain.Moderation{
+		BannedWords:      []string{"scam"},
+		MessageRateLimit: 1,
+	}}
+	chain := New(cfg, &fakeCounter{counts: make(map[string]int64)})
+
+	got, err := chain.Check(ctx, domain.FilterContent{UserID: 1, Kind: domain.ContentKindMessage, Text: "hello"})
+	if err != nil || got.Verdict != domain.FilterVerdictAllow {
+		t.Fatalf("Check() = %v, %v, want allow", got, err)
+	}
+
+	got, err = chain.Check(ctx, domain.FilterContent{UserID: 1, Kind: domain.ContentKindMessage, Text: "hello again"})
+	if err != nil || got.Verdict != domain.FilterVerdictFlag || got.Filter != "message_rate" {
+		t.Fatalf("Check() = %v, %v, want flag by message_rate", got, err)
+	}
+
+	got, err = chain.Check(ctx, domain.FilterContent{UserID: 2, Kind: domain.ContentKindBio, Text: "no scam here"})
+	if err != nil || got.Verdict != domain.FilterVerdictBlock || got.Filter != "banned_words" {
+		t.Fatalf("Check() = %v, %v, want block by banned_words", got, err)
+	}
+}
@@ -0,0 +1,81 @@
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"github.com/zombozo12/tinder-dealls/domain"
+)
+
+const (
+	defaultMessageRateLimit  = 20
+	defaultMessageRateWindow = 60 // in seconds
+)
+
+// Counter is the part of the Redis repository the rate heuristic needs.
+type Counter interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, expiration int) error
+}
+
+// MessageRate counts chat messages per user in a fixed window. Going over the limit flags the message for
+// moderators, going over twice the limit blocks it.
+type MessageRate struct {
+	counter Counter
+	limit   int64
+	window  int
+}
+
+func NewMessageRate(cfg *domain.Config, counter Counter) *MessageRate {
+	limit := cfg.Moderation.MessageRateLimit
+	if limit == 0 {
+		limit = defaultMessageRateLimit
+	}
+
+	window := cfg.Moderation.MessageRateWindow
+	if window == 0 {
+		window = defaultMessageRateWindow
+	}
+
+	return &MessageRate{
+		counter: counter,
+		limit:   int64(limit),
+		window:  window,
+	}
+}
+
+func (m MessageRate) Name() string {
+	return "message_rate"
+}
+
+func (m MessageRate) Check(ctx context.Context, content domain.FilterContent) (domain.FilterResult, error) {
+	if content.Kind != domain.ContentKindMessage {
+		return domain.FilterResult{Verdict: domain.FilterVerdictAllow}, nil
+	}
+
+	key := fmt.Sprintf("message_rate:%d", content.UserID)
+	count, err := m.counter.Incr(ctx, key)
+	if err != nil {
+		return domain.FilterResult{}, err
+	}
+
+	if count == 1 {
+		if err := m.counter.Expire(ctx, key, m.window); err != nil {
+			return domain.FilterResult{}, err
+		}
+	}
+
+	switch {
+	case count > 2*m.limit:
+		return domain.FilterResult{
+			Verdict: domain.FilterVerdictBlock,
+			Reason:  "sending messages too fast",
+		}, nil
+	case count > m.limit:
+		return domain.FilterResult{
+			Verdict: domain.FilterVerdictFlag,
+			Reason:  fmt.Sprintf("sent %d messages within %d seconds", count, m.window),
+		}, nil
+	}
+
+	return domain.FilterResult{Verdict: domain.FilterVerdictAllow}, nil
+}
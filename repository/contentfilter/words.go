@@ -0,0 +1,91 @@
+package contentfilter
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"strings"
+	"unicode"
+)
+
+var leetspeak = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"8", "b",
+	"@", "a",
+	"$", "s",
+	"!", "i",
+	"|", "l",
+)
+
+type BannedWords struct {
+	words map[string]struct{}
+}
+
+func NewBannedWords(words []string) *BannedWords {
+	banned := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		if word = strings.Join(tokenize(word), ""); word != "" {
+			banned[word] = struct{}{}
+		}
+	}
+
+	return &BannedWords{words: banned}
+}
+
+func (b BannedWords) Name() string {
+	return "banned_words"
+}
+
+func (b BannedWords) Check(_ context.Context, content domain.FilterContent) (domain.FilterResult, error) {
+	if len(b.words) == 0 {
+		return domain.FilterResult{Verdict: domain.FilterVerdictAllow}, nil
+	}
+
+	for _, candidate := range candidates(tokenize(content.Text)) {
+		if _, ok := b.words[candidate]; ok {
+			return domain.FilterResult{
+				Verdict: domain.FilterVerdictBlock,
+				Reason:  "contains banned word",
+			}, nil
+		}
+	}
+
+	return domain.FilterResult{Verdict: domain.FilterVerdictAllow}, nil
+}
+
+// tokenize lowercases the text, undoes leetspeak and splits it into words.
+func tokenize(text string) []string {
+	normalized := leetspeak.Replace(strings.ToLower(text))
+	return strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// candidates returns every token plus runs of single letters joined together, so "s p a m" and "s.p.a.m"
+// match "spam" without matching it inside longer words.
+func candidates(tokens []string) []string {
+	result := append([]string{}, tokens...)
+
+	var run strings.Builder
+	flush := func() {
+		if run.Len() > 1 {
+			result = append(result, run.String())
+		}
+		run.Reset()
+	}
+
+	for _, token := range tokens {
+		if len([]rune(token)) == 1 {
+			run.WriteString(token)
+			continue
+		}
+		flush()
+	}
+	flush()
+
+	return result
+}
@@ -0,0 +1,35 @@
+package contentflag
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	cfg *domain.Config
+	dbs dbInterface
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+	}
+}
+
+func (m Module) Create(ctx context.Context, content domain.FilterContent, result domain.FilterResult) error {
+	return m.dbs.create(ctx, content, result)
+}
+
+func (m Module) GetAll(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error) {
+	return m.dbs.getAll(ctx, filter)
+}
+
+func (m Module) GetAllByUserID(ctx context.Context, userID int64) ([]domain.ContentFlag, error) {
+	return m.dbs.getAllByUserID(ctx, userID)
+}
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
@@ -0,0 +1,162 @@
+package contentflag
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"time"
+)
+
+const defaultLimit = 50
+
+type dbModule struct {
+	db  *gorm.DB
+	cfg *domain.Config
+}
+
+type dbInterface interface {
+	create(ctx context.Context, content domain.FilterContent, result domain.FilterResult) error
+	getAll(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error)
+	getAllByUserID(ctx context.Context, userID int64) ([]domain.ContentFlag, error)
+	purgeByUserID(ctx context.Context, userID int64) error
+}
+
+func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
+	return &dbModule{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+func (d dbModule) create(ctx context.Context, content domain.FilterContent, result domain.FilterResult) error {
+	ctx, span := tracing.Start(ctx, "repo.database.content_flag.create")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.content_flag.create"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	flag := domain.ContentFlag{
+		UserID:  content.UserID,
+		Kind:    content.Kind,
+		Content: content.Text,
+		Verdict: result.Verdict,
+		Filter:  result.Filter,
+		Reason:  result.Reason,
+	}
+
+	if res := d.conn(ctx).Table("content_flag").Create(&flag); res.Error != nil {
+		tags["error"] = res.Error.Error()
+		tags["status"] = "error"
+		return dberr.Translate(res.Error)
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (d dbModule) getAll(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.content_flag.get_all")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.content_flag.get_all"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	query := d.conn(ctx).Table("content_flag")
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+
+	if filter.Verdict != "" {
+		query = query.Where("verdict = ?", filter.Verdict)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	var flags []domain.ContentFlag
+	result := query.Order("id DESC").Limit(limit).Offset(filter.Offset).Find(&flags)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return flags, nil
+}
+
+func (d dbModule) getAllByUserID(ctx context.Context, userID int64) ([]domain.ContentFlag, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.content_flag.get_all_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.content_flag.get_all_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var flags []domain.ContentFlag
+	result := d.conn(ctx).Table("content_flag").Where("user_id = ?", userID).Order("id").Find(&flags)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return flags, nil
+}
+
+func (d dbModule) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.content_flag.purge_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.content_flag.purge_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := d.conn(ctx).Table("content_flag").Where("user_id = ?", userID).Delete(&domain.ContentFlag{}); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
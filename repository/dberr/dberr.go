@@ -0,0 +1,41 @@
+package dberr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// Translate turns constraint violations into domain errors, so services can
+// tell a lost race from a broken database. A duplicate becomes
+// domain.ErrAlreadyExists, a reference to a missing row domain.ErrNotFound.
+// The original error stays wrapped and other errors pass through unchanged.
+func Translate(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return fmt.Errorf("%w: %s: %w", domain.ErrAlreadyExists, pgErr.ConstraintName, err)
+		case foreignKeyViolation:
+			return fmt.Errorf("%w: %s: %w", domain.ErrNotFound, pgErr.ConstraintName, err)
+		}
+		return err
+	}
+
+	// Connections opened with gorm's TranslateError already lost the driver error.
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return fmt.Errorf("%w: %w", domain.ErrAlreadyExists, err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated), errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("%w: %w", domain.ErrNotFound, err)
+	}
+
+	return err
+}
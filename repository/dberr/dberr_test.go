@@ -0,0 +1,52 @@
+package dberr
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "unique violation",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "profile_user_id_key"},
+			want: domain.ErrAlreadyExists,
+		},
+		{
+			name: "foreign key violation",
+			err:  &pgconn.PgError{Code: "23503", ConstraintName: "profile_user_id_fkey"},
+			want: domain.ErrNotFound,
+		},
+		{
+			name: "translated by gorm",
+			err:  gorm.ErrDuplicatedKey,
+			want: domain.ErrAlreadyExists,
+		},
+		{
+			name: "record not found",
+			err:  gorm.ErrRecordNotFound,
+			want: domain.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Translate(tt.err)
+			if !errors.Is(got, tt.want) || !errors.Is(got, tt.err) {
+				t.Errorf("Translate() = %v, want wrapping %v and %v", got, tt.want, tt.err)
+			}
+		})
+	}
+
+	other := &pgconn.PgError{Code: "40001"}
+	if got := Translate(other); got != other {
+		t.Errorf("Translate() = %v, want unchanged", got)
+	}
+}
@@ -0,0 +1,188 @@
+package device
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type dbModule struct {
+	db  *gorm.DB
+	cfg *domain.Config
+}
+
+type dbInterface interface {
+	register(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error
+	getByUserID(ctx context.Context, userID int64) ([]domain.DeviceToken, error)
+	unregister(ctx context.Context, userID int64, token string) error
+	deleteByToken(ctx context.Context, token string) error
+	purgeByUserID(ctx context.Context, userID int64) error
+}
+
+func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
+	return &dbModule{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+// register stores the token, a token seen before moves to the new owner since
+// the device was signed in with another account.
+func (d dbModule) register(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.device.register")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.device.register"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	device := domain.DeviceToken{
+		UserID:   userID,
+		Platform: req.Platform,
+		Token:    req.Token,
+		Locale:   req.Locale,
+	}
+
+	result := d.conn(ctx).Table("device_token").
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "token"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"user_id":    userID,
+				"platform":   req.Platform,
+				"locale":     req.Locale,
+				"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+			}),
+		}).
+		Create(&device)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return dberr.Translate(result.Error)
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (d dbModule) getByUserID(ctx context.Context, userID int64) ([]domain.DeviceToken, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.device.get_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.device.get_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var devices []domain.DeviceToken
+	result := d.conn(ctx).Table("device_token").
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&devices)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return devices, nil
+}
+
+func (d dbModule) unregister(ctx context.Context, userID int64, token string) error {
+	ctx, span := tracing.Start(ctx, "repo.database.device.unregister")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.device.unregister"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("device_token").
+		Where("user_id = ? AND token = ?", userID, token).
+		Delete(&domain.DeviceToken{})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (d dbModule) deleteByToken(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "repo.database.device.delete_by_token")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.device.delete_by_token"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("device_token").
+		Where("token = ?", token).
+		Delete(&domain.DeviceToken{})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (d dbModule) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.device.purge_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.device.purge_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("device_token").
+		Where("user_id = ?", userID).
+		Delete(&domain.DeviceToken{})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
@@ -0,0 +1,39 @@
+package device
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	cfg *domain.Config
+	dbs dbInterface
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+	}
+}
+
+func (m Module) Register(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error {
+	return m.dbs.register(ctx, userID, req)
+}
+
+func (m Module) GetByUserID(ctx context.Context, userID int64) ([]domain.DeviceToken, error) {
+	return m.dbs.getByUserID(ctx, userID)
+}
+
+func (m Module) Unregister(ctx context.Context, userID int64, token string) error {
+	return m.dbs.unregister(ctx, userID, token)
+}
+
+func (m Module) DeleteByToken(ctx context.Context, token string) error {
+	return m.dbs.deleteByToken(ctx, token)
+}
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
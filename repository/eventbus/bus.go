@@ -0,0 +1,207 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"sync"
+	"time"
+)
+
+type Handler func(ctx context.Context, event domain.Event) error
+
+// Transport carries envelopes from Publish to the async subscribers, which may
+// live on another instance.
+type Transport interface {
+	Send(ctx context.Context, envelope domain.EventEnvelope) error
+	// Receive hands envelopes to deliver until stop is closed.
+	Receive(stop <-chan struct{}, deliver func(envelope domain.EventEnvelope))
+}
+
+type occurredAtKey struct{}
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// Bus dispatches domain events to subscribers. Sync subscribers run inside
+// Publish with the caller's context, so they join its unit of work and their
+// errors fail the publish. Async subscribers get the event through the
+// transport once the unit of work committed, their errors are only logged.
+type Bus struct {
+	cfg       *domain.Config
+	transport Transport
+	mu        sync.RWMutex
+	sync      map[string][]subscriber
+	async     map[string][]subscriber
+	wg        sync.WaitGroup
+	stop      chan struct{}
+}
+
+func New(cfg *domain.Config, transport Transport) *Bus {
+	return &Bus{
+		cfg:       cfg,
+		transport: transport,
+		sync:      make(map[string][]subscriber),
+		async:     make(map[string][]subscriber),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (b *Bus) Subscribe(event string, name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync[event] = append(b.sync[event], subscriber{name: name, handler: handler})
+}
+
+func (b *Bus) SubscribeAsync(event string, name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.async[event] = append(b.async[event], subscriber{name: name, handler: handler})
+}
+
+// Start receives events for the async subscribers until Stop is called.
+func (b *Bus) Start() {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.transport.Receive(b.stop, b.deliver)
+	}()
+}
+
+func (b *Bus) Stop() {
+	close(b.stop)
+	b.wg.Wait()
+}
+
+func (b *Bus) Publish(ctx context.Context, event domain.Event) error {
+	ctx, span := tracing.Start(ctx, "repo.eventbus.publish")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.eventbus.publish"
+		tags["event"] = event.EventName()
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	b.mu.RLock()
+	subscribers := b.sync[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if err := sub.handler(ctx, event); err != nil {
+			tags["error"] = fmt.Sprintf("%s: %s", sub.name, err.Error())
+			tags["status"] = "error"
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	envelope := domain.EventEnvelope{
+		Name:       event.EventName(),
+		RequestID:  logging.RequestID(ctx),
+		OccurredAt: startTime,
+		Payload:    payload,
+		Trace:      make(map[string]string),
+	}
+	tracing.Inject(ctx, envelope.Trace)
+
+	// A rolled back write must not reach async subscribers, nor another instance.
+	uow.AfterCommit(ctx, func() {
+		if err := b.transport.Send(ctx, envelope); err != nil {
+			log.WithFields(log.Fields{
+				"name":       "repo.eventbus.send",
+				"event":      envelope.Name,
+				"error":      err.Error(),
+				"request_id": envelope.RequestID,
+			}).Warn()
+		}
+	})
+
+	tags["sync_subscribers"] = len(subscribers)
+	tags["status"] = "success"
+	return nil
+}
+
+func (b *Bus) deliver(envelope domain.EventEnvelope) {
+	b.mu.RLock()
+	subscribers := b.async[envelope.Name]
+	b.mu.RUnlock()
+
+	if len(subscribers) == 0 {
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), "requestid", envelope.RequestID)
+	ctx = context.WithValue(ctx, occurredAtKey{}, envelope.OccurredAt)
+	ctx = tracing.Extract(ctx, envelope.Trace)
+	event, err := domain.DecodeEvent(envelope)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"name":       "repo.eventbus.deliver",
+			"event":      envelope.Name,
+			"error":      err.Error(),
+			"request_id": envelope.RequestID,
+		}).Warn()
+		return
+	}
+
+	for _, sub := range subscribers {
+		b.handle(ctx, sub, event)
+	}
+}
+
+func (b *Bus) handle(ctx context.Context, sub subscriber, event domain.Event) {
+	ctx, span := tracing.Start(ctx, "repo.eventbus.handle")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			tags["error"] = fmt.Sprintf("panic: %v", rec)
+			tags["status"] = "error"
+		}
+
+		tags["name"] = "repo.eventbus.handle"
+		tags["event"] = event.EventName()
+		tags["subscriber"] = sub.name
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if err := sub.handler(ctx, event); err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return
+	}
+
+	tags["status"] = "success"
+}
+
+// OccurredAt is when the event handed to a subscriber was published. Async
+// subscribers may run well after that.
+func OccurredAt(ctx context.Context) time.Time {
+	if at, ok := ctx.Value(occurredAtKey{}).(time.Time); ok {
+		return at
+	}
+
+	return time.Now()
+}
@@ -0,0 +1,110 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+	"time"
+)
+
+func TestBus_Publish(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	bus := New(&domain.Config{}, NewMemory())
+
+	var synced []domain.Event
+	bus.Subscribe(domain.EventMatched, "sync", func(_ context.Context, event domain.Event) error {
+		synced = append(synced, event)
+		return nil
+	})
+
+	received := make(chan domain.Event, 1)
+	bus.SubscribeAsync(domain.EventMatched, "async", func(ctx context.Context, event domain.Event) error {
+		if got := ctx.Value("requestid"); got != "test" {
+			t.Errorf("async request id = %v, want test", got)
+		}
+		received <- event
+		return nil
+	})
+	bus.SubscribeAsync(domain.EventMatched, "panics", func(context.Context, domain.Event) error {
+		panic("boom")
+	})
+
+	bus.Start()
+	defer bus.Stop()
+
+	event := domain.MatchedEvent{MatchID: 10, UserID: 1, TargetUserID: 2, UserName: "Alice"}
+	if err := bus.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if len(synced) != 1 || synced[0] != event {
+		t.Errorf("sync subscriber got = %v, want [%v]", synced, event)
+	}
+
+	select {
+	case got := <-received:
+		if got != event {
+			t.Errorf("async subscriber got = %v, want %v", got, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("async subscriber did not receive the event")
+	}
+
+	if err := bus.Publish(ctx, domain.UnmatchedEvent{UserID: 1, TargetUserID: 2}); err != nil {
+		t.Fatalf("Publish() without subscribers error = %v", err)
+	}
+}
+
+func TestBus_PublishFailsOnSyncError(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	transport := NewMemory()
+	bus := New(&domain.Config{}, transport)
+
+	bus.Subscribe(domain.EventSwiped, "failing", func(context.Context, domain.Event) error {
+		return errors.New("test")
+	})
+
+	if err := bus.Publish(ctx, domain.SwipedEvent{UserID: 1, TargetUserID: 2}); err == nil {
+		t.Fatalf("Publish() error = nil, want sync subscriber error")
+	}
+
+	select {
+	case envelope := <-transport.events:
+		t.Errorf("transport got = %v, want nothing after a failed publish", envelope)
+	default:
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	transport := NewMemory()
+	bus := New(&domain.Config{}, transport)
+
+	events := []domain.Event{
+		domain.UserRegisteredEvent{UserID: 1, Method: "phone"},
+		domain.ProfileUpdatedEvent{UserID: 1},
+		domain.SwipedEvent{UserID: 1, TargetUserID: 2, Action: domain.SwipeActionSuperLike, Mutual: true},
+		domain.MatchedEvent{MatchID: 10, UserID: 1, TargetUserID: 2},
+		domain.UnmatchedEvent{UserID: 1, TargetUserID: 2, Reason: domain.UnmatchReasonExpired},
+	}
+
+	for _, event := range events {
+		if err := bus.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+
+		got, err := domain.DecodeEvent(<-transport.events)
+		if err != nil {
+			t.Fatalf("DecodeEvent(%s) error = %v", event.EventName(), err)
+		}
+
+		if got != event {
+			t.Errorf("DecodeEvent() got = %v, want %v", got, event)
+		}
+	}
+
+	if _, err := domain.DecodeEvent(domain.EventEnvelope{Name: "unknown"}); err == nil {
+		t.Errorf("DecodeEvent() error = nil, want unknown event error")
+	}
+}
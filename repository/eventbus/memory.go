@@ -0,0 +1,41 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"github.com/zombozo12/tinder-dealls/domain"
+)
+
+const memoryBuffer = 1024
+
+// Memory keeps events on this instance. Send never blocks, events are
+// dropped while the buffer is full.
+type Memory struct {
+	events chan domain.EventEnvelope
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		events: make(chan domain.EventEnvelope, memoryBuffer),
+	}
+}
+
+func (m *Memory) Send(_ context.Context, envelope domain.EventEnvelope) error {
+	select {
+	case m.events <- envelope:
+		return nil
+	default:
+		return errors.New("event buffer full")
+	}
+}
+
+func (m *Memory) Receive(stop <-chan struct{}, deliver func(envelope domain.EventEnvelope)) {
+	for {
+		select {
+		case <-stop:
+			return
+		case envelope := <-m.events:
+			deliver(envelope)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStream = "events"
+	defaultGroup  = "tinder"
+	streamMaxLen  = 100000
+	readCount     = 100
+	readBlock     = 5 * time.Second
+	retryDelay    = time.Second
+)
+
+// Redis shares events between instances through a Redis stream. All instances
+// read through one consumer group, so every event reaches the async
+// subscribers of exactly one instance. Entries are acknowledged after they
+// were handled and a restarted consumer first picks up what it left pending.
+type Redis struct {
+	rds      *redis.Client
+	stream   string
+	group    string
+	consumer string
+}
+
+func NewRedis(rds *redis.Client, cfg *domain.Config) *Redis {
+	stream := cfg.Events.Stream
+	if stream == "" {
+		stream = defaultStream
+	}
+
+	group := cfg.Events.Group
+	if group == "" {
+		group = defaultGroup
+	}
+
+	hostname, _ := os.Hostname()
+	return &Redis{
+		rds:      rds,
+		stream:   stream,
+		group:    group,
+		consumer: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+func (r *Redis) Send(ctx context.Context, envelope domain.EventEnvelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return r.rds.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"envelope": data},
+	}).Err()
+}
+
+func (r *Redis) Receive(stop <-chan struct{}, deliver func(envelope domain.EventEnvelope)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	// "0" replays entries this consumer read but never acknowledged, ">" waits for new ones.
+	from := "0"
+	for ctx.Err() == nil {
+		if err := r.rds.XGroupCreateMkStream(ctx, r.stream, r.group, "$").Err(); err != nil &&
+			!strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			r.fail(ctx, err)
+			continue
+		}
+
+		streams, err := r.rds.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.group,
+			Consumer: r.consumer,
+			Streams:  []string{r.stream, from},
+			Count:    readCount,
+			Block:    readBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			r.fail(ctx, err)
+			continue
+		}
+
+		read := 0
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				read++
+				r.deliver(ctx, message, deliver)
+			}
+		}
+
+		if from == "0" && read == 0 {
+			from = ">"
+		}
+	}
+}
+
+func (r *Redis) deliver(ctx context.Context, message redis.XMessage, deliver func(envelope domain.EventEnvelope)) {
+	var envelope domain.EventEnvelope
+	raw, _ := message.Values["envelope"].(string)
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		log.WithFields(log.Fields{
+			"name":       "repo.eventbus.redis.deliver",
+			"message_id": message.ID,
+			"error":      err.Error(),
+		}).Warn()
+	} else {
+		deliver(envelope)
+	}
+
+	if err := r.rds.XAck(ctx, r.stream, r.group, message.ID).Err(); err != nil {
+		log.WithFields(log.Fields{
+			"name":       "repo.eventbus.redis.ack",
+			"message_id": message.ID,
+			"error":      err.Error(),
+		}).Warn()
+	}
+}
+
+// fail logs a Redis error and backs off, unless the transport is stopping.
+func (r *Redis) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"name":  "repo.eventbus.redis.receive",
+		"error": err.Error(),
+	}).Warn()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(retryDelay):
+	}
+}
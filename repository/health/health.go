@@ -0,0 +1,149 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// Check is a dependency the instance cannot serve requests without.
+type Check struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+func Database(db *sql.DB) Check {
+	return Check{Name: "database", Ping: db.PingContext}
+}
+
+func Redis(rds *redis.Client) Check {
+	return Check{Name: "redis", Ping: func(ctx context.Context) error {
+		return rds.Ping(ctx).Err()
+	}}
+}
+
+type Module struct {
+	cfg      *domain.Config
+	build    domain.BuildInfo
+	checks   []Check
+	draining atomic.Bool
+}
+
+func New(cfg *domain.Config, build domain.BuildInfo, checks ...Check) *Module {
+	return &Module{
+		cfg:    cfg,
+		build:  build,
+		checks: checks,
+	}
+}
+
+// Build describes the binary, version and commit are the values the command was built with.
+func Build(version string, commit string) domain.BuildInfo {
+	build := domain.BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && build.Commit == "":
+				build.Commit = setting.Value
+			case setting.Key == "vcs.time":
+				build.BuildTime = setting.Value
+			}
+		}
+	}
+
+	return build
+}
+
+func (m *Module) Build() domain.BuildInfo {
+	return m.build
+}
+
+// Drain marks the instance as shutting down, it is not ready from then on.
+func (m *Module) Drain() {
+	m.draining.Store(true)
+}
+
+// Ready pings every dependency at once, each within the configured timeout. Probes are not traced, they
+// would start a trace every few seconds.
+func (m *Module) Ready(ctx context.Context) domain.Readiness {
+	startTime := time.Now()
+	tags := make(log.Fields)
+	readiness := domain.Readiness{Status: domain.HealthStatusUp}
+
+	defer func() {
+		tags["name"] = "repo.health.ready"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+	}()
+
+	if m.draining.Load() {
+		tags["status"] = domain.HealthStatusDraining
+		return domain.Readiness{Status: domain.HealthStatusDraining}
+	}
+
+	timeout := defaultTimeout
+	if m.cfg.Health.Timeout > 0 {
+		timeout = time.Duration(m.cfg.Health.Timeout) * time.Second
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	readiness.Dependencies = make(map[string]domain.DependencyHealth, len(m.checks))
+	for _, check := range m.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			dependency := ping(ctx, check, timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+			readiness.Dependencies[check.Name] = dependency
+			if dependency.Status != domain.HealthStatusUp {
+				readiness.Status = domain.HealthStatusDown
+				tags[check.Name] = dependency.Error
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	readiness.Ready = readiness.Status == domain.HealthStatusUp
+	tags["status"] = readiness.Status
+	return readiness
+}
+
+func ping(ctx context.Context, check Check, timeout time.Duration) domain.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	err := check.Ping(ctx)
+	dependency := domain.DependencyHealth{
+		Status:  domain.HealthStatusUp,
+		Latency: time.Since(startTime).String(),
+	}
+	if err != nil {
+		dependency.Status = domain.HealthStatusDown
+		dependency.Error = err.Error()
+	}
+
+	return dependency
+}
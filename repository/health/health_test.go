@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func up(context.Context) error {
+	return nil
+}
+
+func down(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// hang only returns once the check timed out.
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestModule_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []Check
+		drain      bool
+		wantReady  bool
+		wantStatus string
+		wantDown   []string
+	}{
+		{
+			name:       "every dependency up",
+			checks:     []Check{{Name: "database", Ping: up}, {Name: "redis", Ping: up}},
+			wantReady:  true,
+			wantStatus: domain.HealthStatusUp,
+		},
+		{
+			name:       "dependency down",
+			checks:     []Check{{Name: "database", Ping: up}, {Name: "redis", Ping: down}},
+			wantStatus: domain.HealthStatusDown,
+			wantDown:   []string{"redis"},
+		},
+		{
+			name:       "dependency timed out",
+			checks:     []Check{{Name: "database", Ping: hang}, {Name: "redis", Ping: up}},
+			wantStatus: domain.HealthStatusDown,
+			wantDown:   []string{"database"},
+		},
+		{
+			name:       "draining",
+			checks:     []Check{{Name: "database", Ping: up}},
+			drain:      true,
+			wantStatus: domain.HealthStatusDraining,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(&domain.Config{Health: domain.Health{Timeout: 1}}, domain.BuildInfo{}, tt.checks...)
+			if tt.drain {
+				m.Drain()
+			}
+
+			got := m.Ready(context.WithValue(context.Background(), "requestid", "1"))
+			if got.Ready != tt.wantReady || got.Status != tt.wantStatus {
+				t.Errorf("Ready() = %v %s, want %v %s", got.Ready, got.Status, tt.wantReady, tt.wantStatus)
+			}
+
+			for _, name := range tt.wantDown {
+				if dependency := got.Dependencies[name]; dependency.Status != domain.HealthStatusDown || dependency.Error == "" {
+					t.Errorf("Ready() %s = %+v, want down with an error", name, dependency)
+				}
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	got := Build("1.4.0", "abc123")
+	if got.Version != "1.4.0" || got.Commit != "abc123" || got.GoVersion == "" {
+		t.Errorf("Build() = %+v, want version 1.4.0, commit abc123 and the go version", got)
+	}
+}
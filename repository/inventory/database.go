@@ -5,6 +5,10 @@ import (
 	"errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"gorm.io/gorm"
 	"time"
 )
@@ -20,6 +24,8 @@ type dbInterface interface {
 	updateLikes(ctx context.Context, userID int64, likes int) error
 	updateSuperLikes(ctx context.Context, userID int64, superLikes int) error
 	updateSwipes(ctx context.Context, userID int64, swipes int) error
+	spend(ctx context.Context, userID int64, column string) (bool, error)
+	purgeByUserID(ctx context.Context, userID int64) error
 }
 
 func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
@@ -29,28 +35,36 @@ func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
 	}
 }
 
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
 func (d dbModule) create(ctx context.Context, req domain.CreateInventoryRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.inventory.create")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.inventory.create"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	inventory := domain.Inventory{
 		UserID:     req.UserID,
+		Swipes:     req.Swipes,
 		Likes:      req.Likes,
 		SuperLikes: req.SuperLikes,
 	}
 
-	result := d.db.Table("inventory").Create(&inventory)
+	result := d.conn(ctx).Table("inventory").Create(&inventory)
 	if result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
-		return result.Error
+		return dberr.Translate(result.Error)
 	}
 
 	tags["status"] = "success"
@@ -58,17 +72,19 @@ func (d dbModule) create(ctx context.Context, req domain.CreateInventoryRequest)
 }
 
 func (d dbModule) getByUserId(ctx context.Context, userID int64) (inventory *domain.Inventory, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.inventory.get")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.inventory.get"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	result := d.db.Table("inventory").Where("user_id = ?", userID).First(&inventory)
+	result := d.conn(ctx).Table("inventory").Where("user_id = ?", userID).First(&inventory)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			tags["status"] = "not_found"
@@ -85,17 +101,19 @@ func (d dbModule) getByUserId(ctx context.Context, userID int64) (inventory *dom
 }
 
 func (d dbModule) updateLikes(ctx context.Context, userID int64, likes int) error {
+	ctx, span := tracing.Start(ctx, "repo.database.inventory.update_likes")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.inventory.update_likes"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	result := d.db.Table("inventory").Where("user_id = ?", userID).Update("likes", likes)
+	result := d.conn(ctx).Table("inventory").Where("user_id = ?", userID).Update("likes", likes)
 	if result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
@@ -107,17 +125,19 @@ func (d dbModule) updateLikes(ctx context.Context, userID int64, likes int) erro
 }
 
 func (d dbModule) updateSuperLikes(ctx context.Context, userID int64, superLikes int) error {
+	ctx, span := tracing.Start(ctx, "repo.database.inventory.update_super_likes")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.inventory.update_super_likes"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	result := d.db.Table("inventory").Where("user_id = ?", userID).Update("super_likes", superLikes)
+	result := d.conn(ctx).Table("inventory").Where("user_id = ?", userID).Update("super_likes", superLikes)
 	if result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
@@ -129,17 +149,76 @@ func (d dbModule) updateSuperLikes(ctx context.Context, userID int64, superLikes
 }
 
 func (d dbModule) updateSwipes(ctx context.Context, userID int64, swipes int) error {
+	ctx, span := tracing.Start(ctx, "repo.database.inventory.update_swipes")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.inventory.update_swipes"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("inventory").Where("user_id = ?", userID).Update("swipes", swipes)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// spend takes one off column in a single statement, so concurrent swipes cannot spend the same unit twice.
+// It reports false when nothing was left to spend.
+func (d dbModule) spend(ctx context.Context, userID int64, column string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.inventory.spend")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.inventory.spend"
+		tags["column"] = column
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("inventory").Where("user_id = ? AND "+column+" > 0", userID).
+		UpdateColumn(column, gorm.Expr(column+" - 1"))
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return false, dberr.Translate(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		tags["status"] = "insufficient"
+		return false, nil
+	}
+
+	tags["status"] = "success"
+	return true, nil
+}
+
+func (d dbModule) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.inventory.purge_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.inventory.purge_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	result := d.db.Table("inventory").Where("user_id = ?", userID).Update("swipes", swipes)
+	result := d.conn(ctx).Table("inventory").Where("user_id = ?", userID).Delete(&domain.Inventory{})
 	if result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
@@ -37,3 +37,22 @@ func (m Module) UpdateSuperLikes(ctx context.Context, userID int64, superLikes i
 func (m Module) UpdateSwipes(ctx context.Context, userID int64, swipes int) error {
 	return m.dbs.updateSwipes(ctx, userID, swipes)
 }
+
+// SpendSwipe takes one swipe off the inventory, false means none was left.
+func (m Module) SpendSwipe(ctx context.Context, userID int64) (bool, error) {
+	return m.dbs.spend(ctx, userID, "swipes")
+}
+
+// SpendLike takes one like off the inventory, false means none was left.
+func (m Module) SpendLike(ctx context.Context, userID int64) (bool, error) {
+	return m.dbs.spend(ctx, userID, "likes")
+}
+
+// SpendSuperLike takes one super like off the inventory, false means none was left.
+func (m Module) SpendSuperLike(ctx context.Context, userID int64) (bool, error) {
+	return m.dbs.spend(ctx, userID, "super_likes")
+}
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
@@ -5,7 +5,12 @@ import (
 	"errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -15,9 +20,15 @@ type dbModule struct {
 }
 
 type dbInterface interface {
-	create(ctx context.Context, req domain.MatchRequest) error
+	create(ctx context.Context, req domain.MatchRequest) (int64, error)
 	isMatched(ctx context.Context, req domain.MatchRequest) (bool, error)
 	isExists(ctx context.Context, req domain.MatchRequest) (bool, error)
+	getAllByUserID(ctx context.Context, userID int64) ([]domain.Matched, error)
+	purgeByUserID(ctx context.Context, userID int64) error
+	isMutual(ctx context.Context, req domain.MatchRequest) (bool, error)
+	unmatch(ctx context.Context, req domain.MatchRequest) error
+	getStaleMatches(ctx context.Context, filter domain.StaleMatchFilter) ([]domain.StaleMatch, error)
+	claimReminder(ctx context.Context, match domain.StaleMatch, stage int) (bool, error)
 }
 
 func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
@@ -27,15 +38,22 @@ func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
 	}
 }
 
-func (d dbModule) create(ctx context.Context, req domain.MatchRequest) error {
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+func (d dbModule) create(ctx context.Context, req domain.MatchRequest) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.create")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.matched.create"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	match := domain.Matched{
@@ -43,30 +61,32 @@ func (d dbModule) create(ctx context.Context, req domain.MatchRequest) error {
 		UserBID: req.TargetUserID,
 	}
 
-	result := d.db.Table("matched").Create(&match)
+	result := d.conn(ctx).Table("matched").Create(&match)
 	if result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
-		return result.Error
+		return 0, dberr.Translate(result.Error)
 	}
 
 	tags["status"] = "success"
-	return nil
+	return match.ID, nil
 }
 
 func (d dbModule) isMatched(ctx context.Context, req domain.MatchRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.isMatched")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.matched.isMatched"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	var matched domain.Matched
-	result := d.db.Table("matched").Where("user_a_id = ? AND user_b_id = ?", req.UserID, req.TargetUserID).First(&matched)
+	result := d.conn(ctx).Table("matched").Where("user_a_id = ? AND user_b_id = ?", req.UserID, req.TargetUserID).First(&matched)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			tags["status"] = "not_found"
@@ -83,18 +103,20 @@ func (d dbModule) isMatched(ctx context.Context, req domain.MatchRequest) (bool,
 }
 
 func (d dbModule) isExists(ctx context.Context, req domain.MatchRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.isExists")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.matched.isExists"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	var matched domain.Matched
-	result := d.db.Table("matched").Where("user_a_id = ? AND user_b_id = ?", req.UserID, req.TargetUserID).First(&matched)
+	result := d.conn(ctx).Table("matched").Where("user_a_id = ? AND user_b_id = ?", req.UserID, req.TargetUserID).First(&matched)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			tags["status"] = "not_found"
@@ -109,3 +131,218 @@ func (d dbModule) isExists(ctx context.Context, req domain.MatchRequest) (bool,
 	tags["status"] = "success"
 	return true, nil
 }
+
+func (d dbModule) getAllByUserID(ctx context.Context, userID int64) ([]domain.Matched, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.getAllByUserID")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.matched.getAllByUserID"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var matches []domain.Matched
+	result := d.conn(ctx).Table("matched").
+		Where("user_a_id = ? OR user_b_id = ?", userID, userID).
+		Find(&matches)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return matches, nil
+}
+
+func (d dbModule) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.purgeByUserID")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.matched.purgeByUserID"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Table("match_reminder").
+			Where("user_a_id = ? OR user_b_id = ?", userID, userID).
+			Delete(&domain.MatchReminder{}); result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Table("matched").
+			Where("user_a_id = ? OR user_b_id = ?", userID, userID).
+			Delete(&domain.Matched{}).Error
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// isMutual reports whether both users liked each other and neither side has unmatched since.
+func (d dbModule) isMutual(ctx context.Context, req domain.MatchRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.isMutual")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.matched.isMutual"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var count int64
+	result := d.conn(ctx).Table("matched").
+		Where("((user_a_id = ? AND user_b_id = ?) OR (user_a_id = ? AND user_b_id = ?)) AND deleted_at IS NULL",
+			req.UserID, req.TargetUserID, req.TargetUserID, req.UserID).
+		Count(&count)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return false, result.Error
+	}
+
+	tags["status"] = "success"
+	return count == 2, nil
+}
+
+func (d dbModule) unmatch(ctx context.Context, req domain.MatchRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.unmatch")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.matched.unmatch"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	userAID, userBID := req.UserID, req.TargetUserID
+	if userAID > userBID {
+		userAID, userBID = userBID, userAID
+	}
+
+	now := time.Now()
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Table("matched").
+			Where("((user_a_id = ? AND user_b_id = ?) OR (user_a_id = ? AND user_b_id = ?)) AND deleted_at IS NULL",
+				req.UserID, req.TargetUserID, req.TargetUserID, req.UserID).
+			Updates(map[string]interface{}{
+				"deleted_at": now,
+				"updated_at": now,
+			}); result.Error != nil {
+			return result.Error
+		}
+
+		// Reminders belong to the match, a pair that matches again gets them again.
+		return tx.Table("match_reminder").
+			Where("user_a_id = ? AND user_b_id = ?", userAID, userBID).
+			Delete(&domain.MatchReminder{}).Error
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// getStaleMatches finds active mutual matches without a single chat message, oldest match first. Each pair is
+// returned once with the lower user id as UserAID.
+func (d dbModule) getStaleMatches(ctx context.Context, filter domain.StaleMatchFilter) ([]domain.StaleMatch, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.getStaleMatches")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.matched.getStaleMatches"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	query := d.conn(ctx).Table("matched AS a").
+		Select("a.user_a_id, a.user_b_id, pa.name AS user_a_name, pb.name AS user_b_name, "+
+			"GREATEST(a.created_at, b.created_at) AS matched_at").
+		Joins("JOIN matched AS b ON b.user_a_id = a.user_b_id AND b.user_b_id = a.user_a_id AND b.deleted_at IS NULL").
+		Joins("JOIN profile AS pa ON pa.user_id = a.user_a_id AND pa.deleted_at IS NULL").
+		Joins("JOIN profile AS pb ON pb.user_id = a.user_b_id AND pb.deleted_at IS NULL").
+		Where("a.deleted_at IS NULL AND a.user_a_id < a.user_b_id").
+		Where("GREATEST(a.created_at, b.created_at) <= ?", filter.MatchedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM conversation AS c WHERE c.user_a_id = a.user_a_id " +
+			"AND c.user_b_id = a.user_b_id AND c.last_message_at IS NOT NULL)")
+
+	if !filter.MatchedAfter.IsZero() {
+		query = query.Where("GREATEST(a.created_at, b.created_at) > ?", filter.MatchedAfter)
+	}
+
+	if filter.WithoutReminder != 0 {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM match_reminder AS r WHERE r.user_a_id = a.user_a_id "+
+			"AND r.user_b_id = a.user_b_id AND r.stage = ?)", filter.WithoutReminder)
+	}
+
+	var matches []domain.StaleMatch
+	result := query.Order("matched_at").Limit(filter.Limit).Scan(&matches)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return matches, nil
+}
+
+// claimReminder records that the reminder for this stage went out, false means another run got there first.
+func (d dbModule) claimReminder(ctx context.Context, match domain.StaleMatch, stage int) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.matched.claimReminder")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.matched.claimReminder"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	reminder := domain.MatchReminder{
+		UserAID: match.UserAID,
+		UserBID: match.UserBID,
+		Stage:   stage,
+	}
+
+	result := d.conn(ctx).Table("match_reminder").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&reminder)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return false, result.Error
+	}
+
+	tags["status"] = "success"
+	return result.RowsAffected == 1, nil
+}
@@ -18,7 +18,7 @@ func New(db *gorm.DB, cfg *domain.Config) *Module {
 	}
 }
 
-func (m Module) Create(ctx context.Context, req domain.MatchRequest) error {
+func (m Module) Create(ctx context.Context, req domain.MatchRequest) (int64, error) {
 	return m.dbs.create(ctx, req)
 }
 
@@ -29,3 +29,27 @@ func (m Module) IsMatched(ctx context.Context, req domain.MatchRequest) (bool, e
 func (m Module) IsExists(ctx context.Context, req domain.MatchRequest) (bool, error) {
 	return m.dbs.isExists(ctx, req)
 }
+
+func (m Module) GetAllByUserID(ctx context.Context, userID int64) ([]domain.Matched, error) {
+	return m.dbs.getAllByUserID(ctx, userID)
+}
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
+
+func (m Module) IsMutual(ctx context.Context, req domain.MatchRequest) (bool, error) {
+	return m.dbs.isMutual(ctx, req)
+}
+
+func (m Module) Unmatch(ctx context.Context, req domain.MatchRequest) error {
+	return m.dbs.unmatch(ctx, req)
+}
+
+func (m Module) GetStaleMatches(ctx context.Context, filter domain.StaleMatchFilter) ([]domain.StaleMatch, error) {
+	return m.dbs.getStaleMatches(ctx, filter)
+}
+
+func (m Module) ClaimReminder(ctx context.Context, match domain.StaleMatch, stage int) (bool, error) {
+	return m.dbs.claimReminder(ctx, match, stage)
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const namespace = "tinder"
+
+const (
+	LayerService    = "service"
+	LayerDatabase   = "database"
+	LayerRedis      = "redis"
+	LayerRepository = "repository"
+	LayerJob        = "job"
+)
+
+// layers maps the prefix of the name of every call to the layer it is measured in, the first
+// matching prefix wins. HTTP handlers are measured per route by ObserveRequest instead.
+var layers = []struct {
+	prefix string
+	layer  string
+}{
+	{prefix: "repo.database.", layer: LayerDatabase},
+	{prefix: "repo.redis.", layer: LayerRedis},
+	{prefix: "repo.", layer: LayerRepository},
+	{prefix: "service.", layer: LayerService},
+	{prefix: "handler.job.", layer: LayerJob},
+}
+
+// Module collects Prometheus metrics. Call latencies are observed when the span of a call ends, so it has
+// to be set as the observer of the tracing package.
+type Module struct {
+	cfg           *domain.Config
+	registry      *prometheus.Registry
+	requests      *prometheus.HistogramVec
+	calls         *prometheus.HistogramVec
+	errors        *prometheus.CounterVec
+	swipes        *prometheus.CounterVec
+	matches       prometheus.Counter
+	registrations *prometheus.CounterVec
+}
+
+func New(cfg *domain.Config) *Module {
+	m := &Module{
+		cfg:      cfg,
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time spent serving HTTP requests by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		calls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "call_duration_seconds",
+			Help:      "Time spent in service, repository, database, Redis and job calls by name and status.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"layer", "name", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "call_errors_total",
+			Help:      "Calls that ended with an error by name.",
+		}, []string{"layer", "name"}),
+		swipes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "swipes_total",
+			Help:      "Swipes by action: like, super_like or dislike.",
+		}, []string{"action"}),
+		matches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "matches_total",
+			Help:      "Mutual matches.",
+		}),
+		registrations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "registrations_total",
+			Help:      "New accounts by sign up method.",
+		}, []string{"method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.calls,
+		m.errors,
+		m.swipes,
+		m.matches,
+		m.registrations,
+	)
+
+	return m
+}
+
+// RegisterDB exposes the connection pool stats of db.
+func (m *Module) RegisterDB(db *sql.DB, name string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format. With a token configured scrapers have to send
+// it as a bearer token.
+func (m *Module) Handler() http.Handler {
+	handler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	token := m.cfg.Metrics.Token
+	if token == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ObserveRequest records a served HTTP request, route is the path the request matched, such as
+// /api/profile/:id, so parameters do not create a series per value.
+func (m *Module) ObserveRequest(method string, route string, status int, elapsed time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(elapsed.Seconds())
+}
+
+// Track counts the business events, others are ignored.
+func (m *Module) Track(ctx context.Context, event domain.Event) error {
+	switch e := event.(type) {
+	case domain.SwipedEvent:
+		m.swipes.WithLabelValues(e.Action).Inc()
+	case domain.MatchedEvent:
+		m.matches.Inc()
+	case domain.UserRegisteredEvent:
+		m.registrations.WithLabelValues(e.Method).Inc()
+	}
+
+	return nil
+}
+
+// ObserveCall records the latency and outcome of a call, see tracing.SetObserver. Calls outside of the
+// measured layers are ignored.
+func (m *Module) ObserveCall(name string, status string, elapsed time.Duration) {
+	layer := layerOf(name)
+	if layer == "" {
+		return
+	}
+
+	if status == "" {
+		status = "unknown"
+	}
+
+	m.calls.WithLabelValues(layer, name, status).Observe(elapsed.Seconds())
+	if status == "error" {
+		m.errors.WithLabelValues(layer, name).Inc()
+	}
+}
+
+func layerOf(name string) string {
+	for _, l := range layers {
+		if strings.HasPrefix(name, l.prefix) {
+			return l.layer
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, m *Module) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestModule(t *testing.T) {
+	m := New(&domain.Config{})
+
+	m.ObserveCall("service.matcher.like", "success", 3*time.Millisecond)
+	m.ObserveCall("service.matcher.like", "error", time.Millisecond)
+	m.ObserveCall("repo.database.profile.get_profile", "success", 2*time.Millisecond)
+	m.ObserveCall("repo.redis.get", "success", 500*time.Microsecond)
+	m.ObserveCall("handler.http.matcher.like", "success", 4*time.Millisecond)
+
+	m.ObserveRequest("POST", "/api/matcher/like", 200, 5*time.Millisecond)
+
+	ctx := context.Background()
+	_ = m.Track(ctx, domain.SwipedEvent{Action: domain.SwipeActionLike})
+	_ = m.Track(ctx, domain.SwipedEvent{Action: domain.SwipeActionSuperLike})
+	_ = m.Track(ctx, domain.MatchedEvent{})
+	_ = m.Track(ctx, domain.UserRegisteredEvent{Method: "email"})
+	_ = m.Track(ctx, domain.UnmatchedEvent{})
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`tinder_call_duration_seconds_count{layer="service",name="service.matcher.like",status="success"} 1`,
+		`tinder_call_duration_seconds_count{layer="service",name="service.matcher.like",status="error"} 1`,
+		`tinder_call_errors_total{layer="service",name="service.matcher.like"} 1`,
+		`tinder_call_duration_seconds_count{layer="database",name="repo.database.profile.get_profile",status="success"} 1`,
+		`tinder_call_duration_seconds_count{layer="redis",name="repo.redis.get",status="success"} 1`,
+		`tinder_http_request_duration_seconds_count{method="POST",route="/api/matcher/like",status="200"} 1`,
+		`tinder_swipes_total{action="like"} 1`,
+		`tinder_swipes_total{action="super_like"} 1`,
+		`tinder_matches_total 1`,
+		`tinder_registrations_total{method="email"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+
+	if strings.Contains(out, "handler.http.matcher.like") {
+		t.Errorf("metrics recorded a handler call, want handlers only measured per route")
+	}
+}
+
+func TestHandlerToken(t *testing.T) {
+	m := New(&domain.Config{Metrics: domain.Metrics{Token: "secret"}})
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "without token", want: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer other", want: http.StatusUnauthorized},
+		{name: "token", authorization: "Bearer secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			m.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Handler() status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID keys the advisory lock held while migrating, so instances starting
+// together with auto-migrate do not apply the same migration twice.
+const lockID int64 = 7_140_200_041
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+// Module applies the numbered SQL files embedded from sql/. Every migration
+// runs in its own transaction together with its schema_migrations row.
+type Module struct {
+	cfg        *domain.Config
+	db         *gorm.DB
+	migrations []migration
+}
+
+func New(db *gorm.DB, cfg *domain.Config) (*Module, error) {
+	migrations, err := load(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Module{
+		cfg:        cfg,
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// load pairs up the files in dir by version, every version needs an up and a down file.
+func load(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql", entry.Name())
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.name != match[2] {
+			return nil, fmt.Errorf("migration %d: named both %s and %s", version, m.name, match[2])
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] == "up" {
+			m.up = string(data)
+		} else {
+			m.down = string(data)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %d_%s: needs both an up and a down file", m.version, m.name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// Up applies up to steps pending migrations, all of them when steps is 0.
+func (m Module) Up(ctx context.Context, steps int) (applied int, err error) {
+	ctx, span := tracing.Start(ctx, "repo.migration.up")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.migration.up"
+		tags["applied"] = applied
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err = m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if done[migration.version] != nil {
+				continue
+			}
+
+			if steps > 0 && applied >= steps {
+				break
+			}
+
+			if err := run(ctx, conn, migration.up,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.version, migration.name); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.version, migration.name, err)
+			}
+
+			log.WithFields(log.Fields{"version": migration.version, "migration": migration.name}).Info("Applied migration")
+			applied++
+		}
+
+		return nil
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return applied, err
+	}
+
+	tags["status"] = "success"
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m Module) Down(ctx context.Context, steps int) (reverted int, err error) {
+	ctx, span := tracing.Start(ctx, "repo.migration.down")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.migration.down"
+		tags["reverted"] = reverted
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err = m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if done[migration.version] == nil {
+				continue
+			}
+
+			if err := run(ctx, conn, migration.down,
+				"DELETE FROM schema_migrations WHERE version = $1", migration.version); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.version, migration.name, err)
+			}
+
+			log.WithFields(log.Fields{"version": migration.version, "migration": migration.name}).Info("Reverted migration")
+			reverted++
+		}
+
+		return nil
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return reverted, err
+	}
+
+	tags["status"] = "success"
+	return reverted, nil
+}
+
+// Redo reverts the last applied migration and applies it again.
+func (m Module) Redo(ctx context.Context) error {
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		return err
+	}
+
+	if reverted == 0 {
+		return errors.New("no applied migration to redo")
+	}
+
+	_, err = m.Up(ctx, 1)
+	return err
+}
+
+// Status lists every known migration in order with the time it was applied.
+func (m Module) Status(ctx context.Context) (statuses []domain.MigrationStatus, err error) {
+	ctx, span := tracing.Start(ctx, "repo.migration.status")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.migration.status"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err = m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			statuses = append(statuses, domain.MigrationStatus{
+				Version:   migration.version,
+				Name:      migration.name,
+				AppliedAt: done[migration.version],
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return statuses, nil
+}
+
+// locked runs fn on one connection holding the migration lock, creating the
+// bookkeeping table first.
+func (m Module) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	db, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// The lock belongs to the session, it must be released before the connection returns to the pool.
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]*time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int64]*time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = &appliedAt
+	}
+
+	return done, rows.Err()
+}
+
+// run executes a migration file and its bookkeeping statement in one transaction.
+// The file is sent without arguments, so it may hold several statements.
+func run(ctx context.Context, conn *sql.Conn, script string, bookkeeping string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
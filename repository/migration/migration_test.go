@@ -0,0 +1,88 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := load(files, "sql")
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	for i, m := range migrations {
+		if m.version != int64(i+1) {
+			t.Errorf("migration %s has version %d, want %d", m.name, m.version, i+1)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr string
+	}{
+		{
+			name: "success sorted by version",
+			files: fstest.MapFS{
+				"sql/0010_later.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+				"sql/0010_later.down.sql": {Data: []byte("DROP TABLE b;")},
+				"sql/0002_first.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+				"sql/0002_first.down.sql": {Data: []byte("DROP TABLE a;")},
+			},
+			want: []int64{2, 10},
+		},
+		{
+			name: "failed missing down",
+			files: fstest.MapFS{
+				"sql/0001_first.up.sql": {Data: []byte("CREATE TABLE a ();")},
+			},
+			wantErr: "needs both",
+		},
+		{
+			name: "failed version named twice",
+			files: fstest.MapFS{
+				"sql/0001_first.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+				"sql/0001_other.down.sql": {Data: []byte("DROP TABLE a;")},
+			},
+			wantErr: "named both",
+		},
+		{
+			name: "failed unnumbered file",
+			files: fstest.MapFS{
+				"sql/first.up.sql": {Data: []byte("CREATE TABLE a ();")},
+			},
+			wantErr: "name must look like",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.files, "sql")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("load() got %d migrations, want %d", len(migrations), len(tt.want))
+			}
+
+			for i, m := range migrations {
+				if m.version != tt.want[i] || m.up == "" || m.down == "" {
+					t.Errorf("load() migration %d = %+v", i, m)
+				}
+			}
+		})
+	}
+}
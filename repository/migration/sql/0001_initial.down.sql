@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS notification;
+DROP TABLE IF EXISTS inventory;
+DROP TABLE IF EXISTS matched;
+DROP TABLE IF EXISTS liked;
+DROP TABLE IF EXISTS profile;
+DROP TABLE IF EXISTS users;
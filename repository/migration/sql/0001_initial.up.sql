@@ -1,4 +1,6 @@
-CREATE TABLE users (
+-- Baseline, the schema misc/tinder.sql used to create by hand. Tables are only created when missing so
+-- databases set up from that file can run it, the migrations after it add everything built since.
+CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     email VARCHAR UNIQUE NOT NULL,
     password VARCHAR NOT NULL,
@@ -9,7 +11,7 @@ CREATE TABLE users (
     deleted_at TIMESTAMP
 );
 
-CREATE TABLE profile (
+CREATE TABLE IF NOT EXISTS profile (
     id SERIAL PRIMARY KEY,
     user_id BIGINT NOT NULL,
     name VARCHAR NOT NULL,
@@ -21,7 +23,7 @@ CREATE TABLE profile (
     deleted_at TIMESTAMP
 );
 
-CREATE TABLE liked (
+CREATE TABLE IF NOT EXISTS liked (
     id SERIAL PRIMARY KEY,
     user_id BIGINT NOT NULL,
     liked_user_id BIGINT NOT NULL,
@@ -31,16 +33,16 @@ CREATE TABLE liked (
     deleted_at TIMESTAMP
 );
 
-CREATE TABLE matched (
-    id         SERIAL PRIMARY KEY,
-    user_a_id  BIGINT    NOT NULL,
-    user_b_id  BIGINT    NOT NULL,
+CREATE TABLE IF NOT EXISTS matched (
+    id SERIAL PRIMARY KEY,
+    user_a_id BIGINT NOT NULL,
+    user_b_id BIGINT NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
 );
 
-CREATE TABLE inventory (
+CREATE TABLE IF NOT EXISTS inventory (
     id SERIAL PRIMARY KEY,
     user_id BIGINT NOT NULL,
     swipes SMALLINT DEFAULT 10 NOT NULL,
@@ -51,7 +53,7 @@ CREATE TABLE inventory (
     deleted_at TIMESTAMP
 );
 
-CREATE TABLE notification (
+CREATE TABLE IF NOT EXISTS notification (
     id SERIAL PRIMARY KEY,
     user_id BIGINT NOT NULL,
     message VARCHAR NOT NULL,
@@ -59,4 +61,4 @@ CREATE TABLE notification (
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
-);
\ No newline at end of file
+);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS user_recovery_code;
+DROP TABLE IF EXISTS user_identity;
+
+-- Fails while phone-only accounts exist, they have no email or password to fall back to.
+ALTER TABLE users
+    DROP COLUMN IF EXISTS banned_at,
+    DROP COLUMN IF EXISTS role,
+    DROP COLUMN IF EXISTS totp_confirmed_at,
+    DROP COLUMN IF EXISTS totp_enabled,
+    DROP COLUMN IF EXISTS totp_secret,
+    DROP COLUMN IF EXISTS phone,
+    ALTER COLUMN password SET NOT NULL,
+    ALTER COLUMN email SET NOT NULL;
@@ -0,0 +1,30 @@
+-- Social login, phone sign-up and two-factor authentication. Phone sign-ups have no email or password.
+ALTER TABLE users
+    ALTER COLUMN email DROP NOT NULL,
+    ALTER COLUMN password DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS phone VARCHAR UNIQUE,
+    ADD COLUMN IF NOT EXISTS totp_secret VARCHAR, -- encrypted, set on enrollment
+    ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS totp_confirmed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS role VARCHAR NOT NULL DEFAULT 'user', -- user & admin
+    ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS user_identity (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR NOT NULL, -- google, apple
+    subject VARCHAR NOT NULL,
+    email VARCHAR,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_code (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR NOT NULL, -- bcrypt
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS content_flag;
+DROP TABLE IF EXISTS user_block;
+DROP TABLE IF EXISTS report;
+
+ALTER TABLE profile
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS hidden_at;
+
+DROP TABLE IF EXISTS admin_audit_log;
+DROP FUNCTION IF EXISTS admin_audit_log_immutable();
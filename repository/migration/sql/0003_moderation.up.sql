@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id SERIAL PRIMARY KEY,
+    admin_id BIGINT NOT NULL,
+    action VARCHAR NOT NULL, -- adjust_inventory, ban_user, unban_user, delete_profile, resend_notification
+    target_user_id BIGINT,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Audit entries are append only.
+CREATE OR REPLACE FUNCTION admin_audit_log_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_log is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS admin_audit_log_immutable ON admin_audit_log;
+CREATE TRIGGER admin_audit_log_immutable
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON admin_audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION admin_audit_log_immutable();
+
+ALTER TABLE profile
+    ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP, -- set while reports are pending review
+    ADD COLUMN IF NOT EXISTS bio VARCHAR NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS report (
+    id SERIAL PRIMARY KEY,
+    reporter_id BIGINT NOT NULL,
+    reported_user_id BIGINT NOT NULL,
+    reason VARCHAR NOT NULL, -- spam, harassment, fake_profile, inappropriate_content, underage, other
+    description VARCHAR,
+    photo_ref VARCHAR,
+    status VARCHAR NOT NULL DEFAULT 'open', -- open, triaged, actioned, dismissed
+    reviewed_by BIGINT,
+    reviewed_at TIMESTAMP,
+    review_note VARCHAR,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS report_status_idx ON report (status, created_at);
+CREATE INDEX IF NOT EXISTS report_reported_user_id_idx ON report (reported_user_id, status);
+
+CREATE TABLE IF NOT EXISTS user_block (
+    id SERIAL PRIMARY KEY,
+    blocker_id BIGINT NOT NULL,
+    blocked_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (blocker_id, blocked_id)
+);
+
+CREATE TABLE IF NOT EXISTS content_flag (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    kind VARCHAR NOT NULL, -- name, bio, message
+    content VARCHAR NOT NULL,
+    verdict VARCHAR NOT NULL, -- flag, block
+    filter VARCHAR NOT NULL,
+    reason VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS content_flag_user_id_idx ON content_flag (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE profile DROP COLUMN IF EXISTS show_last_active;
+
+DROP TABLE IF EXISTS message;
+DROP TABLE IF EXISTS conversation;
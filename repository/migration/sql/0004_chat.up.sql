@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS conversation (
+    id SERIAL PRIMARY KEY,
+    user_a_id BIGINT NOT NULL, -- lower user id of the match
+    user_b_id BIGINT NOT NULL,
+    last_message_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_a_id, user_b_id),
+    CHECK (user_a_id < user_b_id)
+);
+
+CREATE TABLE IF NOT EXISTS message (
+    id SERIAL PRIMARY KEY,
+    conversation_id BIGINT NOT NULL,
+    sender_id BIGINT NOT NULL,
+    body VARCHAR NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'sent', -- sent, delivered, read
+    delivered_at TIMESTAMP,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS message_conversation_id_idx ON message (conversation_id, id DESC);
+
+-- Presence, users can hide when they were last active.
+ALTER TABLE profile ADD COLUMN IF NOT EXISTS show_last_active BOOLEAN NOT NULL DEFAULT TRUE;
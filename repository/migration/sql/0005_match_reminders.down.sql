@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS match_reminder;
+DROP INDEX IF EXISTS matched_pair_idx;
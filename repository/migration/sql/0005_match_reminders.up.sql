@@ -0,0 +1,10 @@
+CREATE INDEX IF NOT EXISTS matched_pair_idx ON matched (user_a_id, user_b_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS match_reminder (
+    id SERIAL PRIMARY KEY,
+    user_a_id BIGINT NOT NULL, -- lower user id of the match
+    user_b_id BIGINT NOT NULL,
+    stage INT NOT NULL, -- days after matching
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_a_id, user_b_id, stage)
+);
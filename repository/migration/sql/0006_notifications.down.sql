@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS push_outbox;
+DROP TABLE IF EXISTS device_token;
+DROP TABLE IF EXISTS notification_preference;
+
+-- Typed notifications without legacy text keep their rendered text.
+UPDATE notification SET message = payload ->> 'text' WHERE message IS NULL;
+DELETE FROM notification WHERE message IS NULL;
+
+ALTER TABLE notification
+    ALTER COLUMN message SET NOT NULL,
+    DROP COLUMN IF EXISTS payload,
+    DROP COLUMN IF EXISTS template_key,
+    DROP COLUMN IF EXISTS type;
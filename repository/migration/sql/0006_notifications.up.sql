@@ -0,0 +1,56 @@
+-- Notifications are typed, the text is rendered at read time from template_key and payload.
+ALTER TABLE notification
+    ADD COLUMN IF NOT EXISTS type VARCHAR NOT NULL DEFAULT 'system', -- match, super_like, message, system
+    ADD COLUMN IF NOT EXISTS template_key VARCHAR NOT NULL DEFAULT 'system.text',
+    ADD COLUMN IF NOT EXISTS payload JSONB NOT NULL DEFAULT '{}',
+    ALTER COLUMN message DROP NOT NULL; -- legacy free text
+
+CREATE TABLE IF NOT EXISTS notification_preference (
+    user_id BIGINT PRIMARY KEY,
+    matches BOOLEAN NOT NULL DEFAULT TRUE,
+    messages BOOLEAN NOT NULL DEFAULT TRUE,
+    super_likes BOOLEAN NOT NULL DEFAULT TRUE,
+    marketing BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS device_token (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    platform VARCHAR NOT NULL, -- android, ios
+    token VARCHAR NOT NULL UNIQUE,
+    locale VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS device_token_user_id_idx ON device_token (user_id);
+
+CREATE TABLE IF NOT EXISTS push_outbox (
+    id SERIAL PRIMARY KEY,
+    notification_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending', -- pending, sent, skipped, failed
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS push_outbox_due_idx ON push_outbox (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS push_outbox_user_id_idx ON push_outbox (user_id);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending', -- pending, published, failed
+    attempts INT NOT NULL DEFAULT 0,
+    available_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_due_idx ON outbox (available_at) WHERE status = 'pending';
@@ -0,0 +1,17 @@
+ALTER TABLE message DROP CONSTRAINT IF EXISTS message_conversation_id_fkey;
+ALTER TABLE push_outbox DROP CONSTRAINT IF EXISTS push_outbox_notification_id_fkey;
+ALTER TABLE content_flag DROP CONSTRAINT IF EXISTS content_flag_user_id_fkey;
+ALTER TABLE device_token DROP CONSTRAINT IF EXISTS device_token_user_id_fkey;
+ALTER TABLE notification_preference DROP CONSTRAINT IF EXISTS notification_preference_user_id_fkey;
+ALTER TABLE user_recovery_code DROP CONSTRAINT IF EXISTS user_recovery_code_user_id_fkey;
+ALTER TABLE user_identity DROP CONSTRAINT IF EXISTS user_identity_user_id_fkey;
+
+DROP INDEX IF EXISTS user_block_blocked_id_idx;
+DROP INDEX IF EXISTS message_sender_id_idx;
+DROP INDEX IF EXISTS conversation_user_b_id_idx;
+DROP INDEX IF EXISTS admin_audit_log_target_user_id_idx;
+DROP INDEX IF EXISTS admin_audit_log_admin_id_idx;
+DROP INDEX IF EXISTS users_deleted_at_idx;
+DROP INDEX IF EXISTS user_recovery_code_user_id_idx;
+DROP INDEX IF EXISTS user_identity_user_id_idx;
+DROP INDEX IF EXISTS notification_user_id_idx;
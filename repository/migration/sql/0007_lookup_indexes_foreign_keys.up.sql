@@ -0,0 +1,35 @@
+-- Indexes for the lookups by user the repositories run on every request.
+CREATE INDEX notification_user_id_idx ON notification (user_id, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX user_identity_user_id_idx ON user_identity (user_id);
+CREATE INDEX user_recovery_code_user_id_idx ON user_recovery_code (user_id) WHERE used_at IS NULL;
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX admin_audit_log_admin_id_idx ON admin_audit_log (admin_id, id DESC);
+CREATE INDEX admin_audit_log_target_user_id_idx ON admin_audit_log (target_user_id, id DESC);
+CREATE INDEX conversation_user_b_id_idx ON conversation (user_b_id);
+CREATE INDEX message_sender_id_idx ON message (sender_id);
+CREATE INDEX user_block_blocked_id_idx ON user_block (blocked_id);
+
+-- Rows left behind by users purged before the foreign keys existed would fail them.
+DELETE FROM user_identity WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM user_recovery_code WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM notification_preference WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM device_token WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM content_flag WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM push_outbox WHERE notification_id NOT IN (SELECT id FROM notification);
+DELETE FROM message WHERE conversation_id NOT IN (SELECT id FROM conversation);
+
+-- Data owned by a single user goes with the user.
+ALTER TABLE user_identity
+    ADD CONSTRAINT user_identity_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE user_recovery_code
+    ADD CONSTRAINT user_recovery_code_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE notification_preference
+    ADD CONSTRAINT notification_preference_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE device_token
+    ADD CONSTRAINT device_token_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE content_flag
+    ADD CONSTRAINT content_flag_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE push_outbox
+    ADD CONSTRAINT push_outbox_notification_id_fkey FOREIGN KEY (notification_id) REFERENCES notification (id) ON DELETE CASCADE;
+ALTER TABLE message
+    ADD CONSTRAINT message_conversation_id_fkey FOREIGN KEY (conversation_id) REFERENCES conversation (id) ON DELETE CASCADE;
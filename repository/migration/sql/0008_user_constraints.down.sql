@@ -0,0 +1,30 @@
+ALTER TABLE report
+    DROP CONSTRAINT IF EXISTS report_reviewed_by_fkey,
+    DROP CONSTRAINT IF EXISTS report_reported_user_id_fkey,
+    DROP CONSTRAINT IF EXISTS report_reporter_id_fkey;
+ALTER TABLE message DROP CONSTRAINT IF EXISTS message_sender_id_fkey;
+ALTER TABLE conversation
+    DROP CONSTRAINT IF EXISTS conversation_user_b_id_fkey,
+    DROP CONSTRAINT IF EXISTS conversation_user_a_id_fkey;
+ALTER TABLE user_block
+    DROP CONSTRAINT IF EXISTS user_block_blocked_id_fkey,
+    DROP CONSTRAINT IF EXISTS user_block_blocker_id_fkey;
+ALTER TABLE match_reminder
+    DROP CONSTRAINT IF EXISTS match_reminder_user_b_id_fkey,
+    DROP CONSTRAINT IF EXISTS match_reminder_user_a_id_fkey;
+ALTER TABLE matched
+    DROP CONSTRAINT IF EXISTS matched_user_b_id_fkey,
+    DROP CONSTRAINT IF EXISTS matched_user_a_id_fkey;
+ALTER TABLE push_outbox DROP CONSTRAINT IF EXISTS push_outbox_user_id_fkey;
+ALTER TABLE notification DROP CONSTRAINT IF EXISTS notification_user_id_fkey;
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS inventory_user_id_fkey;
+ALTER TABLE profile DROP CONSTRAINT IF EXISTS profile_user_id_fkey;
+
+DROP INDEX IF EXISTS profile_recommendation_idx;
+DROP INDEX IF EXISTS notification_unread_idx;
+DROP INDEX IF EXISTS matched_user_b_id_idx;
+DROP INDEX IF EXISTS matched_pair_key;
+CREATE INDEX IF NOT EXISTS matched_pair_idx ON matched (user_a_id, user_b_id) WHERE deleted_at IS NULL;
+
+ALTER TABLE inventory DROP CONSTRAINT IF EXISTS inventory_user_id_key;
+ALTER TABLE profile DROP CONSTRAINT IF EXISTS profile_user_id_key;
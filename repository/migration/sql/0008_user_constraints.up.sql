@@ -0,0 +1,69 @@
+-- One profile and one inventory per user. Duplicates written by racing sign-ups are dropped, the
+-- first row is the one every query has been reading.
+DELETE FROM profile AS p USING profile AS keep
+WHERE p.user_id = keep.user_id AND p.id > keep.id;
+
+DELETE FROM inventory AS i USING inventory AS keep
+WHERE i.user_id = keep.user_id AND i.id > keep.id;
+
+ALTER TABLE profile ADD CONSTRAINT profile_user_id_key UNIQUE (user_id);
+ALTER TABLE inventory ADD CONSTRAINT inventory_user_id_key UNIQUE (user_id);
+
+-- A user swipes another user once while the swipe is live, unmatching frees the pair again.
+DELETE FROM matched AS m USING matched AS keep
+WHERE m.user_a_id = keep.user_a_id AND m.user_b_id = keep.user_b_id
+  AND m.deleted_at IS NULL AND keep.deleted_at IS NULL AND m.id > keep.id;
+
+DROP INDEX IF EXISTS matched_pair_idx;
+CREATE UNIQUE INDEX matched_pair_key ON matched (user_a_id, user_b_id) WHERE deleted_at IS NULL;
+-- Serves the "user_a_id = ? OR user_b_id = ?" lookups together with matched_pair_key.
+CREATE INDEX matched_user_b_id_idx ON matched (user_b_id, user_a_id) WHERE deleted_at IS NULL;
+
+-- Unread notifications are listed and counted on every app start.
+CREATE INDEX notification_unread_idx ON notification (user_id, id DESC) WHERE is_read = FALSE AND deleted_at IS NULL;
+
+-- Candidates are picked by gender among visible profiles.
+CREATE INDEX profile_recommendation_idx ON profile (gender, interest_in)
+    WHERE deleted_at IS NULL AND hidden_at IS NULL;
+
+DELETE FROM profile WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM inventory WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM notification WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM push_outbox WHERE user_id NOT IN (SELECT id FROM users);
+DELETE FROM matched WHERE user_a_id NOT IN (SELECT id FROM users) OR user_b_id NOT IN (SELECT id FROM users);
+DELETE FROM match_reminder WHERE user_a_id NOT IN (SELECT id FROM users) OR user_b_id NOT IN (SELECT id FROM users);
+DELETE FROM user_block WHERE blocker_id NOT IN (SELECT id FROM users) OR blocked_id NOT IN (SELECT id FROM users);
+DELETE FROM conversation WHERE user_a_id NOT IN (SELECT id FROM users) OR user_b_id NOT IN (SELECT id FROM users);
+DELETE FROM message WHERE sender_id NOT IN (SELECT id FROM users);
+DELETE FROM report WHERE reporter_id NOT IN (SELECT id FROM users) OR reported_user_id NOT IN (SELECT id FROM users);
+UPDATE report SET reviewed_by = NULL WHERE reviewed_by NOT IN (SELECT id FROM users);
+
+-- Purging a user removes everything that belongs to them. admin_audit_log has no foreign keys on
+-- purpose, it is append only and has to outlive the accounts it mentions.
+ALTER TABLE profile
+    ADD CONSTRAINT profile_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE inventory
+    ADD CONSTRAINT inventory_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE notification
+    ADD CONSTRAINT notification_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE push_outbox
+    ADD CONSTRAINT push_outbox_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE matched
+    ADD CONSTRAINT matched_user_a_id_fkey FOREIGN KEY (user_a_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT matched_user_b_id_fkey FOREIGN KEY (user_b_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE match_reminder
+    ADD CONSTRAINT match_reminder_user_a_id_fkey FOREIGN KEY (user_a_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT match_reminder_user_b_id_fkey FOREIGN KEY (user_b_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE user_block
+    ADD CONSTRAINT user_block_blocker_id_fkey FOREIGN KEY (blocker_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT user_block_blocked_id_fkey FOREIGN KEY (blocked_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE conversation
+    ADD CONSTRAINT conversation_user_a_id_fkey FOREIGN KEY (user_a_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT conversation_user_b_id_fkey FOREIGN KEY (user_b_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE message
+    ADD CONSTRAINT message_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE report
+    ADD CONSTRAINT report_reporter_id_fkey FOREIGN KEY (reporter_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT report_reported_user_id_fkey FOREIGN KEY (reported_user_id) REFERENCES users (id) ON DELETE CASCADE,
+    -- A purged admin leaves the reviews they made in place.
+    ADD CONSTRAINT report_reviewed_by_fkey FOREIGN KEY (reviewed_by) REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,7 @@
+-- Values set at runtime by admins, settings without a row use the default compiled into the service.
+CREATE TABLE runtime_setting (
+    key VARCHAR PRIMARY KEY,
+    value VARCHAR NOT NULL,
+    updated_by BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+ALTER TABLE notification DROP CONSTRAINT IF EXISTS notification_outbox_id_key;
+ALTER TABLE notification DROP COLUMN IF EXISTS outbox_id;
@@ -0,0 +1,3 @@
+-- The outbox relay delivers at least once, a message published again after a crash must not notify twice.
+ALTER TABLE notification ADD COLUMN outbox_id BIGINT;
+ALTER TABLE notification ADD CONSTRAINT notification_outbox_id_key UNIQUE (outbox_id);
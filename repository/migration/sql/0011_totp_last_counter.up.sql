@@ -0,0 +1,2 @@
+-- An authenticator code is only accepted once, later codes have to come from a later period.
+ALTER TABLE users ADD COLUMN totp_last_counter BIGINT;
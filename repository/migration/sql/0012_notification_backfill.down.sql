@@ -0,0 +1,6 @@
+-- The legacy message text is still in place, back-filled rows only lose their typed rendering.
+UPDATE notification
+SET type         = 'system',
+    template_key = 'system.text',
+    payload      = jsonb_build_object('text', message)
+WHERE message IS NOT NULL AND template_key IN ('match.new', 'super_like.received', 'match.reminder');
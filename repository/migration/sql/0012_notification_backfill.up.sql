@@ -0,0 +1,25 @@
+-- Back-fill existing rows from the legacy free-text messages. Actor user ids were never stored so
+-- only the name snapshot can be recovered, anything unrecognised is kept verbatim as system text.
+UPDATE notification
+SET type         = 'match',
+    template_key = 'match.new',
+    payload      = jsonb_build_object('actor_name', substring(message FROM '^You have a new match with (.*)$'))
+WHERE template_key = 'system.text' AND message ~ '^You have a new match with ';
+
+UPDATE notification
+SET type         = 'super_like',
+    template_key = 'super_like.received',
+    payload      = jsonb_build_object('actor_name', substring(message FROM '^(.*) super liked you$'))
+WHERE template_key = 'system.text' AND message ~ ' super liked you$';
+
+UPDATE notification
+SET type         = 'match',
+    template_key = 'match.reminder',
+    payload      = jsonb_build_object(
+        'actor_name', substring(message FROM '^You matched with (.*) (?:today|\d+ days? ago) — say hi$'),
+        'days', COALESCE(substring(message FROM ' (\d+) days? ago — say hi$')::INT, 0))
+WHERE template_key = 'system.text' AND message ~ '^You matched with .* (today|\d+ days? ago) — say hi$';
+
+UPDATE notification
+SET payload = jsonb_build_object('text', message)
+WHERE template_key = 'system.text' AND payload = '{}' AND message IS NOT NULL;
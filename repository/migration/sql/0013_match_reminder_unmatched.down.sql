@@ -0,0 +1,2 @@
+-- The dropped reminders only kept rematched pairs from being reminded, there is nothing to restore.
+SELECT 1;
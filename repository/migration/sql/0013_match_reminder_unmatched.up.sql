@@ -0,0 +1,5 @@
+-- Unmatching now drops the reminders of the pair, drop the ones left behind by earlier unmatches so pairs
+-- that matched again get their reminders.
+DELETE FROM match_reminder AS r
+WHERE NOT EXISTS (SELECT 1 FROM matched AS m
+                  WHERE m.user_a_id = r.user_a_id AND m.user_b_id = r.user_b_id AND m.deleted_at IS NULL);
@@ -2,9 +2,15 @@ package notification
 
 import (
 	"context"
+	"errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -16,7 +22,14 @@ type dbModule struct {
 type dbInterface interface {
 	create(ctx context.Context, req domain.NotificationRequest) error
 	getAllByUserId(ctx context.Context, userID int64) ([]domain.Notification, error)
+	getByFilter(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, error)
 	setRead(ctx context.Context, notificationID int64) error
+	purgeByUserID(ctx context.Context, userID int64) error
+	getByID(ctx context.Context, notificationID int64) (*domain.Notification, error)
+	claimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.PushOutbox, error)
+	updateOutbox(ctx context.Context, outboxID int64, update domain.PushOutboxUpdate) error
+	getPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+	savePreferences(ctx context.Context, prefs domain.NotificationPreferences) error
 }
 
 func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
@@ -26,28 +39,63 @@ func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
 	}
 }
 
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+// create stores the notification together with its push outbox entry so a
+// push is never lost or sent for a notification that was rolled back.
 func (d dbModule) create(ctx context.Context, req domain.NotificationRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.create")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.notification.create"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	notification := domain.Notification{
-		UserID:  req.UserID,
-		Message: req.Message,
-		IsRead:  false,
-	}
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		notification := domain.Notification{
+			UserID:      req.UserID,
+			Type:        req.Type,
+			TemplateKey: req.TemplateKey,
+			Payload:     req.Payload,
+			IsRead:      false,
+		}
+		if req.OutboxID != 0 {
+			notification.OutboxID = &req.OutboxID
+		}
 
-	result := d.db.Table("notification").Create(&notification)
-	if result.Error != nil {
-		tags["error"] = result.Error.Error()
+		result := tx.Table("notification").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "outbox_id"}},
+			DoNothing: true,
+		}).Create(&notification)
+		if result.Error != nil {
+			return dberr.Translate(result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			// Stored and queued for push by an earlier delivery of the same outbox message.
+			tags["duplicate"] = true
+			return nil
+		}
+
+		return dberr.Translate(tx.Table("push_outbox").Create(&domain.PushOutbox{
+			NotificationID: notification.ID,
+			UserID:         notification.UserID,
+			Status:         domain.PushOutboxStatusPending,
+			NextAttemptAt:  time.Now(),
+		}).Error)
+	})
+	if err != nil {
+		tags["error"] = err.Error()
 		tags["status"] = "error"
-		return result.Error
+		return err
 	}
 
 	tags["status"] = "success"
@@ -55,18 +103,20 @@ func (d dbModule) create(ctx context.Context, req domain.NotificationRequest) er
 }
 
 func (d dbModule) getAllByUserId(ctx context.Context, userID int64) ([]domain.Notification, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.getAll")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.notification.getAll"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	var notifications []domain.Notification
-	result := d.db.Table("notification").
+	result := d.conn(ctx).Table("notification").
 		Where("user_id = ?", userID).
 		Find(&notifications)
 	if result.Error != nil {
@@ -79,18 +129,60 @@ func (d dbModule) getAllByUserId(ctx context.Context, userID int64) ([]domain.No
 	return notifications, nil
 }
 
+func (d dbModule) getByFilter(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.getByFilter")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.notification.getByFilter"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	query := d.conn(ctx).Table("notification").
+		Where("user_id = ? AND deleted_at IS NULL", userID)
+	if filter.Unread {
+		query = query.Where("is_read = ?", false)
+	}
+
+	var notifications []domain.Notification
+	result := query.
+		Order("id DESC").
+		Limit(limit).
+		Offset(filter.Offset).
+		Find(&notifications)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return notifications, nil
+}
+
 func (d dbModule) setRead(ctx context.Context, notificationID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.setRead")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.notification.setRead"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	result := d.db.Table("notification").
+	result := d.conn(ctx).Table("notification").
 		Where("id = ?", notificationID).
 		Update("is_read", true)
 	if result.Error != nil {
@@ -102,3 +194,209 @@ func (d dbModule) setRead(ctx context.Context, notificationID int64) error {
 	tags["status"] = "success"
 	return nil
 }
+
+func (d dbModule) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.purgeByUserID")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.notification.purgeByUserID"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if result := tx.Table("push_outbox").
+			Where("user_id = ?", userID).
+			Delete(&domain.PushOutbox{}); result.Error != nil {
+			return result.Error
+		}
+
+		if result := tx.Table("notification_preference").
+			Where("user_id = ?", userID).
+			Delete(&domain.NotificationPreferences{}); result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Table("notification").
+			Where("user_id = ?", userID).
+			Delete(&domain.Notification{}).Error
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (d dbModule) getByID(ctx context.Context, notificationID int64) (*domain.Notification, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.getByID")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.notification.getByID"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var notification domain.Notification
+	result := d.conn(ctx).Table("notification").
+		Where("id = ? AND deleted_at IS NULL", notificationID).
+		First(&notification)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return &notification, nil
+}
+
+// claimOutbox leases up to limit due entries by pushing their next attempt
+// past lease, so concurrent workers skip them and a crashed worker's entries
+// are picked up again once the lease runs out.
+func (d dbModule) claimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.PushOutbox, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.claimOutbox")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.notification.claimOutbox"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	now := time.Now()
+	var entries []domain.PushOutbox
+	result := d.conn(ctx).Raw("UPDATE push_outbox SET next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP "+
+		"WHERE id IN (SELECT id FROM push_outbox WHERE status = ? AND next_attempt_at <= ? "+
+		"ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED) RETURNING *",
+		now.Add(lease), domain.PushOutboxStatusPending, now, limit).
+		Scan(&entries)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["claimed"] = len(entries)
+	tags["status"] = "success"
+	return entries, nil
+}
+
+func (d dbModule) updateOutbox(ctx context.Context, outboxID int64, update domain.PushOutboxUpdate) error {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.updateOutbox")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.notification.updateOutbox"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("push_outbox").
+		Where("id = ?", outboxID).
+		Updates(map[string]interface{}{
+			"status":          update.Status,
+			"attempts":        update.Attempts,
+			"next_attempt_at": update.NextAttemptAt,
+			"last_error":      update.LastError,
+			"updated_at":      gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// getPreferences returns nil when the user never saved preferences.
+func (d dbModule) getPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.getPreferences")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.notification.getPreferences"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var prefs domain.NotificationPreferences
+	result := d.conn(ctx).Table("notification_preference").
+		Where("user_id = ?", userID).
+		First(&prefs)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return &prefs, nil
+}
+
+func (d dbModule) savePreferences(ctx context.Context, prefs domain.NotificationPreferences) error {
+	ctx, span := tracing.Start(ctx, "repo.database.notification.savePreferences")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.notification.savePreferences"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := d.conn(ctx).Table("notification_preference").
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"matches":     prefs.Matches,
+				"messages":    prefs.Messages,
+				"super_likes": prefs.SuperLikes,
+				"marketing":   prefs.Marketing,
+				"updated_at":  gorm.Expr("CURRENT_TIMESTAMP"),
+			}),
+		}).
+		Create(&prefs)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return dberr.Translate(result.Error)
+	}
+
+	tags["status"] = "success"
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/zombozo12/tinder-dealls/domain"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Module struct {
@@ -26,6 +27,34 @@ func (m Module) GetAllByUserId(ctx context.Context, userID int64) ([]domain.Noti
 	return m.dbs.getAllByUserId(ctx, userID)
 }
 
+func (m Module) GetByFilter(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, error) {
+	return m.dbs.getByFilter(ctx, userID, filter)
+}
+
 func (m Module) SetRead(ctx context.Context, notificationID int64) error {
 	return m.dbs.setRead(ctx, notificationID)
 }
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
+
+func (m Module) GetByID(ctx context.Context, notificationID int64) (*domain.Notification, error) {
+	return m.dbs.getByID(ctx, notificationID)
+}
+
+func (m Module) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.PushOutbox, error) {
+	return m.dbs.claimOutbox(ctx, limit, lease)
+}
+
+func (m Module) UpdateOutbox(ctx context.Context, outboxID int64, update domain.PushOutboxUpdate) error {
+	return m.dbs.updateOutbox(ctx, outboxID, update)
+}
+
+func (m Module) GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	return m.dbs.getPreferences(ctx, userID)
+}
+
+func (m Module) SavePreferences(ctx context.Context, prefs domain.NotificationPreferences) error {
+	return m.dbs.savePreferences(ctx, prefs)
+}
@@ -0,0 +1,276 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MicahParks/keyfunc/v2"
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+}
+
+// Module is an OpenID Connect identity provider speaking the authorization code flow with PKCE.
+// The discovery document and JWKS are fetched lazily on first use so the server can start without
+// reaching the provider.
+type Module struct {
+	name     string
+	provider domain.OAuthProvider
+	client   *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	jwks      *keyfunc.JWKS
+}
+
+func New(name string, provider domain.OAuthProvider) *Module {
+	return &Module{
+		name:     name,
+		provider: provider,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (m *Module) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	ctx, span := tracing.Start(ctx, "repo.oidc.auth_code_url")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.oidc.auth_code_url"
+		tags["provider"] = m.name
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	discovery, err := m.discover(ctx)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return "", err
+	}
+
+	scopes := m.provider.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email"}
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", m.provider.ClientID)
+	query.Set("redirect_uri", m.provider.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	tags["status"] = "success"
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (m *Module) Exchange(ctx context.Context, code, codeVerifier, nonce string) (domain.IdentityClaims, error) {
+	ctx, span := tracing.Start(ctx, "repo.oidc.exchange")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.oidc.exchange"
+		tags["provider"] = m.name
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	discovery, err := m.discover(ctx)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return domain.IdentityClaims{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", m.provider.RedirectURL)
+	form.Set("client_id", m.provider.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if m.provider.ClientSecret != "" {
+		form.Set("client_secret", m.provider.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return domain.IdentityClaims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return domain.IdentityClaims{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		tags["error"] = fmt.Sprintf("token endpoint returned %d", res.StatusCode)
+		tags["status"] = "error"
+		return domain.IdentityClaims{}, fmt.Errorf("token endpoint returned %d", res.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return domain.IdentityClaims{}, err
+	}
+
+	if token.IDToken == "" {
+		tags["error"] = "missing id token"
+		tags["status"] = "error"
+		return domain.IdentityClaims{}, errors.New("missing id token")
+	}
+
+	claims, err := m.verifyIDToken(discovery, token.IDToken, nonce)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return domain.IdentityClaims{}, err
+	}
+
+	tags["status"] = "success"
+	return claims, nil
+}
+
+func (m *Module) verifyIDToken(discovery *discoveryDocument, idToken, nonce string) (domain.IdentityClaims, error) {
+	jwks, err := m.keys(discovery)
+	if err != nil {
+		return domain.IdentityClaims{}, err
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(idToken, claims, jwks.Keyfunc,
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(m.provider.ClientID),
+		jwt.WithExpirationRequired(),
+	); err != nil {
+		return domain.IdentityClaims{}, err
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return domain.IdentityClaims{}, errors.New("nonce mismatch")
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return domain.IdentityClaims{}, errors.New("missing subject")
+	}
+
+	identity := domain.IdentityClaims{
+		Subject: subject,
+	}
+	identity.Email, _ = claims["email"].(string)
+
+	// Apple sends email_verified as a string, Google as a boolean.
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+
+	return identity, nil
+}
+
+func (m *Module) discover(ctx context.Context) (*discoveryDocument, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.discovery != nil {
+		return m.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(m.provider.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery returned %d", res.StatusCode)
+	}
+
+	var discovery discoveryDocument
+	if err := json.NewDecoder(res.Body).Decode(&discovery); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(m.provider.Issuer, "/") {
+		return nil, fmt.Errorf("issuer mismatch: %s", discovery.Issuer)
+	}
+
+	m.discovery = &discovery
+	return m.discovery, nil
+}
+
+func (m *Module) keys(discovery *discoveryDocument) (*keyfunc.JWKS, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.jwks != nil {
+		return m.jwks, nil
+	}
+
+	jwks, err := keyfunc.Get(discovery.JWKSURI, keyfunc.Options{
+		Client:            m.client,
+		RefreshUnknownKID: true,
+		RefreshRateLimit:  time.Minute,
+		RefreshTimeout:    10 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.jwks = jwks
+	return m.jwks, nil
+}
@@ -0,0 +1,219 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// mockServer is a minimal OpenID provider serving discovery, JWKS and the token endpoint.
+type mockServer struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+}
+
+func newMockServer(t *testing.T) *mockServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed generating key: %s", err)
+	}
+
+	m := &mockServer{key: key}
+	mux := http.NewServeMux()
+	m.Server = httptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(discoveryDocument{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// The code doubles as the PKCE challenge so the verifier can be checked without server state.
+		challenge := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if r.Form.Get("code") != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, m.claims)
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(tokenResponse{
+			AccessToken: "access",
+			IDToken:     signed,
+			TokenType:   "Bearer",
+		})
+	})
+
+	return m
+}
+
+func TestModule_AuthCodeURL(t *testing.T) {
+	server := newMockServer(t)
+	defer server.Close()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	m := New("mock", domain.OAuthProvider{
+		Issuer:      server.URL,
+		ClientID:    "client",
+		RedirectURL: "http://localhost/callback",
+	})
+
+	got, err := m.AuthCodeURL(ctx, "state", "nonce", "challenge")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	parsed, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() returned invalid url %s", got)
+	}
+
+	query := parsed.Query()
+	if parsed.Path != "/authorize" || query.Get("state") != "state" || query.Get("nonce") != "nonce" ||
+		query.Get("code_challenge") != "challenge" || query.Get("code_challenge_method") != "S256" ||
+		query.Get("client_id") != "client" {
+		t.Errorf("AuthCodeURL() got = %s", got)
+	}
+}
+
+func TestModule_Exchange(t *testing.T) {
+	server := newMockServer(t)
+	defer server.Close()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	verifier := "verifier"
+	challenge := sha256.Sum256([]byte(verifier))
+	code := base64.RawURLEncoding.EncodeToString(challenge[:])
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            server.URL,
+			"aud":            "client",
+			"sub":            "subject",
+			"email":          "test@mail.com",
+			"email_verified": "true",
+			"nonce":          "nonce",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"iat":            time.Now().Unix(),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		claims   func() jwt.MapClaims
+		verifier string
+		want     domain.IdentityClaims
+		wantErr  bool
+	}{
+		{
+			name:     "success",
+			claims:   validClaims,
+			verifier: verifier,
+			want: domain.IdentityClaims{
+				Subject:       "subject",
+				Email:         "test@mail.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name:     "failed wrong code verifier",
+			claims:   validClaims,
+			verifier: "other",
+			wantErr:  true,
+		},
+		{
+			name: "failed wrong audience",
+			claims: func() jwt.MapClaims {
+				claims := validClaims()
+				claims["aud"] = "other-client"
+				return claims
+			},
+			verifier: verifier,
+			wantErr:  true,
+		},
+		{
+			name: "failed wrong nonce",
+			claims: func() jwt.MapClaims {
+				claims := validClaims()
+				claims["nonce"] = "replayed"
+				return claims
+			},
+			verifier: verifier,
+			wantErr:  true,
+		},
+		{
+			name: "failed expired token",
+			claims: func() jwt.MapClaims {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return claims
+			},
+			verifier: verifier,
+			wantErr:  true,
+		},
+	}
+
+	m := New("mock", domain.OAuthProvider{
+		Issuer:      server.URL,
+		ClientID:    "client",
+		RedirectURL: "http://localhost/callback",
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.claims = tt.claims()
+
+			got, err := m.Exchange(ctx, code, tt.verifier, "nonce")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Exchange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Exchange() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"context"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"time"
+)
+
+type dbModule struct {
+	db  *gorm.DB
+	cfg *domain.Config
+}
+
+type dbInterface interface {
+	enqueue(ctx context.Context, kind string, payload interface{}) error
+	claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	update(ctx context.Context, messageID int64, update domain.OutboxUpdate) error
+}
+
+func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
+	return &dbModule{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+// enqueue records a side effect. Called inside a unit of work it is only
+// published when the surrounding transaction commits.
+func (d dbModule) enqueue(ctx context.Context, kind string, payload interface{}) error {
+	ctx, span := tracing.Start(ctx, "repo.database.outbox.enqueue")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.outbox.enqueue"
+		tags["kind"] = kind
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	message := domain.OutboxMessage{
+		Kind:        kind,
+		Payload:     string(data),
+		Status:      domain.OutboxStatusPending,
+		AvailableAt: time.Now(),
+	}
+
+	result := d.conn(ctx).Table("outbox").Create(&message)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return dberr.Translate(result.Error)
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// claim leases up to limit due messages by pushing their availability past
+// lease, so concurrent relays skip them and a crashed relay's messages are
+// picked up again once the lease runs out.
+func (d dbModule) claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.outbox.claim")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.outbox.claim"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	now := time.Now()
+	var messages []domain.OutboxMessage
+	result := d.conn(ctx).Raw("UPDATE outbox SET available_at = ? "+
+		"WHERE id IN (SELECT id FROM outbox WHERE status = ? AND available_at <= ? "+
+		"ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED) RETURNING *",
+		now.Add(lease), domain.OutboxStatusPending, now, limit).
+		Scan(&messages)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["claimed"] = len(messages)
+	tags["status"] = "success"
+	return messages, nil
+}
+
+func (d dbModule) update(ctx context.Context, messageID int64, update domain.OutboxUpdate) error {
+	ctx, span := tracing.Start(ctx, "repo.database.outbox.update")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.outbox.update"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	values := map[string]interface{}{
+		"status":       update.Status,
+		"attempts":     update.Attempts,
+		"available_at": update.AvailableAt,
+		"last_error":   update.LastError,
+	}
+	if update.Status == domain.OutboxStatusPublished {
+		values["published_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+	}
+
+	result := d.conn(ctx).Table("outbox").
+		Where("id = ?", messageID).
+		Updates(values)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
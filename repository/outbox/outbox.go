@@ -0,0 +1,32 @@
+package outbox
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+type Module struct {
+	cfg *domain.Config
+	dbs dbInterface
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+	}
+}
+
+func (m Module) Enqueue(ctx context.Context, kind string, payload interface{}) error {
+	return m.dbs.enqueue(ctx, kind, payload)
+}
+
+func (m Module) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	return m.dbs.claim(ctx, limit, lease)
+}
+
+func (m Module) Update(ctx context.Context, messageID int64, update domain.OutboxUpdate) error {
+	return m.dbs.update(ctx, messageID, update)
+}
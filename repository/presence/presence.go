@@ -0,0 +1,105 @@
+package presence
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"strconv"
+	"time"
+)
+
+type Module struct {
+	rds *redis.Client
+	cfg *domain.Config
+}
+
+func New(rds *redis.Client, cfg *domain.Config) *Module {
+	return &Module{
+		rds: rds,
+		cfg: cfg,
+	}
+}
+
+func key(userID int64) string {
+	return fmt.Sprintf("last_active:%d", userID)
+}
+
+// Touch stores the current time as the last activity of the user, the key expires after domain.PresenceRetention.
+func (m Module) Touch(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.redis.presence.touch")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.redis.presence.touch"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := m.rds.Set(ctx, key(userID), time.Now().Unix(), domain.PresenceRetention)
+	if result.Err() != nil {
+		tags["error"] = result.Err().Error()
+		tags["status"] = "error"
+		return result.Err()
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// GetLastActive returns the last activity of every given user we still know about, others are left out.
+func (m Module) GetLastActive(ctx context.Context, userIDs ...int64) (map[int64]time.Time, error) {
+	ctx, span := tracing.Start(ctx, "repo.redis.presence.get_last_active")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.redis.presence.get_last_active"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	lastActive := make(map[int64]time.Time, len(userIDs))
+	if len(userIDs) == 0 {
+		tags["status"] = "success"
+		return lastActive, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = key(userID)
+	}
+
+	result := m.rds.MGet(ctx, keys...)
+	if result.Err() != nil {
+		tags["error"] = result.Err().Error()
+		tags["status"] = "error"
+		return nil, result.Err()
+	}
+
+	for i, value := range result.Val() {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		unix, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			tags["warning"] = "invalid last active value"
+			continue
+		}
+
+		lastActive[userIDs[i]] = time.Unix(unix, 0)
+	}
+
+	tags["status"] = "success"
+	return lastActive, nil
+}
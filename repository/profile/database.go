@@ -5,6 +5,10 @@ import (
 	"errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"gorm.io/gorm"
 	"time"
 )
@@ -19,7 +23,11 @@ type dbInterface interface {
 	updateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error
 	updateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error
 	getProfile(ctx context.Context, userID int64) (profile *domain.Profile, err error)
-	getProfileRecommendation(ctx context.Context, interest string, notInUserID []int64) (profiles []domain.Profile, err error)
+	getProfileRecommendation(ctx context.Context, interest string, notInUserID []int64, limit int) (profiles []domain.Profile, err error)
+	softDeleteByUserID(ctx context.Context, userID int64) error
+	purgeByUserID(ctx context.Context, userID int64) error
+	setHidden(ctx context.Context, userID int64, hiddenAt *time.Time) error
+	updatePrivacy(ctx context.Context, userID int64, showLastActive bool) error
 }
 
 func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
@@ -29,28 +37,36 @@ func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
 	}
 }
 
+// conn joins the unit of work running on ctx, if any.
+func (m module) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, m.db)
+}
+
 func (m module) create(ctx context.Context, userID int64, req domain.ProfileRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.create")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.profile.create"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	profile := domain.Profile{
 		UserID:     userID,
 		Name:       req.Name,
+		Bio:        req.Bio,
 		Gender:     req.Gender,
 		InterestIn: req.InterestIn,
 	}
 
-	if result := m.db.Table("profile").Create(&profile); result.Error != nil {
+	if result := m.conn(ctx).Table("profile").Create(&profile); result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
-		return result.Error
+		return dberr.Translate(result.Error)
 	}
 
 	tags["status"] = "success"
@@ -58,17 +74,19 @@ func (m module) create(ctx context.Context, userID int64, req domain.ProfileRequ
 }
 
 func (m module) updateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.update_profile_pic")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.profile.update_profile_pic"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	if result := m.db.Table("profile").Where("user_id = ?", userID).Update("pic", req.Pic); result.Error != nil {
+	if result := m.conn(ctx).Table("profile").Where("user_id = ?", userID).Update("pic", req.Pic); result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
 		return result.Error
@@ -79,17 +97,23 @@ func (m module) updateProfilePic(ctx context.Context, userID int64, req domain.U
 }
 
 func (m module) updateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.update_profile")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.profile.update_profile"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	if result := m.db.Table("profile").Where("user_id = ?", userID).Update("name", req.Name); result.Error != nil {
+	if result := m.conn(ctx).Table("profile").Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"name": req.Name,
+			"bio":  req.Bio,
+		}); result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
 		return result.Error
@@ -100,18 +124,20 @@ func (m module) updateProfile(ctx context.Context, userID int64, req domain.Prof
 }
 
 func (m module) getProfile(ctx context.Context, userID int64) (profile *domain.Profile, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.get_profile")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.profile.get_profile"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
+		tags["request_id"] = logging.RequestID(ctx)
 		tags["status"] = "success"
-		log.WithFields(tags).Debug()
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	if result := m.db.Table("profile").Where("user_id = ?", userID).First(&profile); result.Error != nil {
+	if result := m.conn(ctx).Table("profile").Where("user_id = ?", userID).First(&profile); result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			tags["status"] = "not_found"
 			return nil, nil
@@ -126,21 +152,25 @@ func (m module) getProfile(ctx context.Context, userID int64) (profile *domain.P
 	return profile, nil
 }
 
-func (m module) getProfileRecommendation(ctx context.Context, interest string, notInUserID []int64) (profiles []domain.Profile, err error) {
+func (m module) getProfileRecommendation(ctx context.Context, interest string, notInUserID []int64, limit int) (profiles []domain.Profile, err error) {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.get_profile_recommendation")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.database.profile.get_profile_recommendation"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
+		tags["request_id"] = logging.RequestID(ctx)
 		tags["status"] = "success"
-		log.WithFields(tags).Debug()
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	if result := m.db.Table("profile").
-		Where("interest_in = ? AND gender = ? AND user_id NOT IN (?)", interest, interest, notInUserID).
-		Limit(10).
+	if result := m.conn(ctx).Table("profile").
+		Where("interest_in = ? AND gender = ? AND user_id NOT IN (?) AND deleted_at IS NULL AND hidden_at IS NULL",
+			interest, interest, notInUserID).
+		Where("user_id NOT IN (SELECT id FROM users WHERE banned_at IS NOT NULL)").
+		Limit(limit).
 		Find(&profiles); result.Error != nil {
 		tags["error"] = result.Error.Error()
 		tags["status"] = "error"
@@ -150,3 +180,111 @@ func (m module) getProfileRecommendation(ctx context.Context, interest string, n
 	tags["status"] = "success"
 	return profiles, nil
 }
+
+func (m module) softDeleteByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.soft_delete_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.profile.soft_delete_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("profile").Where("user_id = ? AND deleted_at IS NULL", userID).
+		Update("deleted_at", time.Now()); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (m module) purgeByUserID(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.purge_by_user_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.profile.purge_by_user_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("profile").Where("user_id = ?", userID).Delete(&domain.Profile{}); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (m module) setHidden(ctx context.Context, userID int64, hiddenAt *time.Time) error {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.set_hidden")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.profile.set_hidden"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if result := m.conn(ctx).Table("profile").Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"hidden_at":  hiddenAt,
+			"updated_at": time.Now(),
+		}); result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (m module) updatePrivacy(ctx context.Context, userID int64, showLastActive bool) error {
+	ctx, span := tracing.Start(ctx, "repo.database.profile.update_privacy")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.profile.update_privacy"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := m.conn(ctx).Table("profile").Where("user_id = ? AND deleted_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"show_last_active": showLastActive,
+			"updated_at":       time.Now(),
+		})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tags["error"] = "profile not found"
+		tags["status"] = "error"
+		return domain.ErrProfileNotFound
+	}
+
+	tags["status"] = "success"
+	return nil
+}
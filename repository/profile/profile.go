@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/zombozo12/tinder-dealls/domain"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Module struct {
@@ -34,6 +35,22 @@ func (m Module) GetProfile(ctx context.Context, userID int64) (profile *domain.P
 	return m.dbs.getProfile(ctx, userID)
 }
 
-func (m Module) GetProfileRecommendation(ctx context.Context, interest string, notInUserID []int64) (profiles []domain.Profile, err error) {
-	return m.dbs.getProfileRecommendation(ctx, interest, notInUserID)
+func (m Module) GetProfileRecommendation(ctx context.Context, interest string, notInUserID []int64, limit int) (profiles []domain.Profile, err error) {
+	return m.dbs.getProfileRecommendation(ctx, interest, notInUserID, limit)
+}
+
+func (m Module) SoftDeleteByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.softDeleteByUserID(ctx, userID)
+}
+
+func (m Module) PurgeByUserID(ctx context.Context, userID int64) error {
+	return m.dbs.purgeByUserID(ctx, userID)
+}
+
+func (m Module) SetHidden(ctx context.Context, userID int64, hiddenAt *time.Time) error {
+	return m.dbs.setHidden(ctx, userID, hiddenAt)
+}
+
+func (m Module) UpdatePrivacy(ctx context.Context, userID int64, showLastActive bool) error {
+	return m.dbs.updatePrivacy(ctx, userID, showLastActive)
 }
@@ -0,0 +1,178 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apnsProductionEndpoint = "https://api.push.apple.com"
+	apnsSandboxEndpoint    = "https://api.sandbox.push.apple.com"
+	// Apple rejects provider tokens older than an hour and throttles ones refreshed more often than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+	// apns-collapse-id may not exceed 64 bytes.
+	apnsMaxCollapseID = 64
+)
+
+type apnsError struct {
+	Reason string `json:"reason"`
+}
+
+// APNs sends through the Apple Push Notification service HTTP/2 API using
+// token based authentication.
+type APNs struct {
+	cfg      domain.APNs
+	key      *ecdsa.PrivateKey
+	client   *http.Client
+	endpoint string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+func NewAPNs(cfg domain.APNs, client *http.Client) (*APNs, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(cfg.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = apnsProductionEndpoint
+		if cfg.Sandbox {
+			endpoint = apnsSandboxEndpoint
+		}
+	}
+
+	return &APNs{
+		cfg:      cfg,
+		key:      key,
+		client:   client,
+		endpoint: strings.TrimRight(endpoint, "/"),
+	}, nil
+}
+
+func (a *APNs) Send(ctx context.Context, msg domain.PushMessage) error {
+	ctx, span := tracing.Start(ctx, "repo.push.apns.send")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.push.apns.send"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	providerToken, err := a.providerToken()
+	if err != nil {
+		tags["error"] = "failed signing provider token"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	// Custom data travels next to the aps dictionary.
+	body := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range msg.Data {
+		body[key] = value
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/3/device/"+msg.Token, bytes.NewReader(payload))
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apns-topic", a.cfg.BundleID)
+	req.Header.Set("apns-push-type", "alert")
+	if msg.CollapseKey != "" && len(msg.CollapseKey) <= apnsMaxCollapseID {
+		req.Header.Set("apns-collapse-id", msg.CollapseKey)
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		tags["status"] = "success"
+		return nil
+	}
+
+	var apnsErr apnsError
+	_ = json.NewDecoder(io.LimitReader(res.Body, 64*1024)).Decode(&apnsErr)
+	tags["status_code"] = res.StatusCode
+	tags["error"] = apnsErr.Reason
+	tags["status"] = "error"
+
+	switch apnsErr.Reason {
+	case "Unregistered", "BadDeviceToken", "DeviceTokenNotForTopic":
+		return domain.ErrInvalidPushToken
+	}
+
+	if res.StatusCode == http.StatusGone {
+		return domain.ErrInvalidPushToken
+	}
+
+	return fmt.Errorf("apns returned %d: %s", res.StatusCode, apnsErr.Reason)
+}
+
+// providerToken returns the signed provider JWT, reusing it until apnsTokenLifetime passed.
+func (a *APNs) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Since(a.issuedAt) < apnsTokenLifetime {
+		return a.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": a.cfg.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = a.cfg.KeyID
+
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = signed
+	a.issuedAt = now
+
+	return a.token, nil
+}
@@ -0,0 +1,52 @@
+package push
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"sync"
+)
+
+// Fake records pushes instead of handing them to a gateway. It is used for
+// platforms without credentials in local development and in tests.
+type Fake struct {
+	mu      sync.Mutex
+	sent    []domain.PushMessage
+	invalid map[string]bool
+}
+
+func NewFake(invalidTokens ...string) *Fake {
+	invalid := make(map[string]bool, len(invalidTokens))
+	for _, token := range invalidTokens {
+		invalid[token] = true
+	}
+
+	return &Fake{
+		invalid: invalid,
+	}
+}
+
+func (f *Fake) Send(ctx context.Context, msg domain.PushMessage) error {
+	if f.invalid[msg.Token] {
+		return domain.ErrInvalidPushToken
+	}
+
+	f.mu.Lock()
+	f.sent = append(f.sent, msg)
+	f.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"platform": msg.Platform,
+		"body":     msg.Body,
+	}).Info("push sent")
+
+	return nil
+}
+
+// Sent returns a copy of every message accepted so far.
+func (f *Fake) Sent() []domain.PushMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]domain.PushMessage(nil), f.sent...)
+}
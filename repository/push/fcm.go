@@ -0,0 +1,229 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fcmDefaultTokenURL = "https://oauth2.googleapis.com/token"
+	fcmDefaultEndpoint = "https://fcm.googleapis.com"
+	fcmScope           = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+type fcmAccessToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type fcmMessage struct {
+	Message fcmMessageBody `json:"message"`
+}
+
+type fcmMessageBody struct {
+	Token        string            `json:"token"`
+	Notification map[string]string `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      map[string]string `json:"android,omitempty"`
+}
+
+type fcmError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// FCM sends through the Firebase Cloud Messaging HTTP v1 API. It signs in with
+// a service account and caches the access token until shortly before it expires.
+type FCM struct {
+	cfg    domain.FCM
+	key    *rsa.PrivateKey
+	client *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewFCM(cfg domain.FCM, client *http.Client) (*FCM, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = fcmDefaultTokenURL
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fcmDefaultEndpoint
+	}
+
+	return &FCM{
+		cfg:    cfg,
+		key:    key,
+		client: client,
+	}, nil
+}
+
+func (f *FCM) Send(ctx context.Context, msg domain.PushMessage) error {
+	ctx, span := tracing.Start(ctx, "repo.push.fcm.send")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.push.fcm.send"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	accessToken, err := f.token(ctx)
+	if err != nil {
+		tags["error"] = "failed getting access token"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	body := fcmMessage{Message: fcmMessageBody{
+		Token:        msg.Token,
+		Notification: map[string]string{"body": msg.Body},
+		Data:         msg.Data,
+	}}
+	if msg.CollapseKey != "" {
+		body.Message.Android = map[string]string{"collapse_key": msg.CollapseKey}
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", strings.TrimRight(f.cfg.Endpoint, "/"), f.cfg.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		tags["status"] = "success"
+		return nil
+	}
+
+	var fcmErr fcmError
+	_ = json.NewDecoder(io.LimitReader(res.Body, 64*1024)).Decode(&fcmErr)
+	tags["status_code"] = res.StatusCode
+	tags["error"] = fcmErr.Error.Message
+	tags["status"] = "error"
+
+	if res.StatusCode == http.StatusNotFound || fcmInvalidToken(fcmErr) {
+		return domain.ErrInvalidPushToken
+	}
+
+	return fmt.Errorf("fcm returned %d: %s", res.StatusCode, fcmErr.Error.Status)
+}
+
+func fcmInvalidToken(fcmErr fcmError) bool {
+	for _, detail := range fcmErr.Error.Details {
+		switch detail.ErrorCode {
+		case "UNREGISTERED", "INVALID_ARGUMENT":
+			return true
+		}
+	}
+
+	return false
+}
+
+// token returns a cached OAuth access token, exchanging a freshly signed
+// service account assertion when it is about to expire.
+func (f *FCM) token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.accessToken != "" && time.Now().Before(f.expiresAt) {
+		return f.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   f.cfg.ClientEmail,
+		"scope": fcmScope,
+		"aud":   f.cfg.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(f.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d", res.StatusCode)
+	}
+
+	var token fcmAccessToken
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	if token.AccessToken == "" {
+		return "", errors.New("token endpoint returned no access token")
+	}
+
+	// Refresh a minute early so an in-flight send never carries an expired token.
+	f.accessToken = token.AccessToken
+	f.expiresAt = now.Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+
+	return f.accessToken, nil
+}
@@ -0,0 +1,90 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"net/http"
+	"time"
+)
+
+// Sender delivers a single push message to one device. Implementations return
+// domain.ErrInvalidPushToken when the gateway rejects the token for good.
+type Sender interface {
+	Send(ctx context.Context, msg domain.PushMessage) error
+}
+
+// Gateway routes every message to the sender of its platform.
+type Gateway struct {
+	senders map[string]Sender
+}
+
+// New sets up FCM and APNs from the config, platforms that are not configured
+// fall back to a Fake which only logs the push.
+func New(cfg *domain.Config) (*Gateway, error) {
+	// The default transport negotiates HTTP/2 over TLS, which APNs requires.
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	senders := map[string]Sender{
+		domain.PushPlatformAndroid: NewFake(),
+		domain.PushPlatformIOS:     NewFake(),
+	}
+
+	if cfg.Push.FCM != nil {
+		fcm, err := NewFCM(*cfg.Push.FCM, client)
+		if err != nil {
+			return nil, fmt.Errorf("fcm: %w", err)
+		}
+		senders[domain.PushPlatformAndroid] = fcm
+	}
+
+	if cfg.Push.APNs != nil {
+		apns, err := NewAPNs(*cfg.Push.APNs, client)
+		if err != nil {
+			return nil, fmt.Errorf("apns: %w", err)
+		}
+		senders[domain.PushPlatformIOS] = apns
+	}
+
+	return NewGateway(senders), nil
+}
+
+func NewGateway(senders map[string]Sender) *Gateway {
+	return &Gateway{
+		senders: senders,
+	}
+}
+
+func (g Gateway) Send(ctx context.Context, msg domain.PushMessage) error {
+	ctx, span := tracing.Start(ctx, "repo.push.send")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.push.send"
+		tags["platform"] = msg.Platform
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	sender, ok := g.senders[msg.Platform]
+	if !ok {
+		tags["error"] = "unsupported platform"
+		tags["status"] = "error"
+		return fmt.Errorf("unsupported push platform %q", msg.Platform)
+	}
+
+	if err := sender.Send(ctx, msg); err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
@@ -0,0 +1,221 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func encodePKCS8(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed encoding key: %s", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestFCM_Send(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed generating key: %s", err)
+	}
+
+	var tokenRequests int32
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		_ = r.ParseForm()
+
+		claims := jwt.MapClaims{}
+		if _, err := jwt.ParseWithClaims(r.PostForm.Get("assertion"), claims, func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		}); err != nil || claims["iss"] != "push@test.iam.gserviceaccount.com" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(fcmAccessToken{AccessToken: "access", ExpiresIn: 3600})
+	})
+
+	mux.HandleFunc("/v1/projects/tinder/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body fcmMessage
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch body.Message.Token {
+		case "unregistered":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+		case "unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":503,"status":"UNAVAILABLE"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"name":"projects/tinder/messages/1"}`))
+		}
+	})
+
+	fcm, err := NewFCM(domain.FCM{
+		ProjectID:   "tinder",
+		ClientEmail: "push@test.iam.gserviceaccount.com",
+		PrivateKey:  encodePKCS8(t, key),
+		TokenURL:    server.URL + "/token",
+		Endpoint:    server.URL,
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("NewFCM() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "success", token: "valid"},
+		{name: "failed unregistered token", token: "unregistered", wantErr: domain.ErrInvalidPushToken},
+		{name: "failed gateway unavailable", token: "unavailable", wantErr: errors.New("fcm returned 503: UNAVAILABLE")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fcm.Send(ctx, domain.PushMessage{Platform: domain.PushPlatformAndroid, Token: tt.token, Body: "hi"})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Send() error = %v, want nil", err)
+			}
+
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := atomic.LoadInt32(&tokenRequests); got != 1 {
+		t.Errorf("token requests = %d, want the access token to be cached", got)
+	}
+}
+
+func TestAPNs_Send(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed generating key: %s", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+
+		token, err := jwt.Parse(strings.TrimPrefix(r.Header.Get("Authorization"), "bearer "), func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		})
+		if err != nil || token.Header["kid"] != "KEY123" || r.Header.Get("apns-topic") != "com.tinder.dealls" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"reason":"InvalidProviderToken"}`))
+			return
+		}
+
+		switch strings.TrimPrefix(r.URL.Path, "/3/device/") {
+		case "gone":
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"reason":"Unregistered"}`))
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"reason":"BadDeviceToken"}`))
+		case "busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"reason":"TooManyRequests"}`))
+		}
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	apns, err := NewAPNs(domain.APNs{
+		KeyID:      "KEY123",
+		TeamID:     "TEAM123",
+		BundleID:   "com.tinder.dealls",
+		PrivateKey: encodePKCS8(t, key),
+		Endpoint:   server.URL,
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("NewAPNs() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "success", token: "valid"},
+		{name: "failed unregistered token", token: "gone", wantErr: domain.ErrInvalidPushToken},
+		{name: "failed bad device token", token: "bad", wantErr: domain.ErrInvalidPushToken},
+		{name: "failed throttled", token: "busy", wantErr: errors.New("apns returned 429: TooManyRequests")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := apns.Send(ctx, domain.PushMessage{Platform: domain.PushPlatformIOS, Token: tt.token, Body: "hi"})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Send() error = %v, want nil", err)
+			}
+
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGateway_Send(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	android := NewFake("stale")
+	ios := NewFake()
+	gateway := NewGateway(map[string]Sender{
+		domain.PushPlatformAndroid: android,
+		domain.PushPlatformIOS:     ios,
+	})
+
+	if err := gateway.Send(ctx, domain.PushMessage{Platform: domain.PushPlatformIOS, Token: "a", Body: "hi"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if err := gateway.Send(ctx, domain.PushMessage{Platform: domain.PushPlatformAndroid, Token: "stale"}); !errors.Is(err, domain.ErrInvalidPushToken) {
+		t.Fatalf("Send() error = %v, want %v", err, domain.ErrInvalidPushToken)
+	}
+
+	if err := gateway.Send(ctx, domain.PushMessage{Platform: "web", Token: "a"}); err == nil {
+		t.Fatal("Send() expected error for unsupported platform")
+	}
+
+	if got := len(ios.Sent()); got != 1 {
+		t.Errorf("ios sent = %d, want 1", got)
+	}
+
+	if got := len(android.Sent()); got != 0 {
+		t.Errorf("android sent = %d, want 0", got)
+	}
+}
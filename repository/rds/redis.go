@@ -6,6 +6,8 @@ import (
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
 
 	"time"
 )
@@ -17,11 +19,13 @@ type Module struct {
 
 type RedisInterface interface {
 	Get(ctx context.Context, key string) (string, error)
+	GetDel(ctx context.Context, key string) (string, error)
 	GetValues(ctx context.Context, key string) ([]string, error)
 	Set(ctx context.Context, key string, value interface{}, expiration int) error
 	Expire(ctx context.Context, key string, expiration int) error
-	Incr(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string) (int64, error)
 	Exists(ctx context.Context, keys ...string) (bool, error)
+	Del(ctx context.Context, keys ...string) error
 }
 
 func New(rds *redis.Client, cfg *domain.Config) RedisInterface {
@@ -32,14 +36,16 @@ func New(rds *redis.Client, cfg *domain.Config) RedisInterface {
 }
 
 func (r Module) Get(ctx context.Context, key string) (string, error) {
+	ctx, span := tracing.Start(ctx, "repo.redis.get")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.redis.get"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	result, err := r.rds.Get(ctx, key).Result()
@@ -62,15 +68,47 @@ func (r Module) Get(ctx context.Context, key string) (string, error) {
 	return result, nil
 }
 
+// GetDel gets the value of key and deletes it at once, only one of concurrent callers gets the value.
+func (r Module) GetDel(ctx context.Context, key string) (string, error) {
+	ctx, span := tracing.Start(ctx, "repo.redis.get_del")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.redis.get_del"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result, err := r.rds.GetDel(ctx, key).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		tags["status"] = "success"
+		tags["warning"] = "key not found"
+		return "", nil
+	case err != nil:
+		tags["error"] = err.Error()
+		tags["status"] = "error"
+		return "", err
+	}
+
+	tags["status"] = "success"
+	return result, nil
+}
+
 func (r Module) GetValues(ctx context.Context, key string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "repo.redis.get_values")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.redis.get_values"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	result := r.rds.LRange(ctx, key, 0, -1)
@@ -85,14 +123,16 @@ func (r Module) GetValues(ctx context.Context, key string) ([]string, error) {
 }
 
 func (r Module) Set(ctx context.Context, key string, value interface{}, expiration int) error {
+	ctx, span := tracing.Start(ctx, "repo.redis.set_ex")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.redis.set_ex"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	result := r.rds.Set(ctx, key, value, time.Duration(expiration)*time.Second)
@@ -107,14 +147,16 @@ func (r Module) Set(ctx context.Context, key string, value interface{}, expirati
 }
 
 func (r Module) Expire(ctx context.Context, key string, expiration int) error {
+	ctx, span := tracing.Start(ctx, "repo.redis.expire")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.redis.expire"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	result := r.rds.Expire(ctx, key, time.Duration(expiration)*time.Second)
@@ -128,37 +170,41 @@ func (r Module) Expire(ctx context.Context, key string, expiration int) error {
 	return nil
 }
 
-func (r Module) Incr(ctx context.Context, key string) error {
+func (r Module) Incr(ctx context.Context, key string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.redis.incr")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.redis.incr"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	result := r.rds.Incr(ctx, key)
 	if result.Err() != nil {
 		tags["error"] = result.Err().Error()
 		tags["status"] = "error"
-		return result.Err()
+		return 0, result.Err()
 	}
 
 	tags["status"] = "success"
-	return nil
+	return result.Val(), nil
 }
 
 func (r Module) Exists(ctx context.Context, keys ...string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "repo.redis.exists")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "repo.redis.exists"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	result := r.rds.Exists(ctx, keys...)
@@ -171,3 +217,27 @@ func (r Module) Exists(ctx context.Context, keys ...string) (bool, error) {
 	tags["status"] = "success"
 	return result.Val() == 1, nil
 }
+
+func (r Module) Del(ctx context.Context, keys ...string) error {
+	ctx, span := tracing.Start(ctx, "repo.redis.del")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.redis.del"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	result := r.rds.Del(ctx, keys...)
+	if result.Err() != nil {
+		tags["error"] = result.Err().Error()
+		tags["status"] = "error"
+		return result.Err()
+	}
+
+	tags["status"] = "success"
+	return nil
+}
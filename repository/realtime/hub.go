@@ -0,0 +1,91 @@
+package realtime
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"sync"
+	"time"
+)
+
+const subscriberBuffer = 32
+
+// Hub fans realtime events out to the WebSocket connections of a user on this instance. A user
+// can hold several connections at once, one per device.
+type Hub struct {
+	cfg         *domain.Config
+	mu          sync.RWMutex
+	subscribers map[int64]map[chan domain.RealtimeEvent]struct{}
+}
+
+func NewHub(cfg *domain.Config) *Hub {
+	return &Hub{
+		cfg:         cfg,
+		subscribers: make(map[int64]map[chan domain.RealtimeEvent]struct{}),
+	}
+}
+
+// Subscribe registers a new connection for userID. The returned func must be called once the
+// connection is closed.
+func (h *Hub) Subscribe(userID int64) (<-chan domain.RealtimeEvent, func()) {
+	events := make(chan domain.RealtimeEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan domain.RealtimeEvent]struct{})
+	}
+	h.subscribers[userID][events] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[userID], events)
+			if len(h.subscribers[userID]) == 0 {
+				delete(h.subscribers, userID)
+			}
+			h.mu.Unlock()
+			close(events)
+		})
+	}
+}
+
+// Publish never blocks, events for a connection that is not keeping up are dropped. Clients
+// recover by reloading history.
+func (h *Hub) Publish(ctx context.Context, userID int64, event domain.RealtimeEvent) error {
+	ctx, span := tracing.Start(ctx, "repo.realtime.publish")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.realtime.publish"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	tags["user_id"] = userID
+	tags["type"] = event.Type
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	delivered, dropped := 0, 0
+	for events := range h.subscribers[userID] {
+		select {
+		case events <- event:
+			delivered++
+		default:
+			dropped++
+		}
+	}
+
+	tags["delivered"] = delivered
+	tags["dropped"] = dropped
+	tags["status"] = "success"
+	return nil
+}
@@ -0,0 +1,68 @@
+package realtime
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func TestHub_Publish(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	hub := NewHub(&domain.Config{})
+
+	phone, closePhone := hub.Subscribe(1)
+	laptop, closeLaptop := hub.Subscribe(1)
+	other, closeOther := hub.Subscribe(2)
+	defer closeLaptop()
+	defer closeOther()
+
+	event := domain.RealtimeEvent{Type: domain.RealtimeEventMessage, Data: "hi"}
+	if err := hub.Publish(ctx, 1, event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	for name, events := range map[string]<-chan domain.RealtimeEvent{"phone": phone, "laptop": laptop} {
+		select {
+		case got := <-events:
+			if got != event {
+				t.Errorf("%s got = %v, want %v", name, got, event)
+			}
+		default:
+			t.Errorf("%s did not receive the event", name)
+		}
+	}
+
+	select {
+	case got := <-other:
+		t.Errorf("other user got = %v, want nothing", got)
+	default:
+	}
+
+	closePhone()
+	closePhone()
+	if _, ok := <-phone; ok {
+		t.Errorf("phone channel still open after unsubscribe")
+	}
+
+	if err := hub.Publish(ctx, 1, event); err != nil {
+		t.Fatalf("Publish() after unsubscribe error = %v", err)
+	}
+}
+
+func TestHub_PublishDropsWhenFull(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	hub := NewHub(&domain.Config{})
+
+	events, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		if err := hub.Publish(ctx, 1, domain.RealtimeEvent{Type: domain.RealtimeEventMessage, Data: i}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	if len(events) != subscriberBuffer {
+		t.Errorf("buffered events = %d, want %d", len(events), subscriberBuffer)
+	}
+}
@@ -0,0 +1,232 @@
+package report
+
+import (
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/dberr"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"time"
+)
+
+const defaultLimit = 50
+
+type dbModule struct {
+	db  *gorm.DB
+	cfg *domain.Config
+}
+
+type dbInterface interface {
+	create(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error)
+	getByID(ctx context.Context, reportID int64) (*domain.Report, error)
+	getAll(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error)
+	getByReporterID(ctx context.Context, reporterID int64) ([]domain.Report, error)
+	updateStatus(ctx context.Context, reportID int64, reviewerID int64, req domain.ReviewReportRequest) error
+	countPendingReporters(ctx context.Context, reportedUserID int64) (int64, error)
+}
+
+func newDatabase(db *gorm.DB, cfg *domain.Config) dbInterface {
+	return &dbModule{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// conn joins the unit of work running on ctx, if any.
+func (d dbModule) conn(ctx context.Context) *gorm.DB {
+	return uow.Conn(ctx, d.db)
+}
+
+func (d dbModule) create(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.report.create")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.report.create"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	report := domain.Report{
+		ReporterID:     reporterID,
+		ReportedUserID: req.ReportedUserID,
+		Reason:         req.Reason,
+		Description:    req.Description,
+		PhotoRef:       req.PhotoRef,
+		Status:         domain.ReportStatusOpen,
+	}
+
+	result := d.conn(ctx).Table("report").Create(&report)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, dberr.Translate(result.Error)
+	}
+
+	tags["status"] = "success"
+	return &report, nil
+}
+
+func (d dbModule) getByID(ctx context.Context, reportID int64) (*domain.Report, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.report.get_by_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.report.get_by_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var report domain.Report
+	result := d.conn(ctx).Table("report").Where("id = ?", reportID).First(&report)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			tags["status"] = "not_found"
+			return nil, nil
+		}
+
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return &report, nil
+}
+
+func (d dbModule) getAll(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.report.get_all")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.report.get_all"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	query := d.conn(ctx).Table("report")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.ReportedUserID != 0 {
+		query = query.Where("reported_user_id = ?", filter.ReportedUserID)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	// Oldest first so the queue is worked in order.
+	var reports []domain.Report
+	result := query.Order("created_at, id").Limit(limit).Offset(filter.Offset).Find(&reports)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return reports, nil
+}
+
+func (d dbModule) getByReporterID(ctx context.Context, reporterID int64) ([]domain.Report, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.report.get_by_reporter_id")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.report.get_by_reporter_id"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var reports []domain.Report
+	result := d.conn(ctx).Table("report").Where("reporter_id = ?", reporterID).Order("id").Find(&reports)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return nil, result.Error
+	}
+
+	tags["status"] = "success"
+	return reports, nil
+}
+
+func (d dbModule) updateStatus(ctx context.Context, reportID int64, reviewerID int64, req domain.ReviewReportRequest) error {
+	ctx, span := tracing.Start(ctx, "repo.database.report.update_status")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.report.update_status"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	now := time.Now()
+	result := d.conn(ctx).Table("report").Where("id = ?", reportID).
+		Updates(map[string]interface{}{
+			"status":      req.Status,
+			"reviewed_by": reviewerID,
+			"reviewed_at": now,
+			"review_note": req.Note,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return result.Error
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (d dbModule) countPendingReporters(ctx context.Context, reportedUserID int64) (int64, error) {
+	ctx, span := tracing.Start(ctx, "repo.database.report.count_pending_reporters")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.database.report.count_pending_reporters"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	var count int64
+	result := d.conn(ctx).Table("report").
+		Where("reported_user_id = ? AND status IN (?)", reportedUserID,
+			[]string{domain.ReportStatusOpen, domain.ReportStatusTriaged}).
+		Distinct("reporter_id").
+		Count(&count)
+	if result.Error != nil {
+		tags["error"] = result.Error.Error()
+		tags["status"] = "error"
+		return 0, result.Error
+	}
+
+	tags["count"] = count
+	tags["status"] = "success"
+	return count, nil
+}
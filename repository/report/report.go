@@ -0,0 +1,43 @@
+package report
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"gorm.io/gorm"
+)
+
+type Module struct {
+	cfg *domain.Config
+	dbs dbInterface
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+	}
+}
+
+func (m Module) Create(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error) {
+	return m.dbs.create(ctx, reporterID, req)
+}
+
+func (m Module) GetByID(ctx context.Context, reportID int64) (*domain.Report, error) {
+	return m.dbs.getByID(ctx, reportID)
+}
+
+func (m Module) GetAll(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error) {
+	return m.dbs.getAll(ctx, filter)
+}
+
+func (m Module) GetByReporterID(ctx context.Context, reporterID int64) ([]domain.Report, error) {
+	return m.dbs.getByReporterID(ctx, reporterID)
+}
+
+func (m Module) UpdateStatus(ctx context.Context, reportID int64, reviewerID int64, req domain.ReviewReportRequest) error {
+	return m.dbs.updateStatus(ctx, reportID, reviewerID, req)
+}
+
+func (m Module) CountPendingReporters(ctx context.Context, reportedUserID int64) (int64, error) {
+	return m.dbs.countPendingReporters(ctx, reportedUserID)
+}
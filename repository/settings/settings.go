@@ -0,0 +1,227 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/repository/uow"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// cacheKey holds every stored value as one JSON object, so a refresh costs a single Redis read.
+const cacheKey = "runtime_settings"
+
+const cacheTTL = time.Hour
+
+// Module serves runtime settings from memory. Refresh reloads them from the Redis cache, or from the
+// database when the cache is empty, so changes made on any instance are picked up on the next refresh.
+type Module struct {
+	cfg    *domain.Config
+	dbs    dbInterface
+	rds    *redis.Client
+	values atomic.Pointer[map[string]string]
+}
+
+func New(db *gorm.DB, rds *redis.Client, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		dbs: newDatabase(db, cfg),
+		rds: rds,
+	}
+}
+
+// Int, Bool and String return the current value of a setting, or its default when it is not set, was
+// not loaded yet or is no longer valid. Unknown keys return the zero value.
+func (m *Module) Int(ctx context.Context, key string) int {
+	n, _ := strconv.Atoi(m.value(key))
+	return n
+}
+
+func (m *Module) Bool(ctx context.Context, key string) bool {
+	b, _ := strconv.ParseBool(m.value(key))
+	return b
+}
+
+func (m *Module) String(ctx context.Context, key string) string {
+	return m.value(key)
+}
+
+func (m *Module) value(key string) string {
+	definition, ok := domain.GetSettingDefinition(key)
+	if !ok {
+		return ""
+	}
+
+	if values := m.values.Load(); values != nil {
+		if value, ok := (*values)[key]; ok && definition.Validate(value) == nil {
+			return value
+		}
+	}
+
+	return definition.Default
+}
+
+// GetAll returns every known setting with its stored value, read from the database.
+func (m *Module) GetAll(ctx context.Context) ([]domain.Setting, error) {
+	rows, err := m.dbs.getAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]domain.Setting, len(rows))
+	for _, row := range rows {
+		stored[row.Key] = row
+	}
+
+	definitions := domain.SettingDefinitions()
+	settings := make([]domain.Setting, len(definitions))
+	for i, definition := range definitions {
+		setting, ok := stored[definition.Key]
+		if !ok {
+			setting = domain.Setting{Key: definition.Key, Value: definition.Default}
+		}
+
+		setting.Type = definition.Type
+		setting.Default = definition.Default
+		setting.Description = definition.Description
+		settings[i] = setting
+	}
+
+	return settings, nil
+}
+
+// Set stores value, an empty value deletes the row so the setting falls back to its default. The cache
+// is rewritten and this instance uses the value right away, other instances pick it up on their next refresh.
+func (m *Module) Set(ctx context.Context, key string, value string, updatedBy int64) error {
+	var err error
+	if value == "" {
+		err = m.dbs.delete(ctx, key)
+	} else {
+		err = m.dbs.upsert(ctx, domain.Setting{Key: key, Value: value, UpdatedBy: &updatedBy})
+	}
+	if err != nil {
+		return err
+	}
+
+	values, err := m.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Overwrite, a refresh filling the cache with the values from before this write must not win.
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	// Inside a unit of work a rolled back write must not reach the cache.
+	var cacheErr error
+	uow.AfterCommit(ctx, func() {
+		if cacheErr = m.rds.Set(ctx, cacheKey, raw, cacheTTL).Err(); cacheErr != nil {
+			log.WithFields(log.Fields{
+				"name":       "repo.settings.set",
+				"key":        key,
+				"error":      cacheErr.Error(),
+				"request_id": logging.RequestID(ctx),
+			}).Warn()
+			return
+		}
+
+		m.values.Store(&values)
+	})
+
+	return cacheErr
+}
+
+func (m *Module) Refresh(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "repo.settings.refresh")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.settings.refresh"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	values, err := m.cached(ctx)
+	if err != nil {
+		// The database still has the values, Redis being down must not freeze the settings.
+		tags["warning"] = "failed reading cache"
+		tags["actual_error"] = err.Error()
+	}
+
+	if values == nil {
+		tags["source"] = "database"
+
+		values, err = m.load(ctx)
+		if err != nil {
+			tags["error"] = err.Error()
+			tags["status"] = "error"
+			return err
+		}
+
+		if err := m.fill(ctx, values); err != nil {
+			tags["warning"] = "failed writing cache"
+			tags["actual_error"] = err.Error()
+		}
+	}
+
+	m.values.Store(&values)
+
+	tags["settings"] = len(values)
+	tags["status"] = "success"
+	return nil
+}
+
+// cached returns nil without an error when the cache is empty.
+func (m *Module) cached(ctx context.Context) (map[string]string, error) {
+	raw, err := m.rds.Get(ctx, cacheKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// fill only writes an empty cache, a concurrent Set has newer values than the ones loaded here.
+func (m *Module) fill(ctx context.Context, values map[string]string) error {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return m.rds.SetNX(ctx, cacheKey, raw, cacheTTL).Err()
+}
+
+func (m *Module) load(ctx context.Context) (map[string]string, error) {
+	rows, err := m.dbs.getAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		values[row.Key] = row.Value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,41 @@
+package settings
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func TestModule_accessors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	m := &Module{}
+	if got := m.Int(ctx, domain.SettingRecommendationPageSize); got != 10 {
+		t.Errorf("Int() before refresh = %d, want default 10", got)
+	}
+
+	values := map[string]string{
+		domain.SettingRecommendationPageSize:                                          "25",
+		domain.SettingRecommendationFrozenTTL:                                         "5",
+		domain.SettingRecommendationRankByActivity:                                    "false",
+		domain.NotificationTemplateSetting("en", domain.NotificationTemplateMatchNew): "Say hi to {actor_name}",
+	}
+	m.values.Store(&values)
+
+	if got := m.Int(ctx, domain.SettingRecommendationPageSize); got != 25 {
+		t.Errorf("Int() = %d, want 25", got)
+	}
+	// A value that became invalid, for example after a tighter bound was released, falls back to the default.
+	if got := m.Int(ctx, domain.SettingRecommendationFrozenTTL); got != 86400 {
+		t.Errorf("Int() out of range = %d, want default 86400", got)
+	}
+	if got := m.Bool(ctx, domain.SettingRecommendationRankByActivity); got {
+		t.Errorf("Bool() = %v, want false", got)
+	}
+	if got := m.String(ctx, domain.NotificationTemplateSetting("en", domain.NotificationTemplateMatchNew)); got != "Say hi to {actor_name}" {
+		t.Errorf("String() = %q, want stored template", got)
+	}
+	if got := m.Int(ctx, "unknown"); got != 0 {
+		t.Errorf("Int() unknown = %d, want 0", got)
+	}
+}
@@ -0,0 +1,44 @@
+package sms
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+// LogSender writes outgoing text messages to the log instead of handing them to an SMS gateway.
+// It is meant for local development and tests.
+type LogSender struct {
+	cfg *domain.Config
+}
+
+func NewLogSender(cfg *domain.Config) *LogSender {
+	return &LogSender{
+		cfg: cfg,
+	}
+}
+
+func (s LogSender) Send(ctx context.Context, phone, message string) error {
+	ctx, span := tracing.Start(ctx, "repo.sms.log.send")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.sms.log.send"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	log.WithFields(log.Fields{
+		"phone":   phone,
+		"message": message,
+	}).Info("sms sent")
+
+	tags["status"] = "success"
+	return nil
+}
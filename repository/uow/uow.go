@@ -0,0 +1,98 @@
+package uow
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"gorm.io/gorm"
+	"time"
+)
+
+type txKey struct{}
+
+type txState struct {
+	tx          *gorm.DB
+	afterCommit []func()
+}
+
+// Module runs a function inside one database transaction. Repositories take
+// part by resolving their connection through Conn, so every call made with
+// the context handed to the function commits or rolls back together.
+type Module struct {
+	cfg *domain.Config
+	db  *gorm.DB
+}
+
+func New(db *gorm.DB, cfg *domain.Config) *Module {
+	return &Module{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// Do commits when fn returns nil and rolls back otherwise. Nested calls join
+// the transaction that is already running.
+func (m Module) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	ctx, span := tracing.Start(ctx, "repo.uow.do")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "repo.uow.do"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	state := &txState{}
+	err := Bind(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		tags["error"] = err.Error()
+		tags["status"] = "rolled_back"
+		return err
+	}
+
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+
+	tags["after_commit"] = len(state.afterCommit)
+	tags["status"] = "committed"
+	return nil
+}
+
+// Conn returns the transaction bound to ctx by Do, or db outside of one.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return Bind(ctx, state.tx)
+	}
+
+	return Bind(ctx, db)
+}
+
+// Bind runs the queries of db with ctx so they are traced as part of the call. Cancellation is dropped,
+// a request ending while a query runs must not abort it half way.
+func Bind(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(context.WithoutCancel(ctx))
+}
+
+// AfterCommit defers fn until the transaction bound to ctx committed and
+// drops it on rollback. Outside of a transaction fn runs straight away.
+func AfterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+
+	fn()
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+const (
+	accountDeletionGracePeriod = 30 * 24 * time.Hour
+	accountPurgeBatchSize      = 100
+)
+
+type accountServiceModule struct {
+	cfg              *domain.Config
+	authRepo         AuthRepoInterface
+	profileRepo      ProfileRepoInterface
+	inventoryRepo    InventoryRepoInterface
+	matchedRepo      MatchedRepoInterface
+	notificationRepo NotificationRepoInterface
+	redisRepo        RedisRepoInterface
+	blockRepo        BlockRepoInterface
+	chatRepo         ChatRepoInterface
+	contentFlagRepo  ContentFlagRepoInterface
+	deviceRepo       DeviceRepoInterface
+	reportRepo       ReportRepoInterface
+	unitOfWork       UnitOfWorkInterface
+}
+
+type AccountServiceInterface interface {
+	Delete(ctx context.Context, userID int64) (*domain.AccountDeletionResponse, error)
+	Export(ctx context.Context, userID int64) ([]byte, error)
+	PurgeDeleted(ctx context.Context) (int, error)
+}
+
+func NewAccountService(cfg *domain.Config, authRepo AuthRepoInterface, profileRepo ProfileRepoInterface,
+	inventoryRepo InventoryRepoInterface, matchedRepo MatchedRepoInterface, notificationRepo NotificationRepoInterface,
+	redisRepo RedisRepoInterface, blockRepo BlockRepoInterface, chatRepo ChatRepoInterface,
+	contentFlagRepo ContentFlagRepoInterface, deviceRepo DeviceRepoInterface, reportRepo ReportRepoInterface,
+	unitOfWork UnitOfWorkInterface) (AccountServiceInterface, error) {
+	return &accountServiceModule{
+		cfg:              cfg,
+		authRepo:         authRepo,
+		profileRepo:      profileRepo,
+		inventoryRepo:    inventoryRepo,
+		matchedRepo:      matchedRepo,
+		notificationRepo: notificationRepo,
+		redisRepo:        redisRepo,
+		blockRepo:        blockRepo,
+		chatRepo:         chatRepo,
+		contentFlagRepo:  contentFlagRepo,
+		deviceRepo:       deviceRepo,
+		reportRepo:       reportRepo,
+		unitOfWork:       unitOfWork,
+	}, nil
+}
+
+// Delete deactivates the account right away and leaves the data in place
+// until the grace period is over, after which PurgeDeleted removes it.
+func (a accountServiceModule) Delete(ctx context.Context, userID int64) (*domain.AccountDeletionResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.account.delete")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.account.delete"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	tags["user_id"] = userID
+
+	// A failure must not leave a deactivated user with a visible profile or registered devices.
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := a.authRepo.SoftDelete(ctx, userID); err != nil {
+			tags["error"] = "failed to delete user"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		if err := a.profileRepo.SoftDeleteByUserID(ctx, userID); err != nil {
+			tags["error"] = "failed to delete profile"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		// Devices are dropped right away so a deactivated account stops receiving pushes.
+		if err := a.deviceRepo.PurgeByUserID(ctx, userID); err != nil {
+			tags["error"] = "failed to delete devices"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	deletedAt := time.Now()
+
+	tags["status"] = "success"
+	return &domain.AccountDeletionResponse{
+		DeletedAt: deletedAt,
+		PurgeAt:   deletedAt.Add(accountDeletionGracePeriod),
+	}, nil
+}
+
+func (a accountServiceModule) Export(ctx context.Context, userID int64) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "service.account.export")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.account.export"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	tags["user_id"] = userID
+
+	record, err := loadUserRecord(ctx, tags, userID, a.authRepo, a.profileRepo, a.inventoryRepo,
+		a.matchedRepo, a.notificationRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := a.chatRepo.GetMessagesBySenderID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get messages"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	reports, err := a.reportRepo.GetByReporterID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get reports"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	blocks, err := a.blockRepo.GetByBlockerID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get blocks"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	devices, err := a.deviceRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get devices"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	preferences, err := a.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get notification preferences"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	// Users who never changed their preferences get the defaults.
+	if preferences == nil {
+		defaults := domain.DefaultNotificationPreferences(userID)
+		preferences = &defaults
+	}
+
+	flags, err := a.contentFlagRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get content flags"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	export := domain.AccountExport{
+		ExportedAt:              time.Now(),
+		UserRecord:              *record,
+		Messages:                messages,
+		Reports:                 reports,
+		Blocks:                  blocks,
+		Devices:                 devices,
+		NotificationPreferences: *preferences,
+		ContentFlags:            flags,
+	}
+
+	archive, err := buildExportArchive(export)
+	if err != nil {
+		tags["error"] = "failed to build export archive"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return archive, nil
+}
+
+// PurgeDeleted hard deletes every account whose grace period has expired.
+// Rows are removed child tables first and the users row last, so an account
+// that fails half way is picked up again on the next run.
+func (a accountServiceModule) PurgeDeleted(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "service.account.purge_deleted")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.account.purge_deleted"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	users, err := a.authRepo.GetDeletedBefore(ctx, time.Now().Add(-accountDeletionGracePeriod), accountPurgeBatchSize)
+	if err != nil {
+		tags["error"] = "failed to get deleted users"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return 0, err
+	}
+
+	purged := 0
+	for _, user := range users {
+		if err := a.purge(ctx, user); err != nil {
+			tags["error"] = "failed to purge user"
+			tags["actual_error"] = err.Error()
+			tags["user_id"] = user.ID
+			tags["status"] = "error"
+			return purged, err
+		}
+		purged++
+	}
+
+	tags["purged"] = purged
+	tags["status"] = "success"
+	return purged, nil
+}
+
+func (a accountServiceModule) purge(ctx context.Context, user domain.User) error {
+	keys := []string{
+		fmt.Sprintf("frozen:%d", user.ID),
+		fmt.Sprintf("mfa_attempts:%d", user.ID),
+		fmt.Sprintf("last_active:%d", user.ID),
+	}
+	if user.Phone != "" {
+		keys = append(keys,
+			fmt.Sprintf("otp:%s", user.Phone),
+			fmt.Sprintf("otp_attempts:%s", user.Phone),
+			fmt.Sprintf("otp_resend:%s", user.Phone),
+			fmt.Sprintf("otp_sends:%s", user.Phone),
+		)
+	}
+
+	if err := a.redisRepo.Del(ctx, keys...); err != nil {
+		return err
+	}
+
+	if err := a.chatRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := a.contentFlagRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := a.deviceRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := a.notificationRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := a.matchedRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := a.blockRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := a.inventoryRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := a.profileRepo.PurgeByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return a.authRepo.Purge(ctx, user.ID)
+}
+
+func loadUserRecord(ctx context.Context, tags log.Fields, userID int64, authRepo AuthRepoInterface,
+	profileRepo ProfileRepoInterface, inventoryRepo InventoryRepoInterface, matchedRepo MatchedRepoInterface,
+	notificationRepo NotificationRepoInterface) (*domain.UserRecord, error) {
+	user, err := authRepo.GetByID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get user"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if user == nil {
+		tags["error"] = "user not found"
+		tags["status"] = "error"
+		return nil, domain.ErrUserNotFound
+	}
+
+	record := domain.UserRecord{User: user}
+
+	if record.Profile, err = profileRepo.GetProfile(ctx, userID); err != nil {
+		tags["error"] = "failed to get profile"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if record.Inventory, err = inventoryRepo.GetByUserId(ctx, userID); err != nil {
+		tags["error"] = "failed to get inventory"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if record.Identities, err = authRepo.GetIdentitiesByUserID(ctx, userID); err != nil {
+		tags["error"] = "failed to get identities"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if record.TwoFactor, err = authRepo.GetTwoFactorStatus(ctx, userID); err != nil {
+		tags["error"] = "failed to get two factor status"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if record.Matches, err = matchedRepo.GetAllByUserID(ctx, userID); err != nil {
+		tags["error"] = "failed to get matches"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if record.Notifications, err = notificationRepo.GetAllByUserId(ctx, userID); err != nil {
+		tags["error"] = "failed to get notifications"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	for i := range record.Notifications {
+		record.Notifications[i].Message = domain.RenderNotification(record.Notifications[i], domain.DefaultLocale)
+	}
+
+	return &record, nil
+}
+
+func buildExportArchive(export domain.AccountExport) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"account.json", export},
+		{"user.json", export.User},
+		{"profile.json", export.Profile},
+		{"inventory.json", export.Inventory},
+		{"identities.json", export.Identities},
+		{"two_factor.json", export.TwoFactor},
+		{"matches.json", export.Matches},
+		{"notifications.json", export.Notifications},
+		{"messages.json", export.Messages},
+		{"reports.json", export.Reports},
+		{"blocks.json", export.Blocks},
+		{"devices.json", export.Devices},
+		{"notification_preferences.json", export.NotificationPreferences},
+		{"content_flags.json", export.ContentFlags},
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		entry, err := writer.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := entry.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
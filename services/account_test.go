@@ -0,0 +1,388 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func Test_accountServiceModule_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() *accountServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed deleting user",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().SoftDelete(ctx, int64(1)).Return(errors.New("user not found"))
+
+				return &accountServiceModule{authRepo: authMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed deleting profile",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().SoftDelete(ctx, int64(1)).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().SoftDeleteByUserID(ctx, int64(1)).Return(errors.New("error"))
+
+				return &accountServiceModule{authRepo: authMock, profileRepo: profileMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed deleting devices",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().SoftDelete(ctx, int64(1)).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().SoftDeleteByUserID(ctx, int64(1)).Return(nil)
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(errors.New("error"))
+
+				unitOfWorkMock := NewMockUnitOfWorkInterface(ctrl)
+				unitOfWorkMock.EXPECT().Do(ctx, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						if err := fn(ctx); err == nil {
+							t.Errorf("expected the unit of work to roll back")
+						}
+						return errors.New("rolled back")
+					})
+
+				return &accountServiceModule{authRepo: authMock, profileRepo: profileMock, deviceRepo: deviceMock,
+					unitOfWork: unitOfWorkMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().SoftDelete(ctx, int64(1)).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().SoftDeleteByUserID(ctx, int64(1)).Return(nil)
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				return &accountServiceModule{authRepo: authMock, profileRepo: profileMock, deviceRepo: deviceMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			got, err := a.Delete(ctx, 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Delete() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got.PurgeAt.Sub(got.DeletedAt) != accountDeletionGracePeriod {
+				t.Errorf("Delete() purge at = %v, want %v after %v", got.PurgeAt, accountDeletionGracePeriod, got.DeletedAt)
+			}
+		})
+	}
+}
+
+func Test_accountServiceModule_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() *accountServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed user not found",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(1)).Return(nil, nil)
+
+				return &accountServiceModule{authRepo: authMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed getting matches",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(1)).Return(&domain.User{ID: 1}, nil)
+				authMock.EXPECT().GetIdentitiesByUserID(ctx, int64(1)).Return(nil, nil)
+				authMock.EXPECT().GetTwoFactorStatus(ctx, int64(1)).Return(&domain.TwoFactorStatus{UserID: 1}, nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(&domain.Profile{UserID: 1}, nil)
+
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).Return(&domain.Inventory{UserID: 1}, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().GetAllByUserID(ctx, int64(1)).Return(nil, errors.New("error"))
+
+				return &accountServiceModule{
+					authRepo:      authMock,
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(1)).Return(&domain.User{ID: 1}, nil)
+				authMock.EXPECT().GetIdentitiesByUserID(ctx, int64(1)).Return(nil, nil)
+				authMock.EXPECT().GetTwoFactorStatus(ctx, int64(1)).Return(&domain.TwoFactorStatus{UserID: 1}, nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(&domain.Profile{UserID: 1}, nil)
+
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).Return(&domain.Inventory{UserID: 1}, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().GetAllByUserID(ctx, int64(1)).Return([]domain.Matched{{UserAID: 1, UserBID: 2}}, nil)
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetAllByUserId(ctx, int64(1)).Return(nil, nil)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(1)).Return(nil, nil)
+
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetMessagesBySenderID(ctx, int64(1)).
+					Return([]domain.Message{{ID: 1, ConversationID: 1, SenderID: 1, Body: "hi"}}, nil)
+
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().GetByReporterID(ctx, int64(1)).
+					Return([]domain.Report{{ID: 1, ReporterID: 1, ReportedUserID: 2, Reason: domain.ReportReasonSpam}}, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetByBlockerID(ctx, int64(1)).Return([]domain.UserBlock{{ID: 1, BlockerID: 1, BlockedID: 2}}, nil)
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().GetByUserID(ctx, int64(1)).Return(nil, nil)
+
+				contentFlagMock := NewMockContentFlagRepoInterface(ctrl)
+				contentFlagMock.EXPECT().GetAllByUserID(ctx, int64(1)).Return(nil, nil)
+
+				return &accountServiceModule{
+					authRepo:         authMock,
+					profileRepo:      profileMock,
+					inventoryRepo:    inventoryMock,
+					matchedRepo:      matchedMock,
+					notificationRepo: notificationMock,
+					chatRepo:         chatMock,
+					reportRepo:       reportMock,
+					blockRepo:        blockMock,
+					deviceRepo:       deviceMock,
+					contentFlagRepo:  contentFlagMock,
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			got, err := a.Export(ctx, 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Export() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			reader, err := zip.NewReader(bytes.NewReader(got), int64(len(got)))
+			if err != nil {
+				t.Errorf("Export() returned invalid zip: %v", err)
+				return
+			}
+
+			files := make(map[string]bool)
+			for _, file := range reader.File {
+				files[file.Name] = true
+			}
+
+			for _, name := range []string{"account.json", "user.json", "profile.json", "matches.json", "messages.json",
+				"reports.json", "blocks.json", "devices.json", "notification_preferences.json", "content_flags.json"} {
+				if !files[name] {
+					t.Errorf("Export() archive missing %s", name)
+				}
+			}
+		})
+	}
+}
+
+func Test_accountServiceModule_PurgeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() *accountServiceModule
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "failed getting deleted users",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetDeletedBefore(ctx, gomock.Any(), accountPurgeBatchSize).
+					Return(nil, errors.New("error"))
+
+				return &accountServiceModule{authRepo: authMock}
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "failed purging profile",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetDeletedBefore(ctx, gomock.Any(), accountPurgeBatchSize).
+					Return([]domain.User{{ID: 1}}, nil)
+
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Del(ctx, "frozen:1", "mfa_attempts:1", "last_active:1").Return(nil)
+
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				contentFlagMock := NewMockContentFlagRepoInterface(ctrl)
+				contentFlagMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().PurgeByUserID(ctx, int64(1)).Return(errors.New("error"))
+
+				return &accountServiceModule{
+					authRepo:         authMock,
+					profileRepo:      profileMock,
+					inventoryRepo:    inventoryMock,
+					matchedRepo:      matchedMock,
+					notificationRepo: notificationMock,
+					redisRepo:        redisMock,
+					blockRepo:        blockMock,
+					chatRepo:         chatMock,
+					contentFlagRepo:  contentFlagMock,
+					deviceRepo:       deviceMock,
+				}
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mock: func() *accountServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetDeletedBefore(ctx, gomock.Any(), accountPurgeBatchSize).
+					Return([]domain.User{{ID: 1}, {ID: 2, Phone: "+6281234567890"}}, nil)
+				authMock.EXPECT().Purge(ctx, int64(1)).Return(nil)
+				authMock.EXPECT().Purge(ctx, int64(2)).Return(nil)
+
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Del(ctx, "frozen:1", "mfa_attempts:1", "last_active:1").Return(nil)
+				redisMock.EXPECT().Del(ctx, "frozen:2", "mfa_attempts:2", "last_active:2",
+					"otp:+6281234567890", "otp_attempts:+6281234567890",
+					"otp_resend:+6281234567890", "otp_sends:+6281234567890").Return(nil)
+
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				contentFlagMock := NewMockContentFlagRepoInterface(ctrl)
+				contentFlagMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().PurgeByUserID(ctx, gomock.Any()).Return(nil).Times(2)
+
+				return &accountServiceModule{
+					authRepo:         authMock,
+					profileRepo:      profileMock,
+					inventoryRepo:    inventoryMock,
+					matchedRepo:      matchedMock,
+					notificationRepo: notificationMock,
+					redisRepo:        redisMock,
+					blockRepo:        blockMock,
+					chatRepo:         chatMock,
+					contentFlagRepo:  contentFlagMock,
+					deviceRepo:       deviceMock,
+				}
+			},
+			want:    2,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			got, err := a.PurgeDeleted(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PurgeDeleted() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("PurgeDeleted() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,675 @@
+package services
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+type adminServiceModule struct {
+	cfg              *domain.Config
+	authRepo         AuthRepoInterface
+	profileRepo      ProfileRepoInterface
+	inventoryRepo    InventoryRepoInterface
+	matchedRepo      MatchedRepoInterface
+	notificationRepo NotificationRepoInterface
+	auditRepo        AuditRepoInterface
+	reportRepo       ReportRepoInterface
+	contentFlagRepo  ContentFlagRepoInterface
+	settingsRepo     SettingsRepoInterface
+	unitOfWork       UnitOfWorkInterface
+}
+
+type AdminServiceInterface interface {
+	SearchUsers(ctx context.Context, adminID int64, req domain.UserSearchRequest) ([]domain.User, error)
+	GetUser(ctx context.Context, adminID int64, userID int64) (*domain.UserRecord, error)
+	AdjustInventory(ctx context.Context, adminID int64, userID int64, req domain.AdjustInventoryRequest) error
+	BanUser(ctx context.Context, adminID int64, userID int64, req domain.BanRequest) error
+	UnbanUser(ctx context.Context, adminID int64, userID int64) error
+	DeleteProfile(ctx context.Context, adminID int64, userID int64) error
+	ResendNotification(ctx context.Context, adminID int64, notificationID int64) error
+	GetAuditLogs(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error)
+	GetReports(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error)
+	ReviewReport(ctx context.Context, adminID int64, reportID int64, req domain.ReviewReportRequest) error
+	GetContentFlags(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error)
+	GetSettings(ctx context.Context) ([]domain.Setting, error)
+	UpdateSetting(ctx context.Context, adminID int64, key string, req domain.UpdateSettingRequest) error
+}
+
+func NewAdminService(cfg *domain.Config, authRepo AuthRepoInterface, profileRepo ProfileRepoInterface,
+	inventoryRepo InventoryRepoInterface, matchedRepo MatchedRepoInterface, notificationRepo NotificationRepoInterface,
+	auditRepo AuditRepoInterface, reportRepo ReportRepoInterface,
+	contentFlagRepo ContentFlagRepoInterface, settingsRepo SettingsRepoInterface,
+	unitOfWork UnitOfWorkInterface) (AdminServiceInterface, error) {
+	return &adminServiceModule{
+		cfg:              cfg,
+		authRepo:         authRepo,
+		profileRepo:      profileRepo,
+		inventoryRepo:    inventoryRepo,
+		matchedRepo:      matchedRepo,
+		notificationRepo: notificationRepo,
+		auditRepo:        auditRepo,
+		reportRepo:       reportRepo,
+		contentFlagRepo:  contentFlagRepo,
+		settingsRepo:     settingsRepo,
+		unitOfWork:       unitOfWork,
+	}, nil
+}
+
+func (a adminServiceModule) SearchUsers(ctx context.Context, adminID int64, req domain.UserSearchRequest) ([]domain.User, error) {
+	ctx, span := tracing.Start(ctx, "service.admin.search_users")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.search_users"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	users, err := a.authRepo.Search(ctx, req)
+	if err != nil {
+		tags["error"] = "failed to search users"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := a.audit(ctx, tags, adminID, domain.AuditActionSearchUsers, 0, req); err != nil {
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return users, nil
+}
+
+func (a adminServiceModule) GetUser(ctx context.Context, adminID int64, userID int64) (*domain.UserRecord, error) {
+	ctx, span := tracing.Start(ctx, "service.admin.get_user")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.get_user"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	record, err := loadUserRecord(ctx, tags, userID, a.authRepo, a.profileRepo, a.inventoryRepo,
+		a.matchedRepo, a.notificationRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.audit(ctx, tags, adminID, domain.AuditActionViewUser, userID, nil); err != nil {
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return record, nil
+}
+
+func (a adminServiceModule) AdjustInventory(ctx context.Context, adminID int64, userID int64, req domain.AdjustInventoryRequest) error {
+	ctx, span := tracing.Start(ctx, "service.admin.adjust_inventory")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.adjust_inventory"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	if req.Likes == nil && req.SuperLikes == nil && req.Swipes == nil {
+		tags["error"] = "nothing to adjust"
+		tags["status"] = "error"
+		return domain.ErrNothingToAdjust
+	}
+
+	inventory, err := a.inventoryRepo.GetByUserId(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get inventory"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if inventory == nil {
+		tags["error"] = "inventory not found"
+		tags["status"] = "error"
+		return domain.ErrInventoryNotFound
+	}
+
+	// Every adjusted balance and its audit entry commit together.
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if req.Likes != nil {
+			if err := a.inventoryRepo.UpdateLikes(ctx, userID, *req.Likes); err != nil {
+				tags["error"] = "failed to update likes"
+				tags["actual_error"] = err.Error()
+				return err
+			}
+		}
+
+		if req.SuperLikes != nil {
+			if err := a.inventoryRepo.UpdateSuperLikes(ctx, userID, *req.SuperLikes); err != nil {
+				tags["error"] = "failed to update super likes"
+				tags["actual_error"] = err.Error()
+				return err
+			}
+		}
+
+		if req.Swipes != nil {
+			if err := a.inventoryRepo.UpdateSwipes(ctx, userID, *req.Swipes); err != nil {
+				tags["error"] = "failed to update swipes"
+				tags["actual_error"] = err.Error()
+				return err
+			}
+		}
+
+		payload := map[string]interface{}{
+			"before": inventory,
+			"after":  req,
+		}
+		return a.audit(ctx, tags, adminID, domain.AuditActionAdjustInventory, userID, payload)
+	}); err != nil {
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a adminServiceModule) BanUser(ctx context.Context, adminID int64, userID int64, req domain.BanRequest) error {
+	ctx, span := tracing.Start(ctx, "service.admin.ban_user")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.ban_user"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	if adminID == userID {
+		tags["error"] = "cannot ban yourself"
+		tags["status"] = "error"
+		return domain.ErrBanSelf
+	}
+
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		bannedAt := time.Now()
+		if err := a.authRepo.SetBanned(ctx, userID, &bannedAt); err != nil {
+			tags["error"] = "failed to ban user"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		return a.audit(ctx, tags, adminID, domain.AuditActionBanUser, userID, req)
+	}); err != nil {
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a adminServiceModule) UnbanUser(ctx context.Context, adminID int64, userID int64) error {
+	ctx, span := tracing.Start(ctx, "service.admin.unban_user")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.unban_user"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := a.authRepo.SetBanned(ctx, userID, nil); err != nil {
+			tags["error"] = "failed to unban user"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		return a.audit(ctx, tags, adminID, domain.AuditActionUnbanUser, userID, nil)
+	}); err != nil {
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a adminServiceModule) DeleteProfile(ctx context.Context, adminID int64, userID int64) error {
+	ctx, span := tracing.Start(ctx, "service.admin.delete_profile")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.delete_profile"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	profile, err := a.profileRepo.GetProfile(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get profile"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if profile == nil {
+		tags["error"] = "profile not found"
+		tags["status"] = "error"
+		return domain.ErrProfileNotFound
+	}
+
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := a.profileRepo.SoftDeleteByUserID(ctx, userID); err != nil {
+			tags["error"] = "failed to delete profile"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		return a.audit(ctx, tags, adminID, domain.AuditActionDeleteProfile, userID, profile)
+	}); err != nil {
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a adminServiceModule) ResendNotification(ctx context.Context, adminID int64, notificationID int64) error {
+	ctx, span := tracing.Start(ctx, "service.admin.resend_notification")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.resend_notification"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	notification, err := a.notificationRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		tags["error"] = "failed to get notification"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if notification == nil {
+		tags["error"] = "notification not found"
+		tags["status"] = "error"
+		return domain.ErrNotificationNotFound
+	}
+
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := a.notificationRepo.Create(ctx, domain.NotificationRequest{
+			UserID:      notification.UserID,
+			Type:        notification.Type,
+			TemplateKey: notification.TemplateKey,
+			Payload:     notification.Payload,
+		}); err != nil {
+			tags["error"] = "failed to create notification"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		payload := map[string]interface{}{"notification_id": notificationID}
+		return a.audit(ctx, tags, adminID, domain.AuditActionResendNotification, notification.UserID, payload)
+	}); err != nil {
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a adminServiceModule) GetAuditLogs(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	ctx, span := tracing.Start(ctx, "service.admin.get_audit_logs")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.get_audit_logs"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	logs, err := a.auditRepo.GetAll(ctx, filter)
+	if err != nil {
+		tags["error"] = "failed to get audit logs"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return logs, nil
+}
+
+func (a adminServiceModule) GetReports(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error) {
+	ctx, span := tracing.Start(ctx, "service.admin.get_reports")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.get_reports"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	reports, err := a.reportRepo.GetAll(ctx, filter)
+	if err != nil {
+		tags["error"] = "failed to get reports"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return reports, nil
+}
+
+// ReviewReport moves a report through the queue: open -> triaged -> actioned/dismissed. Once the
+// pending reports against a hidden profile drop below the threshold because they were dismissed,
+// the profile is shown in recommendations again. Actioned reports keep it hidden.
+func (a adminServiceModule) ReviewReport(ctx context.Context, adminID int64, reportID int64, req domain.ReviewReportRequest) error {
+	ctx, span := tracing.Start(ctx, "service.admin.review_report")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.review_report"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	report, err := a.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		tags["error"] = "failed to get report"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if report == nil {
+		tags["error"] = "report not found"
+		tags["status"] = "error"
+		return domain.ErrReportNotFound
+	}
+
+	if report.Status != domain.ReportStatusOpen && report.Status != domain.ReportStatusTriaged {
+		tags["error"] = "report already closed"
+		tags["status"] = "error"
+		return domain.ErrReportClosed
+	}
+
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := a.reportRepo.UpdateStatus(ctx, reportID, adminID, req); err != nil {
+			tags["error"] = "failed to update report"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		if req.Status == domain.ReportStatusDismissed {
+			reporters, err := a.reportRepo.CountPendingReporters(ctx, report.ReportedUserID)
+			if err != nil {
+				tags["error"] = "failed to count pending reporters"
+				tags["actual_error"] = err.Error()
+				return err
+			}
+
+			if reporters < int64(reportThreshold(a.cfg)) {
+				if err := a.profileRepo.SetHidden(ctx, report.ReportedUserID, nil); err != nil {
+					tags["error"] = "failed to unhide profile"
+					tags["actual_error"] = err.Error()
+					return err
+				}
+			}
+		}
+
+		payload := map[string]interface{}{
+			"report_id": reportID,
+			"from":      report.Status,
+			"to":        req.Status,
+			"note":      req.Note,
+		}
+		return a.audit(ctx, tags, adminID, domain.AuditActionReviewReport, report.ReportedUserID, payload)
+	}); err != nil {
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a adminServiceModule) audit(ctx context.Context, tags log.Fields, adminID int64, action string,
+	targetUserID int64, payload interface{}) error {
+	tags["admin_id"] = adminID
+	tags["action"] = action
+	tags["target_user_id"] = targetUserID
+
+	if err := a.auditRepo.Create(ctx, domain.AuditLogRequest{
+		AdminID:      adminID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Payload:      payload,
+	}); err != nil {
+		tags["error"] = "failed to write audit log"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	return nil
+}
+
+func (a adminServiceModule) GetContentFlags(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error) {
+	ctx, span := tracing.Start(ctx, "service.admin.get_content_flags")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.get_content_flags"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	flags, err := a.contentFlagRepo.GetAll(ctx, filter)
+	if err != nil {
+		tags["error"] = "failed to get content flags"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return flags, nil
+}
+
+func (a adminServiceModule) GetSettings(ctx context.Context) ([]domain.Setting, error) {
+	ctx, span := tracing.Start(ctx, "service.admin.get_settings")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.get_settings"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	settings, err := a.settingsRepo.GetAll(ctx)
+	if err != nil {
+		tags["error"] = "failed to get settings"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return settings, nil
+}
+
+// UpdateSetting changes a runtime setting, an empty value resets it to its default. Every instance uses the
+// new value after its next settings refresh.
+func (a adminServiceModule) UpdateSetting(ctx context.Context, adminID int64, key string, req domain.UpdateSettingRequest) error {
+	ctx, span := tracing.Start(ctx, "service.admin.update_setting")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.admin.update_setting"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	definition, ok := domain.GetSettingDefinition(key)
+	if !ok {
+		tags["error"] = "setting not found"
+		tags["status"] = "error"
+		return domain.ErrSettingNotFound
+	}
+
+	if err := definition.Validate(req.Value); err != nil {
+		tags["error"] = "invalid setting value"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return domain.ErrInvalidSettingValue.WithDetails(map[string]string{"key": key, "reason": err.Error()})
+	}
+
+	settings, err := a.settingsRepo.GetAll(ctx)
+	if err != nil {
+		tags["error"] = "failed to get settings"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	var from string
+	for _, setting := range settings {
+		if setting.Key == key {
+			from = setting.Value
+		}
+	}
+
+	to := req.Value
+	if to == "" {
+		to = definition.Default
+	}
+
+	if err := a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := a.settingsRepo.Set(ctx, key, req.Value, adminID); err != nil {
+			tags["error"] = "failed to update setting"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		payload := map[string]interface{}{
+			"key":  key,
+			"from": from,
+			"to":   to,
+		}
+		return a.audit(ctx, tags, adminID, domain.AuditActionUpdateSetting, 0, payload)
+	}); err != nil {
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
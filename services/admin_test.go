@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func Test_adminServiceModule_AdjustInventory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	likes := 20
+	negative := -1
+
+	tests := []struct {
+		name    string
+		req     domain.AdjustInventoryRequest
+		mock    func() *adminServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating request",
+			req:  domain.AdjustInventoryRequest{Likes: &negative},
+			mock: func() *adminServiceModule {
+				return &adminServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed nothing to adjust",
+			req:  domain.AdjustInventoryRequest{},
+			mock: func() *adminServiceModule {
+				return &adminServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed inventory not found",
+			req:  domain.AdjustInventoryRequest{Likes: &likes},
+			mock: func() *adminServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(2)).Return(nil, nil)
+
+				return &adminServiceModule{inventoryRepo: inventoryMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed updating swipes",
+			req:  domain.AdjustInventoryRequest{Likes: &likes, Swipes: &likes},
+			mock: func() *adminServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(2)).Return(&domain.Inventory{UserID: 2, Likes: 10}, nil)
+				inventoryMock.EXPECT().UpdateLikes(ctx, int64(2), likes).Return(nil)
+				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(2), likes).Return(errors.New("error"))
+
+				unitOfWorkMock := NewMockUnitOfWorkInterface(ctrl)
+				unitOfWorkMock.EXPECT().Do(ctx, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+						if err := fn(ctx); err == nil {
+							t.Errorf("expected the unit of work to roll back")
+						}
+						return errors.New("rolled back")
+					})
+
+				return &adminServiceModule{inventoryRepo: inventoryMock, unitOfWork: unitOfWorkMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed writing audit log",
+			req:  domain.AdjustInventoryRequest{Likes: &likes},
+			mock: func() *adminServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(2)).Return(&domain.Inventory{UserID: 2, Likes: 10}, nil)
+				inventoryMock.EXPECT().UpdateLikes(ctx, int64(2), likes).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("error"))
+
+				return &adminServiceModule{inventoryRepo: inventoryMock, auditRepo: auditMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			req:  domain.AdjustInventoryRequest{Likes: &likes},
+			mock: func() *adminServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(2)).Return(&domain.Inventory{UserID: 2, Likes: 10}, nil)
+				inventoryMock.EXPECT().UpdateLikes(ctx, int64(2), likes).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, req domain.AuditLogRequest) error {
+						if req.AdminID != 1 || req.TargetUserID != 2 || req.Action != domain.AuditActionAdjustInventory {
+							t.Errorf("unexpected audit log %+v", req)
+						}
+						return nil
+					})
+
+				return &adminServiceModule{inventoryRepo: inventoryMock, auditRepo: auditMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			if err := a.AdjustInventory(ctx, 1, 2, tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("AdjustInventory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_adminServiceModule_BanUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		userID  int64
+		req     domain.BanRequest
+		mock    func() *adminServiceModule
+		wantErr bool
+	}{
+		{
+			name:   "failed validating request",
+			userID: 2,
+			req:    domain.BanRequest{},
+			mock: func() *adminServiceModule {
+				return &adminServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name:   "failed banning yourself",
+			userID: 1,
+			req:    domain.BanRequest{Reason: "spam"},
+			mock: func() *adminServiceModule {
+				return &adminServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name:   "failed user not found",
+			userID: 2,
+			req:    domain.BanRequest{Reason: "spam"},
+			mock: func() *adminServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().SetBanned(ctx, int64(2), gomock.Not(gomock.Nil())).Return(errors.New("user not found"))
+
+				return &adminServiceModule{authRepo: authMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name:   "success",
+			userID: 2,
+			req:    domain.BanRequest{Reason: "spam"},
+			mock: func() *adminServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().SetBanned(ctx, int64(2), gomock.Not(gomock.Nil())).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, domain.AuditLogRequest{
+					AdminID:      1,
+					Action:       domain.AuditActionBanUser,
+					TargetUserID: 2,
+					Payload:      domain.BanRequest{Reason: "spam"},
+				}).Return(nil)
+
+				return &adminServiceModule{authRepo: authMock, auditRepo: auditMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			if err := a.BanUser(ctx, 1, tt.userID, tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("BanUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_adminServiceModule_ResendNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() *adminServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed notification not found",
+			mock: func() *adminServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(nil, nil)
+
+				return &adminServiceModule{notificationRepo: notificationMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mock: func() *adminServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).
+					Return(&domain.Notification{
+						ID:          5,
+						UserID:      2,
+						Type:        domain.NotificationTypeMatch,
+						TemplateKey: domain.NotificationTemplateMatchNew,
+						Payload:     domain.NotificationPayload{ActorUserID: 3, ActorName: "Alice", MatchID: 7},
+					}, nil)
+				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
+					UserID:      2,
+					Type:        domain.NotificationTypeMatch,
+					TemplateKey: domain.NotificationTemplateMatchNew,
+					Payload:     domain.NotificationPayload{ActorUserID: 3, ActorName: "Alice", MatchID: 7},
+				}).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+				return &adminServiceModule{notificationRepo: notificationMock, auditRepo: auditMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			if err := a.ResendNotification(ctx, 1, 5); (err != nil) != tt.wantErr {
+				t.Errorf("ResendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_adminServiceModule_ReviewReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{}
+
+	tests := []struct {
+		name    string
+		req     domain.ReviewReportRequest
+		mock    func() *adminServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating status",
+			req:  domain.ReviewReportRequest{Status: domain.ReportStatusOpen},
+			mock: func() *adminServiceModule {
+				return &adminServiceModule{cfg: config}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed report already closed",
+			req:  domain.ReviewReportRequest{Status: domain.ReportStatusActioned},
+			mock: func() *adminServiceModule {
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().GetByID(ctx, int64(7)).
+					Return(&domain.Report{ID: 7, ReportedUserID: 2, Status: domain.ReportStatusDismissed}, nil)
+
+				return &adminServiceModule{cfg: config, reportRepo: reportMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success actioned keeps profile hidden",
+			req:  domain.ReviewReportRequest{Status: domain.ReportStatusActioned},
+			mock: func() *adminServiceModule {
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().GetByID(ctx, int64(7)).
+					Return(&domain.Report{ID: 7, ReportedUserID: 2, Status: domain.ReportStatusOpen}, nil)
+				reportMock.EXPECT().UpdateStatus(ctx, int64(7), int64(1), gomock.Any()).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+				return &adminServiceModule{cfg: config, reportRepo: reportMock, auditRepo: auditMock, unitOfWork: transactional(ctrl)}
+			},
+			wantErr: false,
+		},
+		{
+			name: "success dismissed unhides profile",
+			req:  domain.ReviewReportRequest{Status: domain.ReportStatusDismissed, Note: "not spam"},
+			mock: func() *adminServiceModule {
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().GetByID(ctx, int64(7)).
+					Return(&domain.Report{ID: 7, ReportedUserID: 2, Status: domain.ReportStatusTriaged}, nil)
+				reportMock.EXPECT().UpdateStatus(ctx, int64(7), int64(1), gomock.Any()).Return(nil)
+				reportMock.EXPECT().CountPendingReporters(ctx, int64(2)).Return(int64(2), nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().SetHidden(ctx, int64(2), nil).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+				return &adminServiceModule{
+					cfg:         config,
+					profileRepo: profileMock,
+					reportRepo:  reportMock,
+					auditRepo:   auditMock,
+					unitOfWork:  transactional(ctrl),
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			if err := a.ReviewReport(ctx, 1, 7, tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("ReviewReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_adminServiceModule_UpdateSetting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{}
+
+	stored := []domain.Setting{{Key: domain.SettingRecommendationPageSize, Value: "10"}}
+
+	tests := []struct {
+		name    string
+		key     string
+		req     domain.UpdateSettingRequest
+		mock    func() *adminServiceModule
+		wantErr error
+	}{
+		{
+			name: "failed unknown setting",
+			key:  "recommendation.unknown",
+			req:  domain.UpdateSettingRequest{Value: "5"},
+			mock: func() *adminServiceModule {
+				return &adminServiceModule{cfg: config}
+			},
+			wantErr: domain.ErrSettingNotFound,
+		},
+		{
+			name: "failed value out of range",
+			key:  domain.SettingRecommendationPageSize,
+			req:  domain.UpdateSettingRequest{Value: "500"},
+			mock: func() *adminServiceModule {
+				return &adminServiceModule{cfg: config}
+			},
+			wantErr: domain.ErrInvalidSettingValue,
+		},
+		{
+			name: "success audits old and new value",
+			key:  domain.SettingRecommendationPageSize,
+			req:  domain.UpdateSettingRequest{Value: "20"},
+			mock: func() *adminServiceModule {
+				settingsMock := NewMockSettingsRepoInterface(ctrl)
+				settingsMock.EXPECT().GetAll(ctx).Return(stored, nil)
+				settingsMock.EXPECT().Set(ctx, domain.SettingRecommendationPageSize, "20", int64(1)).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, domain.AuditLogRequest{
+					AdminID: 1,
+					Action:  domain.AuditActionUpdateSetting,
+					Payload: map[string]interface{}{"key": domain.SettingRecommendationPageSize, "from": "10", "to": "20"},
+				}).Return(nil)
+
+				return &adminServiceModule{cfg: config, settingsRepo: settingsMock, auditRepo: auditMock, unitOfWork: transactional(ctrl)}
+			},
+		},
+		{
+			name: "success reset audits default",
+			key:  domain.SettingRecommendationRankByActivity,
+			req:  domain.UpdateSettingRequest{Value: ""},
+			mock: func() *adminServiceModule {
+				settingsMock := NewMockSettingsRepoInterface(ctrl)
+				settingsMock.EXPECT().GetAll(ctx).Return([]domain.Setting{
+					{Key: domain.SettingRecommendationRankByActivity, Value: "false"},
+				}, nil)
+				settingsMock.EXPECT().Set(ctx, domain.SettingRecommendationRankByActivity, "", int64(1)).Return(nil)
+
+				auditMock := NewMockAuditRepoInterface(ctrl)
+				auditMock.EXPECT().Create(ctx, domain.AuditLogRequest{
+					AdminID: 1,
+					Action:  domain.AuditActionUpdateSetting,
+					Payload: map[string]interface{}{"key": domain.SettingRecommendationRankByActivity, "from": "false", "to": "true"},
+				}).Return(nil)
+
+				return &adminServiceModule{cfg: config, settingsRepo: settingsMock, auditRepo: auditMock, unitOfWork: transactional(ctrl)}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			err := a.UpdateSetting(ctx, 1, tt.key, tt.req)
+			if (tt.wantErr == nil && err != nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("UpdateSetting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,54 +2,83 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/goccy/go-json"
 	"github.com/golang-jwt/jwt/v5"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"time"
 )
 
 type authServiceModule struct {
-	cfg           *domain.Config
-	db            *gorm.DB
-	authRepo      AuthRepoInterface
-	inventoryRepo InventoryRepoInterface
-	profileRepo   ProfileRepoInterface
+	cfg               *domain.Config
+	db                *gorm.DB
+	authRepo          AuthRepoInterface
+	inventoryRepo     InventoryRepoInterface
+	profileRepo       ProfileRepoInterface
+	redisRepo         RedisRepoInterface
+	otpVerifier       OTPVerifierInterface
+	identityProviders map[string]IdentityProviderInterface
+	events            EventPublisherInterface
+	settings          SettingsInterface
 }
 
 type AuthServiceInterface interface {
 	Login(ctx context.Context, req domain.AuthRequest) (*domain.AuthResponse, error)
 	Register(ctx context.Context, req domain.AuthRequest) error
+	Authenticate(ctx context.Context, userID int64, token string) error
+	OAuthURL(ctx context.Context, provider string) (*domain.OAuthURLResponse, error)
+	OAuthCallback(ctx context.Context, provider string, req domain.OAuthCallbackRequest) (*domain.AuthResponse, error)
+	EnrollTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollResponse, error)
+	ConfirmTOTP(ctx context.Context, userID int64, req domain.TOTPConfirmRequest) (*domain.TOTPConfirmResponse, error)
+	VerifyMFA(ctx context.Context, req domain.MFAVerifyRequest) (*domain.AuthResponse, error)
+	TwoFactorStatus(ctx context.Context, userID int64) (*domain.TwoFactorStatus, error)
 }
 
 func NewAuthService(cfg *domain.Config, db *gorm.DB, authRepo AuthRepoInterface, inventoryRepo InventoryRepoInterface,
-	profileRepo ProfileRepoInterface) (AuthServiceInterface, error) {
+	profileRepo ProfileRepoInterface, redisRepo RedisRepoInterface, otpVerifier OTPVerifierInterface,
+	identityProviders map[string]IdentityProviderInterface, events EventPublisherInterface,
+	settings SettingsInterface) (AuthServiceInterface, error) {
 	validate := validator.New()
 	if err := validate.Struct(cfg); err != nil {
 		return nil, err
 	}
 
 	return &authServiceModule{
-		cfg:           cfg,
-		db:            db,
-		authRepo:      authRepo,
-		inventoryRepo: inventoryRepo,
-		profileRepo:   profileRepo,
+		cfg:               cfg,
+		db:                db,
+		authRepo:          authRepo,
+		inventoryRepo:     inventoryRepo,
+		profileRepo:       profileRepo,
+		redisRepo:         redisRepo,
+		otpVerifier:       otpVerifier,
+		identityProviders: identityProviders,
+		events:            events,
+		settings:          settings,
 	}, nil
 }
 
 //goland:noinspection GoLinter
 func (a *authServiceModule) Login(ctx context.Context, req domain.AuthRequest) (*domain.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.auth.login")
 	startTime := time.Now()
 	tags := make(log.Fields)
 	defer func() {
 		tags["name"] = "service.auth.login"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	validate := validator.New()
@@ -59,6 +88,10 @@ func (a *authServiceModule) Login(ctx context.Context, req domain.AuthRequest) (
 		return nil, err
 	}
 
+	if req.Phone != "" {
+		return a.loginWithPhone(ctx, tags, req)
+	}
+
 	user, err := a.authRepo.Login(ctx, req)
 	if err != nil {
 		tags["error"] = "failed login"
@@ -66,101 +99,427 @@ func (a *authServiceModule) Login(ctx context.Context, req domain.AuthRequest) (
 		return nil, err
 	}
 
-	userJSON, err := json.Marshal(user)
+	response, err := a.completeLogin(ctx, tags, user)
 	if err != nil {
-		tags["error"] = "failed marshal user"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return response, nil
+}
+
+func (a *authServiceModule) Register(ctx context.Context, req domain.AuthRequest) error {
+	ctx, span := tracing.Start(ctx, "service.auth.register")
+	startTime := time.Now()
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "service.auth.register"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil
+	}
+
+	if req.Phone != "" {
+		return a.registerWithPhone(ctx, tags, req)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		tags["error"] = "failed hashing password"
+		tags["status"] = "error"
+		return err
+	}
+
+	req.Password = string(hashedPassword)
+
+	user, err := a.authRepo.Register(ctx, req)
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		tags["error"] = "email already registered"
+		tags["status"] = "error"
+		return domain.ErrEmailRegistered
+	}
+
+	if err != nil {
+		tags["error"] = "failed register"
+		tags["status"] = "error"
+		return err
+	}
+
+	if err := a.provisionUser(ctx, tags, user.ID, "email"); err != nil {
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// Authenticate checks the access token is still the one the user last signed in with. Tokens of deleted users
+// and tokens replaced by a later sign in or dropped by a ban are revoked.
+func (a *authServiceModule) Authenticate(ctx context.Context, userID int64, token string) error {
+	ctx, span := tracing.Start(ctx, "service.auth.authenticate")
+	startTime := time.Now()
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "service.auth.authenticate"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	accessToken, err := a.authRepo.GetAccessToken(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get access token"
+		tags["status"] = "error"
+		return err
+	}
+
+	if accessToken == "" || subtle.ConstantTimeCompare([]byte(accessToken), []byte(token)) != 1 {
+		tags["error"] = "token revoked"
+		tags["status"] = "error"
+		return domain.ErrTokenRevoked
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a *authServiceModule) loginWithPhone(ctx context.Context, tags log.Fields, req domain.AuthRequest) (*domain.AuthResponse, error) {
+	if err := a.otpVerifier.Verify(ctx, req.Phone, req.Code); err != nil {
+		tags["error"] = "failed verify otp"
 		tags["status"] = "error"
 		return nil, err
 	}
 
-	userEncode, err := domain.EncryptAESWithGCM(string(userJSON), a.cfg.JWT.Key)
+	user, err := a.authRepo.GetByPhone(ctx, req.Phone)
 	if err != nil {
-		tags["error"] = "failed encrypt user"
+		tags["error"] = "failed get user by phone"
 		tags["status"] = "error"
-		tags["actual_error"] = err.Error()
 		return nil, err
 	}
 
-	expireTime := time.Now().Add(time.Hour * time.Duration(a.cfg.JWT.ExpireTime)).Unix()
-	claims := jwt.MapClaims{
-		"sub": userEncode,
-		"exp": expireTime,
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Unix(),
+	if user == nil {
+		tags["error"] = "user not found"
+		tags["status"] = "error"
+		return nil, domain.ErrUserNotFound
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(a.cfg.Key))
+	response, err := a.completeLogin(ctx, tags, *user)
 	if err != nil {
-		tags["error"] = "failed generating token"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return response, nil
+}
+
+func (a *authServiceModule) registerWithPhone(ctx context.Context, tags log.Fields, req domain.AuthRequest) error {
+	if err := a.otpVerifier.Verify(ctx, req.Phone, req.Code); err != nil {
+		tags["error"] = "failed verify otp"
+		tags["status"] = "error"
+		return err
+	}
+
+	existing, err := a.authRepo.GetByPhone(ctx, req.Phone)
+	if err != nil {
+		tags["error"] = "failed get user by phone"
+		tags["status"] = "error"
+		return err
+	}
+
+	if existing != nil {
+		tags["error"] = "phone already registered"
+		tags["status"] = "error"
+		return domain.ErrPhoneRegistered
+	}
+
+	user, err := a.authRepo.RegisterPhone(ctx, req.Phone)
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		// Registered by a concurrent request since the check above.
+		tags["error"] = "phone already registered"
+		tags["status"] = "error"
+		return domain.ErrPhoneRegistered
+	}
+
+	if err != nil {
+		tags["error"] = "failed register"
+		tags["status"] = "error"
+		return err
+	}
+
+	if err := a.provisionUser(ctx, tags, user.ID, "phone"); err != nil {
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (a *authServiceModule) OAuthURL(ctx context.Context, provider string) (*domain.OAuthURLResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.auth.oauth_url")
+	startTime := time.Now()
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "service.auth.oauth_url"
+		tags["provider"] = provider
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	identityProvider, ok := a.identityProviders[provider]
+	if !ok {
+		tags["error"] = "unknown identity provider"
+		tags["status"] = "error"
+		return nil, domain.ErrUnknownIdentityProvider
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		tags["error"] = "failed generating state"
 		tags["status"] = "error"
 		return nil, err
 	}
 
-	updateTokenReq := domain.UpdateTokenRequest{
-		AccessToken:    tokenString,
-		TokenExpiredAt: time.Unix(expireTime, 0),
+	nonce, err := randomToken(16)
+	if err != nil {
+		tags["error"] = "failed generating nonce"
+		tags["status"] = "error"
+		return nil, err
 	}
 
-	err = a.authRepo.UpdateToken(ctx, user.ID, updateTokenReq)
+	codeVerifier, err := randomToken(32)
 	if err != nil {
-		tags["error"] = "failed update token"
+		tags["error"] = "failed generating code verifier"
 		tags["status"] = "error"
 		return nil, err
 	}
 
-	response := &domain.AuthResponse{
-		Token: tokenString,
+	oauthState, err := json.Marshal(domain.OAuthState{
+		Provider:     provider,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+	})
+	if err != nil {
+		tags["error"] = "failed marshal state"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := a.redisRepo.Set(ctx, fmt.Sprintf("oauth_state:%s", state), oauthState, 60*10); err != nil {
+		tags["error"] = "failed storing state"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	authURL, err := identityProvider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		tags["error"] = "failed building auth url"
+		tags["status"] = "error"
+		return nil, err
 	}
 
 	tags["status"] = "success"
-	return response, nil
+	return &domain.OAuthURLResponse{
+		URL:   authURL,
+		State: state,
+	}, nil
 }
 
-func (a *authServiceModule) Register(ctx context.Context, req domain.AuthRequest) error {
+func (a *authServiceModule) OAuthCallback(ctx context.Context, provider string,
+	req domain.OAuthCallbackRequest) (*domain.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.auth.oauth_callback")
 	startTime := time.Now()
 	tags := make(log.Fields)
 	defer func() {
-		tags["name"] = "service.auth.register"
+		tags["name"] = "service.auth.oauth_callback"
+		tags["provider"] = provider
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	validate := validator.New()
 	if err := validate.Struct(req); err != nil {
 		tags["error"] = "failed validating request"
 		tags["status"] = "error"
-		return nil
+		return nil, err
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	identityProvider, ok := a.identityProviders[provider]
+	if !ok {
+		tags["error"] = "unknown identity provider"
+		tags["status"] = "error"
+		return nil, domain.ErrUnknownIdentityProvider
+	}
+
+	// State is single use, a replayed or concurrent callback must not be able to log in again.
+	rawState, err := a.redisRepo.GetDel(ctx, fmt.Sprintf("oauth_state:%s", req.State))
 	if err != nil {
-		tags["error"] = "failed hashing password"
+		tags["error"] = "failed get state"
 		tags["status"] = "error"
-		return err
+		return nil, err
 	}
 
-	req.Password = string(hashedPassword)
+	if rawState == "" {
+		tags["error"] = "invalid oauth state"
+		tags["status"] = "error"
+		return nil, domain.ErrInvalidOAuthState
+	}
 
-	user, err := a.authRepo.Register(ctx, req)
+	var oauthState domain.OAuthState
+	if err := json.Unmarshal([]byte(rawState), &oauthState); err != nil {
+		tags["error"] = "failed unmarshal state"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if oauthState.Provider != provider {
+		tags["error"] = "invalid oauth state"
+		tags["status"] = "error"
+		return nil, domain.ErrInvalidOAuthState
+	}
+
+	claims, err := identityProvider.Exchange(ctx, req.Code, oauthState.CodeVerifier, oauthState.Nonce)
 	if err != nil {
-		tags["error"] = "failed register"
+		tags["error"] = "failed exchange code"
+		tags["actual_error"] = err.Error()
 		tags["status"] = "error"
-		return err
+		return nil, fmt.Errorf("%w: %w", domain.ErrIdentityRejected, err)
+	}
+
+	user, err := a.resolveIdentity(ctx, tags, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	tags["user_id"] = user.ID
+
+	response, err := a.completeLogin(ctx, tags, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return response, nil
+}
+
+// resolveIdentity finds the user behind an external identity. Unknown identities are linked to an
+// existing account by verified email, or a new account is provisioned on first social login.
+func (a *authServiceModule) resolveIdentity(ctx context.Context, tags log.Fields, provider string,
+	claims domain.IdentityClaims) (*domain.User, error) {
+	identity, err := a.authRepo.GetIdentity(ctx, provider, claims.Subject)
+	if err != nil {
+		tags["error"] = "failed get identity"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if identity != nil {
+		user, err := a.authRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			tags["error"] = "failed get user"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		if user == nil {
+			tags["error"] = "user not found"
+			tags["status"] = "error"
+			return nil, domain.ErrUserNotFound
+		}
+
+		return user, nil
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		tags["error"] = "email not verified"
+		tags["status"] = "error"
+		return nil, domain.ErrEmailNotVerified
+	}
+
+	user, err := a.authRepo.GetByEmail(ctx, claims.Email)
+	if err != nil {
+		tags["error"] = "failed get user by email"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if user == nil {
+		// Social accounts get a random password so the email/password login can never match it.
+		password, err := randomToken(32)
+		if err != nil {
+			tags["error"] = "failed generating password"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			tags["error"] = "failed hashing password"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		registered, err := a.authRepo.Register(ctx, domain.AuthRequest{
+			Email:    claims.Email,
+			Password: string(hashedPassword),
+		})
+		if err != nil {
+			tags["error"] = "failed register"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		if err := a.provisionUser(ctx, tags, registered.ID, provider); err != nil {
+			return nil, err
+		}
+
+		registered.Password = ""
+		user = &registered
+		tags["provisioned"] = true
 	}
 
+	if err := a.authRepo.CreateIdentity(ctx, domain.CreateIdentityRequest{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		tags["error"] = "failed create identity"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// provisionUser creates the starting inventory and an empty profile for a freshly registered user and announces
+// the registration, method is how the user signed up.
+func (a *authServiceModule) provisionUser(ctx context.Context, tags log.Fields, userID int64, method string) error {
 	if err := a.inventoryRepo.Create(ctx, domain.CreateInventoryRequest{
-		UserID:     user.ID,
-		Likes:      10,
-		SuperLikes: 1,
+		UserID:     userID,
+		Swipes:     int64(a.settings.Int(ctx, domain.SettingQuotaSwipes)),
+		Likes:      int64(a.settings.Int(ctx, domain.SettingQuotaLikes)),
+		SuperLikes: int64(a.settings.Int(ctx, domain.SettingQuotaSuperLikes)),
 	}); err != nil {
 		tags["error"] = "failed create inventory"
 		tags["status"] = "error"
 		return err
 	}
 
-	if err := a.profileRepo.Create(ctx, user.ID, domain.ProfileRequest{
+	if err := a.profileRepo.Create(ctx, userID, domain.ProfileRequest{
 		Name:       "",
 		Gender:     "",
 		InterestIn: "",
@@ -170,6 +529,75 @@ func (a *authServiceModule) Register(ctx context.Context, req domain.AuthRequest
 		return err
 	}
 
-	tags["status"] = "success"
+	if err := a.events.Publish(ctx, domain.UserRegisteredEvent{UserID: userID, Method: method}); err != nil {
+		tags["error"] = "failed publish user registered"
+		tags["status"] = "error"
+		return err
+	}
+
 	return nil
 }
+
+func (a *authServiceModule) issueToken(ctx context.Context, tags log.Fields, user domain.User) (*domain.AuthResponse, error) {
+	userJSON, err := json.Marshal(user)
+	if err != nil {
+		tags["error"] = "failed marshal user"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	userEncode, err := domain.EncryptAESWithGCM(string(userJSON), a.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed encrypt user"
+		tags["status"] = "error"
+		tags["actual_error"] = err.Error()
+		return nil, err
+	}
+
+	expireTime := time.Now().Add(time.Hour * time.Duration(a.cfg.JWT.ExpireTime)).Unix()
+	claims := jwt.MapClaims{
+		"sub": userEncode,
+		"exp": expireTime,
+		"iat": time.Now().Unix(),
+		"nbf": time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(a.cfg.Key))
+	if err != nil {
+		tags["error"] = "failed generating token"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	updateTokenReq := domain.UpdateTokenRequest{
+		AccessToken:    tokenString,
+		TokenExpiredAt: time.Unix(expireTime, 0),
+	}
+
+	err = a.authRepo.UpdateToken(ctx, user.ID, updateTokenReq)
+	if err != nil {
+		tags["error"] = "failed update token"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := a.events.Publish(ctx, domain.UserLoggedInEvent{UserID: user.ID, MFA: user.TOTPEnabled}); err != nil {
+		tags["error"] = "failed publish user logged in"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	return &domain.AuthResponse{
+		Token: tokenString,
+	}, nil
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
@@ -17,11 +18,16 @@ func TestNewAuthService(t *testing.T) {
 	defer ctrl.Finish()
 
 	type args struct {
-		cfg           *domain.Config
-		db            *gorm.DB
-		authRepo      AuthRepoInterface
-		inventoryRepo InventoryRepoInterface
-		profileRepo   ProfileRepoInterface
+		cfg               *domain.Config
+		db                *gorm.DB
+		authRepo          AuthRepoInterface
+		inventoryRepo     InventoryRepoInterface
+		profileRepo       ProfileRepoInterface
+		redisRepo         RedisRepoInterface
+		otpVerifier       OTPVerifierInterface
+		identityProviders map[string]IdentityProviderInterface
+		events            EventPublisherInterface
+		settings          SettingsInterface
 	}
 
 	config := &domain.Config{
@@ -54,6 +60,13 @@ func TestNewAuthService(t *testing.T) {
 	authMock := NewMockAuthRepoInterface(ctrl)
 	inventoryMock := NewMockInventoryRepoInterface(ctrl)
 	profileMock := NewMockProfileRepoInterface(ctrl)
+	redisMock := NewMockRedisRepoInterface(ctrl)
+	otpMock := NewMockOTPVerifierInterface(ctrl)
+	identityProviders := map[string]IdentityProviderInterface{
+		"google": NewMockIdentityProviderInterface(ctrl),
+	}
+	eventsMock := NewMockEventPublisherInterface(ctrl)
+	settingsMock := NewMockSettingsInterface(ctrl)
 
 	tests := []struct {
 		name    string
@@ -64,29 +77,44 @@ func TestNewAuthService(t *testing.T) {
 		{
 			name: "success",
 			args: args{
-				cfg:           config,
-				db:            db,
-				authRepo:      authMock,
-				inventoryRepo: inventoryMock,
-				profileRepo:   profileMock,
+				cfg:               config,
+				db:                db,
+				authRepo:          authMock,
+				inventoryRepo:     inventoryMock,
+				profileRepo:       profileMock,
+				redisRepo:         redisMock,
+				otpVerifier:       otpMock,
+				identityProviders: identityProviders,
+				events:            eventsMock,
+				settings:          settingsMock,
 			},
 			want: &authServiceModule{
-				cfg:           config,
-				db:            db,
-				authRepo:      authMock,
-				inventoryRepo: inventoryMock,
-				profileRepo:   profileMock,
+				cfg:               config,
+				db:                db,
+				authRepo:          authMock,
+				inventoryRepo:     inventoryMock,
+				profileRepo:       profileMock,
+				redisRepo:         redisMock,
+				otpVerifier:       otpMock,
+				identityProviders: identityProviders,
+				events:            eventsMock,
+				settings:          settingsMock,
 			},
 			wantErr: false,
 		},
 		{
 			name: "failed",
 			args: args{
-				cfg:           failedConfig,
-				db:            db,
-				authRepo:      authMock,
-				inventoryRepo: inventoryMock,
-				profileRepo:   profileMock,
+				cfg:               failedConfig,
+				db:                db,
+				authRepo:          authMock,
+				inventoryRepo:     inventoryMock,
+				profileRepo:       profileMock,
+				redisRepo:         redisMock,
+				otpVerifier:       otpMock,
+				identityProviders: identityProviders,
+				events:            eventsMock,
+				settings:          settingsMock,
 			},
 			want:    nil,
 			wantErr: true,
@@ -95,7 +123,8 @@ func TestNewAuthService(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAuthService(tt.args.cfg, tt.args.db, tt.args.authRepo, tt.args.inventoryRepo, tt.args.profileRepo)
+			got, err := NewAuthService(tt.args.cfg, tt.args.db, tt.args.authRepo, tt.args.inventoryRepo, tt.args.profileRepo,
+				tt.args.redisRepo, tt.args.otpVerifier, tt.args.identityProviders, tt.args.events, tt.args.settings)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAuthService() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -216,6 +245,36 @@ func Test_authServiceModule_Login(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "failed user banned",
+			args: args{
+				ctx: ctx,
+				req: domain.AuthRequest{
+					Email:    "test@mail.com",
+					Password: "testtest",
+				},
+			},
+			mock: func() AuthServiceInterface {
+				bannedAt := time.Now()
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().Login(ctx, domain.AuthRequest{
+					Email:    "test@mail.com",
+					Password: "testtest",
+				}).Return(domain.User{
+					ID:       1,
+					Email:    "test@mail.com",
+					BannedAt: &bannedAt,
+				}, nil)
+
+				return &authServiceModule{
+					cfg:      config,
+					db:       db,
+					authRepo: authMock,
+				}
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "failed encrypt aes with gcm",
 			args: args{
@@ -314,10 +373,96 @@ func Test_authServiceModule_Login(t *testing.T) {
 
 				authMock.EXPECT().UpdateToken(ctx, gomock.Any(), gomock.Any()).Return(nil)
 
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserLoggedInEvent{UserID: 1}).Return(nil)
+
 				return &authServiceModule{
 					cfg:      config,
 					db:       db,
 					authRepo: authMock,
+					events:   eventsMock,
+				}
+			},
+			want: &domain.AuthResponse{
+				Token: "", // gomock.Any() here
+			},
+			wantErr: false,
+		},
+		{
+			name: "phone - failed verify otp",
+			args: args{
+				ctx: ctx,
+				req: domain.AuthRequest{
+					Phone: "+6281234567890",
+					Code:  "123456",
+				},
+			},
+			mock: func() AuthServiceInterface {
+				otpMock := NewMockOTPVerifierInterface(ctrl)
+				otpMock.EXPECT().Verify(ctx, "+6281234567890", "123456").Return(errors.New("invalid otp"))
+
+				return &authServiceModule{
+					cfg:         config,
+					db:          db,
+					otpVerifier: otpMock,
+				}
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "phone - user not found",
+			args: args{
+				ctx: ctx,
+				req: domain.AuthRequest{
+					Phone: "+6281234567890",
+					Code:  "123456",
+				},
+			},
+			mock: func() AuthServiceInterface {
+				otpMock := NewMockOTPVerifierInterface(ctrl)
+				otpMock.EXPECT().Verify(ctx, "+6281234567890", "123456").Return(nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByPhone(ctx, "+6281234567890").Return(nil, nil)
+
+				return &authServiceModule{
+					cfg:         config,
+					db:          db,
+					authRepo:    authMock,
+					otpVerifier: otpMock,
+				}
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "phone - success",
+			args: args{
+				ctx: ctx,
+				req: domain.AuthRequest{
+					Phone: "+6281234567890",
+					Code:  "123456",
+				},
+			},
+			mock: func() AuthServiceInterface {
+				otpMock := NewMockOTPVerifierInterface(ctrl)
+				otpMock.EXPECT().Verify(ctx, "+6281234567890", "123456").Return(nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByPhone(ctx, "+6281234567890").
+					Return(&domain.User{ID: 1, Phone: "+6281234567890"}, nil)
+				authMock.EXPECT().UpdateToken(ctx, int64(1), gomock.Any()).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserLoggedInEvent{UserID: 1}).Return(nil)
+
+				return &authServiceModule{
+					cfg:         config,
+					db:          db,
+					authRepo:    authMock,
+					otpVerifier: otpMock,
+					events:      eventsMock,
 				}
 			},
 			want: &domain.AuthResponse{
@@ -427,6 +572,29 @@ func Test_authServiceModule_Register(t *testing.T) {
 			},
 			want: errors.New("test"),
 		},
+		{
+			name: "failed email already registered",
+			args: args{
+				ctx: ctx,
+				req: domain.AuthRequest{
+					Email:    "test@mail.com",
+					Password: "testtest",
+				},
+			},
+			mock: func() AuthServiceInterface {
+				authMock := NewMockAuthRepoInterface(ctrl)
+
+				authMock.EXPECT().Register(ctx, gomock.Any()).
+					Return(domain.User{}, fmt.Errorf("%w: users_email_key", domain.ErrAlreadyExists))
+
+				return &authServiceModule{
+					cfg:      failedConfig,
+					db:       db,
+					authRepo: authMock,
+				}
+			},
+			want: errors.New("email already registered"),
+		},
 		{
 			name: "failed create inventory",
 			args: args{
@@ -448,6 +616,7 @@ func Test_authServiceModule_Register(t *testing.T) {
 					db:            db,
 					authRepo:      authMock,
 					inventoryRepo: inventoryMock,
+					settings:      defaultSettingsMock(ctrl),
 				}
 			},
 			want: errors.New("test"),
@@ -476,6 +645,7 @@ func Test_authServiceModule_Register(t *testing.T) {
 					db:            db,
 					authRepo:      authMock,
 					inventoryRepo: inventoryMock,
+					settings:      defaultSettingsMock(ctrl),
 					profileRepo:   profileMock,
 				}
 			},
@@ -500,12 +670,81 @@ func Test_authServiceModule_Register(t *testing.T) {
 				profileMock := NewMockProfileRepoInterface(ctrl)
 				profileMock.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).Return(nil)
 
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserRegisteredEvent{UserID: 0, Method: "email"}).Return(nil)
+
 				return &authServiceModule{
 					cfg:           failedConfig,
 					db:            db,
 					authRepo:      authMock,
 					inventoryRepo: inventoryMock,
+					settings:      defaultSettingsMock(ctrl),
 					profileRepo:   profileMock,
+					events:        eventsMock,
+				}
+			},
+			want: nil,
+		},
+		{
+			name: "phone - already registered",
+			args: args{
+				ctx: ctx,
+				req: domain.AuthRequest{
+					Phone: "+6281234567890",
+					Code:  "123456",
+				},
+			},
+			mock: func() AuthServiceInterface {
+				otpMock := NewMockOTPVerifierInterface(ctrl)
+				otpMock.EXPECT().Verify(ctx, "+6281234567890", "123456").Return(nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByPhone(ctx, "+6281234567890").Return(&domain.User{ID: 1}, nil)
+
+				return &authServiceModule{
+					cfg:         failedConfig,
+					db:          db,
+					authRepo:    authMock,
+					otpVerifier: otpMock,
+				}
+			},
+			want: errors.New("phone already registered"),
+		},
+		{
+			name: "phone - success",
+			args: args{
+				ctx: ctx,
+				req: domain.AuthRequest{
+					Phone: "+6281234567890",
+					Code:  "123456",
+				},
+			},
+			mock: func() AuthServiceInterface {
+				otpMock := NewMockOTPVerifierInterface(ctrl)
+				otpMock.EXPECT().Verify(ctx, "+6281234567890", "123456").Return(nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByPhone(ctx, "+6281234567890").Return(nil, nil)
+				authMock.EXPECT().RegisterPhone(ctx, "+6281234567890").Return(domain.User{ID: 2}, nil)
+
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().Create(ctx, int64(2), gomock.Any()).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserRegisteredEvent{UserID: 2, Method: "phone"}).Return(nil)
+
+				return &authServiceModule{
+					cfg:           failedConfig,
+					db:            db,
+					authRepo:      authMock,
+					inventoryRepo: inventoryMock,
+					settings:      defaultSettingsMock(ctrl),
+					profileRepo:   profileMock,
+					otpVerifier:   otpMock,
+					events:        eventsMock,
 				}
 			},
 			want: nil,
@@ -523,3 +762,405 @@ func Test_authServiceModule_Register(t *testing.T) {
 		})
 	}
 }
+
+func Test_authServiceModule_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() AuthServiceInterface
+		wantErr error
+	}{
+		{
+			name: "failed get access token",
+			mock: func() AuthServiceInterface {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetAccessToken(ctx, int64(1)).Return("", errors.New("test"))
+
+				return &authServiceModule{authRepo: authMock}
+			},
+			wantErr: errors.New("test"),
+		},
+		{
+			name: "failed user deleted or banned",
+			mock: func() AuthServiceInterface {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetAccessToken(ctx, int64(1)).Return("", nil)
+
+				return &authServiceModule{authRepo: authMock}
+			},
+			wantErr: domain.ErrTokenRevoked,
+		},
+		{
+			name: "failed token replaced by a later sign in",
+			mock: func() AuthServiceInterface {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetAccessToken(ctx, int64(1)).Return("newer", nil)
+
+				return &authServiceModule{authRepo: authMock}
+			},
+			wantErr: domain.ErrTokenRevoked,
+		},
+		{
+			name: "success",
+			mock: func() AuthServiceInterface {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetAccessToken(ctx, int64(1)).Return("token", nil)
+
+				return &authServiceModule{authRepo: authMock}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			err := a.Authenticate(ctx, 1, "token")
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if errors.Is(tt.wantErr, domain.ErrTokenRevoked) && !errors.Is(err, domain.ErrTokenRevoked) {
+				t.Errorf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_authServiceModule_OAuthURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name     string
+		provider string
+		mock     func() AuthServiceInterface
+		wantErr  bool
+	}{
+		{
+			name:     "failed unknown provider",
+			provider: "facebook",
+			mock: func() AuthServiceInterface {
+				return &authServiceModule{
+					identityProviders: map[string]IdentityProviderInterface{},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:     "failed storing state",
+			provider: "google",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Set(ctx, gomock.Any(), gomock.Any(), 600).Return(errors.New("test"))
+
+				return &authServiceModule{
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": NewMockIdentityProviderInterface(ctrl),
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:     "failed building auth url",
+			provider: "google",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Set(ctx, gomock.Any(), gomock.Any(), 600).Return(nil)
+
+				providerMock := NewMockIdentityProviderInterface(ctrl)
+				providerMock.EXPECT().AuthCodeURL(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return("", errors.New("test"))
+
+				return &authServiceModule{
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": providerMock,
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:     "success",
+			provider: "google",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Set(ctx, gomock.Any(), gomock.Any(), 600).Return(nil)
+
+				providerMock := NewMockIdentityProviderInterface(ctrl)
+				providerMock.EXPECT().AuthCodeURL(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return("https://accounts.example.com/auth", nil)
+
+				return &authServiceModule{
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": providerMock,
+					},
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			got, err := a.OAuthURL(ctx, tt.provider)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OAuthURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && (got.URL == "" || got.State == "") {
+				t.Errorf("OAuthURL() got = %v, want url and state", got)
+			}
+		})
+	}
+}
+
+func Test_authServiceModule_OAuthCallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{
+		Key: "12345",
+		JWT: domain.JWT{
+			Key:        "TRTgoAnzX&NPBAhA53C6PaMB&E5*d7wx",
+			ExpireTime: 30,
+		},
+	}
+
+	req := domain.OAuthCallbackRequest{
+		Code:  "code",
+		State: "state",
+	}
+
+	storedState := `{"provider":"google","code_verifier":"verifier","nonce":"nonce"}`
+
+	verifiedClaims := domain.IdentityClaims{
+		Subject:       "google-subject",
+		Email:         "test@mail.com",
+		EmailVerified: true,
+	}
+
+	tests := []struct {
+		name    string
+		mock    func() AuthServiceInterface
+		wantErr bool
+	}{
+		{
+			name: "failed invalid state",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, "oauth_state:state").Return("", nil)
+
+				return &authServiceModule{
+					cfg:       config,
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": NewMockIdentityProviderInterface(ctrl),
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed state issued for another provider",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, "oauth_state:state").
+					Return(`{"provider":"apple","code_verifier":"verifier","nonce":"nonce"}`, nil)
+
+				return &authServiceModule{
+					cfg:       config,
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": NewMockIdentityProviderInterface(ctrl),
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed exchange code",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, "oauth_state:state").Return(storedState, nil)
+
+				providerMock := NewMockIdentityProviderInterface(ctrl)
+				providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").
+					Return(domain.IdentityClaims{}, errors.New("test"))
+
+				return &authServiceModule{
+					cfg:       config,
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": providerMock,
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed unverified email",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, "oauth_state:state").Return(storedState, nil)
+
+				providerMock := NewMockIdentityProviderInterface(ctrl)
+				providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").
+					Return(domain.IdentityClaims{Subject: "google-subject", Email: "test@mail.com"}, nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetIdentity(ctx, "google", "google-subject").Return(nil, nil)
+
+				return &authServiceModule{
+					cfg:       config,
+					authRepo:  authMock,
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": providerMock,
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success existing identity",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, "oauth_state:state").Return(storedState, nil)
+
+				providerMock := NewMockIdentityProviderInterface(ctrl)
+				providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(verifiedClaims, nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetIdentity(ctx, "google", "google-subject").
+					Return(&domain.UserIdentity{UserID: 1}, nil)
+				authMock.EXPECT().GetByID(ctx, int64(1)).Return(&domain.User{ID: 1, Email: "test@mail.com"}, nil)
+				authMock.EXPECT().UpdateToken(ctx, int64(1), gomock.Any()).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserLoggedInEvent{UserID: 1}).Return(nil)
+
+				return &authServiceModule{
+					cfg:       config,
+					authRepo:  authMock,
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": providerMock,
+					},
+					events: eventsMock,
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "success link existing user by email",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, "oauth_state:state").Return(storedState, nil)
+
+				providerMock := NewMockIdentityProviderInterface(ctrl)
+				providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(verifiedClaims, nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetIdentity(ctx, "google", "google-subject").Return(nil, nil)
+				authMock.EXPECT().GetByEmail(ctx, "test@mail.com").Return(&domain.User{ID: 1, Email: "test@mail.com"}, nil)
+				authMock.EXPECT().CreateIdentity(ctx, domain.CreateIdentityRequest{
+					UserID:   1,
+					Provider: "google",
+					Subject:  "google-subject",
+					Email:    "test@mail.com",
+				}).Return(nil)
+				authMock.EXPECT().UpdateToken(ctx, int64(1), gomock.Any()).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserLoggedInEvent{UserID: 1}).Return(nil)
+
+				return &authServiceModule{
+					cfg:       config,
+					authRepo:  authMock,
+					redisRepo: redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": providerMock,
+					},
+					events: eventsMock,
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "success provision new user",
+			mock: func() AuthServiceInterface {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, "oauth_state:state").Return(storedState, nil)
+
+				providerMock := NewMockIdentityProviderInterface(ctrl)
+				providerMock.EXPECT().Exchange(ctx, "code", "verifier", "nonce").Return(verifiedClaims, nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetIdentity(ctx, "google", "google-subject").Return(nil, nil)
+				authMock.EXPECT().GetByEmail(ctx, "test@mail.com").Return(nil, nil)
+				authMock.EXPECT().Register(ctx, gomock.Any()).Return(domain.User{ID: 2, Email: "test@mail.com"}, nil)
+				authMock.EXPECT().CreateIdentity(ctx, gomock.Any()).Return(nil)
+				authMock.EXPECT().UpdateToken(ctx, int64(2), gomock.Any()).Return(nil)
+
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().Create(ctx, domain.CreateInventoryRequest{
+					UserID:     2,
+					Swipes:     10,
+					Likes:      10,
+					SuperLikes: 1,
+				}).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().Create(ctx, int64(2), gomock.Any()).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserRegisteredEvent{UserID: 2, Method: "google"}).Return(nil)
+				eventsMock.EXPECT().Publish(ctx, domain.UserLoggedInEvent{UserID: 2}).Return(nil)
+
+				return &authServiceModule{
+					cfg:           config,
+					authRepo:      authMock,
+					inventoryRepo: inventoryMock,
+					settings:      defaultSettingsMock(ctrl),
+					profileRepo:   profileMock,
+					redisRepo:     redisMock,
+					identityProviders: map[string]IdentityProviderInterface{
+						"google": providerMock,
+					},
+					events: eventsMock,
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			got, err := a.OAuthCallback(ctx, "google", req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OAuthCallback() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got.Token == "" {
+				t.Errorf("OAuthCallback() got empty token")
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+const defaultMessagePageSize = 30
+
+type chatServiceModule struct {
+	cfg             *domain.Config
+	chatRepo        ChatRepoInterface
+	matchedRepo     MatchedRepoInterface
+	blockRepo       BlockRepoInterface
+	realtime        RealtimePublisherInterface
+	contentFilter   ContentFilterInterface
+	contentFlagRepo ContentFlagRepoInterface
+	profileRepo     ProfileRepoInterface
+	presenceRepo    PresenceRepoInterface
+	outboxRepo      OutboxRepoInterface
+}
+
+type ChatServiceInterface interface {
+	OpenConversation(ctx context.Context, userID int64, req domain.OpenConversationRequest) (*domain.Conversation, error)
+	GetConversations(ctx context.Context, userID int64) ([]domain.Conversation, error)
+	SendMessage(ctx context.Context, userID int64, conversationID int64, req domain.SendMessageRequest) (*domain.Message, error)
+	GetMessages(ctx context.Context, userID int64, conversationID int64, req domain.MessageHistoryRequest) (*domain.MessagePage, error)
+	UpdateReceipt(ctx context.Context, userID int64, conversationID int64, req domain.ReceiptRequest) error
+	SetTyping(ctx context.Context, userID int64, conversationID int64, req domain.TypingRequest) error
+}
+
+func NewChatService(cfg *domain.Config, chatRepo ChatRepoInterface, matchedRepo MatchedRepoInterface,
+	blockRepo BlockRepoInterface, realtime RealtimePublisherInterface, contentFilter ContentFilterInterface,
+	contentFlagRepo ContentFlagRepoInterface, profileRepo ProfileRepoInterface, presenceRepo PresenceRepoInterface,
+	outboxRepo OutboxRepoInterface) (ChatServiceInterface, error) {
+	return &chatServiceModule{
+		cfg:             cfg,
+		chatRepo:        chatRepo,
+		matchedRepo:     matchedRepo,
+		blockRepo:       blockRepo,
+		realtime:        realtime,
+		contentFilter:   contentFilter,
+		contentFlagRepo: contentFlagRepo,
+		profileRepo:     profileRepo,
+		presenceRepo:    presenceRepo,
+		outboxRepo:      outboxRepo,
+	}, nil
+}
+
+func (c chatServiceModule) OpenConversation(ctx context.Context, userID int64, req domain.OpenConversationRequest) (*domain.Conversation, error) {
+	ctx, span := tracing.Start(ctx, "service.chat.open_conversation")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.chat.open_conversation"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if req.TargetUserID == userID {
+		tags["error"] = "cannot chat with yourself"
+		tags["status"] = "error"
+		return nil, domain.ErrChatSelf
+	}
+
+	if err := c.ensureActive(ctx, tags, userID, req.TargetUserID); err != nil {
+		return nil, err
+	}
+
+	conversation, err := c.chatRepo.GetOrCreateConversation(ctx, userID, req.TargetUserID)
+	if err != nil {
+		tags["error"] = "failed get or create conversation"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return conversation, nil
+}
+
+func (c chatServiceModule) GetConversations(ctx context.Context, userID int64) ([]domain.Conversation, error) {
+	ctx, span := tracing.Start(ctx, "service.chat.get_conversations")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.chat.get_conversations"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	conversations, err := c.chatRepo.GetConversationsByUserID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get conversations"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return conversations, nil
+}
+
+func (c chatServiceModule) SendMessage(ctx context.Context, userID int64, conversationID int64,
+	req domain.SendMessageRequest) (*domain.Message, error) {
+	ctx, span := tracing.Start(ctx, "service.chat.send_message")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.chat.send_message"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	conversation, err := c.getConversation(ctx, tags, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	peerID := conversation.Peer(userID)
+	if err := c.ensureActive(ctx, tags, userID, peerID); err != nil {
+		return nil, err
+	}
+
+	if err := screenContent(ctx, tags, c.contentFilter, c.contentFlagRepo, domain.FilterContent{
+		UserID: userID,
+		Kind:   domain.ContentKindMessage,
+		Text:   req.Body,
+	}); err != nil {
+		return nil, err
+	}
+
+	message, err := c.chatRepo.CreateMessage(ctx, domain.CreateMessageRequest{
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Body:           req.Body,
+	})
+	if err != nil {
+		tags["error"] = "failed create message"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	// The sender is published to as well so their other devices stay in sync.
+	event := domain.RealtimeEvent{Type: domain.RealtimeEventMessage, Data: message}
+	for _, recipientID := range []int64{peerID, userID} {
+		if err := c.realtime.Publish(ctx, recipientID, event); err != nil {
+			tags["error"] = "failed publish message"
+			tags["actual_error"] = err.Error()
+		}
+	}
+
+	c.notifyOffline(ctx, tags, userID, peerID, conversationID)
+
+	tags["status"] = "success"
+	return message, nil
+}
+
+// notifyOffline queues a message notification in the outbox for a peer who is
+// not in the app, the relay stores it and the push worker then delivers it. The
+// message is already stored so failures here are only logged.
+func (c chatServiceModule) notifyOffline(ctx context.Context, tags log.Fields, userID int64, peerID int64,
+	conversationID int64) {
+	lastActive, err := c.presenceRepo.GetLastActive(ctx, peerID)
+	if err != nil {
+		tags["warning"] = "failed get peer presence"
+	} else if domain.NewPresence(lastActive[peerID]).Online {
+		return
+	}
+
+	sender, err := c.profileRepo.GetProfile(ctx, userID)
+	if err != nil || sender == nil {
+		tags["warning"] = "failed get sender profile"
+		return
+	}
+
+	if err := c.outboxRepo.Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+		UserID:      peerID,
+		Type:        domain.NotificationTypeMessage,
+		TemplateKey: domain.NotificationTemplateMessageNew,
+		Payload: domain.NotificationPayload{
+			ActorUserID:    userID,
+			ActorName:      sender.Name,
+			ConversationID: conversationID,
+		},
+	}); err != nil {
+		tags["warning"] = "failed enqueue message notification"
+		tags["actual_error"] = err.Error()
+	}
+}
+
+func (c chatServiceModule) GetMessages(ctx context.Context, userID int64, conversationID int64,
+	req domain.MessageHistoryRequest) (*domain.MessagePage, error) {
+	ctx, span := tracing.Start(ctx, "service.chat.get_messages")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.chat.get_messages"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	conversation, err := c.getConversation(ctx, tags, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultMessagePageSize
+	}
+
+	messages, err := c.chatRepo.GetMessages(ctx, conversationID, req.Before, limit)
+	if err != nil {
+		tags["error"] = "failed get messages"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	page := &domain.MessagePage{Messages: messages}
+	if len(messages) == limit {
+		page.NextCursor = messages[len(messages)-1].ID
+	}
+
+	// Loading history counts as delivery for anything the peer sent that was still pending.
+	pending := lo.Filter(messages, func(m domain.Message, _ int) bool {
+		return m.SenderID != userID && m.Status == domain.MessageStatusSent
+	})
+	if len(pending) > 0 {
+		if err := c.markReceipts(ctx, tags, conversation, userID, pending[0].ID, domain.MessageStatusDelivered); err != nil {
+			return nil, err
+		}
+	}
+
+	tags["status"] = "success"
+	return page, nil
+}
+
+func (c chatServiceModule) UpdateReceipt(ctx context.Context, userID int64, conversationID int64, req domain.ReceiptRequest) error {
+	ctx, span := tracing.Start(ctx, "service.chat.update_receipt")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.chat.update_receipt"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	conversation, err := c.getConversation(ctx, tags, userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.markReceipts(ctx, tags, conversation, userID, req.MessageID, req.Status); err != nil {
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+// SetTyping relays a typing indicator to the peer, nothing is stored. Clients should resend it every few seconds
+// while the user keeps typing and treat it as stopped when it goes quiet.
+func (c chatServiceModule) SetTyping(ctx context.Context, userID int64, conversationID int64, req domain.TypingRequest) error {
+	ctx, span := tracing.Start(ctx, "service.chat.set_typing")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.chat.set_typing"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	conversation, err := c.getConversation(ctx, tags, userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	event := domain.RealtimeEvent{
+		Type: domain.RealtimeEventTyping,
+		Data: domain.TypingEvent{
+			ConversationID: conversationID,
+			UserID:         userID,
+			Typing:         req.Typing,
+		},
+	}
+	if err := c.realtime.Publish(ctx, conversation.Peer(userID), event); err != nil {
+		tags["error"] = "failed publish typing"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (c chatServiceModule) getConversation(ctx context.Context, tags log.Fields, userID int64,
+	conversationID int64) (*domain.Conversation, error) {
+	tags["conversation_id"] = conversationID
+
+	conversation, err := c.chatRepo.GetConversation(ctx, conversationID)
+	if err != nil {
+		tags["error"] = "failed get conversation"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if conversation == nil || conversation.Peer(userID) == 0 {
+		tags["error"] = "conversation not found"
+		tags["status"] = "error"
+		return nil, domain.ErrConversationNotFound
+	}
+
+	return conversation, nil
+}
+
+// ensureActive allows chatting only while both users still match each other and neither has
+// blocked the other.
+func (c chatServiceModule) ensureActive(ctx context.Context, tags log.Fields, userID int64, peerID int64) error {
+	mutual, err := c.matchedRepo.IsMutual(ctx, domain.MatchRequest{
+		UserID:       userID,
+		TargetUserID: peerID,
+	})
+	if err != nil {
+		tags["error"] = "failed check match"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if !mutual {
+		tags["error"] = "match is not active"
+		tags["status"] = "error"
+		return domain.ErrMatchNotActive
+	}
+
+	blockedIDs, err := c.blockRepo.GetBlockedUserIDs(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get blocked ids"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if lo.Contains(blockedIDs, peerID) {
+		tags["error"] = "match is not active"
+		tags["actual_error"] = "blocked"
+		tags["status"] = "error"
+		return domain.ErrMatchNotActive
+	}
+
+	return nil
+}
+
+func (c chatServiceModule) markReceipts(ctx context.Context, tags log.Fields, conversation *domain.Conversation,
+	userID int64, upToMessageID int64, status string) error {
+	updated, err := c.chatRepo.UpdateReceipts(ctx, conversation.ID, userID, upToMessageID, status)
+	if err != nil {
+		tags["error"] = "failed update receipts"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if updated == 0 {
+		return nil
+	}
+
+	receipt := domain.Receipt{
+		ConversationID: conversation.ID,
+		UserID:         userID,
+		Status:         status,
+		MessageID:      upToMessageID,
+	}
+	if err := c.realtime.Publish(ctx, conversation.Peer(userID),
+		domain.RealtimeEvent{Type: domain.RealtimeEventReceipt, Data: receipt}); err != nil {
+		tags["error"] = "failed publish receipt"
+		tags["actual_error"] = err.Error()
+	}
+
+	return nil
+}
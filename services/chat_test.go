@@ -0,0 +1,510 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+	"time"
+)
+
+func Test_chatServiceModule_OpenConversation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	req := domain.OpenConversationRequest{TargetUserID: 2}
+	match := domain.MatchRequest{UserID: 1, TargetUserID: 2}
+
+	tests := []struct {
+		name    string
+		req     domain.OpenConversationRequest
+		mock    func() *chatServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed chatting with yourself",
+			req:  domain.OpenConversationRequest{TargetUserID: 1},
+			mock: func() *chatServiceModule {
+				return &chatServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed match not mutual",
+			req:  req,
+			mock: func() *chatServiceModule {
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsMutual(ctx, match).Return(false, nil)
+
+				return &chatServiceModule{matchedRepo: matchedMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed user blocked",
+			req:  req,
+			mock: func() *chatServiceModule {
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsMutual(ctx, match).Return(true, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return([]int64{2}, nil)
+
+				return &chatServiceModule{matchedRepo: matchedMock, blockRepo: blockMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			req:  req,
+			mock: func() *chatServiceModule {
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsMutual(ctx, match).Return(true, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return(nil, nil)
+
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetOrCreateConversation(ctx, int64(1), int64(2)).
+					Return(&domain.Conversation{ID: 1, UserAID: 1, UserBID: 2}, nil)
+
+				return &chatServiceModule{matchedRepo: matchedMock, blockRepo: blockMock, chatRepo: chatMock}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.mock()
+			_, err := c.OpenConversation(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OpenConversation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_chatServiceModule_SendMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	req := domain.SendMessageRequest{Body: "hello"}
+	conversation := &domain.Conversation{ID: 1, UserAID: 1, UserBID: 2}
+
+	tests := []struct {
+		name    string
+		req     domain.SendMessageRequest
+		mock    func() *chatServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating empty body",
+			req:  domain.SendMessageRequest{},
+			mock: func() *chatServiceModule {
+				return &chatServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed not a participant",
+			req:  req,
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).
+					Return(&domain.Conversation{ID: 1, UserAID: 2, UserBID: 3}, nil)
+
+				return &chatServiceModule{chatRepo: chatMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed match no longer active",
+			req:  req,
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsMutual(ctx, domain.MatchRequest{UserID: 1, TargetUserID: 2}).Return(false, nil)
+
+				return &chatServiceModule{chatRepo: chatMock, matchedRepo: matchedMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed content blocked",
+			req:  domain.SendMessageRequest{Body: "call me 0812 3456 7890"},
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsMutual(ctx, domain.MatchRequest{UserID: 1, TargetUserID: 2}).Return(true, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return(nil, nil)
+
+				content := domain.FilterContent{UserID: 1, Kind: domain.ContentKindMessage, Text: "call me 0812 3456 7890"}
+				result := domain.FilterResult{
+					Verdict: domain.FilterVerdictBlock,
+					Filter:  "contact_details",
+					Reason:  "contains a phone number",
+				}
+
+				filterMock := NewMockContentFilterInterface(ctrl)
+				filterMock.EXPECT().Check(ctx, content).Return(result, nil)
+
+				contentFlagMock := NewMockContentFlagRepoInterface(ctrl)
+				contentFlagMock.EXPECT().Create(ctx, content, result).Return(nil)
+
+				return &chatServiceModule{
+					chatRepo:        chatMock,
+					matchedRepo:     matchedMock,
+					blockRepo:       blockMock,
+					contentFilter:   filterMock,
+					contentFlagRepo: contentFlagMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success publishes to both users",
+			req:  req,
+			mock: func() *chatServiceModule {
+				message := &domain.Message{ID: 10, ConversationID: 1, SenderID: 1, Body: "hello",
+					Status: domain.MessageStatusSent}
+
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+				chatMock.EXPECT().CreateMessage(ctx, domain.CreateMessageRequest{
+					ConversationID: 1,
+					SenderID:       1,
+					Body:           "hello",
+				}).Return(message, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsMutual(ctx, domain.MatchRequest{UserID: 1, TargetUserID: 2}).Return(true, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return(nil, nil)
+
+				filterMock := NewMockContentFilterInterface(ctrl)
+				filterMock.EXPECT().Check(ctx, domain.FilterContent{
+					UserID: 1,
+					Kind:   domain.ContentKindMessage,
+					Text:   "hello",
+				}).Return(domain.FilterResult{Verdict: domain.FilterVerdictAllow}, nil)
+
+				event := domain.RealtimeEvent{Type: domain.RealtimeEventMessage, Data: message}
+				realtimeMock := NewMockRealtimePublisherInterface(ctrl)
+				realtimeMock.EXPECT().Publish(ctx, int64(2), event).Return(nil)
+				realtimeMock.EXPECT().Publish(ctx, int64(1), event).Return(nil)
+
+				presenceMock := NewMockPresenceRepoInterface(ctrl)
+				presenceMock.EXPECT().GetLastActive(ctx, int64(2)).Return(map[int64]time.Time{2: time.Now()}, nil)
+
+				return &chatServiceModule{
+					chatRepo:      chatMock,
+					matchedRepo:   matchedMock,
+					blockRepo:     blockMock,
+					realtime:      realtimeMock,
+					contentFilter: filterMock,
+					presenceRepo:  presenceMock,
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "success notifies offline peer",
+			req:  req,
+			mock: func() *chatServiceModule {
+				message := &domain.Message{ID: 10, ConversationID: 1, SenderID: 1, Body: "hello",
+					Status: domain.MessageStatusSent}
+
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+				chatMock.EXPECT().CreateMessage(ctx, domain.CreateMessageRequest{
+					ConversationID: 1,
+					SenderID:       1,
+					Body:           "hello",
+				}).Return(message, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsMutual(ctx, domain.MatchRequest{UserID: 1, TargetUserID: 2}).Return(true, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return(nil, nil)
+
+				filterMock := NewMockContentFilterInterface(ctrl)
+				filterMock.EXPECT().Check(ctx, domain.FilterContent{
+					UserID: 1,
+					Kind:   domain.ContentKindMessage,
+					Text:   "hello",
+				}).Return(domain.FilterResult{Verdict: domain.FilterVerdictAllow}, nil)
+
+				event := domain.RealtimeEvent{Type: domain.RealtimeEventMessage, Data: message}
+				realtimeMock := NewMockRealtimePublisherInterface(ctrl)
+				realtimeMock.EXPECT().Publish(ctx, int64(2), event).Return(nil)
+				realtimeMock.EXPECT().Publish(ctx, int64(1), event).Return(nil)
+
+				presenceMock := NewMockPresenceRepoInterface(ctrl)
+				presenceMock.EXPECT().GetLastActive(ctx, int64(2)).
+					Return(map[int64]time.Time{2: time.Now().Add(-time.Hour)}, nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(&domain.Profile{UserID: 1, Name: "Alice"}, nil)
+
+				// A failing notification does not fail the send, the message is already stored.
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+					UserID:      2,
+					Type:        domain.NotificationTypeMessage,
+					TemplateKey: domain.NotificationTemplateMessageNew,
+					Payload:     domain.NotificationPayload{ActorUserID: 1, ActorName: "Alice", ConversationID: 1},
+				}).Return(errors.New("test"))
+
+				return &chatServiceModule{
+					chatRepo:      chatMock,
+					matchedRepo:   matchedMock,
+					blockRepo:     blockMock,
+					realtime:      realtimeMock,
+					contentFilter: filterMock,
+					presenceRepo:  presenceMock,
+					profileRepo:   profileMock,
+					outboxRepo:    outboxMock,
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.mock()
+			_, err := c.SendMessage(ctx, 1, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SendMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_chatServiceModule_GetMessages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	conversation := &domain.Conversation{ID: 1, UserAID: 1, UserBID: 2}
+
+	tests := []struct {
+		name       string
+		req        domain.MessageHistoryRequest
+		mock       func() *chatServiceModule
+		wantCursor int64
+		wantErr    bool
+	}{
+		{
+			name: "failed get messages",
+			req:  domain.MessageHistoryRequest{},
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+				chatMock.EXPECT().GetMessages(ctx, int64(1), int64(0), defaultMessagePageSize).
+					Return(nil, errors.New("error"))
+
+				return &chatServiceModule{chatRepo: chatMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success full page marks peer messages delivered",
+			req:  domain.MessageHistoryRequest{Before: 20, Limit: 2},
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+				chatMock.EXPECT().GetMessages(ctx, int64(1), int64(20), 2).Return([]domain.Message{
+					{ID: 12, SenderID: 2, Status: domain.MessageStatusSent},
+					{ID: 11, SenderID: 1, Status: domain.MessageStatusSent},
+				}, nil)
+				chatMock.EXPECT().UpdateReceipts(ctx, int64(1), int64(1), int64(12), domain.MessageStatusDelivered).
+					Return(int64(1), nil)
+
+				realtimeMock := NewMockRealtimePublisherInterface(ctrl)
+				realtimeMock.EXPECT().Publish(ctx, int64(2), domain.RealtimeEvent{
+					Type: domain.RealtimeEventReceipt,
+					Data: domain.Receipt{
+						ConversationID: 1,
+						UserID:         1,
+						Status:         domain.MessageStatusDelivered,
+						MessageID:      12,
+					},
+				}).Return(nil)
+
+				return &chatServiceModule{chatRepo: chatMock, realtime: realtimeMock}
+			},
+			wantCursor: 11,
+			wantErr:    false,
+		},
+		{
+			name: "success last page",
+			req:  domain.MessageHistoryRequest{},
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+				chatMock.EXPECT().GetMessages(ctx, int64(1), int64(0), defaultMessagePageSize).
+					Return([]domain.Message{{ID: 1, SenderID: 2, Status: domain.MessageStatusRead}}, nil)
+
+				return &chatServiceModule{chatRepo: chatMock}
+			},
+			wantCursor: 0,
+			wantErr:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.mock()
+			got, err := c.GetMessages(ctx, 1, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetMessages() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && got.NextCursor != tt.wantCursor {
+				t.Errorf("GetMessages() NextCursor = %v, want %v", got.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func Test_chatServiceModule_UpdateReceipt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	conversation := &domain.Conversation{ID: 1, UserAID: 1, UserBID: 2}
+
+	tests := []struct {
+		name    string
+		req     domain.ReceiptRequest
+		mock    func() *chatServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating status",
+			req:  domain.ReceiptRequest{Status: domain.MessageStatusSent, MessageID: 1},
+			mock: func() *chatServiceModule {
+				return &chatServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed conversation not found",
+			req:  domain.ReceiptRequest{Status: domain.MessageStatusRead, MessageID: 1},
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(nil, nil)
+
+				return &chatServiceModule{chatRepo: chatMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success nothing to update",
+			req:  domain.ReceiptRequest{Status: domain.MessageStatusRead, MessageID: 5},
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+				chatMock.EXPECT().UpdateReceipts(ctx, int64(1), int64(1), int64(5), domain.MessageStatusRead).
+					Return(int64(0), nil)
+
+				return &chatServiceModule{chatRepo: chatMock}
+			},
+			wantErr: false,
+		},
+		{
+			name: "success",
+			req:  domain.ReceiptRequest{Status: domain.MessageStatusRead, MessageID: 5},
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).Return(conversation, nil)
+				chatMock.EXPECT().UpdateReceipts(ctx, int64(1), int64(1), int64(5), domain.MessageStatusRead).
+					Return(int64(3), nil)
+
+				realtimeMock := NewMockRealtimePublisherInterface(ctrl)
+				realtimeMock.EXPECT().Publish(ctx, int64(2), gomock.Any()).Return(nil)
+
+				return &chatServiceModule{chatRepo: chatMock, realtime: realtimeMock}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.mock()
+			if err := c.UpdateReceipt(ctx, 1, 1, tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("UpdateReceipt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_chatServiceModule_SetTyping(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() *chatServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed not a participant",
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).
+					Return(&domain.Conversation{ID: 1, UserAID: 2, UserBID: 3}, nil)
+
+				return &chatServiceModule{chatRepo: chatMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mock: func() *chatServiceModule {
+				chatMock := NewMockChatRepoInterface(ctrl)
+				chatMock.EXPECT().GetConversation(ctx, int64(1)).
+					Return(&domain.Conversation{ID: 1, UserAID: 1, UserBID: 2}, nil)
+
+				realtimeMock := NewMockRealtimePublisherInterface(ctrl)
+				realtimeMock.EXPECT().Publish(ctx, int64(2), domain.RealtimeEvent{
+					Type: domain.RealtimeEventTyping,
+					Data: domain.TypingEvent{ConversationID: 1, UserID: 1, Typing: true},
+				}).Return(nil)
+
+				return &chatServiceModule{chatRepo: chatMock, realtime: realtimeMock}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.mock()
+			if err := c.SetTyping(ctx, 1, 1, domain.TypingRequest{Typing: true}); (err != nil) != tt.wantErr {
+				t.Errorf("SetTyping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+)
+
+// screenContent runs the filter chain over user generated text. Flagged and blocked content is stored for
+// moderators, only blocked content is rejected.
+func screenContent(ctx context.Context, tags log.Fields, contentFilter ContentFilterInterface,
+	contentFlagRepo ContentFlagRepoInterface, contents ...domain.FilterContent) error {
+	for _, content := range contents {
+		if content.Text == "" {
+			continue
+		}
+
+		result, err := contentFilter.Check(ctx, content)
+		if err != nil {
+			tags["error"] = "failed to check content"
+			tags["actual_error"] = err.Error()
+			tags["status"] = "error"
+			return err
+		}
+
+		if result.Verdict == domain.FilterVerdictAllow {
+			continue
+		}
+
+		if err := contentFlagRepo.Create(ctx, content, result); err != nil {
+			tags["error"] = "failed to store content flag"
+			tags["actual_error"] = err.Error()
+			tags["status"] = "error"
+			return err
+		}
+
+		tags["content_verdict"] = result.Verdict
+		tags["content_filter"] = result.Filter
+
+		if result.Blocked() {
+			tags["error"] = "content rejected"
+			tags["actual_error"] = result.Reason
+			tags["status"] = "error"
+			return domain.ErrContentRejected.WithDetails(map[string]string{"field": content.Kind, "reason": result.Reason})
+		}
+	}
+
+	return nil
+}
@@ -3,12 +3,45 @@ package services
 import (
 	"context"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"time"
 )
 
 type AuthRepoInterface interface {
 	Login(ctx context.Context, req domain.AuthRequest) (user domain.User, err error)
 	Register(ctx context.Context, req domain.AuthRequest) (domain.User, error)
 	UpdateToken(ctx context.Context, userID int64, req domain.UpdateTokenRequest) error
+	GetByID(ctx context.Context, userID int64) (*domain.User, error)
+	GetAccessToken(ctx context.Context, userID int64) (string, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetByPhone(ctx context.Context, phone string) (*domain.User, error)
+	RegisterPhone(ctx context.Context, phone string) (domain.User, error)
+	GetIdentity(ctx context.Context, provider, subject string) (*domain.UserIdentity, error)
+	CreateIdentity(ctx context.Context, req domain.CreateIdentityRequest) error
+	GetTOTP(ctx context.Context, userID int64) (*domain.UserTOTP, error)
+	SetTOTPSecret(ctx context.Context, userID int64, secret string) error
+	EnableTOTP(ctx context.Context, userID int64, recoveryCodeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, code string) (bool, error)
+	UseTOTPCounter(ctx context.Context, userID int64, counter int64) (bool, error)
+	GetTwoFactorStatus(ctx context.Context, userID int64) (*domain.TwoFactorStatus, error)
+	GetIdentitiesByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error)
+	SoftDelete(ctx context.Context, userID int64) error
+	GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.User, error)
+	Purge(ctx context.Context, userID int64) error
+	Search(ctx context.Context, req domain.UserSearchRequest) ([]domain.User, error)
+	SetBanned(ctx context.Context, userID int64, bannedAt *time.Time) error
+}
+
+type IdentityProviderInterface interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (domain.IdentityClaims, error)
+}
+
+type OTPVerifierInterface interface {
+	Verify(ctx context.Context, phone, code string) error
+}
+
+type SMSSenderInterface interface {
+	Send(ctx context.Context, phone, message string) error
 }
 
 type ProfileRepoInterface interface {
@@ -16,16 +49,22 @@ type ProfileRepoInterface interface {
 	UpdateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error
 	UpdateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error
 	GetProfile(ctx context.Context, userID int64) (profile *domain.Profile, err error)
-	GetProfileRecommendation(ctx context.Context, interest string, notInUserID []int64) (profiles []domain.Profile, err error)
+	GetProfileRecommendation(ctx context.Context, interest string, notInUserID []int64, limit int) (profiles []domain.Profile, err error)
+	SoftDeleteByUserID(ctx context.Context, userID int64) error
+	PurgeByUserID(ctx context.Context, userID int64) error
+	SetHidden(ctx context.Context, userID int64, hiddenAt *time.Time) error
+	UpdatePrivacy(ctx context.Context, userID int64, showLastActive bool) error
 }
 
 type RedisRepoInterface interface {
 	Get(ctx context.Context, key string) (string, error)
+	GetDel(ctx context.Context, key string) (string, error)
 	GetValues(ctx context.Context, key string) ([]string, error)
 	Set(ctx context.Context, key string, value interface{}, expiration int) error
 	Expire(ctx context.Context, key string, expiration int) error
-	Incr(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string) (int64, error)
 	Exists(ctx context.Context, keys ...string) (bool, error)
+	Del(ctx context.Context, keys ...string) error
 }
 
 type InventoryRepoInterface interface {
@@ -34,16 +73,130 @@ type InventoryRepoInterface interface {
 	UpdateLikes(ctx context.Context, userID int64, likes int) error
 	UpdateSuperLikes(ctx context.Context, userID int64, superLikes int) error
 	UpdateSwipes(ctx context.Context, userID int64, swipes int) error
+	SpendSwipe(ctx context.Context, userID int64) (bool, error)
+	SpendLike(ctx context.Context, userID int64) (bool, error)
+	SpendSuperLike(ctx context.Context, userID int64) (bool, error)
+	PurgeByUserID(ctx context.Context, userID int64) error
 }
 
 type MatchedRepoInterface interface {
-	Create(ctx context.Context, req domain.MatchRequest) error
+	Create(ctx context.Context, req domain.MatchRequest) (int64, error)
 	IsMatched(ctx context.Context, req domain.MatchRequest) (bool, error)
 	IsExists(ctx context.Context, req domain.MatchRequest) (bool, error)
+	GetAllByUserID(ctx context.Context, userID int64) ([]domain.Matched, error)
+	PurgeByUserID(ctx context.Context, userID int64) error
+	IsMutual(ctx context.Context, req domain.MatchRequest) (bool, error)
+	Unmatch(ctx context.Context, req domain.MatchRequest) error
+	GetStaleMatches(ctx context.Context, filter domain.StaleMatchFilter) ([]domain.StaleMatch, error)
+	ClaimReminder(ctx context.Context, match domain.StaleMatch, stage int) (bool, error)
 }
 
 type NotificationRepoInterface interface {
 	Create(ctx context.Context, req domain.NotificationRequest) error
 	GetAllByUserId(ctx context.Context, userID int64) ([]domain.Notification, error)
+	GetByFilter(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, error)
 	SetRead(ctx context.Context, notificationID int64) error
+	PurgeByUserID(ctx context.Context, userID int64) error
+	GetByID(ctx context.Context, notificationID int64) (*domain.Notification, error)
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.PushOutbox, error)
+	UpdateOutbox(ctx context.Context, outboxID int64, update domain.PushOutboxUpdate) error
+	GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, prefs domain.NotificationPreferences) error
+}
+
+type DeviceRepoInterface interface {
+	Register(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error
+	GetByUserID(ctx context.Context, userID int64) ([]domain.DeviceToken, error)
+	Unregister(ctx context.Context, userID int64, token string) error
+	DeleteByToken(ctx context.Context, token string) error
+	PurgeByUserID(ctx context.Context, userID int64) error
+}
+
+type PushSenderInterface interface {
+	Send(ctx context.Context, msg domain.PushMessage) error
+}
+
+type UnitOfWorkInterface interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type OutboxRepoInterface interface {
+	Enqueue(ctx context.Context, kind string, payload interface{}) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	Update(ctx context.Context, messageID int64, update domain.OutboxUpdate) error
+}
+
+type EventPublisherInterface interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+type AuditRepoInterface interface {
+	Create(ctx context.Context, req domain.AuditLogRequest) error
+	GetAll(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error)
+}
+
+type ReportRepoInterface interface {
+	Create(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error)
+	GetByID(ctx context.Context, reportID int64) (*domain.Report, error)
+	GetAll(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error)
+	GetByReporterID(ctx context.Context, reporterID int64) ([]domain.Report, error)
+	UpdateStatus(ctx context.Context, reportID int64, reviewerID int64, req domain.ReviewReportRequest) error
+	CountPendingReporters(ctx context.Context, reportedUserID int64) (int64, error)
+}
+
+type BlockRepoInterface interface {
+	Create(ctx context.Context, blockerID int64, blockedID int64) error
+	GetBlockedUserIDs(ctx context.Context, userID int64) ([]int64, error)
+	GetByBlockerID(ctx context.Context, blockerID int64) ([]domain.UserBlock, error)
+	PurgeByUserID(ctx context.Context, userID int64) error
+}
+
+type ChatRepoInterface interface {
+	GetOrCreateConversation(ctx context.Context, userID int64, targetUserID int64) (*domain.Conversation, error)
+	GetConversation(ctx context.Context, conversationID int64) (*domain.Conversation, error)
+	GetConversationsByUserID(ctx context.Context, userID int64) ([]domain.Conversation, error)
+	CreateMessage(ctx context.Context, req domain.CreateMessageRequest) (*domain.Message, error)
+	GetMessages(ctx context.Context, conversationID int64, before int64, limit int) ([]domain.Message, error)
+	UpdateReceipts(ctx context.Context, conversationID int64, recipientID int64, upToMessageID int64,
+		status string) (int64, error)
+	GetMessagesBySenderID(ctx context.Context, senderID int64) ([]domain.Message, error)
+	PurgeByUserID(ctx context.Context, userID int64) error
+}
+
+type ContentFilterInterface interface {
+	Check(ctx context.Context, content domain.FilterContent) (domain.FilterResult, error)
+}
+
+type ContentFlagRepoInterface interface {
+	Create(ctx context.Context, content domain.FilterContent, result domain.FilterResult) error
+	GetAll(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error)
+	GetAllByUserID(ctx context.Context, userID int64) ([]domain.ContentFlag, error)
+	PurgeByUserID(ctx context.Context, userID int64) error
+}
+
+type PresenceRepoInterface interface {
+	Touch(ctx context.Context, userID int64) error
+	GetLastActive(ctx context.Context, userIDs ...int64) (map[int64]time.Time, error)
+}
+
+type RealtimePublisherInterface interface {
+	Publish(ctx context.Context, userID int64, event domain.RealtimeEvent) error
+}
+
+// SettingsInterface reads runtime settings. Values fall back to their defaults, so reads never fail.
+type SettingsInterface interface {
+	Int(ctx context.Context, key string) int
+	Bool(ctx context.Context, key string) bool
+	String(ctx context.Context, key string) string
+}
+
+type SettingsRepoInterface interface {
+	GetAll(ctx context.Context) ([]domain.Setting, error)
+	Set(ctx context.Context, key string, value string, updatedBy int64) error
+}
+
+// ExperimentsInterface evaluates experiments and feature flags for the user of the request context.
+type ExperimentsInterface interface {
+	Variant(ctx context.Context, experiment string) string
+	Enabled(ctx context.Context, flag string) bool
 }
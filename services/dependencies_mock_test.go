@@ -7,6 +7,7 @@ package services
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/zombozo12/tinder-dealls/domain"
@@ -35,6 +36,169 @@ func (m *MockAuthRepoInterface) EXPECT() *MockAuthRepoInterfaceMockRecorder {
 	return m.recorder
 }
 
+// CreateIdentity mocks base method.
+func (m *MockAuthRepoInterface) CreateIdentity(ctx context.Context, req domain.CreateIdentityRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdentity", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdentity indicates an expected call of CreateIdentity.
+func (mr *MockAuthRepoInterfaceMockRecorder) CreateIdentity(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdentity", reflect.TypeOf((*MockAuthRepoInterface)(nil).CreateIdentity), ctx, req)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockAuthRepoInterface) EnableTOTP(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, userID, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockAuthRepoInterfaceMockRecorder) EnableTOTP(ctx, userID, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockAuthRepoInterface)(nil).EnableTOTP), ctx, userID, recoveryCodeHashes)
+}
+
+// GetAccessToken mocks base method.
+func (m *MockAuthRepoInterface) GetAccessToken(ctx context.Context, userID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessToken", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessToken indicates an expected call of GetAccessToken.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetAccessToken(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessToken", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetAccessToken), ctx, userID)
+}
+
+// GetByEmail mocks base method.
+func (m *MockAuthRepoInterface) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetByEmail), ctx, email)
+}
+
+// GetByID mocks base method.
+func (m *MockAuthRepoInterface) GetByID(ctx context.Context, userID int64) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, userID)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetByID), ctx, userID)
+}
+
+// GetByPhone mocks base method.
+func (m *MockAuthRepoInterface) GetByPhone(ctx context.Context, phone string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPhone", ctx, phone)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPhone indicates an expected call of GetByPhone.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetByPhone(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPhone", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetByPhone), ctx, phone)
+}
+
+// GetDeletedBefore mocks base method.
+func (m *MockAuthRepoInterface) GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedBefore", ctx, before, limit)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedBefore indicates an expected call of GetDeletedBefore.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetDeletedBefore(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedBefore", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetDeletedBefore), ctx, before, limit)
+}
+
+// GetIdentitiesByUserID mocks base method.
+func (m *MockAuthRepoInterface) GetIdentitiesByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdentitiesByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentitiesByUserID indicates an expected call of GetIdentitiesByUserID.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetIdentitiesByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentitiesByUserID", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetIdentitiesByUserID), ctx, userID)
+}
+
+// GetIdentity mocks base method.
+func (m *MockAuthRepoInterface) GetIdentity(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdentity", ctx, provider, subject)
+	ret0, _ := ret[0].(*domain.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentity indicates an expected call of GetIdentity.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetIdentity(ctx, provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentity", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetIdentity), ctx, provider, subject)
+}
+
+// GetTOTP mocks base method.
+func (m *MockAuthRepoInterface) GetTOTP(ctx context.Context, userID int64) (*domain.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(*domain.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetTOTP), ctx, userID)
+}
+
+// GetTwoFactorStatus mocks base method.
+func (m *MockAuthRepoInterface) GetTwoFactorStatus(ctx context.Context, userID int64) (*domain.TwoFactorStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorStatus", ctx, userID)
+	ret0, _ := ret[0].(*domain.TwoFactorStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactorStatus indicates an expected call of GetTwoFactorStatus.
+func (mr *MockAuthRepoInterfaceMockRecorder) GetTwoFactorStatus(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorStatus", reflect.TypeOf((*MockAuthRepoInterface)(nil).GetTwoFactorStatus), ctx, userID)
+}
+
 // Login mocks base method.
 func (m *MockAuthRepoInterface) Login(ctx context.Context, req domain.AuthRequest) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +214,20 @@ func (mr *MockAuthRepoInterfaceMockRecorder) Login(ctx, req interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthRepoInterface)(nil).Login), ctx, req)
 }
 
+// Purge mocks base method.
+func (m *MockAuthRepoInterface) Purge(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockAuthRepoInterfaceMockRecorder) Purge(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockAuthRepoInterface)(nil).Purge), ctx, userID)
+}
+
 // Register mocks base method.
 func (m *MockAuthRepoInterface) Register(ctx context.Context, req domain.AuthRequest) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +243,78 @@ func (mr *MockAuthRepoInterfaceMockRecorder) Register(ctx, req interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthRepoInterface)(nil).Register), ctx, req)
 }
 
+// RegisterPhone mocks base method.
+func (m *MockAuthRepoInterface) RegisterPhone(ctx context.Context, phone string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterPhone", ctx, phone)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterPhone indicates an expected call of RegisterPhone.
+func (mr *MockAuthRepoInterfaceMockRecorder) RegisterPhone(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterPhone", reflect.TypeOf((*MockAuthRepoInterface)(nil).RegisterPhone), ctx, phone)
+}
+
+// Search mocks base method.
+func (m *MockAuthRepoInterface) Search(ctx context.Context, req domain.UserSearchRequest) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, req)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockAuthRepoInterfaceMockRecorder) Search(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockAuthRepoInterface)(nil).Search), ctx, req)
+}
+
+// SetBanned mocks base method.
+func (m *MockAuthRepoInterface) SetBanned(ctx context.Context, userID int64, bannedAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBanned", ctx, userID, bannedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBanned indicates an expected call of SetBanned.
+func (mr *MockAuthRepoInterfaceMockRecorder) SetBanned(ctx, userID, bannedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBanned", reflect.TypeOf((*MockAuthRepoInterface)(nil).SetBanned), ctx, userID, bannedAt)
+}
+
+// SetTOTPSecret mocks base method.
+func (m *MockAuthRepoInterface) SetTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockAuthRepoInterfaceMockRecorder) SetTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockAuthRepoInterface)(nil).SetTOTPSecret), ctx, userID, secret)
+}
+
+// SoftDelete mocks base method.
+func (m *MockAuthRepoInterface) SoftDelete(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockAuthRepoInterfaceMockRecorder) SoftDelete(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockAuthRepoInterface)(nil).SoftDelete), ctx, userID)
+}
+
 // UpdateToken mocks base method.
 func (m *MockAuthRepoInterface) UpdateToken(ctx context.Context, userID int64, req domain.UpdateTokenRequest) error {
 	m.ctrl.T.Helper()
@@ -79,6 +329,163 @@ func (mr *MockAuthRepoInterfaceMockRecorder) UpdateToken(ctx, userID, req interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateToken", reflect.TypeOf((*MockAuthRepoInterface)(nil).UpdateToken), ctx, userID, req)
 }
 
+// UseRecoveryCode mocks base method.
+func (m *MockAuthRepoInterface) UseRecoveryCode(ctx context.Context, userID int64, code string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, code)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockAuthRepoInterfaceMockRecorder) UseRecoveryCode(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockAuthRepoInterface)(nil).UseRecoveryCode), ctx, userID, code)
+}
+
+// UseTOTPCounter mocks base method.
+func (m *MockAuthRepoInterface) UseTOTPCounter(ctx context.Context, userID, counter int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPCounter", ctx, userID, counter)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPCounter indicates an expected call of UseTOTPCounter.
+func (mr *MockAuthRepoInterfaceMockRecorder) UseTOTPCounter(ctx, userID, counter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPCounter", reflect.TypeOf((*MockAuthRepoInterface)(nil).UseTOTPCounter), ctx, userID, counter)
+}
+
+// MockIdentityProviderInterface is a mock of IdentityProviderInterface interface.
+type MockIdentityProviderInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityProviderInterfaceMockRecorder
+}
+
+// MockIdentityProviderInterfaceMockRecorder is the mock recorder for MockIdentityProviderInterface.
+type MockIdentityProviderInterfaceMockRecorder struct {
+	mock *MockIdentityProviderInterface
+}
+
+// NewMockIdentityProviderInterface creates a new mock instance.
+func NewMockIdentityProviderInterface(ctrl *gomock.Controller) *MockIdentityProviderInterface {
+	mock := &MockIdentityProviderInterface{ctrl: ctrl}
+	mock.recorder = &MockIdentityProviderInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityProviderInterface) EXPECT() *MockIdentityProviderInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockIdentityProviderInterface) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", ctx, state, nonce, codeChallenge)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockIdentityProviderInterfaceMockRecorder) AuthCodeURL(ctx, state, nonce, codeChallenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockIdentityProviderInterface)(nil).AuthCodeURL), ctx, state, nonce, codeChallenge)
+}
+
+// Exchange mocks base method.
+func (m *MockIdentityProviderInterface) Exchange(ctx context.Context, code, codeVerifier, nonce string) (domain.IdentityClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exchange", ctx, code, codeVerifier, nonce)
+	ret0, _ := ret[0].(domain.IdentityClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exchange indicates an expected call of Exchange.
+func (mr *MockIdentityProviderInterfaceMockRecorder) Exchange(ctx, code, codeVerifier, nonce interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockIdentityProviderInterface)(nil).Exchange), ctx, code, codeVerifier, nonce)
+}
+
+// MockOTPVerifierInterface is a mock of OTPVerifierInterface interface.
+type MockOTPVerifierInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPVerifierInterfaceMockRecorder
+}
+
+// MockOTPVerifierInterfaceMockRecorder is the mock recorder for MockOTPVerifierInterface.
+type MockOTPVerifierInterfaceMockRecorder struct {
+	mock *MockOTPVerifierInterface
+}
+
+// NewMockOTPVerifierInterface creates a new mock instance.
+func NewMockOTPVerifierInterface(ctrl *gomock.Controller) *MockOTPVerifierInterface {
+	mock := &MockOTPVerifierInterface{ctrl: ctrl}
+	mock.recorder = &MockOTPVerifierInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPVerifierInterface) EXPECT() *MockOTPVerifierInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockOTPVerifierInterface) Verify(ctx context.Context, phone, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, phone, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockOTPVerifierInterfaceMockRecorder) Verify(ctx, phone, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockOTPVerifierInterface)(nil).Verify), ctx, phone, code)
+}
+
+// MockSMSSenderInterface is a mock of SMSSenderInterface interface.
+type MockSMSSenderInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSMSSenderInterfaceMockRecorder
+}
+
+// MockSMSSenderInterfaceMockRecorder is the mock recorder for MockSMSSenderInterface.
+type MockSMSSenderInterfaceMockRecorder struct {
+	mock *MockSMSSenderInterface
+}
+
+// NewMockSMSSenderInterface creates a new mock instance.
+func NewMockSMSSenderInterface(ctrl *gomock.Controller) *MockSMSSenderInterface {
+	mock := &MockSMSSenderInterface{ctrl: ctrl}
+	mock.recorder = &MockSMSSenderInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSMSSenderInterface) EXPECT() *MockSMSSenderInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockSMSSenderInterface) Send(ctx context.Context, phone, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, phone, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockSMSSenderInterfaceMockRecorder) Send(ctx, phone, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSMSSenderInterface)(nil).Send), ctx, phone, message)
+}
+
 // MockProfileRepoInterface is a mock of ProfileRepoInterface interface.
 type MockProfileRepoInterface struct {
 	ctrl     *gomock.Controller
@@ -128,390 +535,1770 @@ func (m *MockProfileRepoInterface) GetProfile(ctx context.Context, userID int64)
 // GetProfile indicates an expected call of GetProfile.
 func (mr *MockProfileRepoInterfaceMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockProfileRepoInterface)(nil).GetProfile), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockProfileRepoInterface)(nil).GetProfile), ctx, userID)
+}
+
+// GetProfileRecommendation mocks base method.
+func (m *MockProfileRepoInterface) GetProfileRecommendation(ctx context.Context, interest string, notInUserID []int64, limit int) ([]domain.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfileRecommendation", ctx, interest, notInUserID, limit)
+	ret0, _ := ret[0].([]domain.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfileRecommendation indicates an expected call of GetProfileRecommendation.
+func (mr *MockProfileRepoInterfaceMockRecorder) GetProfileRecommendation(ctx, interest, notInUserID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfileRecommendation", reflect.TypeOf((*MockProfileRepoInterface)(nil).GetProfileRecommendation), ctx, interest, notInUserID, limit)
+}
+
+// PurgeByUserID mocks base method.
+func (m *MockProfileRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockProfileRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockProfileRepoInterface)(nil).PurgeByUserID), ctx, userID)
+}
+
+// SetHidden mocks base method.
+func (m *MockProfileRepoInterface) SetHidden(ctx context.Context, userID int64, hiddenAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHidden", ctx, userID, hiddenAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHidden indicates an expected call of SetHidden.
+func (mr *MockProfileRepoInterfaceMockRecorder) SetHidden(ctx, userID, hiddenAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHidden", reflect.TypeOf((*MockProfileRepoInterface)(nil).SetHidden), ctx, userID, hiddenAt)
+}
+
+// SoftDeleteByUserID mocks base method.
+func (m *MockProfileRepoInterface) SoftDeleteByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDeleteByUserID indicates an expected call of SoftDeleteByUserID.
+func (mr *MockProfileRepoInterfaceMockRecorder) SoftDeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteByUserID", reflect.TypeOf((*MockProfileRepoInterface)(nil).SoftDeleteByUserID), ctx, userID)
+}
+
+// UpdatePrivacy mocks base method.
+func (m *MockProfileRepoInterface) UpdatePrivacy(ctx context.Context, userID int64, showLastActive bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrivacy", ctx, userID, showLastActive)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrivacy indicates an expected call of UpdatePrivacy.
+func (mr *MockProfileRepoInterfaceMockRecorder) UpdatePrivacy(ctx, userID, showLastActive interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrivacy", reflect.TypeOf((*MockProfileRepoInterface)(nil).UpdatePrivacy), ctx, userID, showLastActive)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockProfileRepoInterface) UpdateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockProfileRepoInterfaceMockRecorder) UpdateProfile(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockProfileRepoInterface)(nil).UpdateProfile), ctx, userID, req)
+}
+
+// UpdateProfilePic mocks base method.
+func (m *MockProfileRepoInterface) UpdateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfilePic", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfilePic indicates an expected call of UpdateProfilePic.
+func (mr *MockProfileRepoInterfaceMockRecorder) UpdateProfilePic(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfilePic", reflect.TypeOf((*MockProfileRepoInterface)(nil).UpdateProfilePic), ctx, userID, req)
+}
+
+// MockRedisRepoInterface is a mock of RedisRepoInterface interface.
+type MockRedisRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRedisRepoInterfaceMockRecorder
+}
+
+// MockRedisRepoInterfaceMockRecorder is the mock recorder for MockRedisRepoInterface.
+type MockRedisRepoInterfaceMockRecorder struct {
+	mock *MockRedisRepoInterface
+}
+
+// NewMockRedisRepoInterface creates a new mock instance.
+func NewMockRedisRepoInterface(ctrl *gomock.Controller) *MockRedisRepoInterface {
+	mock := &MockRedisRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockRedisRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRedisRepoInterface) EXPECT() *MockRedisRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Del mocks base method.
+func (m *MockRedisRepoInterface) Del(ctx context.Context, keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Del", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Del indicates an expected call of Del.
+func (mr *MockRedisRepoInterfaceMockRecorder) Del(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockRedisRepoInterface)(nil).Del), varargs...)
+}
+
+// Exists mocks base method.
+func (m *MockRedisRepoInterface) Exists(ctx context.Context, keys ...string) (bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Exists", varargs...)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockRedisRepoInterfaceMockRecorder) Exists(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockRedisRepoInterface)(nil).Exists), varargs...)
+}
+
+// Expire mocks base method.
+func (m *MockRedisRepoInterface) Expire(ctx context.Context, key string, expiration int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", ctx, key, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockRedisRepoInterfaceMockRecorder) Expire(ctx, key, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockRedisRepoInterface)(nil).Expire), ctx, key, expiration)
+}
+
+// Get mocks base method.
+func (m *MockRedisRepoInterface) Get(ctx context.Context, key string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRedisRepoInterfaceMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRedisRepoInterface)(nil).Get), ctx, key)
+}
+
+// GetDel mocks base method.
+func (m *MockRedisRepoInterface) GetDel(ctx context.Context, key string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDel", ctx, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDel indicates an expected call of GetDel.
+func (mr *MockRedisRepoInterfaceMockRecorder) GetDel(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDel", reflect.TypeOf((*MockRedisRepoInterface)(nil).GetDel), ctx, key)
+}
+
+// GetValues mocks base method.
+func (m *MockRedisRepoInterface) GetValues(ctx context.Context, key string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValues", ctx, key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetValues indicates an expected call of GetValues.
+func (mr *MockRedisRepoInterfaceMockRecorder) GetValues(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValues", reflect.TypeOf((*MockRedisRepoInterface)(nil).GetValues), ctx, key)
+}
+
+// Incr mocks base method.
+func (m *MockRedisRepoInterface) Incr(ctx context.Context, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", ctx, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockRedisRepoInterfaceMockRecorder) Incr(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockRedisRepoInterface)(nil).Incr), ctx, key)
+}
+
+// Set mocks base method.
+func (m *MockRedisRepoInterface) Set(ctx context.Context, key string, value interface{}, expiration int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, value, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockRedisRepoInterfaceMockRecorder) Set(ctx, key, value, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRedisRepoInterface)(nil).Set), ctx, key, value, expiration)
+}
+
+// MockInventoryRepoInterface is a mock of InventoryRepoInterface interface.
+type MockInventoryRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryRepoInterfaceMockRecorder
+}
+
+// MockInventoryRepoInterfaceMockRecorder is the mock recorder for MockInventoryRepoInterface.
+type MockInventoryRepoInterfaceMockRecorder struct {
+	mock *MockInventoryRepoInterface
+}
+
+// NewMockInventoryRepoInterface creates a new mock instance.
+func NewMockInventoryRepoInterface(ctrl *gomock.Controller) *MockInventoryRepoInterface {
+	mock := &MockInventoryRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockInventoryRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInventoryRepoInterface) EXPECT() *MockInventoryRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockInventoryRepoInterface) Create(ctx context.Context, req domain.CreateInventoryRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockInventoryRepoInterfaceMockRecorder) Create(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInventoryRepoInterface)(nil).Create), ctx, req)
+}
+
+// GetByUserId mocks base method.
+func (m *MockInventoryRepoInterface) GetByUserId(ctx context.Context, userID int64) (*domain.Inventory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserId", ctx, userID)
+	ret0, _ := ret[0].(*domain.Inventory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserId indicates an expected call of GetByUserId.
+func (mr *MockInventoryRepoInterfaceMockRecorder) GetByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserId", reflect.TypeOf((*MockInventoryRepoInterface)(nil).GetByUserId), ctx, userID)
+}
+
+// PurgeByUserID mocks base method.
+func (m *MockInventoryRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockInventoryRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockInventoryRepoInterface)(nil).PurgeByUserID), ctx, userID)
+}
+
+// SpendLike mocks base method.
+func (m *MockInventoryRepoInterface) SpendLike(ctx context.Context, userID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpendLike", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SpendLike indicates an expected call of SpendLike.
+func (mr *MockInventoryRepoInterfaceMockRecorder) SpendLike(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpendLike", reflect.TypeOf((*MockInventoryRepoInterface)(nil).SpendLike), ctx, userID)
+}
+
+// SpendSuperLike mocks base method.
+func (m *MockInventoryRepoInterface) SpendSuperLike(ctx context.Context, userID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpendSuperLike", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SpendSuperLike indicates an expected call of SpendSuperLike.
+func (mr *MockInventoryRepoInterfaceMockRecorder) SpendSuperLike(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpendSuperLike", reflect.TypeOf((*MockInventoryRepoInterface)(nil).SpendSuperLike), ctx, userID)
+}
+
+// SpendSwipe mocks base method.
+func (m *MockInventoryRepoInterface) SpendSwipe(ctx context.Context, userID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpendSwipe", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SpendSwipe indicates an expected call of SpendSwipe.
+func (mr *MockInventoryRepoInterfaceMockRecorder) SpendSwipe(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpendSwipe", reflect.TypeOf((*MockInventoryRepoInterface)(nil).SpendSwipe), ctx, userID)
+}
+
+// UpdateLikes mocks base method.
+func (m *MockInventoryRepoInterface) UpdateLikes(ctx context.Context, userID int64, likes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLikes", ctx, userID, likes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLikes indicates an expected call of UpdateLikes.
+func (mr *MockInventoryRepoInterfaceMockRecorder) UpdateLikes(ctx, userID, likes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLikes", reflect.TypeOf((*MockInventoryRepoInterface)(nil).UpdateLikes), ctx, userID, likes)
+}
+
+// UpdateSuperLikes mocks base method.
+func (m *MockInventoryRepoInterface) UpdateSuperLikes(ctx context.Context, userID int64, superLikes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSuperLikes", ctx, userID, superLikes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSuperLikes indicates an expected call of UpdateSuperLikes.
+func (mr *MockInventoryRepoInterfaceMockRecorder) UpdateSuperLikes(ctx, userID, superLikes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSuperLikes", reflect.TypeOf((*MockInventoryRepoInterface)(nil).UpdateSuperLikes), ctx, userID, superLikes)
+}
+
+// UpdateSwipes mocks base method.
+func (m *MockInventoryRepoInterface) UpdateSwipes(ctx context.Context, userID int64, swipes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSwipes", ctx, userID, swipes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSwipes indicates an expected call of UpdateSwipes.
+func (mr *MockInventoryRepoInterfaceMockRecorder) UpdateSwipes(ctx, userID, swipes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSwipes", reflect.TypeOf((*MockInventoryRepoInterface)(nil).UpdateSwipes), ctx, userID, swipes)
+}
+
+// MockMatchedRepoInterface is a mock of MatchedRepoInterface interface.
+type MockMatchedRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMatchedRepoInterfaceMockRecorder
+}
+
+// MockMatchedRepoInterfaceMockRecorder is the mock recorder for MockMatchedRepoInterface.
+type MockMatchedRepoInterfaceMockRecorder struct {
+	mock *MockMatchedRepoInterface
+}
+
+// NewMockMatchedRepoInterface creates a new mock instance.
+func NewMockMatchedRepoInterface(ctrl *gomock.Controller) *MockMatchedRepoInterface {
+	mock := &MockMatchedRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockMatchedRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMatchedRepoInterface) EXPECT() *MockMatchedRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ClaimReminder mocks base method.
+func (m *MockMatchedRepoInterface) ClaimReminder(ctx context.Context, match domain.StaleMatch, stage int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimReminder", ctx, match, stage)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimReminder indicates an expected call of ClaimReminder.
+func (mr *MockMatchedRepoInterfaceMockRecorder) ClaimReminder(ctx, match, stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimReminder", reflect.TypeOf((*MockMatchedRepoInterface)(nil).ClaimReminder), ctx, match, stage)
+}
+
+// Create mocks base method.
+func (m *MockMatchedRepoInterface) Create(ctx context.Context, req domain.MatchRequest) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, req)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMatchedRepoInterfaceMockRecorder) Create(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMatchedRepoInterface)(nil).Create), ctx, req)
+}
+
+// GetAllByUserID mocks base method.
+func (m *MockMatchedRepoInterface) GetAllByUserID(ctx context.Context, userID int64) ([]domain.Matched, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.Matched)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUserID indicates an expected call of GetAllByUserID.
+func (mr *MockMatchedRepoInterfaceMockRecorder) GetAllByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockMatchedRepoInterface)(nil).GetAllByUserID), ctx, userID)
+}
+
+// GetStaleMatches mocks base method.
+func (m *MockMatchedRepoInterface) GetStaleMatches(ctx context.Context, filter domain.StaleMatchFilter) ([]domain.StaleMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStaleMatches", ctx, filter)
+	ret0, _ := ret[0].([]domain.StaleMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStaleMatches indicates an expected call of GetStaleMatches.
+func (mr *MockMatchedRepoInterfaceMockRecorder) GetStaleMatches(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStaleMatches", reflect.TypeOf((*MockMatchedRepoInterface)(nil).GetStaleMatches), ctx, filter)
+}
+
+// IsExists mocks base method.
+func (m *MockMatchedRepoInterface) IsExists(ctx context.Context, req domain.MatchRequest) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsExists", ctx, req)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsExists indicates an expected call of IsExists.
+func (mr *MockMatchedRepoInterfaceMockRecorder) IsExists(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExists", reflect.TypeOf((*MockMatchedRepoInterface)(nil).IsExists), ctx, req)
+}
+
+// IsMatched mocks base method.
+func (m *MockMatchedRepoInterface) IsMatched(ctx context.Context, req domain.MatchRequest) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMatched", ctx, req)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMatched indicates an expected call of IsMatched.
+func (mr *MockMatchedRepoInterfaceMockRecorder) IsMatched(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMatched", reflect.TypeOf((*MockMatchedRepoInterface)(nil).IsMatched), ctx, req)
+}
+
+// IsMutual mocks base method.
+func (m *MockMatchedRepoInterface) IsMutual(ctx context.Context, req domain.MatchRequest) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMutual", ctx, req)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMutual indicates an expected call of IsMutual.
+func (mr *MockMatchedRepoInterfaceMockRecorder) IsMutual(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMutual", reflect.TypeOf((*MockMatchedRepoInterface)(nil).IsMutual), ctx, req)
+}
+
+// PurgeByUserID mocks base method.
+func (m *MockMatchedRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockMatchedRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockMatchedRepoInterface)(nil).PurgeByUserID), ctx, userID)
+}
+
+// Unmatch mocks base method.
+func (m *MockMatchedRepoInterface) Unmatch(ctx context.Context, req domain.MatchRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unmatch", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unmatch indicates an expected call of Unmatch.
+func (mr *MockMatchedRepoInterfaceMockRecorder) Unmatch(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmatch", reflect.TypeOf((*MockMatchedRepoInterface)(nil).Unmatch), ctx, req)
+}
+
+// MockNotificationRepoInterface is a mock of NotificationRepoInterface interface.
+type MockNotificationRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationRepoInterfaceMockRecorder
+}
+
+// MockNotificationRepoInterfaceMockRecorder is the mock recorder for MockNotificationRepoInterface.
+type MockNotificationRepoInterfaceMockRecorder struct {
+	mock *MockNotificationRepoInterface
+}
+
+// NewMockNotificationRepoInterface creates a new mock instance.
+func NewMockNotificationRepoInterface(ctrl *gomock.Controller) *MockNotificationRepoInterface {
+	mock := &MockNotificationRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockNotificationRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationRepoInterface) EXPECT() *MockNotificationRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ClaimOutbox mocks base method.
+func (m *MockNotificationRepoInterface) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.PushOutbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutbox", ctx, limit, lease)
+	ret0, _ := ret[0].([]domain.PushOutbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutbox indicates an expected call of ClaimOutbox.
+func (mr *MockNotificationRepoInterfaceMockRecorder) ClaimOutbox(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutbox", reflect.TypeOf((*MockNotificationRepoInterface)(nil).ClaimOutbox), ctx, limit, lease)
+}
+
+// Create mocks base method.
+func (m *MockNotificationRepoInterface) Create(ctx context.Context, req domain.NotificationRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockNotificationRepoInterfaceMockRecorder) Create(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockNotificationRepoInterface)(nil).Create), ctx, req)
+}
+
+// GetAllByUserId mocks base method.
+func (m *MockNotificationRepoInterface) GetAllByUserId(ctx context.Context, userID int64) ([]domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUserId", ctx, userID)
+	ret0, _ := ret[0].([]domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUserId indicates an expected call of GetAllByUserId.
+func (mr *MockNotificationRepoInterfaceMockRecorder) GetAllByUserId(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserId", reflect.TypeOf((*MockNotificationRepoInterface)(nil).GetAllByUserId), ctx, userID)
+}
+
+// GetByFilter mocks base method.
+func (m *MockNotificationRepoInterface) GetByFilter(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByFilter", ctx, userID, filter)
+	ret0, _ := ret[0].([]domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByFilter indicates an expected call of GetByFilter.
+func (mr *MockNotificationRepoInterfaceMockRecorder) GetByFilter(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByFilter", reflect.TypeOf((*MockNotificationRepoInterface)(nil).GetByFilter), ctx, userID, filter)
+}
+
+// GetByID mocks base method.
+func (m *MockNotificationRepoInterface) GetByID(ctx context.Context, notificationID int64) (*domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, notificationID)
+	ret0, _ := ret[0].(*domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockNotificationRepoInterfaceMockRecorder) GetByID(ctx, notificationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockNotificationRepoInterface)(nil).GetByID), ctx, notificationID)
+}
+
+// GetPreferences mocks base method.
+func (m *MockNotificationRepoInterface) GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].(*domain.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockNotificationRepoInterfaceMockRecorder) GetPreferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockNotificationRepoInterface)(nil).GetPreferences), ctx, userID)
+}
+
+// PurgeByUserID mocks base method.
+func (m *MockNotificationRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockNotificationRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockNotificationRepoInterface)(nil).PurgeByUserID), ctx, userID)
+}
+
+// SavePreferences mocks base method.
+func (m *MockNotificationRepoInterface) SavePreferences(ctx context.Context, prefs domain.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreferences", ctx, prefs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreferences indicates an expected call of SavePreferences.
+func (mr *MockNotificationRepoInterfaceMockRecorder) SavePreferences(ctx, prefs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferences", reflect.TypeOf((*MockNotificationRepoInterface)(nil).SavePreferences), ctx, prefs)
+}
+
+// SetRead mocks base method.
+func (m *MockNotificationRepoInterface) SetRead(ctx context.Context, notificationID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRead", ctx, notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRead indicates an expected call of SetRead.
+func (mr *MockNotificationRepoInterfaceMockRecorder) SetRead(ctx, notificationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRead", reflect.TypeOf((*MockNotificationRepoInterface)(nil).SetRead), ctx, notificationID)
+}
+
+// UpdateOutbox mocks base method.
+func (m *MockNotificationRepoInterface) UpdateOutbox(ctx context.Context, outboxID int64, update domain.PushOutboxUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOutbox", ctx, outboxID, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOutbox indicates an expected call of UpdateOutbox.
+func (mr *MockNotificationRepoInterfaceMockRecorder) UpdateOutbox(ctx, outboxID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOutbox", reflect.TypeOf((*MockNotificationRepoInterface)(nil).UpdateOutbox), ctx, outboxID, update)
+}
+
+// MockDeviceRepoInterface is a mock of DeviceRepoInterface interface.
+type MockDeviceRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeviceRepoInterfaceMockRecorder
+}
+
+// MockDeviceRepoInterfaceMockRecorder is the mock recorder for MockDeviceRepoInterface.
+type MockDeviceRepoInterfaceMockRecorder struct {
+	mock *MockDeviceRepoInterface
+}
+
+// NewMockDeviceRepoInterface creates a new mock instance.
+func NewMockDeviceRepoInterface(ctrl *gomock.Controller) *MockDeviceRepoInterface {
+	mock := &MockDeviceRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockDeviceRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeviceRepoInterface) EXPECT() *MockDeviceRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DeleteByToken mocks base method.
+func (m *MockDeviceRepoInterface) DeleteByToken(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByToken indicates an expected call of DeleteByToken.
+func (mr *MockDeviceRepoInterfaceMockRecorder) DeleteByToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByToken", reflect.TypeOf((*MockDeviceRepoInterface)(nil).DeleteByToken), ctx, token)
+}
+
+// GetByUserID mocks base method.
+func (m *MockDeviceRepoInterface) GetByUserID(ctx context.Context, userID int64) ([]domain.DeviceToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.DeviceToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockDeviceRepoInterfaceMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockDeviceRepoInterface)(nil).GetByUserID), ctx, userID)
+}
+
+// PurgeByUserID mocks base method.
+func (m *MockDeviceRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockDeviceRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockDeviceRepoInterface)(nil).PurgeByUserID), ctx, userID)
+}
+
+// Register mocks base method.
+func (m *MockDeviceRepoInterface) Register(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockDeviceRepoInterfaceMockRecorder) Register(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockDeviceRepoInterface)(nil).Register), ctx, userID, req)
+}
+
+// Unregister mocks base method.
+func (m *MockDeviceRepoInterface) Unregister(ctx context.Context, userID int64, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unregister", ctx, userID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unregister indicates an expected call of Unregister.
+func (mr *MockDeviceRepoInterfaceMockRecorder) Unregister(ctx, userID, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unregister", reflect.TypeOf((*MockDeviceRepoInterface)(nil).Unregister), ctx, userID, token)
+}
+
+// MockPushSenderInterface is a mock of PushSenderInterface interface.
+type MockPushSenderInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushSenderInterfaceMockRecorder
+}
+
+// MockPushSenderInterfaceMockRecorder is the mock recorder for MockPushSenderInterface.
+type MockPushSenderInterfaceMockRecorder struct {
+	mock *MockPushSenderInterface
+}
+
+// NewMockPushSenderInterface creates a new mock instance.
+func NewMockPushSenderInterface(ctrl *gomock.Controller) *MockPushSenderInterface {
+	mock := &MockPushSenderInterface{ctrl: ctrl}
+	mock.recorder = &MockPushSenderInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushSenderInterface) EXPECT() *MockPushSenderInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockPushSenderInterface) Send(ctx context.Context, msg domain.PushMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockPushSenderInterfaceMockRecorder) Send(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockPushSenderInterface)(nil).Send), ctx, msg)
+}
+
+// MockUnitOfWorkInterface is a mock of UnitOfWorkInterface interface.
+type MockUnitOfWorkInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnitOfWorkInterfaceMockRecorder
+}
+
+// MockUnitOfWorkInterfaceMockRecorder is the mock recorder for MockUnitOfWorkInterface.
+type MockUnitOfWorkInterfaceMockRecorder struct {
+	mock *MockUnitOfWorkInterface
+}
+
+// NewMockUnitOfWorkInterface creates a new mock instance.
+func NewMockUnitOfWorkInterface(ctrl *gomock.Controller) *MockUnitOfWorkInterface {
+	mock := &MockUnitOfWorkInterface{ctrl: ctrl}
+	mock.recorder = &MockUnitOfWorkInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnitOfWorkInterface) EXPECT() *MockUnitOfWorkInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Do mocks base method.
+func (m *MockUnitOfWorkInterface) Do(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockUnitOfWorkInterfaceMockRecorder) Do(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockUnitOfWorkInterface)(nil).Do), ctx, fn)
+}
+
+// MockOutboxRepoInterface is a mock of OutboxRepoInterface interface.
+type MockOutboxRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepoInterfaceMockRecorder
+}
+
+// MockOutboxRepoInterfaceMockRecorder is the mock recorder for MockOutboxRepoInterface.
+type MockOutboxRepoInterfaceMockRecorder struct {
+	mock *MockOutboxRepoInterface
+}
+
+// NewMockOutboxRepoInterface creates a new mock instance.
+func NewMockOutboxRepoInterface(ctrl *gomock.Controller) *MockOutboxRepoInterface {
+	mock := &MockOutboxRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepoInterface) EXPECT() *MockOutboxRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockOutboxRepoInterface) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, limit, lease)
+	ret0, _ := ret[0].([]domain.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockOutboxRepoInterfaceMockRecorder) Claim(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockOutboxRepoInterface)(nil).Claim), ctx, limit, lease)
+}
+
+// Enqueue mocks base method.
+func (m *MockOutboxRepoInterface) Enqueue(ctx context.Context, kind string, payload interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, kind, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockOutboxRepoInterfaceMockRecorder) Enqueue(ctx, kind, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockOutboxRepoInterface)(nil).Enqueue), ctx, kind, payload)
+}
+
+// Update mocks base method.
+func (m *MockOutboxRepoInterface) Update(ctx context.Context, messageID int64, update domain.OutboxUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, messageID, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOutboxRepoInterfaceMockRecorder) Update(ctx, messageID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOutboxRepoInterface)(nil).Update), ctx, messageID, update)
+}
+
+// MockEventPublisherInterface is a mock of EventPublisherInterface interface.
+type MockEventPublisherInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherInterfaceMockRecorder
+}
+
+// MockEventPublisherInterfaceMockRecorder is the mock recorder for MockEventPublisherInterface.
+type MockEventPublisherInterfaceMockRecorder struct {
+	mock *MockEventPublisherInterface
+}
+
+// NewMockEventPublisherInterface creates a new mock instance.
+func NewMockEventPublisherInterface(ctrl *gomock.Controller) *MockEventPublisherInterface {
+	mock := &MockEventPublisherInterface{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisherInterface) EXPECT() *MockEventPublisherInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisherInterface) Publish(ctx context.Context, event domain.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherInterfaceMockRecorder) Publish(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisherInterface)(nil).Publish), ctx, event)
+}
+
+// MockAuditRepoInterface is a mock of AuditRepoInterface interface.
+type MockAuditRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepoInterfaceMockRecorder
+}
+
+// MockAuditRepoInterfaceMockRecorder is the mock recorder for MockAuditRepoInterface.
+type MockAuditRepoInterfaceMockRecorder struct {
+	mock *MockAuditRepoInterface
+}
+
+// NewMockAuditRepoInterface creates a new mock instance.
+func NewMockAuditRepoInterface(ctrl *gomock.Controller) *MockAuditRepoInterface {
+	mock := &MockAuditRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockAuditRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepoInterface) EXPECT() *MockAuditRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAuditRepoInterface) Create(ctx context.Context, req domain.AuditLogRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditRepoInterfaceMockRecorder) Create(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditRepoInterface)(nil).Create), ctx, req)
+}
+
+// GetAll mocks base method.
+func (m *MockAuditRepoInterface) GetAll(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter)
+	ret0, _ := ret[0].([]domain.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockAuditRepoInterfaceMockRecorder) GetAll(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAuditRepoInterface)(nil).GetAll), ctx, filter)
+}
+
+// MockReportRepoInterface is a mock of ReportRepoInterface interface.
+type MockReportRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepoInterfaceMockRecorder
+}
+
+// MockReportRepoInterfaceMockRecorder is the mock recorder for MockReportRepoInterface.
+type MockReportRepoInterfaceMockRecorder struct {
+	mock *MockReportRepoInterface
+}
+
+// NewMockReportRepoInterface creates a new mock instance.
+func NewMockReportRepoInterface(ctrl *gomock.Controller) *MockReportRepoInterface {
+	mock := &MockReportRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockReportRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepoInterface) EXPECT() *MockReportRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CountPendingReporters mocks base method.
+func (m *MockReportRepoInterface) CountPendingReporters(ctx context.Context, reportedUserID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPendingReporters", ctx, reportedUserID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPendingReporters indicates an expected call of CountPendingReporters.
+func (mr *MockReportRepoInterfaceMockRecorder) CountPendingReporters(ctx, reportedUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPendingReporters", reflect.TypeOf((*MockReportRepoInterface)(nil).CountPendingReporters), ctx, reportedUserID)
+}
+
+// Create mocks base method.
+func (m *MockReportRepoInterface) Create(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, reporterID, req)
+	ret0, _ := ret[0].(*domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReportRepoInterfaceMockRecorder) Create(ctx, reporterID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReportRepoInterface)(nil).Create), ctx, reporterID, req)
+}
+
+// GetAll mocks base method.
+func (m *MockReportRepoInterface) GetAll(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter)
+	ret0, _ := ret[0].([]domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockReportRepoInterfaceMockRecorder) GetAll(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockReportRepoInterface)(nil).GetAll), ctx, filter)
+}
+
+// GetByID mocks base method.
+func (m *MockReportRepoInterface) GetByID(ctx context.Context, reportID int64) (*domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, reportID)
+	ret0, _ := ret[0].(*domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockReportRepoInterfaceMockRecorder) GetByID(ctx, reportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockReportRepoInterface)(nil).GetByID), ctx, reportID)
+}
+
+// GetByReporterID mocks base method.
+func (m *MockReportRepoInterface) GetByReporterID(ctx context.Context, reporterID int64) ([]domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByReporterID", ctx, reporterID)
+	ret0, _ := ret[0].([]domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByReporterID indicates an expected call of GetByReporterID.
+func (mr *MockReportRepoInterfaceMockRecorder) GetByReporterID(ctx, reporterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByReporterID", reflect.TypeOf((*MockReportRepoInterface)(nil).GetByReporterID), ctx, reporterID)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockReportRepoInterface) UpdateStatus(ctx context.Context, reportID, reviewerID int64, req domain.ReviewReportRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, reportID, reviewerID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockReportRepoInterfaceMockRecorder) UpdateStatus(ctx, reportID, reviewerID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockReportRepoInterface)(nil).UpdateStatus), ctx, reportID, reviewerID, req)
+}
+
+// MockBlockRepoInterface is a mock of BlockRepoInterface interface.
+type MockBlockRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockRepoInterfaceMockRecorder
+}
+
+// MockBlockRepoInterfaceMockRecorder is the mock recorder for MockBlockRepoInterface.
+type MockBlockRepoInterfaceMockRecorder struct {
+	mock *MockBlockRepoInterface
+}
+
+// NewMockBlockRepoInterface creates a new mock instance.
+func NewMockBlockRepoInterface(ctrl *gomock.Controller) *MockBlockRepoInterface {
+	mock := &MockBlockRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockBlockRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlockRepoInterface) EXPECT() *MockBlockRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockBlockRepoInterface) Create(ctx context.Context, blockerID, blockedID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, blockerID, blockedID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockBlockRepoInterfaceMockRecorder) Create(ctx, blockerID, blockedID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBlockRepoInterface)(nil).Create), ctx, blockerID, blockedID)
+}
+
+// GetBlockedUserIDs mocks base method.
+func (m *MockBlockRepoInterface) GetBlockedUserIDs(ctx context.Context, userID int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockedUserIDs", ctx, userID)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockedUserIDs indicates an expected call of GetBlockedUserIDs.
+func (mr *MockBlockRepoInterfaceMockRecorder) GetBlockedUserIDs(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockedUserIDs", reflect.TypeOf((*MockBlockRepoInterface)(nil).GetBlockedUserIDs), ctx, userID)
+}
+
+// GetByBlockerID mocks base method.
+func (m *MockBlockRepoInterface) GetByBlockerID(ctx context.Context, blockerID int64) ([]domain.UserBlock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByBlockerID", ctx, blockerID)
+	ret0, _ := ret[0].([]domain.UserBlock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByBlockerID indicates an expected call of GetByBlockerID.
+func (mr *MockBlockRepoInterfaceMockRecorder) GetByBlockerID(ctx, blockerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByBlockerID", reflect.TypeOf((*MockBlockRepoInterface)(nil).GetByBlockerID), ctx, blockerID)
+}
+
+// PurgeByUserID mocks base method.
+func (m *MockBlockRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockBlockRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockBlockRepoInterface)(nil).PurgeByUserID), ctx, userID)
+}
+
+// MockChatRepoInterface is a mock of ChatRepoInterface interface.
+type MockChatRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatRepoInterfaceMockRecorder
+}
+
+// MockChatRepoInterfaceMockRecorder is the mock recorder for MockChatRepoInterface.
+type MockChatRepoInterfaceMockRecorder struct {
+	mock *MockChatRepoInterface
+}
+
+// NewMockChatRepoInterface creates a new mock instance.
+func NewMockChatRepoInterface(ctrl *gomock.Controller) *MockChatRepoInterface {
+	mock := &MockChatRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockChatRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatRepoInterface) EXPECT() *MockChatRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateMessage mocks base method.
+func (m *MockChatRepoInterface) CreateMessage(ctx context.Context, req domain.CreateMessageRequest) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMessage", ctx, req)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMessage indicates an expected call of CreateMessage.
+func (mr *MockChatRepoInterfaceMockRecorder) CreateMessage(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockChatRepoInterface)(nil).CreateMessage), ctx, req)
+}
+
+// GetConversation mocks base method.
+func (m *MockChatRepoInterface) GetConversation(ctx context.Context, conversationID int64) (*domain.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversation", ctx, conversationID)
+	ret0, _ := ret[0].(*domain.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversation indicates an expected call of GetConversation.
+func (mr *MockChatRepoInterfaceMockRecorder) GetConversation(ctx, conversationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversation", reflect.TypeOf((*MockChatRepoInterface)(nil).GetConversation), ctx, conversationID)
+}
+
+// GetConversationsByUserID mocks base method.
+func (m *MockChatRepoInterface) GetConversationsByUserID(ctx context.Context, userID int64) ([]domain.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversationsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversationsByUserID indicates an expected call of GetConversationsByUserID.
+func (mr *MockChatRepoInterfaceMockRecorder) GetConversationsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversationsByUserID", reflect.TypeOf((*MockChatRepoInterface)(nil).GetConversationsByUserID), ctx, userID)
+}
+
+// GetMessages mocks base method.
+func (m *MockChatRepoInterface) GetMessages(ctx context.Context, conversationID, before int64, limit int) ([]domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", ctx, conversationID, before, limit)
+	ret0, _ := ret[0].([]domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockChatRepoInterfaceMockRecorder) GetMessages(ctx, conversationID, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockChatRepoInterface)(nil).GetMessages), ctx, conversationID, before, limit)
+}
+
+// GetMessagesBySenderID mocks base method.
+func (m *MockChatRepoInterface) GetMessagesBySenderID(ctx context.Context, senderID int64) ([]domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessagesBySenderID", ctx, senderID)
+	ret0, _ := ret[0].([]domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessagesBySenderID indicates an expected call of GetMessagesBySenderID.
+func (mr *MockChatRepoInterfaceMockRecorder) GetMessagesBySenderID(ctx, senderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessagesBySenderID", reflect.TypeOf((*MockChatRepoInterface)(nil).GetMessagesBySenderID), ctx, senderID)
 }
 
-// GetProfileRecommendation mocks base method.
-func (m *MockProfileRepoInterface) GetProfileRecommendation(ctx context.Context, interest string, notInUserID []int64) ([]domain.Profile, error) {
+// GetOrCreateConversation mocks base method.
+func (m *MockChatRepoInterface) GetOrCreateConversation(ctx context.Context, userID, targetUserID int64) (*domain.Conversation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProfileRecommendation", ctx, interest, notInUserID)
-	ret0, _ := ret[0].([]domain.Profile)
+	ret := m.ctrl.Call(m, "GetOrCreateConversation", ctx, userID, targetUserID)
+	ret0, _ := ret[0].(*domain.Conversation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetProfileRecommendation indicates an expected call of GetProfileRecommendation.
-func (mr *MockProfileRepoInterfaceMockRecorder) GetProfileRecommendation(ctx, interest, notInUserID interface{}) *gomock.Call {
+// GetOrCreateConversation indicates an expected call of GetOrCreateConversation.
+func (mr *MockChatRepoInterfaceMockRecorder) GetOrCreateConversation(ctx, userID, targetUserID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfileRecommendation", reflect.TypeOf((*MockProfileRepoInterface)(nil).GetProfileRecommendation), ctx, interest, notInUserID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrCreateConversation", reflect.TypeOf((*MockChatRepoInterface)(nil).GetOrCreateConversation), ctx, userID, targetUserID)
 }
 
-// UpdateProfile mocks base method.
-func (m *MockProfileRepoInterface) UpdateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error {
+// PurgeByUserID mocks base method.
+func (m *MockChatRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, req)
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateProfile indicates an expected call of UpdateProfile.
-func (mr *MockProfileRepoInterfaceMockRecorder) UpdateProfile(ctx, userID, req interface{}) *gomock.Call {
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockChatRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockProfileRepoInterface)(nil).UpdateProfile), ctx, userID, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockChatRepoInterface)(nil).PurgeByUserID), ctx, userID)
 }
 
-// UpdateProfilePic mocks base method.
-func (m *MockProfileRepoInterface) UpdateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error {
+// UpdateReceipts mocks base method.
+func (m *MockChatRepoInterface) UpdateReceipts(ctx context.Context, conversationID, recipientID, upToMessageID int64, status string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProfilePic", ctx, userID, req)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdateReceipts", ctx, conversationID, recipientID, upToMessageID, status)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateProfilePic indicates an expected call of UpdateProfilePic.
-func (mr *MockProfileRepoInterfaceMockRecorder) UpdateProfilePic(ctx, userID, req interface{}) *gomock.Call {
+// UpdateReceipts indicates an expected call of UpdateReceipts.
+func (mr *MockChatRepoInterfaceMockRecorder) UpdateReceipts(ctx, conversationID, recipientID, upToMessageID, status interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfilePic", reflect.TypeOf((*MockProfileRepoInterface)(nil).UpdateProfilePic), ctx, userID, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReceipts", reflect.TypeOf((*MockChatRepoInterface)(nil).UpdateReceipts), ctx, conversationID, recipientID, upToMessageID, status)
 }
 
-// MockRedisRepoInterface is a mock of RedisRepoInterface interface.
-type MockRedisRepoInterface struct {
+// MockContentFilterInterface is a mock of ContentFilterInterface interface.
+type MockContentFilterInterface struct {
 	ctrl     *gomock.Controller
-	recorder *MockRedisRepoInterfaceMockRecorder
+	recorder *MockContentFilterInterfaceMockRecorder
 }
 
-// MockRedisRepoInterfaceMockRecorder is the mock recorder for MockRedisRepoInterface.
-type MockRedisRepoInterfaceMockRecorder struct {
-	mock *MockRedisRepoInterface
+// MockContentFilterInterfaceMockRecorder is the mock recorder for MockContentFilterInterface.
+type MockContentFilterInterfaceMockRecorder struct {
+	mock *MockContentFilterInterface
 }
 
-// NewMockRedisRepoInterface creates a new mock instance.
-func NewMockRedisRepoInterface(ctrl *gomock.Controller) *MockRedisRepoInterface {
-	mock := &MockRedisRepoInterface{ctrl: ctrl}
-	mock.recorder = &MockRedisRepoInterfaceMockRecorder{mock}
+// NewMockContentFilterInterface creates a new mock instance.
+func NewMockContentFilterInterface(ctrl *gomock.Controller) *MockContentFilterInterface {
+	mock := &MockContentFilterInterface{ctrl: ctrl}
+	mock.recorder = &MockContentFilterInterfaceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockRedisRepoInterface) EXPECT() *MockRedisRepoInterfaceMockRecorder {
+func (m *MockContentFilterInterface) EXPECT() *MockContentFilterInterfaceMockRecorder {
 	return m.recorder
 }
 
-// Exists mocks base method.
-func (m *MockRedisRepoInterface) Exists(ctx context.Context, keys ...string) (bool, error) {
+// Check mocks base method.
+func (m *MockContentFilterInterface) Check(ctx context.Context, content domain.FilterContent) (domain.FilterResult, error) {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{ctx}
-	for _, a := range keys {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "Exists", varargs...)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "Check", ctx, content)
+	ret0, _ := ret[0].(domain.FilterResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Exists indicates an expected call of Exists.
-func (mr *MockRedisRepoInterfaceMockRecorder) Exists(ctx interface{}, keys ...interface{}) *gomock.Call {
+// Check indicates an expected call of Check.
+func (mr *MockContentFilterInterfaceMockRecorder) Check(ctx, content interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]interface{}{ctx}, keys...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockRedisRepoInterface)(nil).Exists), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockContentFilterInterface)(nil).Check), ctx, content)
 }
 
-// Expire mocks base method.
-func (m *MockRedisRepoInterface) Expire(ctx context.Context, key string, expiration int) error {
+// MockContentFlagRepoInterface is a mock of ContentFlagRepoInterface interface.
+type MockContentFlagRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockContentFlagRepoInterfaceMockRecorder
+}
+
+// MockContentFlagRepoInterfaceMockRecorder is the mock recorder for MockContentFlagRepoInterface.
+type MockContentFlagRepoInterfaceMockRecorder struct {
+	mock *MockContentFlagRepoInterface
+}
+
+// NewMockContentFlagRepoInterface creates a new mock instance.
+func NewMockContentFlagRepoInterface(ctrl *gomock.Controller) *MockContentFlagRepoInterface {
+	mock := &MockContentFlagRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockContentFlagRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContentFlagRepoInterface) EXPECT() *MockContentFlagRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockContentFlagRepoInterface) Create(ctx context.Context, content domain.FilterContent, result domain.FilterResult) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Expire", ctx, key, expiration)
+	ret := m.ctrl.Call(m, "Create", ctx, content, result)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Expire indicates an expected call of Expire.
-func (mr *MockRedisRepoInterfaceMockRecorder) Expire(ctx, key, expiration interface{}) *gomock.Call {
+// Create indicates an expected call of Create.
+func (mr *MockContentFlagRepoInterfaceMockRecorder) Create(ctx, content, result interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockRedisRepoInterface)(nil).Expire), ctx, key, expiration)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockContentFlagRepoInterface)(nil).Create), ctx, content, result)
 }
 
-// Get mocks base method.
-func (m *MockRedisRepoInterface) Get(ctx context.Context, key string) (string, error) {
+// GetAll mocks base method.
+func (m *MockContentFlagRepoInterface) GetAll(ctx context.Context, filter domain.ContentFlagFilter) ([]domain.ContentFlag, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, key)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter)
+	ret0, _ := ret[0].([]domain.ContentFlag)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Get indicates an expected call of Get.
-func (mr *MockRedisRepoInterfaceMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+// GetAll indicates an expected call of GetAll.
+func (mr *MockContentFlagRepoInterfaceMockRecorder) GetAll(ctx, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRedisRepoInterface)(nil).Get), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockContentFlagRepoInterface)(nil).GetAll), ctx, filter)
 }
 
-// GetValues mocks base method.
-func (m *MockRedisRepoInterface) GetValues(ctx context.Context, key string) ([]string, error) {
+// GetAllByUserID mocks base method.
+func (m *MockContentFlagRepoInterface) GetAllByUserID(ctx context.Context, userID int64) ([]domain.ContentFlag, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetValues", ctx, key)
-	ret0, _ := ret[0].([]string)
+	ret := m.ctrl.Call(m, "GetAllByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.ContentFlag)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetValues indicates an expected call of GetValues.
-func (mr *MockRedisRepoInterfaceMockRecorder) GetValues(ctx, key interface{}) *gomock.Call {
+// GetAllByUserID indicates an expected call of GetAllByUserID.
+func (mr *MockContentFlagRepoInterfaceMockRecorder) GetAllByUserID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValues", reflect.TypeOf((*MockRedisRepoInterface)(nil).GetValues), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockContentFlagRepoInterface)(nil).GetAllByUserID), ctx, userID)
 }
 
-// Incr mocks base method.
-func (m *MockRedisRepoInterface) Incr(ctx context.Context, key string) error {
+// PurgeByUserID mocks base method.
+func (m *MockContentFlagRepoInterface) PurgeByUserID(ctx context.Context, userID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Incr", ctx, key)
+	ret := m.ctrl.Call(m, "PurgeByUserID", ctx, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Incr indicates an expected call of Incr.
-func (mr *MockRedisRepoInterfaceMockRecorder) Incr(ctx, key interface{}) *gomock.Call {
+// PurgeByUserID indicates an expected call of PurgeByUserID.
+func (mr *MockContentFlagRepoInterfaceMockRecorder) PurgeByUserID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockRedisRepoInterface)(nil).Incr), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeByUserID", reflect.TypeOf((*MockContentFlagRepoInterface)(nil).PurgeByUserID), ctx, userID)
 }
 
-// Set mocks base method.
-func (m *MockRedisRepoInterface) Set(ctx context.Context, key string, value interface{}, expiration int) error {
+// MockPresenceRepoInterface is a mock of PresenceRepoInterface interface.
+type MockPresenceRepoInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceRepoInterfaceMockRecorder
+}
+
+// MockPresenceRepoInterfaceMockRecorder is the mock recorder for MockPresenceRepoInterface.
+type MockPresenceRepoInterfaceMockRecorder struct {
+	mock *MockPresenceRepoInterface
+}
+
+// NewMockPresenceRepoInterface creates a new mock instance.
+func NewMockPresenceRepoInterface(ctrl *gomock.Controller) *MockPresenceRepoInterface {
+	mock := &MockPresenceRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockPresenceRepoInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceRepoInterface) EXPECT() *MockPresenceRepoInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetLastActive mocks base method.
+func (m *MockPresenceRepoInterface) GetLastActive(ctx context.Context, userIDs ...int64) (map[int64]time.Time, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", ctx, key, value, expiration)
+	varargs := []interface{}{ctx}
+	for _, a := range userIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetLastActive", varargs...)
+	ret0, _ := ret[0].(map[int64]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastActive indicates an expected call of GetLastActive.
+func (mr *MockPresenceRepoInterfaceMockRecorder) GetLastActive(ctx interface{}, userIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, userIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastActive", reflect.TypeOf((*MockPresenceRepoInterface)(nil).GetLastActive), varargs...)
+}
+
+// Touch mocks base method.
+func (m *MockPresenceRepoInterface) Touch(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Set indicates an expected call of Set.
-func (mr *MockRedisRepoInterfaceMockRecorder) Set(ctx, key, value, expiration interface{}) *gomock.Call {
+// Touch indicates an expected call of Touch.
+func (mr *MockPresenceRepoInterfaceMockRecorder) Touch(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRedisRepoInterface)(nil).Set), ctx, key, value, expiration)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockPresenceRepoInterface)(nil).Touch), ctx, userID)
 }
 
-// MockInventoryRepoInterface is a mock of InventoryRepoInterface interface.
-type MockInventoryRepoInterface struct {
+// MockRealtimePublisherInterface is a mock of RealtimePublisherInterface interface.
+type MockRealtimePublisherInterface struct {
 	ctrl     *gomock.Controller
-	recorder *MockInventoryRepoInterfaceMockRecorder
+	recorder *MockRealtimePublisherInterfaceMockRecorder
 }
 
-// MockInventoryRepoInterfaceMockRecorder is the mock recorder for MockInventoryRepoInterface.
-type MockInventoryRepoInterfaceMockRecorder struct {
-	mock *MockInventoryRepoInterface
+// MockRealtimePublisherInterfaceMockRecorder is the mock recorder for MockRealtimePublisherInterface.
+type MockRealtimePublisherInterfaceMockRecorder struct {
+	mock *MockRealtimePublisherInterface
 }
 
-// NewMockInventoryRepoInterface creates a new mock instance.
-func NewMockInventoryRepoInterface(ctrl *gomock.Controller) *MockInventoryRepoInterface {
-	mock := &MockInventoryRepoInterface{ctrl: ctrl}
-	mock.recorder = &MockInventoryRepoInterfaceMockRecorder{mock}
+// NewMockRealtimePublisherInterface creates a new mock instance.
+func NewMockRealtimePublisherInterface(ctrl *gomock.Controller) *MockRealtimePublisherInterface {
+	mock := &MockRealtimePublisherInterface{ctrl: ctrl}
+	mock.recorder = &MockRealtimePublisherInterfaceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockInventoryRepoInterface) EXPECT() *MockInventoryRepoInterfaceMockRecorder {
+func (m *MockRealtimePublisherInterface) EXPECT() *MockRealtimePublisherInterfaceMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method.
-func (m *MockInventoryRepoInterface) Create(ctx context.Context, req domain.CreateInventoryRequest) error {
+// Publish mocks base method.
+func (m *MockRealtimePublisherInterface) Publish(ctx context.Context, userID int64, event domain.RealtimeEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, req)
+	ret := m.ctrl.Call(m, "Publish", ctx, userID, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Create indicates an expected call of Create.
-func (mr *MockInventoryRepoInterfaceMockRecorder) Create(ctx, req interface{}) *gomock.Call {
+// Publish indicates an expected call of Publish.
+func (mr *MockRealtimePublisherInterfaceMockRecorder) Publish(ctx, userID, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockInventoryRepoInterface)(nil).Create), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockRealtimePublisherInterface)(nil).Publish), ctx, userID, event)
 }
 
-// GetByUserId mocks base method.
-func (m *MockInventoryRepoInterface) GetByUserId(ctx context.Context, userID int64) (*domain.Inventory, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByUserId", ctx, userID)
-	ret0, _ := ret[0].(*domain.Inventory)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+// MockSettingsInterface is a mock of SettingsInterface interface.
+type MockSettingsInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsInterfaceMockRecorder
 }
 
-// GetByUserId indicates an expected call of GetByUserId.
-func (mr *MockInventoryRepoInterfaceMockRecorder) GetByUserId(ctx, userID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserId", reflect.TypeOf((*MockInventoryRepoInterface)(nil).GetByUserId), ctx, userID)
+// MockSettingsInterfaceMockRecorder is the mock recorder for MockSettingsInterface.
+type MockSettingsInterfaceMockRecorder struct {
+	mock *MockSettingsInterface
 }
 
-// UpdateLikes mocks base method.
-func (m *MockInventoryRepoInterface) UpdateLikes(ctx context.Context, userID int64, likes int) error {
+// NewMockSettingsInterface creates a new mock instance.
+func NewMockSettingsInterface(ctrl *gomock.Controller) *MockSettingsInterface {
+	mock := &MockSettingsInterface{ctrl: ctrl}
+	mock.recorder = &MockSettingsInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettingsInterface) EXPECT() *MockSettingsInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Bool mocks base method.
+func (m *MockSettingsInterface) Bool(ctx context.Context, key string) bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateLikes", ctx, userID, likes)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "Bool", ctx, key)
+	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
-// UpdateLikes indicates an expected call of UpdateLikes.
-func (mr *MockInventoryRepoInterfaceMockRecorder) UpdateLikes(ctx, userID, likes interface{}) *gomock.Call {
+// Bool indicates an expected call of Bool.
+func (mr *MockSettingsInterfaceMockRecorder) Bool(ctx, key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLikes", reflect.TypeOf((*MockInventoryRepoInterface)(nil).UpdateLikes), ctx, userID, likes)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bool", reflect.TypeOf((*MockSettingsInterface)(nil).Bool), ctx, key)
 }
 
-// UpdateSuperLikes mocks base method.
-func (m *MockInventoryRepoInterface) UpdateSuperLikes(ctx context.Context, userID int64, superLikes int) error {
+// Int mocks base method.
+func (m *MockSettingsInterface) Int(ctx context.Context, key string) int {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSuperLikes", ctx, userID, superLikes)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "Int", ctx, key)
+	ret0, _ := ret[0].(int)
 	return ret0
 }
 
-// UpdateSuperLikes indicates an expected call of UpdateSuperLikes.
-func (mr *MockInventoryRepoInterfaceMockRecorder) UpdateSuperLikes(ctx, userID, superLikes interface{}) *gomock.Call {
+// Int indicates an expected call of Int.
+func (mr *MockSettingsInterfaceMockRecorder) Int(ctx, key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSuperLikes", reflect.TypeOf((*MockInventoryRepoInterface)(nil).UpdateSuperLikes), ctx, userID, superLikes)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Int", reflect.TypeOf((*MockSettingsInterface)(nil).Int), ctx, key)
 }
 
-// UpdateSwipes mocks base method.
-func (m *MockInventoryRepoInterface) UpdateSwipes(ctx context.Context, userID int64, swipes int) error {
+// String mocks base method.
+func (m *MockSettingsInterface) String(ctx context.Context, key string) string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSwipes", ctx, userID, swipes)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "String", ctx, key)
+	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-// UpdateSwipes indicates an expected call of UpdateSwipes.
-func (mr *MockInventoryRepoInterfaceMockRecorder) UpdateSwipes(ctx, userID, swipes interface{}) *gomock.Call {
+// String indicates an expected call of String.
+func (mr *MockSettingsInterfaceMockRecorder) String(ctx, key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSwipes", reflect.TypeOf((*MockInventoryRepoInterface)(nil).UpdateSwipes), ctx, userID, swipes)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "String", reflect.TypeOf((*MockSettingsInterface)(nil).String), ctx, key)
 }
 
-// MockMatchedRepoInterface is a mock of MatchedRepoInterface interface.
-type MockMatchedRepoInterface struct {
+// MockSettingsRepoInterface is a mock of SettingsRepoInterface interface.
+type MockSettingsRepoInterface struct {
 	ctrl     *gomock.Controller
-	recorder *MockMatchedRepoInterfaceMockRecorder
+	recorder *MockSettingsRepoInterfaceMockRecorder
 }
 
-// MockMatchedRepoInterfaceMockRecorder is the mock recorder for MockMatchedRepoInterface.
-type MockMatchedRepoInterfaceMockRecorder struct {
-	mock *MockMatchedRepoInterface
+// MockSettingsRepoInterfaceMockRecorder is the mock recorder for MockSettingsRepoInterface.
+type MockSettingsRepoInterfaceMockRecorder struct {
+	mock *MockSettingsRepoInterface
 }
 
-// NewMockMatchedRepoInterface creates a new mock instance.
-func NewMockMatchedRepoInterface(ctrl *gomock.Controller) *MockMatchedRepoInterface {
-	mock := &MockMatchedRepoInterface{ctrl: ctrl}
-	mock.recorder = &MockMatchedRepoInterfaceMockRecorder{mock}
+// NewMockSettingsRepoInterface creates a new mock instance.
+func NewMockSettingsRepoInterface(ctrl *gomock.Controller) *MockSettingsRepoInterface {
+	mock := &MockSettingsRepoInterface{ctrl: ctrl}
+	mock.recorder = &MockSettingsRepoInterfaceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockMatchedRepoInterface) EXPECT() *MockMatchedRepoInterfaceMockRecorder {
+func (m *MockSettingsRepoInterface) EXPECT() *MockSettingsRepoInterfaceMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method.
-func (m *MockMatchedRepoInterface) Create(ctx context.Context, req domain.MatchRequest) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, req)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Create indicates an expected call of Create.
-func (mr *MockMatchedRepoInterfaceMockRecorder) Create(ctx, req interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMatchedRepoInterface)(nil).Create), ctx, req)
-}
-
-// IsExists mocks base method.
-func (m *MockMatchedRepoInterface) IsExists(ctx context.Context, req domain.MatchRequest) (bool, error) {
+// GetAll mocks base method.
+func (m *MockSettingsRepoInterface) GetAll(ctx context.Context) ([]domain.Setting, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsExists", ctx, req)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]domain.Setting)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsExists indicates an expected call of IsExists.
-func (mr *MockMatchedRepoInterfaceMockRecorder) IsExists(ctx, req interface{}) *gomock.Call {
+// GetAll indicates an expected call of GetAll.
+func (mr *MockSettingsRepoInterfaceMockRecorder) GetAll(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsExists", reflect.TypeOf((*MockMatchedRepoInterface)(nil).IsExists), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockSettingsRepoInterface)(nil).GetAll), ctx)
 }
 
-// IsMatched mocks base method.
-func (m *MockMatchedRepoInterface) IsMatched(ctx context.Context, req domain.MatchRequest) (bool, error) {
+// Set mocks base method.
+func (m *MockSettingsRepoInterface) Set(ctx context.Context, key, value string, updatedBy int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsMatched", ctx, req)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Set", ctx, key, value, updatedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// IsMatched indicates an expected call of IsMatched.
-func (mr *MockMatchedRepoInterfaceMockRecorder) IsMatched(ctx, req interface{}) *gomock.Call {
+// Set indicates an expected call of Set.
+func (mr *MockSettingsRepoInterfaceMockRecorder) Set(ctx, key, value, updatedBy interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMatched", reflect.TypeOf((*MockMatchedRepoInterface)(nil).IsMatched), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockSettingsRepoInterface)(nil).Set), ctx, key, value, updatedBy)
 }
 
-// MockNotificationRepoInterface is a mock of NotificationRepoInterface interface.
-type MockNotificationRepoInterface struct {
+// MockExperimentsInterface is a mock of ExperimentsInterface interface.
+type MockExperimentsInterface struct {
 	ctrl     *gomock.Controller
-	recorder *MockNotificationRepoInterfaceMockRecorder
+	recorder *MockExperimentsInterfaceMockRecorder
 }
 
-// MockNotificationRepoInterfaceMockRecorder is the mock recorder for MockNotificationRepoInterface.
-type MockNotificationRepoInterfaceMockRecorder struct {
-	mock *MockNotificationRepoInterface
+// MockExperimentsInterfaceMockRecorder is the mock recorder for MockExperimentsInterface.
+type MockExperimentsInterfaceMockRecorder struct {
+	mock *MockExperimentsInterface
 }
 
-// NewMockNotificationRepoInterface creates a new mock instance.
-func NewMockNotificationRepoInterface(ctrl *gomock.Controller) *MockNotificationRepoInterface {
-	mock := &MockNotificationRepoInterface{ctrl: ctrl}
-	mock.recorder = &MockNotificationRepoInterfaceMockRecorder{mock}
+// NewMockExperimentsInterface creates a new mock instance.
+func NewMockExperimentsInterface(ctrl *gomock.Controller) *MockExperimentsInterface {
+	mock := &MockExperimentsInterface{ctrl: ctrl}
+	mock.recorder = &MockExperimentsInterfaceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockNotificationRepoInterface) EXPECT() *MockNotificationRepoInterfaceMockRecorder {
+func (m *MockExperimentsInterface) EXPECT() *MockExperimentsInterfaceMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method.
-func (m *MockNotificationRepoInterface) Create(ctx context.Context, req domain.NotificationRequest) error {
+// Enabled mocks base method.
+func (m *MockExperimentsInterface) Enabled(ctx context.Context, flag string) bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, req)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "Enabled", ctx, flag)
+	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
-// Create indicates an expected call of Create.
-func (mr *MockNotificationRepoInterfaceMockRecorder) Create(ctx, req interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockNotificationRepoInterface)(nil).Create), ctx, req)
-}
-
-// GetAllByUserId mocks base method.
-func (m *MockNotificationRepoInterface) GetAllByUserId(ctx context.Context, userID int64) ([]domain.Notification, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUserId", ctx, userID)
-	ret0, _ := ret[0].([]domain.Notification)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetAllByUserId indicates an expected call of GetAllByUserId.
-func (mr *MockNotificationRepoInterfaceMockRecorder) GetAllByUserId(ctx, userID interface{}) *gomock.Call {
+// Enabled indicates an expected call of Enabled.
+func (mr *MockExperimentsInterfaceMockRecorder) Enabled(ctx, flag interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserId", reflect.TypeOf((*MockNotificationRepoInterface)(nil).GetAllByUserId), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockExperimentsInterface)(nil).Enabled), ctx, flag)
 }
 
-// SetRead mocks base method.
-func (m *MockNotificationRepoInterface) SetRead(ctx context.Context, notificationID int64) error {
+// Variant mocks base method.
+func (m *MockExperimentsInterface) Variant(ctx context.Context, experiment string) string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetRead", ctx, notificationID)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "Variant", ctx, experiment)
+	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-// SetRead indicates an expected call of SetRead.
-func (mr *MockNotificationRepoInterfaceMockRecorder) SetRead(ctx, notificationID interface{}) *gomock.Call {
+// Variant indicates an expected call of Variant.
+func (mr *MockExperimentsInterfaceMockRecorder) Variant(ctx, experiment interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRead", reflect.TypeOf((*MockNotificationRepoInterface)(nil).SetRead), ctx, notificationID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Variant", reflect.TypeOf((*MockExperimentsInterface)(nil).Variant), ctx, experiment)
 }
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+type experimentServiceModule struct {
+	cfg      *domain.Config
+	settings SettingsInterface
+	events   EventPublisherInterface
+}
+
+func NewExperimentService(cfg *domain.Config, settings SettingsInterface,
+	events EventPublisherInterface) (ExperimentsInterface, error) {
+	return &experimentServiceModule{
+		cfg:      cfg,
+		settings: settings,
+		events:   events,
+	}, nil
+}
+
+// Variant returns the variant of experiment for the user of the request. Requests without a subject, such
+// as background jobs, and experiments without a valid config get the control variant. Every participant
+// served a variant is logged with an exposure event.
+func (e experimentServiceModule) Variant(ctx context.Context, experiment string) string {
+	ctx, span := tracing.Start(ctx, "service.experiment.variant")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.experiment.variant"
+		tags["experiment"] = experiment
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	variants := domain.ExperimentVariants(experiment)
+	if len(variants) == 0 {
+		tags["warning"] = "unknown experiment"
+		tags["status"] = "success"
+		return ""
+	}
+
+	// An empty or invalid config keeps the experiment off, the settings store already drops invalid values.
+	config, _ := domain.ParseExperimentConfig(experiment, e.settings.String(ctx, domain.ExperimentSetting(experiment)))
+
+	subject, _ := domain.ExperimentSubjectFrom(ctx)
+	variant, participating := config.Assign(experiment, subject)
+
+	tags["variant"] = variant
+	tags["participating"] = participating
+
+	if participating {
+		// Exposure logging must not break the code path asking for the variant.
+		if err := e.events.Publish(ctx, domain.ExperimentExposedEvent{
+			UserID:     subject.UserID,
+			Experiment: experiment,
+			Variant:    variant,
+		}); err != nil {
+			tags["warning"] = "failed publish exposure"
+			tags["actual_error"] = err.Error()
+		}
+	}
+
+	tags["status"] = "success"
+	return variant
+}
+
+// Enabled reports whether flag is on for the user of the request.
+func (e experimentServiceModule) Enabled(ctx context.Context, flag string) bool {
+	return e.Variant(ctx, flag) == domain.VariantOn
+}
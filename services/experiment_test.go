@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func experimentSettingsMock(ctrl *gomock.Controller, experiment string, config string) *MockSettingsInterface {
+	settingsMock := NewMockSettingsInterface(ctrl)
+	settingsMock.EXPECT().String(gomock.Any(), domain.ExperimentSetting(experiment)).Return(config).AnyTimes()
+	return settingsMock
+}
+
+func subjectContext(userID int64, attributes map[string]string) context.Context {
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	return context.WithValue(ctx, domain.ExperimentSubjectKey, domain.ExperimentSubject{
+		UserID:     userID,
+		Attributes: attributes,
+	})
+}
+
+func Test_experimentServiceModule_Variant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iosInIndonesia := map[string]string{
+		domain.ExperimentAttributePlatform: "ios",
+		domain.ExperimentAttributeCountry:  "ID",
+		domain.ExperimentAttributePlan:     domain.PlanFree,
+	}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		experiment string
+		config     string
+		exposed    bool
+		publishErr error
+		want       string
+	}{
+		{
+			name:       "no subject gets control",
+			ctx:        context.WithValue(context.Background(), "requestid", "test"),
+			experiment: domain.ExperimentRecommendationRanking,
+			config:     `{"enabled": true, "rollout": 100, "weights": {"newest": 1}}`,
+			want:       domain.VariantControl,
+		},
+		{
+			name:       "not configured gets control",
+			ctx:        subjectContext(1, iosInIndonesia),
+			experiment: domain.ExperimentRecommendationRanking,
+			config:     "",
+			want:       domain.VariantControl,
+		},
+		{
+			name:       "disabled gets control",
+			ctx:        subjectContext(1, iosInIndonesia),
+			experiment: domain.ExperimentRecommendationRanking,
+			config:     `{"enabled": false, "rollout": 100, "weights": {"newest": 1}}`,
+			want:       domain.VariantControl,
+		},
+		{
+			name:       "zero rollout gets control",
+			ctx:        subjectContext(1, iosInIndonesia),
+			experiment: domain.ExperimentRecommendationRanking,
+			config:     `{"enabled": true, "rollout": 0, "weights": {"newest": 1}}`,
+			want:       domain.VariantControl,
+		},
+		{
+			name:       "not targeted gets control",
+			ctx:        subjectContext(1, iosInIndonesia),
+			experiment: domain.ExperimentRecommendationRanking,
+			config:     `{"enabled": true, "rollout": 100, "weights": {"newest": 1}, "targeting": {"platform": ["android"]}}`,
+			want:       domain.VariantControl,
+		},
+		{
+			name:       "targeted participant is exposed",
+			ctx:        subjectContext(1, iosInIndonesia),
+			experiment: domain.ExperimentRecommendationRanking,
+			config:     `{"enabled": true, "rollout": 100, "weights": {"newest": 1}, "targeting": {"country": ["id", "sg"], "plan": ["free"]}}`,
+			exposed:    true,
+			want:       domain.VariantRankingNewest,
+		},
+		{
+			name:       "exposure failure still serves the variant",
+			ctx:        subjectContext(1, iosInIndonesia),
+			experiment: domain.FlagFreeDislikes,
+			config:     `{"enabled": true, "rollout": 100, "weights": {"on": 1}}`,
+			exposed:    true,
+			publishErr: errors.New("test"),
+			want:       domain.VariantOn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventsMock := NewMockEventPublisherInterface(ctrl)
+			if tt.exposed {
+				subject, _ := domain.ExperimentSubjectFrom(tt.ctx)
+				eventsMock.EXPECT().Publish(tt.ctx, domain.ExperimentExposedEvent{
+					UserID:     subject.UserID,
+					Experiment: tt.experiment,
+					Variant:    tt.want,
+				}).Return(tt.publishErr)
+			}
+
+			e := experimentServiceModule{
+				settings: experimentSettingsMock(ctrl, tt.experiment, tt.config),
+				events:   eventsMock,
+			}
+
+			if got := e.Variant(tt.ctx, tt.experiment); got != tt.want {
+				t.Errorf("Variant() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_experimentServiceModule_Variant_bucketing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eventsMock := NewMockEventPublisherInterface(ctrl)
+	eventsMock.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	variants := func(config string) map[int64]string {
+		e := experimentServiceModule{
+			settings: experimentSettingsMock(ctrl, domain.ExperimentRecommendationRanking, config),
+			events:   eventsMock,
+		}
+
+		assigned := make(map[int64]string)
+		for userID := int64(1); userID <= 1000; userID++ {
+			assigned[userID] = e.Variant(subjectContext(userID, nil), domain.ExperimentRecommendationRanking)
+		}
+		return assigned
+	}
+
+	half := variants(`{"enabled": true, "rollout": 50, "weights": {"newest": 1}}`)
+	if again := variants(`{"enabled": true, "rollout": 50, "weights": {"newest": 1}}`); len(again) != len(half) {
+		t.Fatalf("Variant() assigned %d users, want %d", len(again), len(half))
+	} else {
+		for userID, variant := range half {
+			if again[userID] != variant {
+				t.Fatalf("Variant() user %d got %s then %s, want a stable assignment", userID, variant, again[userID])
+			}
+		}
+	}
+
+	newest := 0
+	for _, variant := range half {
+		if variant == domain.VariantRankingNewest {
+			newest++
+		}
+	}
+	if newest < 400 || newest > 600 {
+		t.Errorf("Variant() put %d of 1000 users in a 50%% rollout", newest)
+	}
+
+	// Raising the rollout only adds users, nobody already in the experiment leaves it.
+	for userID, variant := range variants(`{"enabled": true, "rollout": 80, "weights": {"newest": 1}}`) {
+		if half[userID] == domain.VariantRankingNewest && variant != domain.VariantRankingNewest {
+			t.Fatalf("Variant() moved user %d out of the experiment when raising the rollout", userID)
+		}
+	}
+
+	// Without weights participants are split evenly between the variants.
+	even := 0
+	for _, variant := range variants(`{"enabled": true, "rollout": 100}`) {
+		if variant == domain.VariantRankingNewest {
+			even++
+		}
+	}
+	if even < 400 || even > 600 {
+		t.Errorf("Variant() put %d of 1000 users in newest with an even split", even)
+	}
+}
+
+func Test_experimentServiceModule_Enabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eventsMock := NewMockEventPublisherInterface(ctrl)
+	eventsMock.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+	e := experimentServiceModule{
+		settings: experimentSettingsMock(ctrl, domain.FlagFreeDislikes, `{"enabled": true, "rollout": 100, "weights": {"on": 1}}`),
+		events:   eventsMock,
+	}
+
+	if !e.Enabled(subjectContext(1, nil), domain.FlagFreeDislikes) {
+		t.Errorf("Enabled() = false, want true")
+	}
+	if e.Enabled(context.WithValue(context.Background(), "requestid", "test"), domain.FlagFreeDislikes) {
+		t.Errorf("Enabled() without subject = true, want false")
+	}
+}
+
+func TestExperimentSettingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "valid", value: `{"enabled": true, "rollout": 10, "weights": {"control": 1, "newest": 1}}`},
+		{name: "not json", value: "on", wantErr: true},
+		{name: "rollout above 100", value: `{"enabled": true, "rollout": 101}`, wantErr: true},
+		{name: "unknown variant", value: `{"enabled": true, "rollout": 10, "weights": {"oldest": 1}}`, wantErr: true},
+		{name: "negative weight", value: `{"enabled": true, "rollout": 10, "weights": {"newest": -1}}`, wantErr: true},
+		{name: "zero weights", value: `{"enabled": true, "rollout": 10, "weights": {"newest": 0}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition, _ := domain.GetSettingDefinition(domain.ExperimentSetting(domain.ExperimentRecommendationRanking))
+			if err := definition.Validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
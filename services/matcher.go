@@ -3,55 +3,77 @@ package services
 import (
 	"context"
 	"errors"
-	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"time"
 )
 
+const (
+	defaultMatchExpiryDays  = 7
+	matchLifecycleBatchSize = 100
+)
+
+var defaultMatchReminderDays = []int{1, 3}
+
 type matcherServiceModule struct {
-	cfg              *domain.Config
-	profileRepo      ProfileRepoInterface
-	inventoryRepo    InventoryRepoInterface
-	matchedRepo      MatchedRepoInterface
-	notificationRepo NotificationRepoInterface
+	cfg           *domain.Config
+	profileRepo   ProfileRepoInterface
+	inventoryRepo InventoryRepoInterface
+	matchedRepo   MatchedRepoInterface
+	unitOfWork    UnitOfWorkInterface
+	outboxRepo    OutboxRepoInterface
+	events        EventPublisherInterface
+	experiments   ExperimentsInterface
 }
 
 type MatcherServiceInterface interface {
 	Like(ctx context.Context, userID int64, targetUserID int64) error
 	SuperLike(ctx context.Context, userID int64, targetUserID int64) error
 	Dislike(ctx context.Context, userID int64, targetUserID int64) error
+	Unmatch(ctx context.Context, userID int64, targetUserID int64) error
+	ExpireStaleMatches(ctx context.Context) (int, error)
+	SendMatchReminders(ctx context.Context) (int, error)
 }
 
 func NewMatcherService(cfg *domain.Config,
 	profileRepo ProfileRepoInterface,
 	inventoryRepo InventoryRepoInterface,
 	matchedRepo MatchedRepoInterface,
-	notificationRepo NotificationRepoInterface) (MatcherServiceInterface, error) {
+	unitOfWork UnitOfWorkInterface,
+	outboxRepo OutboxRepoInterface,
+	events EventPublisherInterface,
+	experiments ExperimentsInterface) (MatcherServiceInterface, error) {
 	return &matcherServiceModule{
-		cfg:              cfg,
-		profileRepo:      profileRepo,
-		inventoryRepo:    inventoryRepo,
-		matchedRepo:      matchedRepo,
-		notificationRepo: notificationRepo,
+		cfg:           cfg,
+		profileRepo:   profileRepo,
+		inventoryRepo: inventoryRepo,
+		matchedRepo:   matchedRepo,
+		unitOfWork:    unitOfWork,
+		outboxRepo:    outboxRepo,
+		events:        events,
+		experiments:   experiments,
 	}, nil
 }
 
 func (m matcherServiceModule) Like(ctx context.Context, userID int64, targetUserID int64) error {
+	ctx, span := tracing.Start(ctx, "service.matcher.like")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "service.matcher.like"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	if targetUserID == userID {
 		tags["error"] = "cannot like yourself"
 		tags["status"] = "error"
-		return errors.New("cannot like yourself")
+		return domain.ErrSwipeSelf
 	}
 
 	inventory, err := m.inventoryRepo.GetByUserId(ctx, userID)
@@ -64,13 +86,13 @@ func (m matcherServiceModule) Like(ctx context.Context, userID int64, targetUser
 	if inventory.Swipes < 1 {
 		tags["error"] = "insufficient swipes"
 		tags["status"] = "error"
-		return errors.New("insufficient swipes")
+		return domain.ErrInsufficientSwipes
 	}
 
 	if inventory.Likes < 1 {
 		tags["error"] = "insufficient likes"
 		tags["status"] = "error"
-		return errors.New("insufficient likes")
+		return domain.ErrInsufficientLikes
 	}
 
 	targetProfile, err := m.profileRepo.GetProfile(ctx, userID)
@@ -83,7 +105,7 @@ func (m matcherServiceModule) Like(ctx context.Context, userID int64, targetUser
 	if targetProfile == nil {
 		tags["error"] = "profile not found"
 		tags["status"] = "error"
-		return errors.New("profile not found")
+		return domain.ErrProfileNotFound
 	}
 
 	isExists, err := m.matchedRepo.IsExists(ctx, domain.MatchRequest{
@@ -99,48 +121,84 @@ func (m matcherServiceModule) Like(ctx context.Context, userID int64, targetUser
 	if isExists {
 		tags["error"] = "already matched"
 		tags["status"] = "warning"
-		return errors.New("already matched")
+		return domain.ErrAlreadyMatched
 	}
 
-	if err := m.matchedRepo.Create(ctx, domain.MatchRequest{
-		UserID:       userID,
-		TargetUserID: targetUserID,
-	}); err != nil {
-		tags["error"] = "failed create matched"
-		tags["status"] = "error"
-		return err
-	}
+	// The swipe, the inventory and whatever sync subscribers write for it commit together.
+	if err := m.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		matchID, err := m.matchedRepo.Create(ctx, domain.MatchRequest{
+			UserID:       userID,
+			TargetUserID: targetUserID,
+		})
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			// A concurrent swipe on the same user won the race since the check above.
+			tags["error"] = "already matched"
+			return domain.ErrAlreadyMatched
+		}
 
-	isMatched, err := m.matchedRepo.IsMatched(ctx, domain.MatchRequest{
-		UserID:       targetUserID,
-		TargetUserID: userID,
-	})
-	if err != nil {
-		tags["error"] = "failed check matched"
-		tags["status"] = "error"
-		return err
-	}
+		if err != nil {
+			tags["error"] = "failed create matched"
+			return err
+		}
 
-	if isMatched {
-		if err := m.notificationRepo.Create(ctx, domain.NotificationRequest{
-			UserID:  targetUserID,
-			Message: fmt.Sprintf("You have a new match with %s", targetProfile.Name),
+		isMatched, err := m.matchedRepo.IsMatched(ctx, domain.MatchRequest{
+			UserID:       targetUserID,
+			TargetUserID: userID,
+		})
+		if err != nil {
+			tags["error"] = "failed check matched"
+			return err
+		}
+
+		if err := m.events.Publish(ctx, domain.SwipedEvent{
+			UserID:       userID,
+			TargetUserID: targetUserID,
+			UserName:     targetProfile.Name,
+			Action:       domain.SwipeActionLike,
+			Mutual:       isMatched,
 		}); err != nil {
-			tags["error"] = "failed create notification"
-			tags["status"] = "error"
+			tags["error"] = "failed publish swiped"
 			return err
 		}
-		return nil
-	}
 
-	if err := m.inventoryRepo.UpdateSwipes(ctx, userID, int(inventory.Swipes)-1); err != nil {
-		tags["error"] = "failed update swipes"
-		tags["status"] = "error"
-		return err
-	}
+		if isMatched {
+			if err := m.events.Publish(ctx, domain.MatchedEvent{
+				MatchID:      matchID,
+				UserID:       userID,
+				TargetUserID: targetUserID,
+				UserName:     targetProfile.Name,
+			}); err != nil {
+				tags["error"] = "failed publish matched"
+				return err
+			}
+			return nil
+		}
+
+		spent, err := m.inventoryRepo.SpendSwipe(ctx, userID)
+		if err != nil {
+			tags["error"] = "failed spend swipes"
+			return err
+		}
+
+		if !spent {
+			// Spent by a concurrent swipe since the inventory was read.
+			tags["error"] = "insufficient swipes"
+			return domain.ErrInsufficientSwipes
+		}
 
-	if err := m.inventoryRepo.UpdateLikes(ctx, userID, int(inventory.Likes)-1); err != nil {
-		tags["error"] = "failed update likes"
+		spent, err = m.inventoryRepo.SpendLike(ctx, userID)
+		if err != nil {
+			tags["error"] = "failed spend likes"
+			return err
+		}
+
+		if !spent {
+			tags["error"] = "insufficient likes"
+			return domain.ErrInsufficientLikes
+		}
+
+		return nil
+	}); err != nil {
 		tags["status"] = "error"
 		return err
 	}
@@ -150,20 +208,22 @@ func (m matcherServiceModule) Like(ctx context.Context, userID int64, targetUser
 }
 
 func (m matcherServiceModule) SuperLike(ctx context.Context, userID int64, targetUserID int64) error {
+	ctx, span := tracing.Start(ctx, "service.matcher.super_like")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "service.matcher.super_like"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	if targetUserID == userID {
 		tags["error"] = "cannot super like yourself"
 		tags["status"] = "error"
-		return errors.New("cannot super like yourself")
+		return domain.ErrSwipeSelf
 	}
 
 	inventory, err := m.inventoryRepo.GetByUserId(ctx, userID)
@@ -176,13 +236,13 @@ func (m matcherServiceModule) SuperLike(ctx context.Context, userID int64, targe
 	if inventory.Swipes < 1 {
 		tags["error"] = "insufficient swipes"
 		tags["status"] = "error"
-		return errors.New("insufficient swipes")
+		return domain.ErrInsufficientSwipes
 	}
 
 	if inventory.SuperLikes < 1 {
 		tags["error"] = "insufficient super likes"
 		tags["status"] = "error"
-		return errors.New("insufficient super likes")
+		return domain.ErrInsufficientSuperLikes
 	}
 
 	targetProfile, err := m.profileRepo.GetProfile(ctx, userID)
@@ -195,7 +255,7 @@ func (m matcherServiceModule) SuperLike(ctx context.Context, userID int64, targe
 	if targetProfile == nil {
 		tags["error"] = "profile not found"
 		tags["status"] = "error"
-		return errors.New("profile not found")
+		return domain.ErrProfileNotFound
 	}
 
 	isExists, err := m.matchedRepo.IsExists(ctx, domain.MatchRequest{
@@ -211,57 +271,149 @@ func (m matcherServiceModule) SuperLike(ctx context.Context, userID int64, targe
 	if isExists {
 		tags["error"] = "already matched"
 		tags["status"] = "warning"
-		return errors.New("already matched")
+		return domain.ErrAlreadyMatched
 	}
 
-	if err := m.matchedRepo.Create(ctx, domain.MatchRequest{
-		UserID:       userID,
-		TargetUserID: targetUserID,
+	// The swipe, the inventory and whatever sync subscribers write for it commit together.
+	if err := m.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		matchID, err := m.matchedRepo.Create(ctx, domain.MatchRequest{
+			UserID:       userID,
+			TargetUserID: targetUserID,
+		})
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			// A concurrent swipe on the same user won the race since the check above.
+			tags["error"] = "already matched"
+			return domain.ErrAlreadyMatched
+		}
+
+		if err != nil {
+			tags["error"] = "failed create matched"
+			return err
+		}
+
+		isMatched, err := m.matchedRepo.IsMatched(ctx, domain.MatchRequest{
+			UserID:       targetUserID,
+			TargetUserID: userID,
+		})
+		if err != nil {
+			tags["error"] = "failed check matched"
+			return err
+		}
+
+		if err := m.events.Publish(ctx, domain.SwipedEvent{
+			UserID:       userID,
+			TargetUserID: targetUserID,
+			UserName:     targetProfile.Name,
+			Action:       domain.SwipeActionSuperLike,
+			Mutual:       isMatched,
+		}); err != nil {
+			tags["error"] = "failed publish swiped"
+			return err
+		}
+
+		if isMatched {
+			if err := m.events.Publish(ctx, domain.MatchedEvent{
+				MatchID:      matchID,
+				UserID:       userID,
+				TargetUserID: targetUserID,
+				UserName:     targetProfile.Name,
+			}); err != nil {
+				tags["error"] = "failed publish matched"
+				return err
+			}
+			return nil
+		}
+
+		spent, err := m.inventoryRepo.SpendSwipe(ctx, userID)
+		if err != nil {
+			tags["error"] = "failed spend swipes"
+			return err
+		}
+
+		if !spent {
+			// Spent by a concurrent swipe since the inventory was read.
+			tags["error"] = "insufficient swipes"
+			return domain.ErrInsufficientSwipes
+		}
+
+		spent, err = m.inventoryRepo.SpendSuperLike(ctx, userID)
+		if err != nil {
+			tags["error"] = "failed spend super likes"
+			return err
+		}
+
+		if !spent {
+			tags["error"] = "insufficient super likes"
+			return domain.ErrInsufficientSuperLikes
+		}
+
+		return nil
 	}); err != nil {
-		tags["error"] = "failed create matched"
 		tags["status"] = "error"
 		return err
 	}
 
-	isMatched, err := m.matchedRepo.IsMatched(ctx, domain.MatchRequest{
-		UserID:       targetUserID,
-		TargetUserID: userID,
-	})
-	if err != nil {
-		tags["error"] = "failed check matched"
+	tags["status"] = "success"
+	return nil
+}
+
+func (m matcherServiceModule) Dislike(ctx context.Context, userID int64, targetUserID int64) error {
+	ctx, span := tracing.Start(ctx, "service.matcher.dislike")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.matcher.dislike"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if targetUserID == userID {
+		tags["error"] = "cannot dislike yourself"
 		tags["status"] = "error"
-		return err
+		return domain.ErrSwipeSelf
 	}
 
-	if isMatched {
-		if err := m.notificationRepo.Create(ctx, domain.NotificationRequest{
-			UserID:  targetUserID,
-			Message: fmt.Sprintf("You have a new match with %s", targetProfile.Name),
-		}); err != nil {
-			tags["error"] = "failed create notification"
+	// Dislikes are free for users in the flag, they neither need nor spend a swipe.
+	if m.experiments.Enabled(ctx, domain.FlagFreeDislikes) {
+		tags["free_dislike"] = true
+	} else {
+		inventory, err := m.inventoryRepo.GetByUserId(ctx, userID)
+		if err != nil {
+			tags["error"] = "failed get inventory"
 			tags["status"] = "error"
 			return err
 		}
-		return nil
-	}
 
-	if err := m.notificationRepo.Create(ctx, domain.NotificationRequest{
-		UserID:  targetUserID,
-		Message: fmt.Sprintf("%s super liked you", targetProfile.Name),
-	}); err != nil {
-		tags["error"] = "failed create notification"
-		tags["status"] = "error"
-		return err
-	}
+		if inventory.Swipes < 1 {
+			tags["error"] = "insufficient swipes"
+			tags["status"] = "error"
+			return domain.ErrInsufficientSwipes
+		}
 
-	if err := m.inventoryRepo.UpdateSwipes(ctx, userID, int(inventory.Swipes)-1); err != nil {
-		tags["error"] = "failed update swipes"
-		tags["status"] = "error"
-		return err
+		spent, err := m.inventoryRepo.SpendSwipe(ctx, userID)
+		if err != nil {
+			tags["error"] = "failed spend swipes"
+			tags["status"] = "error"
+			return err
+		}
+
+		if !spent {
+			// Spent by a concurrent swipe since the inventory was read.
+			tags["error"] = "insufficient swipes"
+			tags["status"] = "error"
+			return domain.ErrInsufficientSwipes
+		}
 	}
 
-	if err := m.inventoryRepo.UpdateSuperLikes(ctx, userID, int(inventory.SuperLikes)-1); err != nil {
-		tags["error"] = "failed update super likes"
+	if err := m.events.Publish(ctx, domain.SwipedEvent{
+		UserID:       userID,
+		TargetUserID: targetUserID,
+		Action:       domain.SwipeActionDislike,
+	}); err != nil {
+		tags["error"] = "failed publish swiped"
 		tags["status"] = "error"
 		return err
 	}
@@ -270,38 +422,49 @@ func (m matcherServiceModule) SuperLike(ctx context.Context, userID int64, targe
 	return nil
 }
 
-func (m matcherServiceModule) Dislike(ctx context.Context, userID int64, targetUserID int64) error {
+func (m matcherServiceModule) Unmatch(ctx context.Context, userID int64, targetUserID int64) error {
+	ctx, span := tracing.Start(ctx, "service.matcher.unmatch")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
-		tags["name"] = "service.matcher.dislike"
+		tags["name"] = "service.matcher.unmatch"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
-	if targetUserID == userID {
-		tags["error"] = "cannot dislike yourself"
-		tags["status"] = "error"
-		return errors.New("cannot dislike yourself")
+	req := domain.MatchRequest{
+		UserID:       userID,
+		TargetUserID: targetUserID,
 	}
 
-	inventory, err := m.inventoryRepo.GetByUserId(ctx, userID)
+	isMutual, err := m.matchedRepo.IsMutual(ctx, req)
 	if err != nil {
-		tags["error"] = "failed get inventory"
+		tags["error"] = "failed check matched"
 		tags["status"] = "error"
 		return err
 	}
 
-	if inventory.Swipes < 1 {
-		tags["error"] = "insufficient swipes"
+	if !isMutual {
+		tags["error"] = "not matched"
+		tags["status"] = "error"
+		return domain.ErrNotMatched
+	}
+
+	if err := m.matchedRepo.Unmatch(ctx, req); err != nil {
+		tags["error"] = "failed unmatch"
 		tags["status"] = "error"
-		return errors.New("insufficient swipes")
+		return err
 	}
 
-	if err := m.inventoryRepo.UpdateSwipes(ctx, userID, int(inventory.Swipes)-1); err != nil {
-		tags["error"] = "failed update swipes"
+	if err := m.events.Publish(ctx, domain.UnmatchedEvent{
+		UserID:       userID,
+		TargetUserID: targetUserID,
+		Reason:       domain.UnmatchReasonUser,
+	}); err != nil {
+		tags["error"] = "failed publish unmatched"
 		tags["status"] = "error"
 		return err
 	}
@@ -309,3 +472,187 @@ func (m matcherServiceModule) Dislike(ctx context.Context, userID int64, targetU
 	tags["status"] = "success"
 	return nil
 }
+
+// ExpireStaleMatches soft-deletes mutual matches that went ExpiryDays without a message.
+func (m matcherServiceModule) ExpireStaleMatches(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "service.matcher.expire_stale_matches")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.matcher.expire_stale_matches"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	filter := domain.StaleMatchFilter{
+		MatchedBefore: time.Now().Add(-matchExpiry(m.cfg)),
+		Limit:         matchLifecycleBatchSize,
+	}
+
+	// Expired matches drop out of the query, so every batch starts from the top again.
+	expired := 0
+	for {
+		matches, err := m.matchedRepo.GetStaleMatches(ctx, filter)
+		if err != nil {
+			tags["error"] = "failed get stale matches"
+			tags["actual_error"] = err.Error()
+			tags["status"] = "error"
+			return expired, err
+		}
+
+		for _, match := range matches {
+			if err := m.matchedRepo.Unmatch(ctx, domain.MatchRequest{
+				UserID:       match.UserAID,
+				TargetUserID: match.UserBID,
+			}); err != nil {
+				tags["error"] = "failed expire match"
+				tags["actual_error"] = err.Error()
+				tags["status"] = "error"
+				return expired, err
+			}
+
+			if err := m.events.Publish(ctx, domain.UnmatchedEvent{
+				UserID:       match.UserAID,
+				TargetUserID: match.UserBID,
+				Reason:       domain.UnmatchReasonExpired,
+			}); err != nil {
+				tags["error"] = "failed publish unmatched"
+				tags["actual_error"] = err.Error()
+				tags["status"] = "error"
+				return expired, err
+			}
+			expired++
+		}
+
+		if len(matches) < filter.Limit {
+			break
+		}
+	}
+
+	tags["expired"] = expired
+	tags["status"] = "success"
+	return expired, nil
+}
+
+// SendMatchReminders nudges both sides of a quiet match once per reminder stage. A stage is claimed in the same
+// transaction that queues the notifications, so overlapping runs never send it twice and a failed run retries it.
+func (m matcherServiceModule) SendMatchReminders(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "service.matcher.send_match_reminders")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.matcher.send_match_reminders"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	now := time.Now()
+	expiry := matchExpiry(m.cfg)
+
+	sent := 0
+	for _, days := range matchReminderDays(m.cfg) {
+		stage := time.Duration(days) * 24 * time.Hour
+		if stage >= expiry {
+			continue
+		}
+
+		filter := domain.StaleMatchFilter{
+			MatchedBefore:   now.Add(-stage),
+			MatchedAfter:    now.Add(-expiry),
+			WithoutReminder: days,
+			Limit:           matchLifecycleBatchSize,
+		}
+
+		for {
+			matches, err := m.matchedRepo.GetStaleMatches(ctx, filter)
+			if err != nil {
+				tags["error"] = "failed get stale matches"
+				tags["actual_error"] = err.Error()
+				tags["status"] = "error"
+				return sent, err
+			}
+
+			for _, match := range matches {
+				ago := int(now.Sub(match.MatchedAt) / (24 * time.Hour))
+				reminders := []domain.NotificationRequest{
+					matchReminder(match.UserAID, match.UserBID, match.UserBName, ago),
+					matchReminder(match.UserBID, match.UserAID, match.UserAName, ago),
+				}
+
+				claimed := false
+				if err := m.unitOfWork.Do(ctx, func(ctx context.Context) error {
+					claimed, err = m.matchedRepo.ClaimReminder(ctx, match, days)
+					if err != nil {
+						tags["error"] = "failed claim reminder"
+						return err
+					}
+
+					if !claimed {
+						return nil
+					}
+
+					for _, req := range reminders {
+						if err := m.outboxRepo.Enqueue(ctx, domain.OutboxKindNotification, req); err != nil {
+							tags["error"] = "failed enqueue notification"
+							return err
+						}
+					}
+
+					return nil
+				}); err != nil {
+					tags["actual_error"] = err.Error()
+					tags["status"] = "error"
+					return sent, err
+				}
+
+				if claimed {
+					sent += len(reminders)
+				}
+			}
+
+			if len(matches) < filter.Limit {
+				break
+			}
+		}
+	}
+
+	tags["sent"] = sent
+	tags["status"] = "success"
+	return sent, nil
+}
+
+func matchExpiry(cfg *domain.Config) time.Duration {
+	days := defaultMatchExpiryDays
+	if cfg.Matches.ExpiryDays > 0 {
+		days = cfg.Matches.ExpiryDays
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func matchReminderDays(cfg *domain.Config) []int {
+	if len(cfg.Matches.ReminderDays) > 0 {
+		return cfg.Matches.ReminderDays
+	}
+
+	return defaultMatchReminderDays
+}
+
+func matchReminder(userID, actorUserID int64, actorName string, days int) domain.NotificationRequest {
+	return domain.NotificationRequest{
+		UserID:      userID,
+		Type:        domain.NotificationTypeMatch,
+		TemplateKey: domain.NotificationTemplateMatchReminder,
+		Payload: domain.NotificationPayload{
+			ActorUserID: actorUserID,
+			ActorName:   actorName,
+			Days:        days,
+		},
+	}
+}
@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/zombozo12/tinder-dealls/domain"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewMatcherService(t *testing.T) {
@@ -14,11 +16,14 @@ func TestNewMatcherService(t *testing.T) {
 	defer ctrl.Finish()
 
 	type args struct {
-		cfg              *domain.Config
-		profileRepo      ProfileRepoInterface
-		inventoryRepo    InventoryRepoInterface
-		matchedRepo      MatchedRepoInterface
-		notificationRepo NotificationRepoInterface
+		cfg           *domain.Config
+		profileRepo   ProfileRepoInterface
+		inventoryRepo InventoryRepoInterface
+		matchedRepo   MatchedRepoInterface
+		unitOfWork    UnitOfWorkInterface
+		outboxRepo    OutboxRepoInterface
+		events        EventPublisherInterface
+		experiments   ExperimentsInterface
 	}
 
 	config := &domain.Config{
@@ -47,7 +52,10 @@ func TestNewMatcherService(t *testing.T) {
 	inventoryMock := NewMockInventoryRepoInterface(ctrl)
 	profileMock := NewMockProfileRepoInterface(ctrl)
 	matchedMock := NewMockMatchedRepoInterface(ctrl)
-	notificationMock := NewMockNotificationRepoInterface(ctrl)
+	unitOfWorkMock := NewMockUnitOfWorkInterface(ctrl)
+	outboxMock := NewMockOutboxRepoInterface(ctrl)
+	eventsMock := NewMockEventPublisherInterface(ctrl)
+	experimentsMock := NewMockExperimentsInterface(ctrl)
 
 	tests := []struct {
 		name    string
@@ -58,18 +66,24 @@ func TestNewMatcherService(t *testing.T) {
 		{
 			name: "success",
 			args: args{
-				cfg:              config,
-				profileRepo:      profileMock,
-				inventoryRepo:    inventoryMock,
-				matchedRepo:      matchedMock,
-				notificationRepo: notificationMock,
+				cfg:           config,
+				profileRepo:   profileMock,
+				inventoryRepo: inventoryMock,
+				matchedRepo:   matchedMock,
+				unitOfWork:    unitOfWorkMock,
+				outboxRepo:    outboxMock,
+				events:        eventsMock,
+				experiments:   experimentsMock,
 			},
 			want: &matcherServiceModule{
-				cfg:              config,
-				profileRepo:      profileMock,
-				inventoryRepo:    inventoryMock,
-				matchedRepo:      matchedMock,
-				notificationRepo: notificationMock,
+				cfg:           config,
+				profileRepo:   profileMock,
+				inventoryRepo: inventoryMock,
+				matchedRepo:   matchedMock,
+				unitOfWork:    unitOfWorkMock,
+				outboxRepo:    outboxMock,
+				events:        eventsMock,
+				experiments:   experimentsMock,
 			},
 			wantErr: false,
 		},
@@ -77,7 +91,8 @@ func TestNewMatcherService(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewMatcherService(tt.args.cfg, tt.args.profileRepo, tt.args.inventoryRepo, tt.args.matchedRepo, tt.args.notificationRepo)
+			got, err := NewMatcherService(tt.args.cfg, tt.args.profileRepo, tt.args.inventoryRepo, tt.args.matchedRepo,
+				tt.args.unitOfWork, tt.args.outboxRepo, tt.args.events, tt.args.experiments)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewAuthService() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -147,11 +162,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 			name: "failed target user id is same with user id",
 			mock: func() *matcherServiceModule {
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    nil,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: nil,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    failedArgs,
@@ -164,11 +180,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).Return(nil, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -183,11 +200,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				}, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -203,11 +221,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				}, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -226,11 +245,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(nil, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -249,11 +269,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(nil, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -280,11 +301,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				}).Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -311,11 +333,12 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				}).Return(true, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -343,14 +366,51 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(errors.New("test"))
+				}).Return(int64(0), errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
+				}
+			},
+			args:    successArgs,
+			wantErr: true,
+		},
+		{
+			name: "failed create match lost race",
+			mock: func() *matcherServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).Return(&domain.Inventory{
+					Swipes: 1,
+					Likes:  1,
+				}, nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(&domain.Profile{
+					ID: 1,
+				}, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsExists(ctx, domain.MatchRequest{
+					UserID:       int64(1),
+					TargetUserID: int64(2),
+				}).Return(false, nil)
+				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
+					UserID:       int64(1),
+					TargetUserID: int64(2),
+				}).Return(int64(0), fmt.Errorf("%w: matched_pair_key", domain.ErrAlreadyExists))
+
+				return &matcherServiceModule{
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -378,25 +438,26 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
 			wantErr: true,
 		},
 		{
-			name:    "is matched - failed create notification",
+			name:    "is matched - failed publish matched",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -419,29 +480,37 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(true, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: "You have a new match with ",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionLike,
+					Mutual:       true,
+				}).Return(nil)
+				eventsMock.EXPECT().Publish(ctx, domain.MatchedEvent{
+					MatchID:      10,
+					UserID:       1,
+					TargetUserID: 2,
 				}).Return(errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "is matched - success create notification",
+			name:    "is matched - success",
 			args:    successArgs,
 			wantErr: false,
 			mock: func() *matcherServiceModule {
@@ -464,29 +533,37 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(true, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: "You have a new match with ",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionLike,
+					Mutual:       true,
+				}).Return(nil)
+				eventsMock.EXPECT().Publish(ctx, domain.MatchedEvent{
+					MatchID:      10,
+					UserID:       1,
+					TargetUserID: 2,
 				}).Return(nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "failed update swipes",
+			name:    "failed spend swipes",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -509,26 +586,34 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(errors.New("test"))
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionLike,
+				}).Return(nil)
+
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "failed update swipes",
+			name:    "failed spend swipes",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -551,26 +636,34 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(errors.New("test"))
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionLike,
+				}).Return(nil)
+
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "failed update likes",
+			name:    "failed spend likes",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -593,24 +686,82 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
+				}).Return(int64(10), nil)
+				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
+					UserID:       int64(2),
+					TargetUserID: int64(1),
+				}).Return(false, nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionLike,
 				}).Return(nil)
+
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(true, nil)
+
+				inventoryMock.EXPECT().SpendLike(ctx, int64(1)).
+					Return(false, errors.New("test"))
+
+				return &matcherServiceModule{
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
+				}
+			},
+		},
+		{
+			name:    "failed swipe spent by a concurrent swipe",
+			args:    successArgs,
+			wantErr: true,
+			mock: func() *matcherServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).Return(&domain.Inventory{
+					ID:     1,
+					Swipes: 1,
+					Likes:  1,
+				}, nil)
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(&domain.Profile{
+					ID: 1,
+				}, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsExists(ctx, domain.MatchRequest{
+					UserID:       int64(1),
+					TargetUserID: int64(2),
+				}).Return(false, nil)
+				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
+					UserID:       int64(1),
+					TargetUserID: int64(2),
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(nil)
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionLike,
+				}).Return(nil)
 
-				inventoryMock.EXPECT().UpdateLikes(ctx, int64(1), 0).
-					Return(errors.New("test"))
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(false, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
@@ -638,24 +789,32 @@ func Test_matcherServiceModule_Like(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(nil)
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionLike,
+				}).Return(nil)
 
-				inventoryMock.EXPECT().UpdateLikes(ctx, int64(1), 0).
-					Return(nil)
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(true, nil)
+
+				inventoryMock.EXPECT().SpendLike(ctx, int64(1)).
+					Return(true, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
@@ -728,11 +887,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 			name: "failed target user id is same with user id",
 			mock: func() *matcherServiceModule {
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    nil,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: nil,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    failedArgs,
@@ -745,11 +905,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).Return(nil, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -764,11 +925,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				}, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -784,11 +946,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				}, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -807,11 +970,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(nil, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -830,11 +994,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(nil, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -861,11 +1026,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				}).Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -892,11 +1058,12 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				}).Return(true, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -924,14 +1091,15 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(errors.New("test"))
+				}).Return(int64(0), errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
@@ -959,25 +1127,26 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        nil,
 				}
 			},
 			args:    successArgs,
 			wantErr: true,
 		},
 		{
-			name:    "is matched - failed create notification",
+			name:    "is matched - failed publish matched",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -1000,29 +1169,37 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(true, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: "You have a new match with ",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionSuperLike,
+					Mutual:       true,
+				}).Return(nil)
+				eventsMock.EXPECT().Publish(ctx, domain.MatchedEvent{
+					MatchID:      10,
+					UserID:       1,
+					TargetUserID: 2,
 				}).Return(errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "is matched - success create notification",
+			name:    "is matched - success",
 			args:    successArgs,
 			wantErr: false,
 			mock: func() *matcherServiceModule {
@@ -1045,29 +1222,37 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(true, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: "You have a new match with ",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionSuperLike,
+					Mutual:       true,
+				}).Return(nil)
+				eventsMock.EXPECT().Publish(ctx, domain.MatchedEvent{
+					MatchID:      10,
+					UserID:       1,
+					TargetUserID: 2,
 				}).Return(nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "failed notification super liked",
+			name:    "failed publish swiped",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -1090,29 +1275,31 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: " super liked you",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionSuperLike,
 				}).Return(errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "failed update swipes",
+			name:    "failed spend swipes",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -1135,32 +1322,34 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: " super liked you",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionSuperLike,
 				}).Return(nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(errors.New("test"))
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
 		{
-			name:    "failed update super likes",
+			name:    "failed spend super likes",
 			args:    successArgs,
 			wantErr: true,
 			mock: func() *matcherServiceModule {
@@ -1183,30 +1372,85 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
+				}).Return(int64(10), nil)
+				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
+					UserID:       int64(2),
+					TargetUserID: int64(1),
+				}).Return(false, nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionSuperLike,
 				}).Return(nil)
+
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(true, nil)
+
+				inventoryMock.EXPECT().SpendSuperLike(ctx, int64(1)).
+					Return(false, errors.New("test"))
+
+				return &matcherServiceModule{
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
+				}
+			},
+		},
+		{
+			name:    "failed super like spent by a concurrent swipe",
+			args:    successArgs,
+			wantErr: true,
+			mock: func() *matcherServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).Return(&domain.Inventory{
+					ID:         1,
+					Swipes:     1,
+					SuperLikes: 1,
+				}, nil)
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).Return(&domain.Profile{
+					ID: 1,
+				}, nil)
+
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().IsExists(ctx, domain.MatchRequest{
+					UserID:       int64(1),
+					TargetUserID: int64(2),
+				}).Return(false, nil)
+				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
+					UserID:       int64(1),
+					TargetUserID: int64(2),
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: " super liked you",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionSuperLike,
 				}).Return(nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(nil)
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(true, nil)
 
-				inventoryMock.EXPECT().UpdateSuperLikes(ctx, int64(1), 0).
-					Return(errors.New("test"))
+				inventoryMock.EXPECT().SpendSuperLike(ctx, int64(1)).
+					Return(false, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
@@ -1234,30 +1478,32 @@ func Test_matcherServiceModule_SuperLike(t *testing.T) {
 				matchedMock.EXPECT().Create(ctx, domain.MatchRequest{
 					UserID:       int64(1),
 					TargetUserID: int64(2),
-				}).Return(nil)
+				}).Return(int64(10), nil)
 				matchedMock.EXPECT().IsMatched(ctx, domain.MatchRequest{
 					UserID:       int64(2),
 					TargetUserID: int64(1),
 				}).Return(false, nil)
 
-				notificationMock := NewMockNotificationRepoInterface(ctrl)
-				notificationMock.EXPECT().Create(ctx, domain.NotificationRequest{
-					UserID:  int64(2),
-					Message: " super liked you",
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionSuperLike,
 				}).Return(nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(nil)
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(true, nil)
 
-				inventoryMock.EXPECT().UpdateSuperLikes(ctx, int64(1), 0).
-					Return(nil)
+				inventoryMock.EXPECT().SpendSuperLike(ctx, int64(1)).
+					Return(true, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      profileMock,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      matchedMock,
-					notificationRepo: notificationMock,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   profileMock,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   matchedMock,
+					events:        eventsMock,
 				}
 			},
 		},
@@ -1330,11 +1576,12 @@ func Test_matcherServiceModule_Dislike(t *testing.T) {
 			name: "failed target user id is same with user id",
 			mock: func() *matcherServiceModule {
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    nil,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: nil,
+					matchedRepo:   nil,
+					events:        nil,
 				}
 			},
 			args:    failedArgs,
@@ -1348,11 +1595,13 @@ func Test_matcherServiceModule_Dislike(t *testing.T) {
 					Return(nil, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
+					experiments:   defaultExperimentsMock(ctrl),
 				}
 			},
 			args:    successArgs,
@@ -1368,18 +1617,20 @@ func Test_matcherServiceModule_Dislike(t *testing.T) {
 					}, nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
+					experiments:   defaultExperimentsMock(ctrl),
 				}
 			},
 			args:    successArgs,
 			wantErr: true,
 		},
 		{
-			name: "failed update swipes",
+			name: "failed spend swipes",
 			mock: func() *matcherServiceModule {
 				inventoryMock := NewMockInventoryRepoInterface(ctrl)
 				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).
@@ -1387,15 +1638,42 @@ func Test_matcherServiceModule_Dislike(t *testing.T) {
 						Swipes: 1,
 					}, nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(errors.New("test"))
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(false, errors.New("test"))
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
+					experiments:   defaultExperimentsMock(ctrl),
+				}
+			},
+			args:    successArgs,
+			wantErr: true,
+		},
+		{
+			name: "failed swipe spent by a concurrent swipe",
+			mock: func() *matcherServiceModule {
+				inventoryMock := NewMockInventoryRepoInterface(ctrl)
+				inventoryMock.EXPECT().GetByUserId(ctx, int64(1)).
+					Return(&domain.Inventory{
+						Swipes: 1,
+					}, nil)
+
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(false, nil)
+
+				return &matcherServiceModule{
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        nil,
+					experiments:   defaultExperimentsMock(ctrl),
 				}
 			},
 			args:    successArgs,
@@ -1410,15 +1688,51 @@ func Test_matcherServiceModule_Dislike(t *testing.T) {
 						Swipes: 1,
 					}, nil)
 
-				inventoryMock.EXPECT().UpdateSwipes(ctx, int64(1), 0).
-					Return(nil)
+				inventoryMock.EXPECT().SpendSwipe(ctx, int64(1)).
+					Return(true, nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionDislike,
+				}).Return(nil)
 
 				return &matcherServiceModule{
-					cfg:              config,
-					profileRepo:      nil,
-					inventoryRepo:    inventoryMock,
-					matchedRepo:      nil,
-					notificationRepo: nil,
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: inventoryMock,
+					matchedRepo:   nil,
+					events:        eventsMock,
+					experiments:   defaultExperimentsMock(ctrl),
+				}
+			},
+			args:    successArgs,
+			wantErr: false,
+		},
+		{
+			name: "success free dislike spends no swipe",
+			mock: func() *matcherServiceModule {
+				experimentsMock := NewMockExperimentsInterface(ctrl)
+				experimentsMock.EXPECT().Enabled(ctx, domain.FlagFreeDislikes).
+					Return(true)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.SwipedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Action:       domain.SwipeActionDislike,
+				}).Return(nil)
+
+				return &matcherServiceModule{
+					cfg:           config,
+					unitOfWork:    transactional(ctrl),
+					profileRepo:   nil,
+					inventoryRepo: NewMockInventoryRepoInterface(ctrl),
+					matchedRepo:   nil,
+					events:        eventsMock,
+					experiments:   experimentsMock,
 				}
 			},
 			args:    successArgs,
@@ -1435,3 +1749,191 @@ func Test_matcherServiceModule_Dislike(t *testing.T) {
 		})
 	}
 }
+
+func Test_matcherServiceModule_ExpireStaleMatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() *matcherServiceModule
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "failed get stale matches",
+			mock: func() *matcherServiceModule {
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().GetStaleMatches(ctx, gomock.Any()).Return(nil, errors.New("test"))
+
+				return &matcherServiceModule{cfg: &domain.Config{}, matchedRepo: matchedMock}
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mock: func() *matcherServiceModule {
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().GetStaleMatches(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, filter domain.StaleMatchFilter) ([]domain.StaleMatch, error) {
+						if time.Since(filter.MatchedBefore) < 10*24*time.Hour {
+							t.Errorf("MatchedBefore = %v, want at least 10 days ago", filter.MatchedBefore)
+						}
+
+						return []domain.StaleMatch{{UserAID: 1, UserBID: 2}, {UserAID: 3, UserBID: 4}}, nil
+					})
+				matchedMock.EXPECT().Unmatch(ctx, domain.MatchRequest{UserID: 1, TargetUserID: 2}).Return(nil)
+				matchedMock.EXPECT().Unmatch(ctx, domain.MatchRequest{UserID: 3, TargetUserID: 4}).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UnmatchedEvent{
+					UserID:       1,
+					TargetUserID: 2,
+					Reason:       domain.UnmatchReasonExpired,
+				}).Return(nil)
+				eventsMock.EXPECT().Publish(ctx, domain.UnmatchedEvent{
+					UserID:       3,
+					TargetUserID: 4,
+					Reason:       domain.UnmatchReasonExpired,
+				}).Return(nil)
+
+				return &matcherServiceModule{
+					cfg:         &domain.Config{Matches: domain.Matches{ExpiryDays: 10}},
+					matchedRepo: matchedMock,
+					events:      eventsMock,
+				}
+			},
+			want:    2,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.mock()
+			got, err := m.ExpireStaleMatches(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExpireStaleMatches() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("ExpireStaleMatches() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_matcherServiceModule_SendMatchReminders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{Matches: domain.Matches{ExpiryDays: 7, ReminderDays: []int{2, 7}}}
+	match := domain.StaleMatch{
+		UserAID:   1,
+		UserBID:   2,
+		UserAName: "Alice",
+		UserBName: "Bob",
+		MatchedAt: time.Now().Add(-50 * time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		mock    func() *matcherServiceModule
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "success skips reminders already claimed",
+			mock: func() *matcherServiceModule {
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().GetStaleMatches(ctx, gomock.Any()).Return([]domain.StaleMatch{match}, nil)
+				matchedMock.EXPECT().ClaimReminder(ctx, match, 2).Return(false, nil)
+
+				return &matcherServiceModule{cfg: config, unitOfWork: transactional(ctrl), matchedRepo: matchedMock}
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "failed enqueue notification",
+			mock: func() *matcherServiceModule {
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().GetStaleMatches(ctx, gomock.Any()).Return([]domain.StaleMatch{match}, nil)
+				matchedMock.EXPECT().ClaimReminder(ctx, match, 2).Return(true, nil)
+
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Enqueue(ctx, domain.OutboxKindNotification, gomock.Any()).Return(errors.New("test"))
+
+				return &matcherServiceModule{cfg: config, unitOfWork: transactional(ctrl), matchedRepo: matchedMock, outboxRepo: outboxMock}
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "success notifies both users",
+			mock: func() *matcherServiceModule {
+				// The 7 day stage is skipped, the match expires before it is due.
+				matchedMock := NewMockMatchedRepoInterface(ctrl)
+				matchedMock.EXPECT().GetStaleMatches(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, filter domain.StaleMatchFilter) ([]domain.StaleMatch, error) {
+						if filter.WithoutReminder != 2 {
+							t.Errorf("WithoutReminder = %v, want 2", filter.WithoutReminder)
+						}
+
+						return []domain.StaleMatch{match}, nil
+					})
+				matchedMock.EXPECT().ClaimReminder(ctx, match, 2).Return(true, nil)
+
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+					UserID:      1,
+					Type:        domain.NotificationTypeMatch,
+					TemplateKey: domain.NotificationTemplateMatchReminder,
+					Payload:     domain.NotificationPayload{ActorUserID: 2, ActorName: "Bob", Days: 2},
+				}).Return(nil)
+				outboxMock.EXPECT().Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+					UserID:      2,
+					Type:        domain.NotificationTypeMatch,
+					TemplateKey: domain.NotificationTemplateMatchReminder,
+					Payload:     domain.NotificationPayload{ActorUserID: 1, ActorName: "Alice", Days: 2},
+				}).Return(nil)
+
+				return &matcherServiceModule{cfg: config, unitOfWork: transactional(ctrl), matchedRepo: matchedMock, outboxRepo: outboxMock}
+			},
+			want:    2,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.mock()
+			got, err := m.SendMatchReminders(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SendMatchReminders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("SendMatchReminders() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// transactional runs every unit of work straight away, as a committed transaction would.
+func transactional(ctrl *gomock.Controller) *MockUnitOfWorkInterface {
+	unitOfWorkMock := NewMockUnitOfWorkInterface(ctrl)
+	unitOfWorkMock.EXPECT().Do(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	return unitOfWorkMock
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+type notificationServiceModule struct {
+	cfg              *domain.Config
+	notificationRepo NotificationRepoInterface
+	deviceRepo       DeviceRepoInterface
+	pushSender       PushSenderInterface
+	settings         SettingsInterface
+}
+
+type NotificationServiceInterface interface {
+	GetNotifications(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.NotificationResponse, error)
+	MarkRead(ctx context.Context, userID int64, notificationID int64) error
+	RegisterDevice(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error
+	UnregisterDevice(ctx context.Context, userID int64, token string) error
+	GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID int64, req domain.NotificationPreferencesRequest) (*domain.NotificationPreferences, error)
+	DeliverPush(ctx context.Context) (int, error)
+}
+
+func NewNotificationService(cfg *domain.Config, notificationRepo NotificationRepoInterface, deviceRepo DeviceRepoInterface,
+	pushSender PushSenderInterface, settings SettingsInterface) (NotificationServiceInterface, error) {
+	return &notificationServiceModule{
+		cfg:              cfg,
+		notificationRepo: notificationRepo,
+		deviceRepo:       deviceRepo,
+		pushSender:       pushSender,
+		settings:         settings,
+	}, nil
+}
+
+// renderNotification prefers a template set at runtime for the locale over the built-in one.
+func renderNotification(ctx context.Context, settings SettingsInterface, notification domain.Notification,
+	locale string) string {
+	locale = domain.NormalizeLocale(locale)
+	if text := settings.String(ctx, domain.NotificationTemplateSetting(locale, notification.TemplateKey)); text != "" {
+		return domain.RenderNotificationTemplate(text, notification.Payload)
+	}
+
+	return domain.RenderNotification(notification, locale)
+}
+
+func (n notificationServiceModule) GetNotifications(ctx context.Context, userID int64, filter domain.NotificationFilter) ([]domain.NotificationResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.notification.get_notifications")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.notification.get_notifications"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	notifications, err := n.notificationRepo.GetByFilter(ctx, userID, filter)
+	if err != nil {
+		tags["error"] = "failed get notifications"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	locale := domain.NormalizeLocale(filter.Locale)
+	res := make([]domain.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		res = append(res, domain.NotificationResponse{
+			ID:          notification.ID,
+			UserID:      notification.UserID,
+			Type:        notification.Type,
+			TemplateKey: notification.TemplateKey,
+			Payload:     notification.Payload,
+			Message:     renderNotification(ctx, n.settings, notification, locale),
+			IsRead:      notification.IsRead,
+			CreatedAt:   notification.CreatedAt,
+		})
+	}
+
+	tags["locale"] = locale
+	tags["status"] = "success"
+	return res, nil
+}
+
+func (n notificationServiceModule) MarkRead(ctx context.Context, userID int64, notificationID int64) error {
+	ctx, span := tracing.Start(ctx, "service.notification.mark_read")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.notification.mark_read"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	notification, err := n.notificationRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		tags["error"] = "failed get notification"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	if notification == nil || notification.UserID != userID {
+		tags["error"] = "notification not found"
+		tags["status"] = "error"
+		return domain.ErrNotificationNotFound
+	}
+
+	if notification.IsRead {
+		tags["status"] = "success"
+		return nil
+	}
+
+	if err := n.notificationRepo.SetRead(ctx, notificationID); err != nil {
+		tags["error"] = "failed set notification read"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (n notificationServiceModule) RegisterDevice(ctx context.Context, userID int64, req domain.DeviceTokenRequest) error {
+	ctx, span := tracing.Start(ctx, "service.notification.register_device")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.notification.register_device"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	if req.Locale != "" {
+		req.Locale = domain.NormalizeLocale(req.Locale)
+	}
+
+	if err := n.deviceRepo.Register(ctx, userID, req); err != nil {
+		tags["error"] = "failed register device"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["platform"] = req.Platform
+	tags["status"] = "success"
+	return nil
+}
+
+func (n notificationServiceModule) UnregisterDevice(ctx context.Context, userID int64, token string) error {
+	ctx, span := tracing.Start(ctx, "service.notification.unregister_device")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.notification.unregister_device"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if err := n.deviceRepo.Unregister(ctx, userID, token); err != nil {
+		tags["error"] = "failed unregister device"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (n notificationServiceModule) GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	ctx, span := tracing.Start(ctx, "service.notification.get_preferences")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.notification.get_preferences"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	prefs, err := n.preferences(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get preferences"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return prefs, nil
+}
+
+func (n notificationServiceModule) UpdatePreferences(ctx context.Context, userID int64,
+	req domain.NotificationPreferencesRequest) (*domain.NotificationPreferences, error) {
+	ctx, span := tracing.Start(ctx, "service.notification.update_preferences")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.notification.update_preferences"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	prefs, err := n.preferences(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get preferences"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	// Only the switches present in the request change.
+	if req.Matches != nil {
+		prefs.Matches = *req.Matches
+	}
+	if req.Messages != nil {
+		prefs.Messages = *req.Messages
+	}
+	if req.SuperLikes != nil {
+		prefs.SuperLikes = *req.SuperLikes
+	}
+	if req.Marketing != nil {
+		prefs.Marketing = *req.Marketing
+	}
+
+	if err := n.notificationRepo.SavePreferences(ctx, *prefs); err != nil {
+		tags["error"] = "failed save preferences"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return prefs, nil
+}
+
+// preferences returns the saved preferences or the defaults when there are none.
+func (n notificationServiceModule) preferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	prefs, err := n.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefs == nil {
+		defaults := domain.DefaultNotificationPreferences(userID)
+		return &defaults, nil
+	}
+
+	return prefs, nil
+}
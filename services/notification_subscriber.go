@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"github.com/zombozo12/tinder-dealls/domain"
+)
+
+// notificationSubscriberModule turns swipe and match events into notifications. It runs as a sync subscriber, so
+// the notifications are queued in the outbox inside the transaction of the swipe.
+type notificationSubscriberModule struct {
+	cfg        *domain.Config
+	outboxRepo OutboxRepoInterface
+}
+
+type NotificationSubscriberInterface interface {
+	OnSwiped(ctx context.Context, event domain.Event) error
+	OnMatched(ctx context.Context, event domain.Event) error
+}
+
+func NewNotificationSubscriber(cfg *domain.Config, outboxRepo OutboxRepoInterface) (NotificationSubscriberInterface, error) {
+	return &notificationSubscriberModule{
+		cfg:        cfg,
+		outboxRepo: outboxRepo,
+	}, nil
+}
+
+// OnSwiped tells users they were super liked. A super like that completed a match is announced by OnMatched instead.
+func (n notificationSubscriberModule) OnSwiped(ctx context.Context, event domain.Event) error {
+	swiped, ok := event.(domain.SwipedEvent)
+	if !ok || swiped.Action != domain.SwipeActionSuperLike || swiped.Mutual {
+		return nil
+	}
+
+	return n.outboxRepo.Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+		UserID:      swiped.TargetUserID,
+		Type:        domain.NotificationTypeSuperLike,
+		TemplateKey: domain.NotificationTemplateSuperLikeReceived,
+		Payload: domain.NotificationPayload{
+			ActorUserID: swiped.UserID,
+			ActorName:   swiped.UserName,
+		},
+	})
+}
+
+// OnMatched tells the user who swiped first that the match is complete.
+func (n notificationSubscriberModule) OnMatched(ctx context.Context, event domain.Event) error {
+	matched, ok := event.(domain.MatchedEvent)
+	if !ok {
+		return nil
+	}
+
+	return n.outboxRepo.Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+		UserID:      matched.TargetUserID,
+		Type:        domain.NotificationTypeMatch,
+		TemplateKey: domain.NotificationTemplateMatchNew,
+		Payload: domain.NotificationPayload{
+			ActorUserID: matched.UserID,
+			ActorName:   matched.UserName,
+			MatchID:     matched.MatchID,
+		},
+	})
+}
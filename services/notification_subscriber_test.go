@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func Test_notificationSubscriberModule_OnSwiped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		event   domain.Event
+		mock    func() *notificationSubscriberModule
+		wantErr bool
+	}{
+		{
+			name:  "success ignores likes",
+			event: domain.SwipedEvent{UserID: 1, TargetUserID: 2, Action: domain.SwipeActionLike},
+			mock: func() *notificationSubscriberModule {
+				return &notificationSubscriberModule{}
+			},
+			wantErr: false,
+		},
+		{
+			name:  "success ignores super like that matched",
+			event: domain.SwipedEvent{UserID: 1, TargetUserID: 2, Action: domain.SwipeActionSuperLike, Mutual: true},
+			mock: func() *notificationSubscriberModule {
+				return &notificationSubscriberModule{}
+			},
+			wantErr: false,
+		},
+		{
+			name:  "failed enqueue notification",
+			event: domain.SwipedEvent{UserID: 1, TargetUserID: 2, Action: domain.SwipeActionSuperLike},
+			mock: func() *notificationSubscriberModule {
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Enqueue(ctx, domain.OutboxKindNotification, gomock.Any()).Return(errors.New("test"))
+
+				return &notificationSubscriberModule{outboxRepo: outboxMock}
+			},
+			wantErr: true,
+		},
+		{
+			name:  "success super like",
+			event: domain.SwipedEvent{UserID: 1, TargetUserID: 2, UserName: "Alice", Action: domain.SwipeActionSuperLike},
+			mock: func() *notificationSubscriberModule {
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+					UserID:      2,
+					Type:        domain.NotificationTypeSuperLike,
+					TemplateKey: domain.NotificationTemplateSuperLikeReceived,
+					Payload:     domain.NotificationPayload{ActorUserID: 1, ActorName: "Alice"},
+				}).Return(nil)
+
+				return &notificationSubscriberModule{outboxRepo: outboxMock}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.mock()
+			if err := n.OnSwiped(ctx, tt.event); (err != nil) != tt.wantErr {
+				t.Errorf("OnSwiped() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_notificationSubscriberModule_OnMatched(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	outboxMock := NewMockOutboxRepoInterface(ctrl)
+	outboxMock.EXPECT().Enqueue(ctx, domain.OutboxKindNotification, domain.NotificationRequest{
+		UserID:      2,
+		Type:        domain.NotificationTypeMatch,
+		TemplateKey: domain.NotificationTemplateMatchNew,
+		Payload:     domain.NotificationPayload{ActorUserID: 1, ActorName: "Alice", MatchID: 10},
+	}).Return(nil)
+
+	n := &notificationSubscriberModule{outboxRepo: outboxMock}
+	event := domain.MatchedEvent{MatchID: 10, UserID: 1, TargetUserID: 2, UserName: "Alice"}
+	if err := n.OnMatched(ctx, event); err != nil {
+		t.Errorf("OnMatched() error = %v", err)
+	}
+}
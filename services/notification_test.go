@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"reflect"
+	"testing"
+)
+
+func Test_notificationServiceModule_GetNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	notifications := []domain.Notification{
+		{
+			ID:          2,
+			UserID:      1,
+			Type:        domain.NotificationTypeMatch,
+			TemplateKey: domain.NotificationTemplateMatchReminder,
+			Payload:     domain.NotificationPayload{ActorUserID: 3, ActorName: "Bob", Days: 1},
+		},
+		{
+			ID:          1,
+			UserID:      1,
+			Type:        domain.NotificationTypeSuperLike,
+			TemplateKey: domain.NotificationTemplateSuperLikeReceived,
+			Payload:     domain.NotificationPayload{ActorUserID: 3, ActorName: "Bob"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filter  domain.NotificationFilter
+		mock    func() *notificationServiceModule
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "failed get notifications",
+			filter: domain.NotificationFilter{},
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByFilter(ctx, int64(1), domain.NotificationFilter{}).
+					Return(nil, errors.New("test"))
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			wantErr: true,
+		},
+		{
+			name:   "success renders default locale",
+			filter: domain.NotificationFilter{},
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByFilter(ctx, int64(1), domain.NotificationFilter{}).
+					Return(notifications, nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock, settings: defaultSettingsMock(ctrl)}
+			},
+			want:    []string{"You matched with Bob 1 day ago — say hi", "Bob super liked you"},
+			wantErr: false,
+		},
+		{
+			name:   "success renders requested locale",
+			filter: domain.NotificationFilter{Locale: "id-ID"},
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByFilter(ctx, int64(1), domain.NotificationFilter{Locale: "id-ID"}).
+					Return(notifications, nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock, settings: defaultSettingsMock(ctrl)}
+			},
+			want:    []string{"Kamu match dengan Bob 1 hari yang lalu — sapa dia", "Bob memberimu super like"},
+			wantErr: false,
+		},
+		{
+			name:   "success unsupported locale falls back",
+			filter: domain.NotificationFilter{Locale: "fr"},
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByFilter(ctx, int64(1), domain.NotificationFilter{Locale: "fr"}).
+					Return([]domain.Notification{{
+						ID:          3,
+						UserID:      1,
+						Type:        domain.NotificationTypeSystem,
+						TemplateKey: domain.NotificationTemplateSystemText,
+						Payload:     domain.NotificationPayload{Text: "Welcome back"},
+					}}, nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock, settings: defaultSettingsMock(ctrl)}
+			},
+			want:    []string{"Welcome back"},
+			wantErr: false,
+		},
+		{
+			name:   "success renders runtime template",
+			filter: domain.NotificationFilter{},
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByFilter(ctx, int64(1), domain.NotificationFilter{}).
+					Return(notifications, nil)
+				settingsMock := NewMockSettingsInterface(ctrl)
+				settingsMock.EXPECT().String(ctx, "notification.template.en.match.reminder").
+					Return("{actor_name} is waiting since {days} day")
+				settingsMock.EXPECT().String(ctx, "notification.template.en.super_like.received").
+					Return("")
+
+				return &notificationServiceModule{notificationRepo: notificationMock, settings: settingsMock}
+			},
+			want:    []string{"Bob is waiting since 1 day", "Bob super liked you"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.mock()
+			got, err := n.GetNotifications(ctx, 1, tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetNotifications() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var messages []string
+			for _, notification := range got {
+				messages = append(messages, notification.Message)
+			}
+
+			if !reflect.DeepEqual(messages, tt.want) {
+				t.Errorf("GetNotifications() got = %v, want %v", messages, tt.want)
+			}
+		})
+	}
+}
+
+func Test_notificationServiceModule_MarkRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		mock    func() *notificationServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed get notification",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(nil, errors.New("test"))
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed notification belongs to another user",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(&domain.Notification{ID: 5, UserID: 2}, nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed set read",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(&domain.Notification{ID: 5, UserID: 1}, nil)
+				notificationMock.EXPECT().SetRead(ctx, int64(5)).Return(errors.New("test"))
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success already read",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).
+					Return(&domain.Notification{ID: 5, UserID: 1, IsRead: true}, nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			wantErr: false,
+		},
+		{
+			name: "success",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(&domain.Notification{ID: 5, UserID: 1}, nil)
+				notificationMock.EXPECT().SetRead(ctx, int64(5)).Return(nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.mock()
+			if err := n.MarkRead(ctx, 1, 5); (err != nil) != tt.wantErr {
+				t.Errorf("MarkRead() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_notificationServiceModule_RegisterDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	tests := []struct {
+		name    string
+		req     domain.DeviceTokenRequest
+		mock    func() *notificationServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating platform",
+			req:  domain.DeviceTokenRequest{Platform: "web", Token: "token"},
+			mock: func() *notificationServiceModule {
+				return &notificationServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success normalizes locale",
+			req:  domain.DeviceTokenRequest{Platform: domain.PushPlatformIOS, Token: "token", Locale: "id-ID"},
+			mock: func() *notificationServiceModule {
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().Register(ctx, int64(1), domain.DeviceTokenRequest{
+					Platform: domain.PushPlatformIOS,
+					Token:    "token",
+					Locale:   "id",
+				}).Return(nil)
+
+				return &notificationServiceModule{deviceRepo: deviceMock}
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.mock()
+			if err := n.RegisterDevice(ctx, 1, tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("RegisterDevice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_notificationServiceModule_UpdatePreferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	off := false
+	on := true
+
+	tests := []struct {
+		name    string
+		req     domain.NotificationPreferencesRequest
+		mock    func() *notificationServiceModule
+		want    *domain.NotificationPreferences
+		wantErr bool
+	}{
+		{
+			name: "failed get preferences",
+			req:  domain.NotificationPreferencesRequest{Matches: &off},
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(1)).Return(nil, errors.New("test"))
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success starts from defaults",
+			req:  domain.NotificationPreferencesRequest{Matches: &off, Marketing: &on},
+			mock: func() *notificationServiceModule {
+				want := domain.NotificationPreferences{UserID: 1, Messages: true, SuperLikes: true, Marketing: true}
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(1)).Return(nil, nil)
+				notificationMock.EXPECT().SavePreferences(ctx, want).Return(nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			want:    &domain.NotificationPreferences{UserID: 1, Messages: true, SuperLikes: true, Marketing: true},
+			wantErr: false,
+		},
+		{
+			name: "success keeps switches left out",
+			req:  domain.NotificationPreferencesRequest{Messages: &on},
+			mock: func() *notificationServiceModule {
+				saved := &domain.NotificationPreferences{UserID: 1}
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(1)).Return(saved, nil)
+				notificationMock.EXPECT().SavePreferences(ctx, domain.NotificationPreferences{UserID: 1, Messages: true}).
+					Return(nil)
+
+				return &notificationServiceModule{notificationRepo: notificationMock}
+			},
+			want:    &domain.NotificationPreferences{UserID: 1, Messages: true},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.mock()
+			got, err := n.UpdatePreferences(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdatePreferences() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpdatePreferences() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
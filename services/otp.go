@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"math/big"
+	"time"
+)
+
+const (
+	otpLength         = 6
+	otpTTL            = 60 * 5  // seconds a code stays valid
+	otpResendInterval = 60      // seconds between two sends to the same phone
+	otpSendWindow     = 60 * 60 // seconds of the rolling send quota
+	otpMaxSends       = 5       // sends allowed per phone within otpSendWindow
+	otpMaxAttempts    = 5       // wrong guesses allowed before the code is burned
+)
+
+type otpServiceModule struct {
+	cfg       *domain.Config
+	redisRepo RedisRepoInterface
+	smsSender SMSSenderInterface
+}
+
+type OTPServiceInterface interface {
+	Send(ctx context.Context, req domain.OTPRequest) (*domain.OTPResponse, error)
+	Verify(ctx context.Context, phone, code string) error
+}
+
+func NewOTPService(cfg *domain.Config, redisRepo RedisRepoInterface, smsSender SMSSenderInterface) (OTPServiceInterface, error) {
+	return &otpServiceModule{
+		cfg:       cfg,
+		redisRepo: redisRepo,
+		smsSender: smsSender,
+	}, nil
+}
+
+func (o otpServiceModule) Send(ctx context.Context, req domain.OTPRequest) (*domain.OTPResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.otp.send")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.otp.send"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	throttled, err := o.redisRepo.Exists(ctx, fmt.Sprintf("otp_resend:%s", req.Phone))
+	if err != nil {
+		tags["error"] = "failed check resend throttle"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if throttled {
+		tags["error"] = "otp resend throttled"
+		tags["status"] = "warning"
+		return nil, domain.ErrOTPResendThrottled
+	}
+
+	sendsKey := fmt.Sprintf("otp_sends:%s", req.Phone)
+	sends, err := o.redisRepo.Incr(ctx, sendsKey)
+	if err != nil {
+		tags["error"] = "failed count sends"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if sends == 1 {
+		if err := o.redisRepo.Expire(ctx, sendsKey, otpSendWindow); err != nil {
+			tags["error"] = "failed expire sends"
+			tags["status"] = "error"
+			return nil, err
+		}
+	}
+
+	if sends > otpMaxSends {
+		tags["error"] = "otp send quota exceeded"
+		tags["status"] = "warning"
+		return nil, domain.ErrOTPQuotaExceeded
+	}
+
+	code, err := generateOTP()
+	if err != nil {
+		tags["error"] = "failed generating otp"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := o.redisRepo.Set(ctx, fmt.Sprintf("otp:%s", req.Phone), hashOTP(req.Phone, code), otpTTL); err != nil {
+		tags["error"] = "failed storing otp"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	// A fresh code gets a fresh attempt budget.
+	if err := o.redisRepo.Del(ctx, fmt.Sprintf("otp_attempts:%s", req.Phone)); err != nil {
+		tags["error"] = "failed reset attempts"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := o.redisRepo.Set(ctx, fmt.Sprintf("otp_resend:%s", req.Phone), 1, otpResendInterval); err != nil {
+		tags["error"] = "failed storing resend throttle"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := o.smsSender.Send(ctx, req.Phone, fmt.Sprintf("Your Tinder Dealls code is %s", code)); err != nil {
+		tags["error"] = "failed sending sms"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return &domain.OTPResponse{
+		ExpiresIn: otpTTL,
+		ResendIn:  otpResendInterval,
+	}, nil
+}
+
+func (o otpServiceModule) Verify(ctx context.Context, phone, code string) error {
+	ctx, span := tracing.Start(ctx, "service.otp.verify")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.otp.verify"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	otpKey := fmt.Sprintf("otp:%s", phone)
+	attemptsKey := fmt.Sprintf("otp_attempts:%s", phone)
+
+	stored, err := o.redisRepo.Get(ctx, otpKey)
+	if err != nil {
+		tags["error"] = "failed get otp"
+		tags["status"] = "error"
+		return err
+	}
+
+	if stored == "" {
+		tags["error"] = "otp expired"
+		tags["status"] = "error"
+		return domain.ErrOTPExpired
+	}
+
+	attempts, err := o.redisRepo.Incr(ctx, attemptsKey)
+	if err != nil {
+		tags["error"] = "failed count attempts"
+		tags["status"] = "error"
+		return err
+	}
+
+	if attempts == 1 {
+		if err := o.redisRepo.Expire(ctx, attemptsKey, otpTTL); err != nil {
+			tags["error"] = "failed expire attempts"
+			tags["status"] = "error"
+			return err
+		}
+	}
+
+	if attempts > otpMaxAttempts {
+		if err := o.redisRepo.Del(ctx, otpKey, attemptsKey); err != nil {
+			tags["error"] = "failed burn otp"
+			tags["status"] = "error"
+			return err
+		}
+
+		tags["error"] = "too many otp attempts"
+		tags["status"] = "warning"
+		return domain.ErrOTPTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashOTP(phone, code))) != 1 {
+		tags["error"] = "invalid otp"
+		tags["status"] = "warning"
+		return domain.ErrInvalidOTP
+	}
+
+	if err := o.redisRepo.Del(ctx, otpKey, attemptsKey); err != nil {
+		tags["error"] = "failed delete otp"
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func generateOTP() (string, error) {
+	upper := big.NewInt(1)
+	for i := 0; i < otpLength; i++ {
+		upper.Mul(upper, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, upper)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", otpLength, n.Int64()), nil
+}
+
+// hashOTP keeps plain codes out of redis, the phone is mixed in so equal codes hash differently.
+func hashOTP(phone, code string) string {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
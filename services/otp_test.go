@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func Test_otpServiceModule_Send(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	phone := "+6281234567890"
+
+	tests := []struct {
+		name    string
+		req     domain.OTPRequest
+		mock    func() *otpServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating request",
+			req:  domain.OTPRequest{Phone: "0812"},
+			mock: func() *otpServiceModule {
+				return &otpServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed resend throttled",
+			req:  domain.OTPRequest{Phone: phone},
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Exists(ctx, "otp_resend:"+phone).Return(true, nil)
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed send quota exceeded",
+			req:  domain.OTPRequest{Phone: phone},
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Exists(ctx, "otp_resend:"+phone).Return(false, nil)
+				redisMock.EXPECT().Incr(ctx, "otp_sends:"+phone).Return(int64(otpMaxSends+1), nil)
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed sending sms",
+			req:  domain.OTPRequest{Phone: phone},
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Exists(ctx, "otp_resend:"+phone).Return(false, nil)
+				redisMock.EXPECT().Incr(ctx, "otp_sends:"+phone).Return(int64(2), nil)
+				redisMock.EXPECT().Set(ctx, "otp:"+phone, gomock.Any(), otpTTL).Return(nil)
+				redisMock.EXPECT().Del(ctx, "otp_attempts:"+phone).Return(nil)
+				redisMock.EXPECT().Set(ctx, "otp_resend:"+phone, 1, otpResendInterval).Return(nil)
+
+				smsMock := NewMockSMSSenderInterface(ctrl)
+				smsMock.EXPECT().Send(ctx, phone, gomock.Any()).Return(errors.New("test"))
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+					smsSender: smsMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success first send",
+			req:  domain.OTPRequest{Phone: phone},
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Exists(ctx, "otp_resend:"+phone).Return(false, nil)
+				redisMock.EXPECT().Incr(ctx, "otp_sends:"+phone).Return(int64(1), nil)
+				redisMock.EXPECT().Expire(ctx, "otp_sends:"+phone, otpSendWindow).Return(nil)
+				redisMock.EXPECT().Set(ctx, "otp:"+phone, gomock.Any(), otpTTL).Return(nil)
+				redisMock.EXPECT().Del(ctx, "otp_attempts:"+phone).Return(nil)
+				redisMock.EXPECT().Set(ctx, "otp_resend:"+phone, 1, otpResendInterval).Return(nil)
+
+				smsMock := NewMockSMSSenderInterface(ctrl)
+				smsMock.EXPECT().Send(ctx, phone, gomock.Any()).Return(nil)
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+					smsSender: smsMock,
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.mock()
+			got, err := o.Send(ctx, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got.ExpiresIn != otpTTL {
+				t.Errorf("Send() got = %v", got)
+			}
+		})
+	}
+}
+
+func Test_otpServiceModule_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+	phone := "+6281234567890"
+
+	tests := []struct {
+		name    string
+		code    string
+		mock    func() *otpServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed otp expired",
+			code: "123456",
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "otp:"+phone).Return("", nil)
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed too many attempts",
+			code: "123456",
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "otp:"+phone).Return(hashOTP(phone, "123456"), nil)
+				redisMock.EXPECT().Incr(ctx, "otp_attempts:"+phone).Return(int64(otpMaxAttempts+1), nil)
+				redisMock.EXPECT().Del(ctx, "otp:"+phone, "otp_attempts:"+phone).Return(nil)
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed invalid code",
+			code: "654321",
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "otp:"+phone).Return(hashOTP(phone, "123456"), nil)
+				redisMock.EXPECT().Incr(ctx, "otp_attempts:"+phone).Return(int64(2), nil)
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			code: "123456",
+			mock: func() *otpServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "otp:"+phone).Return(hashOTP(phone, "123456"), nil)
+				redisMock.EXPECT().Incr(ctx, "otp_attempts:"+phone).Return(int64(1), nil)
+				redisMock.EXPECT().Expire(ctx, "otp_attempts:"+phone, otpTTL).Return(nil)
+				redisMock.EXPECT().Del(ctx, "otp:"+phone, "otp_attempts:"+phone).Return(nil)
+
+				return &otpServiceModule{
+					redisRepo: redisMock,
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.mock()
+			if err := o.Verify(ctx, phone, tt.code); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+const (
+	outboxBatchSize   = 100
+	outboxLease       = time.Minute
+	outboxMaxAttempts = 10
+	outboxRetryDelay  = 5 * time.Second
+	outboxMaxDelay    = 10 * time.Minute
+)
+
+type outboxServiceModule struct {
+	cfg              *domain.Config
+	outboxRepo       OutboxRepoInterface
+	notificationRepo NotificationRepoInterface
+}
+
+type OutboxServiceInterface interface {
+	Relay(ctx context.Context) (int, error)
+}
+
+func NewOutboxService(cfg *domain.Config, outboxRepo OutboxRepoInterface,
+	notificationRepo NotificationRepoInterface) (OutboxServiceInterface, error) {
+	return &outboxServiceModule{
+		cfg:              cfg,
+		outboxRepo:       outboxRepo,
+		notificationRepo: notificationRepo,
+	}, nil
+}
+
+// Relay publishes side effects whose transaction committed. A message is
+// published at least once: a relay that dies between publishing and marking
+// it published sends it again after the lease runs out.
+func (o outboxServiceModule) Relay(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "service.outbox.relay")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.outbox.relay"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	published := 0
+	for {
+		messages, err := o.outboxRepo.Claim(ctx, outboxBatchSize, outboxLease)
+		if err != nil {
+			tags["error"] = "failed claim outbox"
+			tags["actual_error"] = err.Error()
+			tags["status"] = "error"
+			return published, err
+		}
+
+		for _, message := range messages {
+			update := domain.OutboxUpdate{
+				Status:      domain.OutboxStatusPublished,
+				Attempts:    message.Attempts + 1,
+				AvailableAt: message.AvailableAt,
+			}
+
+			if err := o.publish(ctx, message); err != nil {
+				update.LastError = err.Error()
+				update.Status = domain.OutboxStatusPending
+				update.AvailableAt = time.Now().Add(outboxBackoff(update.Attempts))
+				if update.Attempts >= outboxMaxAttempts {
+					update.Status = domain.OutboxStatusFailed
+					update.AvailableAt = message.AvailableAt
+				}
+			}
+
+			if err := o.outboxRepo.Update(ctx, message.ID, update); err != nil {
+				tags["error"] = "failed update outbox"
+				tags["actual_error"] = err.Error()
+				tags["status"] = "error"
+				return published, err
+			}
+
+			if update.Status == domain.OutboxStatusPublished {
+				published++
+			}
+		}
+
+		if len(messages) < outboxBatchSize {
+			break
+		}
+	}
+
+	tags["published"] = published
+	tags["status"] = "success"
+	return published, nil
+}
+
+func (o outboxServiceModule) publish(ctx context.Context, message domain.OutboxMessage) error {
+	switch message.Kind {
+	case domain.OutboxKindNotification:
+		var req domain.NotificationRequest
+		if err := json.Unmarshal([]byte(message.Payload), &req); err != nil {
+			return err
+		}
+
+		// The relay may publish a message again, the notification is only stored once per message.
+		req.OutboxID = message.ID
+		return o.notificationRepo.Create(ctx, req)
+	default:
+		return fmt.Errorf("unknown outbox kind %q", message.Kind)
+	}
+}
+
+// outboxBackoff doubles outboxRetryDelay for every failed attempt, capped at outboxMaxDelay.
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxRetryDelay
+	for i := 1; i < attempts && delay < outboxMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > outboxMaxDelay {
+		return outboxMaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+	"time"
+)
+
+func Test_outboxServiceModule_Relay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	availableAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	message := domain.OutboxMessage{
+		ID:          3,
+		Kind:        domain.OutboxKindNotification,
+		Payload:     `{"user_id":2,"type":"match","template_key":"match.new","payload":{"actor_user_id":1,"match_id":10}}`,
+		Status:      domain.OutboxStatusPending,
+		AvailableAt: availableAt,
+	}
+	notification := domain.NotificationRequest{
+		UserID:      2,
+		Type:        domain.NotificationTypeMatch,
+		TemplateKey: domain.NotificationTemplateMatchNew,
+		Payload:     domain.NotificationPayload{ActorUserID: 1, MatchID: 10},
+		// Keys the notification, so publishing the message again stores no duplicate.
+		OutboxID: 3,
+	}
+
+	tests := []struct {
+		name    string
+		mock    func() *outboxServiceModule
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "failed claim outbox",
+			mock: func() *outboxServiceModule {
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Claim(ctx, outboxBatchSize, outboxLease).Return(nil, errors.New("test"))
+
+				return &outboxServiceModule{outboxRepo: outboxMock}
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "success publishes notification",
+			mock: func() *outboxServiceModule {
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Claim(ctx, outboxBatchSize, outboxLease).Return([]domain.OutboxMessage{message}, nil)
+				outboxMock.EXPECT().Update(ctx, int64(3), domain.OutboxUpdate{
+					Status:      domain.OutboxStatusPublished,
+					Attempts:    1,
+					AvailableAt: availableAt,
+				}).Return(nil)
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().Create(ctx, notification).Return(nil)
+
+				return &outboxServiceModule{outboxRepo: outboxMock, notificationRepo: notificationMock}
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "success retries failed publish with backoff",
+			mock: func() *outboxServiceModule {
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Claim(ctx, outboxBatchSize, outboxLease).Return([]domain.OutboxMessage{message}, nil)
+				outboxMock.EXPECT().Update(ctx, int64(3), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, update domain.OutboxUpdate) error {
+						if update.Status != domain.OutboxStatusPending || update.Attempts != 1 || update.LastError != "test" {
+							t.Errorf("Update() got = %+v, want pending retry", update)
+						}
+
+						if wait := time.Until(update.AvailableAt); wait <= 0 || wait > outboxRetryDelay {
+							t.Errorf("Update() available in %v, want within %v", wait, outboxRetryDelay)
+						}
+
+						return nil
+					})
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().Create(ctx, notification).Return(errors.New("test"))
+
+				return &outboxServiceModule{outboxRepo: outboxMock, notificationRepo: notificationMock}
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "success gives up on unknown kind after max attempts",
+			mock: func() *outboxServiceModule {
+				unknown := message
+				unknown.Kind = "unknown"
+				unknown.Attempts = outboxMaxAttempts - 1
+
+				outboxMock := NewMockOutboxRepoInterface(ctrl)
+				outboxMock.EXPECT().Claim(ctx, outboxBatchSize, outboxLease).Return([]domain.OutboxMessage{unknown}, nil)
+				outboxMock.EXPECT().Update(ctx, int64(3), domain.OutboxUpdate{
+					Status:      domain.OutboxStatusFailed,
+					Attempts:    outboxMaxAttempts,
+					AvailableAt: availableAt,
+					LastError:   `unknown outbox kind "unknown"`,
+				}).Return(nil)
+
+				return &outboxServiceModule{outboxRepo: outboxMock}
+			},
+			want:    0,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.mock()
+			got, err := o.Relay(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Relay() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Relay() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
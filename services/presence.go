@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+type presenceServiceModule struct {
+	cfg          *domain.Config
+	presenceRepo PresenceRepoInterface
+}
+
+type PresenceServiceInterface interface {
+	Touch(ctx context.Context, userID int64) error
+}
+
+func NewPresenceService(cfg *domain.Config, presenceRepo PresenceRepoInterface) (PresenceServiceInterface, error) {
+	return &presenceServiceModule{
+		cfg:          cfg,
+		presenceRepo: presenceRepo,
+	}, nil
+}
+
+func (p presenceServiceModule) Touch(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "service.presence.touch")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.presence.touch"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	if err := p.presenceRepo.Touch(ctx, userID); err != nil {
+		tags["error"] = "failed touch presence"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
@@ -3,41 +3,60 @@ package services
 import (
 	"context"
 	"github.com/go-playground/validator/v10"
+	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"gorm.io/gorm"
 	"time"
 )
 
 type profileServiceModule struct {
-	cfg         *domain.Config
-	db          *gorm.DB
-	profileRepo ProfileRepoInterface
+	cfg             *domain.Config
+	db              *gorm.DB
+	profileRepo     ProfileRepoInterface
+	blockRepo       BlockRepoInterface
+	presenceRepo    PresenceRepoInterface
+	contentFilter   ContentFilterInterface
+	contentFlagRepo ContentFlagRepoInterface
+	events          EventPublisherInterface
 }
 
 type ProfileServiceModuleInterface interface {
 	Create(ctx context.Context, userId int64, req domain.ProfileRequest) error
 	UpdateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error
 	UpdateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error
+	GetProfile(ctx context.Context, viewerID int64, userID int64) (*domain.ProfileResponse, error)
+	UpdatePrivacy(ctx context.Context, userID int64, req domain.PrivacyRequest) error
 }
 
-func NewProfileService(cfg *domain.Config, db *gorm.DB, profileRepo ProfileRepoInterface) (ProfileServiceModuleInterface, error) {
+func NewProfileService(cfg *domain.Config, db *gorm.DB, profileRepo ProfileRepoInterface, blockRepo BlockRepoInterface,
+	presenceRepo PresenceRepoInterface, contentFilter ContentFilterInterface,
+	contentFlagRepo ContentFlagRepoInterface, events EventPublisherInterface) (ProfileServiceModuleInterface, error) {
 	return &profileServiceModule{
-		cfg:         cfg,
-		db:          db,
-		profileRepo: profileRepo,
+		cfg:             cfg,
+		db:              db,
+		profileRepo:     profileRepo,
+		blockRepo:       blockRepo,
+		presenceRepo:    presenceRepo,
+		contentFilter:   contentFilter,
+		contentFlagRepo: contentFlagRepo,
+		events:          events,
 	}, nil
 }
 
 func (p profileServiceModule) Create(ctx context.Context, userId int64, req domain.ProfileRequest) error {
+	ctx, span := tracing.Start(ctx, "service.profile.create")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "service.profile.create"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	validate := validator.New()
@@ -47,6 +66,10 @@ func (p profileServiceModule) Create(ctx context.Context, userId int64, req doma
 		return err
 	}
 
+	if err := p.screen(ctx, tags, userId, req); err != nil {
+		return err
+	}
+
 	if err := p.profileRepo.Create(ctx, userId, req); err != nil {
 		tags["error"] = "failed to create profile"
 		tags["status"] = "error"
@@ -58,14 +81,16 @@ func (p profileServiceModule) Create(ctx context.Context, userId int64, req doma
 }
 
 func (p profileServiceModule) UpdateProfilePic(ctx context.Context, userID int64, req domain.UpdateProfilePicRequest) error {
+	ctx, span := tracing.Start(ctx, "service.profile.update_profile_pic")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "service.profile.update_profile_pic"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	validate := validator.New()
@@ -81,19 +106,27 @@ func (p profileServiceModule) UpdateProfilePic(ctx context.Context, userID int64
 		return err
 	}
 
+	if err := p.events.Publish(ctx, domain.ProfileUpdatedEvent{UserID: userID}); err != nil {
+		tags["error"] = "failed publish profile updated"
+		tags["status"] = "error"
+		return err
+	}
+
 	tags["status"] = "success"
 	return nil
 }
 
 func (p profileServiceModule) UpdateProfile(ctx context.Context, userID int64, req domain.ProfileRequest) error {
+	ctx, span := tracing.Start(ctx, "service.profile.update_profile")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "service.profile.update_profile"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	validate := validator.New()
@@ -103,12 +136,136 @@ func (p profileServiceModule) UpdateProfile(ctx context.Context, userID int64, r
 		return err
 	}
 
+	if err := p.screen(ctx, tags, userID, req); err != nil {
+		return err
+	}
+
 	if err := p.profileRepo.UpdateProfile(ctx, userID, req); err != nil {
 		tags["error"] = "failed to update profile"
 		tags["status"] = "error"
 		return err
 	}
 
+	if err := p.events.Publish(ctx, domain.ProfileUpdatedEvent{UserID: userID}); err != nil {
+		tags["error"] = "failed publish profile updated"
+		tags["status"] = "error"
+		return err
+	}
+
 	tags["status"] = "success"
 	return nil
 }
+
+// GetProfile shows the profile of userID to viewerID. Last activity is left out when the owner turned it off,
+// owners always see their own.
+func (p profileServiceModule) GetProfile(ctx context.Context, viewerID int64, userID int64) (*domain.ProfileResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.profile.get_profile")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.profile.get_profile"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	tags["user_id"] = userID
+
+	profile, err := p.profileRepo.GetProfile(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed to get profile"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	isOwner := viewerID == userID
+	if profile == nil || profile.DeletedAt != nil || (profile.HiddenAt != nil && !isOwner) {
+		tags["error"] = "profile not found"
+		tags["status"] = "error"
+		return nil, domain.ErrProfileNotFound
+	}
+
+	if !isOwner {
+		blockedIDs, err := p.blockRepo.GetBlockedUserIDs(ctx, viewerID)
+		if err != nil {
+			tags["error"] = "failed to get blocked ids"
+			tags["actual_error"] = err.Error()
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		if lo.Contains(blockedIDs, userID) {
+			tags["error"] = "profile not found"
+			tags["actual_error"] = "blocked"
+			tags["status"] = "error"
+			return nil, domain.ErrProfileNotFound
+		}
+	}
+
+	response := &domain.ProfileResponse{
+		UserID:     profile.UserID,
+		Name:       profile.Name,
+		Pic:        profile.Pic,
+		Bio:        profile.Bio,
+		Gender:     profile.Gender,
+		InterestIn: profile.InterestIn,
+	}
+
+	if profile.ShowLastActive || isOwner {
+		lastActive, err := p.presenceRepo.GetLastActive(ctx, userID)
+		if err != nil {
+			tags["error"] = "failed to get last active"
+			tags["actual_error"] = err.Error()
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		presence := domain.NewPresence(lastActive[userID])
+		response.Online = presence.Online
+		response.LastActiveAt = presence.LastActiveAt
+	}
+
+	tags["status"] = "success"
+	return response, nil
+}
+
+func (p profileServiceModule) UpdatePrivacy(ctx context.Context, userID int64, req domain.PrivacyRequest) error {
+	ctx, span := tracing.Start(ctx, "service.profile.update_privacy")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.profile.update_privacy"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return err
+	}
+
+	if err := p.profileRepo.UpdatePrivacy(ctx, userID, *req.ShowLastActive); err != nil {
+		tags["error"] = "failed to update privacy"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return err
+	}
+
+	tags["status"] = "success"
+	return nil
+}
+
+func (p profileServiceModule) screen(ctx context.Context, tags log.Fields, userID int64, req domain.ProfileRequest) error {
+	return screenContent(ctx, tags, p.contentFilter, p.contentFlagRepo,
+		domain.FilterContent{UserID: userID, Kind: domain.ContentKindName, Text: req.Name},
+		domain.FilterContent{UserID: userID, Kind: domain.ContentKindBio, Text: req.Bio},
+	)
+}
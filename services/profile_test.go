@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+	"time"
+)
+
+func Test_profileServiceModule_GetProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	lastActive := time.Now().Add(-2 * time.Hour)
+
+	tests := []struct {
+		name           string
+		viewerID       int64
+		mock           func() *profileServiceModule
+		wantLastActive bool
+		wantErr        bool
+	}{
+		{
+			name:     "failed profile not found",
+			viewerID: 1,
+			mock: func() *profileServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(2)).Return(nil, nil)
+
+				return &profileServiceModule{profileRepo: profileMock}
+			},
+			wantErr: true,
+		},
+		{
+			name:     "failed profile hidden",
+			viewerID: 1,
+			mock: func() *profileServiceModule {
+				hiddenAt := time.Now()
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(2)).
+					Return(&domain.Profile{UserID: 2, HiddenAt: &hiddenAt}, nil)
+
+				return &profileServiceModule{profileRepo: profileMock}
+			},
+			wantErr: true,
+		},
+		{
+			name:     "failed viewer blocked",
+			viewerID: 1,
+			mock: func() *profileServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(2)).
+					Return(&domain.Profile{UserID: 2, ShowLastActive: true}, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return([]int64{2}, nil)
+
+				return &profileServiceModule{profileRepo: profileMock, blockRepo: blockMock}
+			},
+			wantErr: true,
+		},
+		{
+			name:     "success last active hidden by privacy",
+			viewerID: 1,
+			mock: func() *profileServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(2)).
+					Return(&domain.Profile{UserID: 2, ShowLastActive: false}, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return(nil, nil)
+
+				return &profileServiceModule{profileRepo: profileMock, blockRepo: blockMock}
+			},
+			wantLastActive: false,
+			wantErr:        false,
+		},
+		{
+			name:     "success owner always sees last active",
+			viewerID: 2,
+			mock: func() *profileServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(2)).
+					Return(&domain.Profile{UserID: 2, ShowLastActive: false}, nil)
+
+				presenceMock := NewMockPresenceRepoInterface(ctrl)
+				presenceMock.EXPECT().GetLastActive(ctx, int64(2)).
+					Return(map[int64]time.Time{2: lastActive}, nil)
+
+				return &profileServiceModule{profileRepo: profileMock, presenceRepo: presenceMock}
+			},
+			wantLastActive: true,
+			wantErr:        false,
+		},
+		{
+			name:     "success",
+			viewerID: 1,
+			mock: func() *profileServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(2)).
+					Return(&domain.Profile{UserID: 2, ShowLastActive: true}, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).Return(nil, nil)
+
+				presenceMock := NewMockPresenceRepoInterface(ctrl)
+				presenceMock.EXPECT().GetLastActive(ctx, int64(2)).
+					Return(map[int64]time.Time{2: lastActive}, nil)
+
+				return &profileServiceModule{profileRepo: profileMock, blockRepo: blockMock, presenceRepo: presenceMock}
+			},
+			wantLastActive: true,
+			wantErr:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.mock()
+			got, err := p.GetProfile(ctx, tt.viewerID, 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && (got.LastActiveAt != nil) != tt.wantLastActive {
+				t.Errorf("GetProfile() LastActiveAt = %v, wantLastActive %v", got.LastActiveAt, tt.wantLastActive)
+			}
+
+			if err == nil && got.Online {
+				t.Errorf("GetProfile() Online = %v, want false", got.Online)
+			}
+		})
+	}
+}
+
+func Test_profileServiceModule_UpdatePrivacy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	hide := false
+
+	tests := []struct {
+		name    string
+		req     domain.PrivacyRequest
+		mock    func() *profileServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating request",
+			req:  domain.PrivacyRequest{},
+			mock: func() *profileServiceModule {
+				return &profileServiceModule{}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed update privacy",
+			req:  domain.PrivacyRequest{ShowLastActive: &hide},
+			mock: func() *profileServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().UpdatePrivacy(ctx, int64(1), false).Return(errors.New("error"))
+
+				return &profileServiceModule{profileRepo: profileMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			req:  domain.PrivacyRequest{ShowLastActive: &hide},
+			mock: func() *profileServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().UpdatePrivacy(ctx, int64(1), false).Return(nil)
+
+				return &profileServiceModule{profileRepo: profileMock}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.mock()
+			if err := p.UpdatePrivacy(ctx, 1, tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("UpdatePrivacy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_profileServiceModule_UpdateProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	req := domain.ProfileRequest{
+		Name:       "test",
+		Bio:        "find me on www.example.com",
+		Gender:     "male",
+		InterestIn: "female",
+	}
+	name := domain.FilterContent{UserID: 1, Kind: domain.ContentKindName, Text: req.Name}
+	bio := domain.FilterContent{UserID: 1, Kind: domain.ContentKindBio, Text: req.Bio}
+	allow := domain.FilterResult{Verdict: domain.FilterVerdictAllow}
+
+	tests := []struct {
+		name    string
+		mock    func() *profileServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed bio blocked",
+			mock: func() *profileServiceModule {
+				blocked := domain.FilterResult{
+					Verdict: domain.FilterVerdictBlock,
+					Filter:  "contact_details",
+					Reason:  "contains a link",
+				}
+
+				filterMock := NewMockContentFilterInterface(ctrl)
+				filterMock.EXPECT().Check(ctx, name).Return(allow, nil)
+				filterMock.EXPECT().Check(ctx, bio).Return(blocked, nil)
+
+				contentFlagMock := NewMockContentFlagRepoInterface(ctrl)
+				contentFlagMock.EXPECT().Create(ctx, bio, blocked).Return(nil)
+
+				return &profileServiceModule{contentFilter: filterMock, contentFlagRepo: contentFlagMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success flagged content is stored and saved",
+			mock: func() *profileServiceModule {
+				flagged := domain.FilterResult{
+					Verdict: domain.FilterVerdictFlag,
+					Filter:  "custom",
+					Reason:  "looks suspicious",
+				}
+
+				filterMock := NewMockContentFilterInterface(ctrl)
+				filterMock.EXPECT().Check(ctx, name).Return(flagged, nil)
+				filterMock.EXPECT().Check(ctx, bio).Return(allow, nil)
+
+				contentFlagMock := NewMockContentFlagRepoInterface(ctrl)
+				contentFlagMock.EXPECT().Create(ctx, name, flagged).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().UpdateProfile(ctx, int64(1), req).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.ProfileUpdatedEvent{UserID: 1}).Return(nil)
+
+				return &profileServiceModule{
+					profileRepo:     profileMock,
+					contentFilter:   filterMock,
+					contentFlagRepo: contentFlagMock,
+					events:          eventsMock,
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.mock()
+			if err := p.UpdateProfile(ctx, 1, req); (err != nil) != tt.wantErr {
+				t.Errorf("UpdateProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPushMaxAttempts = 5
+	pushBatchSize          = 100
+	// pushLease is how long a claimed outbox entry is hidden from other workers.
+	pushLease      = 5 * time.Minute
+	pushRetryDelay = 30 * time.Second
+	pushMaxDelay   = time.Hour
+)
+
+// DeliverPush drains the push outbox. Every entry is pushed to all devices of
+// its user unless their preferences rule it out. Entries are marked sent as
+// soon as one device accepted the push, devices whose token the gateway
+// rejects are pruned and other failures are retried with exponential backoff.
+func (n notificationServiceModule) DeliverPush(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "service.notification.deliver_push")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.notification.deliver_push"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	delivered := 0
+	for {
+		entries, err := n.notificationRepo.ClaimOutbox(ctx, pushBatchSize, pushLease)
+		if err != nil {
+			tags["error"] = "failed claim outbox"
+			tags["actual_error"] = err.Error()
+			tags["status"] = "error"
+			return delivered, err
+		}
+
+		for _, entry := range entries {
+			update, err := n.deliver(ctx, entry)
+			if err != nil {
+				tags["error"] = "failed deliver push"
+				tags["actual_error"] = err.Error()
+				tags["status"] = "error"
+				return delivered, err
+			}
+
+			if err := n.notificationRepo.UpdateOutbox(ctx, entry.ID, update); err != nil {
+				tags["error"] = "failed update outbox"
+				tags["actual_error"] = err.Error()
+				tags["status"] = "error"
+				return delivered, err
+			}
+
+			if update.Status == domain.PushOutboxStatusSent {
+				delivered++
+			}
+		}
+
+		if len(entries) < pushBatchSize {
+			break
+		}
+	}
+
+	tags["delivered"] = delivered
+	tags["status"] = "success"
+	return delivered, nil
+}
+
+// deliver pushes one outbox entry and works out its next state. Only storage
+// errors are returned, gateway errors end up in the update.
+func (n notificationServiceModule) deliver(ctx context.Context, entry domain.PushOutbox) (domain.PushOutboxUpdate, error) {
+	skip := func(reason string) (domain.PushOutboxUpdate, error) {
+		return domain.PushOutboxUpdate{
+			Status:        domain.PushOutboxStatusSkipped,
+			Attempts:      entry.Attempts,
+			NextAttemptAt: entry.NextAttemptAt,
+			LastError:     reason,
+		}, nil
+	}
+
+	notification, err := n.notificationRepo.GetByID(ctx, entry.NotificationID)
+	if err != nil {
+		return domain.PushOutboxUpdate{}, err
+	}
+
+	if notification == nil {
+		return skip("notification deleted")
+	}
+
+	if notification.IsRead {
+		return skip("notification already read")
+	}
+
+	prefs, err := n.preferences(ctx, entry.UserID)
+	if err != nil {
+		return domain.PushOutboxUpdate{}, err
+	}
+
+	if !prefs.Allows(notification.Type) {
+		return skip("disabled by preferences")
+	}
+
+	devices, err := n.deviceRepo.GetByUserID(ctx, entry.UserID)
+	if err != nil {
+		return domain.PushOutboxUpdate{}, err
+	}
+
+	sent := 0
+	var lastErr error
+	for _, device := range devices {
+		body := renderNotification(ctx, n.settings, *notification, device.Locale)
+		err := n.pushSender.Send(ctx, pushMessage(*notification, device, body))
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, domain.ErrInvalidPushToken):
+			if err := n.deviceRepo.DeleteByToken(ctx, device.Token); err != nil {
+				return domain.PushOutboxUpdate{}, err
+			}
+		default:
+			lastErr = err
+		}
+	}
+
+	if sent > 0 {
+		return domain.PushOutboxUpdate{
+			Status:        domain.PushOutboxStatusSent,
+			Attempts:      entry.Attempts + 1,
+			NextAttemptAt: entry.NextAttemptAt,
+		}, nil
+	}
+
+	if lastErr == nil {
+		return skip("no registered devices")
+	}
+
+	attempts := entry.Attempts + 1
+	if attempts >= pushMaxAttempts(n.cfg) {
+		return domain.PushOutboxUpdate{
+			Status:        domain.PushOutboxStatusFailed,
+			Attempts:      attempts,
+			NextAttemptAt: entry.NextAttemptAt,
+			LastError:     lastErr.Error(),
+		}, nil
+	}
+
+	return domain.PushOutboxUpdate{
+		Status:        domain.PushOutboxStatusPending,
+		Attempts:      attempts,
+		NextAttemptAt: time.Now().Add(pushBackoff(attempts)),
+		LastError:     lastErr.Error(),
+	}, nil
+}
+
+func pushMessage(notification domain.Notification, device domain.DeviceToken, body string) domain.PushMessage {
+	msg := domain.PushMessage{
+		Platform: device.Platform,
+		Token:    device.Token,
+		Body:     body,
+		Data: map[string]string{
+			"notification_id": strconv.FormatInt(notification.ID, 10),
+			"type":            notification.Type,
+		},
+	}
+
+	// Pushes for the same conversation replace each other on the lock screen.
+	if notification.Payload.ConversationID != 0 {
+		msg.CollapseKey = fmt.Sprintf("conversation-%d", notification.Payload.ConversationID)
+		msg.Data["conversation_id"] = strconv.FormatInt(notification.Payload.ConversationID, 10)
+	}
+
+	return msg
+}
+
+func pushMaxAttempts(cfg *domain.Config) int {
+	if cfg.Push.MaxAttempts > 0 {
+		return cfg.Push.MaxAttempts
+	}
+
+	return defaultPushMaxAttempts
+}
+
+// pushBackoff doubles pushRetryDelay for every failed attempt, capped at pushMaxDelay.
+func pushBackoff(attempts int) time.Duration {
+	delay := pushRetryDelay
+	for i := 1; i < attempts && delay < pushMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > pushMaxDelay {
+		return pushMaxDelay
+	}
+
+	return delay
+}
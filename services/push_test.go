@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+	"time"
+)
+
+func Test_notificationServiceModule_DeliverPush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{Push: domain.Push{MaxAttempts: 3}}
+	nextAttemptAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := domain.PushOutbox{ID: 7, NotificationID: 5, UserID: 2, Status: domain.PushOutboxStatusPending,
+		NextAttemptAt: nextAttemptAt}
+	notification := &domain.Notification{
+		ID:          5,
+		UserID:      2,
+		Type:        domain.NotificationTypeMatch,
+		TemplateKey: domain.NotificationTemplateMatchNew,
+		Payload:     domain.NotificationPayload{ActorUserID: 1, ActorName: "Alice", MatchID: 10},
+	}
+	devices := []domain.DeviceToken{
+		{UserID: 2, Platform: domain.PushPlatformIOS, Token: "ios-token", Locale: "id"},
+		{UserID: 2, Platform: domain.PushPlatformAndroid, Token: "android-token"},
+	}
+
+	tests := []struct {
+		name    string
+		mock    func() *notificationServiceModule
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "failed claim outbox",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().ClaimOutbox(ctx, pushBatchSize, pushLease).Return(nil, errors.New("test"))
+
+				return &notificationServiceModule{cfg: config, notificationRepo: notificationMock}
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "success skips disabled by preferences",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().ClaimOutbox(ctx, pushBatchSize, pushLease).Return([]domain.PushOutbox{entry}, nil)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(notification, nil)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(2)).
+					Return(&domain.NotificationPreferences{UserID: 2, Messages: true}, nil)
+				notificationMock.EXPECT().UpdateOutbox(ctx, int64(7), domain.PushOutboxUpdate{
+					Status:        domain.PushOutboxStatusSkipped,
+					NextAttemptAt: nextAttemptAt,
+					LastError:     "disabled by preferences",
+				}).Return(nil)
+
+				return &notificationServiceModule{cfg: config, notificationRepo: notificationMock}
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "success sends rendered per device locale and prunes invalid token",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().ClaimOutbox(ctx, pushBatchSize, pushLease).Return([]domain.PushOutbox{entry}, nil)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(notification, nil)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(2)).Return(nil, nil)
+				notificationMock.EXPECT().UpdateOutbox(ctx, int64(7), domain.PushOutboxUpdate{
+					Status:        domain.PushOutboxStatusSent,
+					Attempts:      1,
+					NextAttemptAt: nextAttemptAt,
+				}).Return(nil)
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().GetByUserID(ctx, int64(2)).Return(devices, nil)
+				deviceMock.EXPECT().DeleteByToken(ctx, "android-token").Return(nil)
+
+				pushMock := NewMockPushSenderInterface(ctrl)
+				pushMock.EXPECT().Send(ctx, domain.PushMessage{
+					Platform: domain.PushPlatformIOS,
+					Token:    "ios-token",
+					Body:     "Kamu punya match baru dengan Alice",
+					Data:     map[string]string{"notification_id": "5", "type": domain.NotificationTypeMatch},
+				}).Return(nil)
+				pushMock.EXPECT().Send(ctx, domain.PushMessage{
+					Platform: domain.PushPlatformAndroid,
+					Token:    "android-token",
+					Body:     "You have a new match with Alice",
+					Data:     map[string]string{"notification_id": "5", "type": domain.NotificationTypeMatch},
+				}).Return(domain.ErrInvalidPushToken)
+
+				return &notificationServiceModule{cfg: config, notificationRepo: notificationMock,
+					deviceRepo: deviceMock, pushSender: pushMock, settings: defaultSettingsMock(ctrl)}
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "success retries with backoff on gateway error",
+			mock: func() *notificationServiceModule {
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().ClaimOutbox(ctx, pushBatchSize, pushLease).Return([]domain.PushOutbox{entry}, nil)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(notification, nil)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(2)).Return(nil, nil)
+				notificationMock.EXPECT().UpdateOutbox(ctx, int64(7), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, update domain.PushOutboxUpdate) error {
+						if update.Status != domain.PushOutboxStatusPending || update.Attempts != 1 ||
+							update.LastError != "unavailable" {
+							t.Errorf("UpdateOutbox() got = %+v, want pending retry", update)
+						}
+
+						if wait := time.Until(update.NextAttemptAt); wait <= 0 || wait > pushRetryDelay {
+							t.Errorf("UpdateOutbox() next attempt in %v, want within %v", wait, pushRetryDelay)
+						}
+
+						return nil
+					})
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().GetByUserID(ctx, int64(2)).Return(devices[:1], nil)
+
+				pushMock := NewMockPushSenderInterface(ctrl)
+				pushMock.EXPECT().Send(ctx, gomock.Any()).Return(errors.New("unavailable"))
+
+				return &notificationServiceModule{cfg: config, notificationRepo: notificationMock,
+					deviceRepo: deviceMock, pushSender: pushMock, settings: defaultSettingsMock(ctrl)}
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "success gives up after max attempts",
+			mock: func() *notificationServiceModule {
+				retried := entry
+				retried.Attempts = 2
+
+				notificationMock := NewMockNotificationRepoInterface(ctrl)
+				notificationMock.EXPECT().ClaimOutbox(ctx, pushBatchSize, pushLease).Return([]domain.PushOutbox{retried}, nil)
+				notificationMock.EXPECT().GetByID(ctx, int64(5)).Return(notification, nil)
+				notificationMock.EXPECT().GetPreferences(ctx, int64(2)).Return(nil, nil)
+				notificationMock.EXPECT().UpdateOutbox(ctx, int64(7), domain.PushOutboxUpdate{
+					Status:        domain.PushOutboxStatusFailed,
+					Attempts:      3,
+					NextAttemptAt: nextAttemptAt,
+					LastError:     "unavailable",
+				}).Return(nil)
+
+				deviceMock := NewMockDeviceRepoInterface(ctrl)
+				deviceMock.EXPECT().GetByUserID(ctx, int64(2)).Return(devices[:1], nil)
+
+				pushMock := NewMockPushSenderInterface(ctrl)
+				pushMock.EXPECT().Send(ctx, gomock.Any()).Return(errors.New("unavailable"))
+
+				return &notificationServiceModule{cfg: config, notificationRepo: notificationMock,
+					deviceRepo: deviceMock, pushSender: pushMock, settings: defaultSettingsMock(ctrl)}
+			},
+			want:    0,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.mock()
+			got, err := n.DeliverPush(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeliverPush() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("DeliverPush() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pushBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 20, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := pushBackoff(tt.attempts); got != tt.want {
+			t.Errorf("pushBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
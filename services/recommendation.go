@@ -7,15 +7,22 @@ import (
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
 	"gorm.io/gorm"
+	"sort"
 	"time"
 )
 
 type recommendationServiceModule struct {
-	cfg         *domain.Config
-	db          *gorm.DB
-	profileRepo ProfileRepoInterface
-	redisRepo   RedisRepoInterface
+	cfg          *domain.Config
+	db           *gorm.DB
+	profileRepo  ProfileRepoInterface
+	redisRepo    RedisRepoInterface
+	blockRepo    BlockRepoInterface
+	presenceRepo PresenceRepoInterface
+	settings     SettingsInterface
+	experiments  ExperimentsInterface
 }
 
 type RecommendationServiceInterface interface {
@@ -23,24 +30,32 @@ type RecommendationServiceInterface interface {
 }
 
 func NewRecommendationService(cfg *domain.Config, db *gorm.DB, redisRepo RedisRepoInterface,
-	profileRepo ProfileRepoInterface) (RecommendationServiceInterface, error) {
+	profileRepo ProfileRepoInterface, blockRepo BlockRepoInterface,
+	presenceRepo PresenceRepoInterface, settings SettingsInterface,
+	experiments ExperimentsInterface) (RecommendationServiceInterface, error) {
 	return &recommendationServiceModule{
-		cfg:         cfg,
-		db:          db,
-		profileRepo: profileRepo,
-		redisRepo:   redisRepo,
+		cfg:          cfg,
+		db:           db,
+		profileRepo:  profileRepo,
+		redisRepo:    redisRepo,
+		blockRepo:    blockRepo,
+		presenceRepo: presenceRepo,
+		settings:     settings,
+		experiments:  experiments,
 	}, nil
 }
 
 func (r recommendationServiceModule) GetRecommendation(ctx context.Context, userID int64) (recommendation []domain.Profile, err error) {
+	ctx, span := tracing.Start(ctx, "service.recommendation.get_recommendation")
 	startTime := time.Now()
 	tags := make(log.Fields)
 
 	defer func() {
 		tags["name"] = "service.recommendation.get_recommendation"
 		tags["elapsed_time"] = time.Since(startTime).String()
-		tags["request_id"] = ctx.Value("requestid").(string)
-		log.WithFields(tags).Debug()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
 	}()
 
 	profile, err := r.profileRepo.GetProfile(ctx, userID)
@@ -77,17 +92,46 @@ func (r recommendationServiceModule) GetRecommendation(ctx context.Context, user
 		excludedIDs = append(excludedIDs, userID)
 	}
 
-	profileRecommendations, err := r.profileRepo.GetProfileRecommendation(ctx, profile.InterestIn, excludedIDs)
+	blockedIDs, err := r.blockRepo.GetBlockedUserIDs(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get blocked ids"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	// Blocked users are excluded on every request, they are not added to the frozen list.
+	queryIDs := append(append([]int64{}, excludedIDs...), blockedIDs...)
+
+	pageSize := r.settings.Int(ctx, domain.SettingRecommendationPageSize)
+	profileRecommendations, err := r.profileRepo.GetProfileRecommendation(ctx, profile.InterestIn, queryIDs, pageSize)
 	if err != nil {
 		tags["error"] = "failed get recommendation"
 		tags["status"] = "error"
 		return nil, err
 	}
 
-	for _, v := range profileRecommendations {
-		excludedIDs = append(excludedIDs, v.UserID)
+	userIDs := lo.Map(profileRecommendations, func(p domain.Profile, _ int) int64 {
+		return p.UserID
+	})
+
+	variant := r.experiments.Variant(ctx, domain.ExperimentRecommendationRanking)
+	tags["ranking_variant"] = variant
+
+	if variant == domain.VariantRankingNewest {
+		rankByNewest(profileRecommendations)
+	} else if r.settings.Bool(ctx, domain.SettingRecommendationRankByActivity) {
+		// Presence only changes the order, recommendations are still served when it is unavailable.
+		lastActive, err := r.presenceRepo.GetLastActive(ctx, userIDs...)
+		if err != nil {
+			tags["warning"] = "failed get last active"
+			tags["actual_error"] = err.Error()
+		} else {
+			rankByActivity(profileRecommendations, lastActive)
+		}
 	}
 
+	excludedIDs = append(excludedIDs, userIDs...)
+
 	marshaledFrozenIds, err := json.Marshal(excludedIDs)
 	if err != nil {
 		tags["error"] = "failed marshal"
@@ -95,7 +139,8 @@ func (r recommendationServiceModule) GetRecommendation(ctx context.Context, user
 		return nil, err
 	}
 
-	if err := r.redisRepo.Set(ctx, key, marshaledFrozenIds, 60*60*24); err != nil {
+	frozenTTL := r.settings.Int(ctx, domain.SettingRecommendationFrozenTTL)
+	if err := r.redisRepo.Set(ctx, key, marshaledFrozenIds, frozenTTL); err != nil {
 		tags["error"] = "failed set frozen ids"
 		tags["status"] = "error"
 		return nil, err
@@ -104,3 +149,17 @@ func (r recommendationServiceModule) GetRecommendation(ctx context.Context, user
 	tags["status"] = "success"
 	return profileRecommendations, nil
 }
+
+// rankByActivity moves recently active users to the front, users we have no activity for keep their order at the end.
+func rankByActivity(profiles []domain.Profile, lastActive map[int64]time.Time) {
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return lastActive[profiles[i].UserID].After(lastActive[profiles[j].UserID])
+	})
+}
+
+// rankByNewest moves the most recently created profiles to the front.
+func rankByNewest(profiles []domain.Profile) {
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return profiles[i].CreatedAt.After(profiles[j].CreatedAt)
+	})
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/zombozo12/tinder-dealls/domain"
 	"gorm.io/gorm"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestNewRecommendationService(t *testing.T) {
@@ -15,16 +17,24 @@ func TestNewRecommendationService(t *testing.T) {
 	defer ctrl.Finish()
 
 	type args struct {
-		cfg         *domain.Config
-		db          *gorm.DB
-		redisRepo   RedisRepoInterface
-		profileRepo ProfileRepoInterface
+		cfg          *domain.Config
+		db           *gorm.DB
+		redisRepo    RedisRepoInterface
+		profileRepo  ProfileRepoInterface
+		blockRepo    BlockRepoInterface
+		presenceRepo PresenceRepoInterface
+		settings     SettingsInterface
+		experiments  ExperimentsInterface
 	}
 
 	config := &domain.Config{}
 	db := &gorm.DB{}
 	redisMock := NewMockRedisRepoInterface(ctrl)
 	profileMock := NewMockProfileRepoInterface(ctrl)
+	blockMock := NewMockBlockRepoInterface(ctrl)
+	presenceMock := NewMockPresenceRepoInterface(ctrl)
+	settingsMock := NewMockSettingsInterface(ctrl)
+	experimentsMock := NewMockExperimentsInterface(ctrl)
 
 	tests := []struct {
 		name    string
@@ -35,16 +45,22 @@ func TestNewRecommendationService(t *testing.T) {
 		{
 			name: "success",
 			args: args{
-				cfg:         config,
-				db:          db,
-				profileRepo: profileMock,
-				redisRepo:   redisMock,
+				cfg:          config,
+				db:           db,
+				profileRepo:  profileMock,
+				redisRepo:    redisMock,
+				blockRepo:    blockMock,
+				presenceRepo: presenceMock,
+				settings:     settingsMock,
+				experiments:  experimentsMock,
 			},
 			want: &recommendationServiceModule{
-				cfg:         config,
-				db:          db,
-				profileRepo: profileMock,
-				redisRepo:   redisMock,
+				cfg:          config,
+				db:           db,
+				profileRepo:  profileMock,
+				redisRepo:    redisMock,
+				blockRepo:    blockMock,
+				presenceRepo: presenceMock,
 			},
 			wantErr: false,
 		},
@@ -52,7 +68,8 @@ func TestNewRecommendationService(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewRecommendationService(tt.args.cfg, tt.args.db, tt.args.redisRepo, tt.args.profileRepo)
+			got, err := NewRecommendationService(tt.args.cfg, tt.args.db, tt.args.redisRepo, tt.args.profileRepo,
+				tt.args.blockRepo, tt.args.presenceRepo, tt.args.settings, tt.args.experiments)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewRecommendationService() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -172,6 +189,33 @@ func TestRecommendationServiceModule_GetRecommendation(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "failed get blocked ids",
+			args: successArgs,
+			mock: func() *recommendationServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).
+					Return(&domain.Profile{
+						UserID: 1,
+					}, nil)
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "frozen:1").
+					Return("[1]", nil)
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).
+					Return(nil, errors.New("test"))
+				return &recommendationServiceModule{
+					cfg:         config,
+					db:          nil,
+					profileRepo: profileMock,
+					redisRepo:   redisMock,
+					blockRepo:   blockMock,
+					settings:    defaultSettingsMock(ctrl),
+				}
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "failed get profile recommendation",
 			args: successArgs,
@@ -184,13 +228,18 @@ func TestRecommendationServiceModule_GetRecommendation(t *testing.T) {
 				redisMock := NewMockRedisRepoInterface(ctrl)
 				redisMock.EXPECT().Get(ctx, "frozen:1").
 					Return("[1]", nil)
-				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1}).
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).
+					Return(nil, nil)
+				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1}, 10).
 					Return(nil, errors.New("test"))
 				return &recommendationServiceModule{
 					cfg:         config,
 					db:          nil,
 					profileRepo: profileMock,
 					redisRepo:   redisMock,
+					blockRepo:   blockMock,
+					settings:    defaultSettingsMock(ctrl),
 				}
 			},
 			want:    nil,
@@ -208,26 +257,196 @@ func TestRecommendationServiceModule_GetRecommendation(t *testing.T) {
 				redisMock := NewMockRedisRepoInterface(ctrl)
 				redisMock.EXPECT().Get(ctx, "frozen:1").
 					Return("[1]", nil)
-				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1}).
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).
+					Return([]int64{3}, nil)
+				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1, 3}, 10).
 					Return([]domain.Profile{
 						{
 							UserID: 1,
 						},
 					}, nil)
 
-				redisMock.EXPECT().Set(ctx, "frozen:1", gomock.Any(), 60*60*24).
+				presenceMock := NewMockPresenceRepoInterface(ctrl)
+				presenceMock.EXPECT().GetLastActive(ctx, int64(1)).
+					Return(map[int64]time.Time{}, nil)
+
+				redisMock.EXPECT().Set(ctx, "frozen:1", []byte("[1,1]"), 60*60*24).
+					Return(nil)
+				return &recommendationServiceModule{
+					cfg:          config,
+					db:           nil,
+					profileRepo:  profileMock,
+					redisRepo:    redisMock,
+					blockRepo:    blockMock,
+					presenceRepo: presenceMock,
+					settings:     defaultSettingsMock(ctrl),
+					experiments:  defaultExperimentsMock(ctrl),
+				}
+			},
+			want: []domain.Profile{
+				{
+					UserID: 1,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "success ranks recently active first",
+			args: successArgs,
+			mock: func() *recommendationServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).
+					Return(&domain.Profile{
+						UserID: 1,
+					}, nil)
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "frozen:1").
+					Return("", nil)
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).
+					Return(nil, nil)
+				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1}, 10).
+					Return([]domain.Profile{{UserID: 4}, {UserID: 5}, {UserID: 6}}, nil)
+
+				now := time.Now()
+				presenceMock := NewMockPresenceRepoInterface(ctrl)
+				presenceMock.EXPECT().GetLastActive(ctx, int64(4), int64(5), int64(6)).
+					Return(map[int64]time.Time{
+						5: now.Add(-2 * time.Hour),
+						6: now,
+					}, nil)
+
+				redisMock.EXPECT().Set(ctx, "frozen:1", []byte("[1,4,5,6]"), 60*60*24).
+					Return(nil)
+				return &recommendationServiceModule{
+					cfg:          config,
+					db:           nil,
+					profileRepo:  profileMock,
+					redisRepo:    redisMock,
+					blockRepo:    blockMock,
+					presenceRepo: presenceMock,
+					settings:     defaultSettingsMock(ctrl),
+					experiments:  defaultExperimentsMock(ctrl),
+				}
+			},
+			want:    []domain.Profile{{UserID: 6}, {UserID: 5}, {UserID: 4}},
+			wantErr: false,
+		},
+		{
+			name: "success presence unavailable keeps order",
+			args: successArgs,
+			mock: func() *recommendationServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).
+					Return(&domain.Profile{
+						UserID: 1,
+					}, nil)
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "frozen:1").
+					Return("", nil)
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).
+					Return(nil, nil)
+				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1}, 10).
+					Return([]domain.Profile{{UserID: 4}, {UserID: 5}}, nil)
+
+				presenceMock := NewMockPresenceRepoInterface(ctrl)
+				presenceMock.EXPECT().GetLastActive(ctx, int64(4), int64(5)).
+					Return(nil, errors.New("test"))
+
+				redisMock.EXPECT().Set(ctx, "frozen:1", []byte("[1,4,5]"), 60*60*24).
+					Return(nil)
+				return &recommendationServiceModule{
+					cfg:          config,
+					db:           nil,
+					profileRepo:  profileMock,
+					redisRepo:    redisMock,
+					blockRepo:    blockMock,
+					presenceRepo: presenceMock,
+					settings:     defaultSettingsMock(ctrl),
+					experiments:  defaultExperimentsMock(ctrl),
+				}
+			},
+			want:    []domain.Profile{{UserID: 4}, {UserID: 5}},
+			wantErr: false,
+		},
+		{
+			name: "success uses runtime settings",
+			args: successArgs,
+			mock: func() *recommendationServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).
+					Return(&domain.Profile{
+						UserID: 1,
+					}, nil)
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "frozen:1").
+					Return("", nil)
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).
+					Return(nil, nil)
+				settingsMock := NewMockSettingsInterface(ctrl)
+				settingsMock.EXPECT().Int(ctx, domain.SettingRecommendationPageSize).Return(2)
+				settingsMock.EXPECT().Bool(ctx, domain.SettingRecommendationRankByActivity).Return(false)
+				settingsMock.EXPECT().Int(ctx, domain.SettingRecommendationFrozenTTL).Return(3600)
+				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1}, 2).
+					Return([]domain.Profile{{UserID: 4}, {UserID: 5}}, nil)
+
+				redisMock.EXPECT().Set(ctx, "frozen:1", []byte("[1,4,5]"), 3600).
+					Return(nil)
+				return &recommendationServiceModule{
+					cfg:         config,
+					db:          nil,
+					profileRepo: profileMock,
+					redisRepo:   redisMock,
+					blockRepo:   blockMock,
+					settings:    settingsMock,
+					experiments: defaultExperimentsMock(ctrl),
+				}
+			},
+			want:    []domain.Profile{{UserID: 4}, {UserID: 5}},
+			wantErr: false,
+		},
+		{
+			name: "success newest variant ranks newest first",
+			args: successArgs,
+			mock: func() *recommendationServiceModule {
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().GetProfile(ctx, int64(1)).
+					Return(&domain.Profile{
+						UserID: 1,
+					}, nil)
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().Get(ctx, "frozen:1").
+					Return("", nil)
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().GetBlockedUserIDs(ctx, int64(1)).
+					Return(nil, nil)
+				profileMock.EXPECT().GetProfileRecommendation(ctx, "", []int64{1}, 10).
+					Return([]domain.Profile{
+						{UserID: 4, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+						{UserID: 5, CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+					}, nil)
+				experimentsMock := NewMockExperimentsInterface(ctrl)
+				experimentsMock.EXPECT().Variant(ctx, domain.ExperimentRecommendationRanking).
+					Return(domain.VariantRankingNewest)
+
+				redisMock.EXPECT().Set(ctx, "frozen:1", []byte("[1,4,5]"), 60*60*24).
 					Return(nil)
 				return &recommendationServiceModule{
 					cfg:         config,
 					db:          nil,
 					profileRepo: profileMock,
 					redisRepo:   redisMock,
+					blockRepo:   blockMock,
+					settings:    defaultSettingsMock(ctrl),
+					experiments: experimentsMock,
 				}
 			},
 			want: []domain.Profile{
-				{
-					UserID: 1,
-				},
+				{UserID: 5, CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+				{UserID: 4, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
 			},
 			wantErr: false,
 		},
@@ -247,3 +466,37 @@ func TestRecommendationServiceModule_GetRecommendation(t *testing.T) {
 		})
 	}
 }
+
+// defaultSettingsMock serves every runtime setting with its default value.
+func defaultSettingsMock(ctrl *gomock.Controller) *MockSettingsInterface {
+	value := func(key string) string {
+		definition, _ := domain.GetSettingDefinition(key)
+		return definition.Default
+	}
+
+	settingsMock := NewMockSettingsInterface(ctrl)
+	settingsMock.EXPECT().Int(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key string) int {
+		n, _ := strconv.Atoi(value(key))
+		return n
+	}).AnyTimes()
+	settingsMock.EXPECT().Bool(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key string) bool {
+		b, _ := strconv.ParseBool(value(key))
+		return b
+	}).AnyTimes()
+	settingsMock.EXPECT().String(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key string) string {
+		return value(key)
+	}).AnyTimes()
+
+	return settingsMock
+}
+
+// defaultExperimentsMock serves the control variant of every experiment and keeps every flag off.
+func defaultExperimentsMock(ctrl *gomock.Controller) *MockExperimentsInterface {
+	experimentsMock := NewMockExperimentsInterface(ctrl)
+	experimentsMock.EXPECT().Variant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, experiment string) string {
+		return domain.ExperimentVariants(experiment)[0]
+	}).AnyTimes()
+	experimentsMock.EXPECT().Enabled(gomock.Any(), gomock.Any()).Return(false).AnyTimes()
+
+	return experimentsMock
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"time"
+)
+
+const defaultReportThreshold = 3
+
+type reportServiceModule struct {
+	cfg         *domain.Config
+	authRepo    AuthRepoInterface
+	profileRepo ProfileRepoInterface
+	reportRepo  ReportRepoInterface
+	blockRepo   BlockRepoInterface
+	unitOfWork  UnitOfWorkInterface
+}
+
+type ReportServiceInterface interface {
+	Report(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error)
+}
+
+func NewReportService(cfg *domain.Config, authRepo AuthRepoInterface, profileRepo ProfileRepoInterface,
+	reportRepo ReportRepoInterface, blockRepo BlockRepoInterface, unitOfWork UnitOfWorkInterface) (ReportServiceInterface, error) {
+	return &reportServiceModule{
+		cfg:         cfg,
+		authRepo:    authRepo,
+		profileRepo: profileRepo,
+		reportRepo:  reportRepo,
+		blockRepo:   blockRepo,
+		unitOfWork:  unitOfWork,
+	}, nil
+}
+
+// Report files a report into the moderation queue and blocks the reported user for the reporter.
+// Once enough distinct users have pending reports against someone, their profile is hidden from
+// recommendations until a moderator reviews it.
+func (r reportServiceModule) Report(ctx context.Context, reporterID int64, req domain.ReportRequest) (*domain.Report, error) {
+	ctx, span := tracing.Start(ctx, "service.report.report")
+	startTime := time.Now()
+	tags := make(log.Fields)
+
+	defer func() {
+		tags["name"] = "service.report.report"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["reporter_id"] = reporterID
+	tags["reported_user_id"] = req.ReportedUserID
+
+	if reporterID == req.ReportedUserID {
+		tags["error"] = "cannot report yourself"
+		tags["status"] = "error"
+		return nil, domain.ErrReportSelf
+	}
+
+	reportedUser, err := r.authRepo.GetByID(ctx, req.ReportedUserID)
+	if err != nil {
+		tags["error"] = "failed get reported user"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if reportedUser == nil {
+		tags["error"] = "reported user not found"
+		tags["status"] = "error"
+		return nil, domain.ErrUserNotFound
+	}
+
+	// The report, the block and the auto-hide commit together, a failure must not leave a report without them.
+	var report *domain.Report
+	if err := r.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		report, err = r.reportRepo.Create(ctx, reporterID, req)
+		if err != nil {
+			tags["error"] = "failed create report"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		if err := r.blockRepo.Create(ctx, reporterID, req.ReportedUserID); err != nil {
+			tags["error"] = "failed block reported user"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		reporters, err := r.reportRepo.CountPendingReporters(ctx, req.ReportedUserID)
+		if err != nil {
+			tags["error"] = "failed count pending reporters"
+			tags["actual_error"] = err.Error()
+			return err
+		}
+
+		if reporters >= int64(reportThreshold(r.cfg)) {
+			hiddenAt := time.Now()
+			if err := r.profileRepo.SetHidden(ctx, req.ReportedUserID, &hiddenAt); err != nil {
+				tags["error"] = "failed hide reported profile"
+				tags["actual_error"] = err.Error()
+				return err
+			}
+
+			tags["hidden"] = true
+		}
+
+		return nil
+	}); err != nil {
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return report, nil
+}
+
+func reportThreshold(cfg *domain.Config) int {
+	if cfg == nil || cfg.Moderation.ReportThreshold == 0 {
+		return defaultReportThreshold
+	}
+
+	return cfg.Moderation.ReportThreshold
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+)
+
+func Test_reportServiceModule_Report(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{
+		Moderation: domain.Moderation{
+			ReportThreshold: 2,
+		},
+	}
+
+	req := domain.ReportRequest{
+		ReportedUserID: 2,
+		Reason:         domain.ReportReasonSpam,
+	}
+
+	tests := []struct {
+		name    string
+		req     domain.ReportRequest
+		mock    func() *reportServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating reason",
+			req: domain.ReportRequest{
+				ReportedUserID: 2,
+				Reason:         "boring",
+			},
+			mock: func() *reportServiceModule {
+				return &reportServiceModule{cfg: config}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed validating description for other",
+			req: domain.ReportRequest{
+				ReportedUserID: 2,
+				Reason:         domain.ReportReasonOther,
+			},
+			mock: func() *reportServiceModule {
+				return &reportServiceModule{cfg: config}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed reporting yourself",
+			req: domain.ReportRequest{
+				ReportedUserID: 1,
+				Reason:         domain.ReportReasonSpam,
+			},
+			mock: func() *reportServiceModule {
+				return &reportServiceModule{cfg: config}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed reported user not found",
+			req:  req,
+			mock: func() *reportServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(2)).Return(nil, nil)
+
+				return &reportServiceModule{cfg: config, authRepo: authMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed blocking reported user",
+			req:  req,
+			mock: func() *reportServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(2)).Return(&domain.User{ID: 2}, nil)
+
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().Create(ctx, int64(1), req).Return(&domain.Report{ID: 1}, nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().Create(ctx, int64(1), int64(2)).Return(errors.New("error"))
+
+				return &reportServiceModule{cfg: config, authRepo: authMock, reportRepo: reportMock, blockRepo: blockMock,
+					unitOfWork: transactional(ctrl)}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success below threshold",
+			req:  req,
+			mock: func() *reportServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(2)).Return(&domain.User{ID: 2}, nil)
+
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().Create(ctx, int64(1), req).Return(&domain.Report{ID: 1}, nil)
+				reportMock.EXPECT().CountPendingReporters(ctx, int64(2)).Return(int64(1), nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().Create(ctx, int64(1), int64(2)).Return(nil)
+
+				return &reportServiceModule{cfg: config, authRepo: authMock, reportRepo: reportMock, blockRepo: blockMock,
+					unitOfWork: transactional(ctrl)}
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed hiding reported profile",
+			req:  req,
+			mock: func() *reportServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(2)).Return(&domain.User{ID: 2}, nil)
+
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().Create(ctx, int64(1), req).Return(&domain.Report{ID: 1}, nil)
+				reportMock.EXPECT().CountPendingReporters(ctx, int64(2)).Return(int64(2), nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().Create(ctx, int64(1), int64(2)).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().SetHidden(ctx, int64(2), gomock.Not(gomock.Nil())).Return(errors.New("error"))
+
+				return &reportServiceModule{
+					cfg:         config,
+					authRepo:    authMock,
+					profileRepo: profileMock,
+					reportRepo:  reportMock,
+					blockRepo:   blockMock,
+					unitOfWork:  transactional(ctrl),
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success hides profile at threshold",
+			req:  req,
+			mock: func() *reportServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetByID(ctx, int64(2)).Return(&domain.User{ID: 2}, nil)
+
+				reportMock := NewMockReportRepoInterface(ctrl)
+				reportMock.EXPECT().Create(ctx, int64(1), req).Return(&domain.Report{ID: 1}, nil)
+				reportMock.EXPECT().CountPendingReporters(ctx, int64(2)).Return(int64(2), nil)
+
+				blockMock := NewMockBlockRepoInterface(ctrl)
+				blockMock.EXPECT().Create(ctx, int64(1), int64(2)).Return(nil)
+
+				profileMock := NewMockProfileRepoInterface(ctrl)
+				profileMock.EXPECT().SetHidden(ctx, int64(2), gomock.Not(gomock.Nil())).Return(nil)
+
+				return &reportServiceModule{
+					cfg:         config,
+					authRepo:    authMock,
+					profileRepo: profileMock,
+					reportRepo:  reportMock,
+					blockRepo:   blockMock,
+					unitOfWork:  transactional(ctrl),
+				}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.mock()
+			got, err := r.Report(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Report() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got == nil {
+				t.Errorf("Report() got = nil, want report")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_totpCode(t *testing.T) {
+	// RFC 6238 appendix B vectors for HMAC-SHA1, truncated to six digits.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "t=59", unix: 59, want: "287082"},
+		{name: "t=1111111109", unix: 1111111109, want: "081804"},
+		{name: "t=1234567890", unix: 1234567890, want: "005924"},
+		{name: "t=2000000000", unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := totpCode(secret, uint64(tt.unix/totpPeriod))
+			if err != nil {
+				t.Fatalf("totpCode() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("totpCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateTOTP(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generateTOTPSecret() error = %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	current, _ := totpCode(secret, uint64(now.Unix()/totpPeriod))
+	previous, _ := totpCode(secret, uint64(now.Unix()/totpPeriod-1))
+	stale, _ := totpCode(secret, uint64(now.Unix()/totpPeriod-3))
+
+	tests := []struct {
+		name        string
+		code        string
+		want        bool
+		wantCounter int64
+	}{
+		{name: "current period", code: current, want: true, wantCounter: now.Unix() / totpPeriod},
+		{name: "previous period within skew", code: previous, want: true, wantCounter: now.Unix()/totpPeriod - 1},
+		{name: "stale period", code: stale, want: current == stale || previous == stale},
+		{name: "wrong length", code: "12345", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, got := validateTOTP(secret, tt.code, now)
+			if got != tt.want {
+				t.Errorf("validateTOTP() got = %v, want %v", got, tt.want)
+			}
+			if got && tt.wantCounter != 0 && counter != tt.wantCounter {
+				t.Errorf("validateTOTP() counter = %v, want %v", counter, tt.wantCounter)
+			}
+		})
+	}
+}
+
+func Test_totpProvisioningURI(t *testing.T) {
+	got := totpProvisioningURI("SECRET", "test@mail.com")
+	if !strings.HasPrefix(got, "otpauth://totp/Tinder%20Dealls:test@mail.com?") ||
+		!strings.Contains(got, "secret=SECRET") || !strings.Contains(got, "issuer=Tinder+Dealls") {
+		t.Errorf("totpProvisioningURI() got = %v", got)
+	}
+}
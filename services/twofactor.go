@@ -0,0 +1,428 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"github.com/zombozo12/tinder-dealls/logging"
+	"github.com/zombozo12/tinder-dealls/tracing"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"time"
+)
+
+const (
+	mfaTokenScope      = "mfa_pending"
+	mfaTokenTTL        = 60 * 5 // seconds
+	mfaMaxAttempts     = 5
+	recoveryCodesCount = 10
+)
+
+func (a *authServiceModule) EnrollTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.auth.enroll_totp")
+	startTime := time.Now()
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "service.auth.enroll_totp"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	user, err := a.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get user"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if user == nil {
+		tags["error"] = "user not found"
+		tags["status"] = "error"
+		return nil, domain.ErrUserNotFound
+	}
+
+	if user.TOTPEnabled {
+		tags["error"] = "totp already enabled"
+		tags["status"] = "error"
+		return nil, domain.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		tags["error"] = "failed generating secret"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	encryptedSecret, err := domain.EncryptAESWithGCM(secret, a.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed encrypt secret"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := a.authRepo.SetTOTPSecret(ctx, userID, encryptedSecret); err != nil {
+		tags["error"] = "failed storing secret"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Phone
+	}
+
+	tags["status"] = "success"
+	return &domain.TOTPEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(secret, account),
+	}, nil
+}
+
+func (a *authServiceModule) ConfirmTOTP(ctx context.Context, userID int64, req domain.TOTPConfirmRequest) (*domain.TOTPConfirmResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.auth.confirm_totp")
+	startTime := time.Now()
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "service.auth.confirm_totp"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	totp, err := a.authRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get totp"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if totp == nil || totp.Secret == "" {
+		tags["error"] = "totp not enrolled"
+		tags["status"] = "error"
+		return nil, domain.ErrTOTPNotEnrolled
+	}
+
+	if totp.Enabled {
+		tags["error"] = "totp already enabled"
+		tags["status"] = "error"
+		return nil, domain.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := domain.DecryptAESWithGCM(totp.Secret, a.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed decrypt secret"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if _, ok := validateTOTP(secret, req.Code, time.Now()); !ok {
+		tags["error"] = "invalid totp code"
+		tags["status"] = "warning"
+		return nil, domain.ErrInvalidTOTPCode
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			tags["error"] = "failed generating recovery code"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			tags["error"] = "failed hashing recovery code"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, string(hash))
+	}
+
+	if err := a.authRepo.EnableTOTP(ctx, userID, hashes); err != nil {
+		tags["error"] = "failed enable totp"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return &domain.TOTPConfirmResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (a *authServiceModule) VerifyMFA(ctx context.Context, req domain.MFAVerifyRequest) (*domain.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.auth.verify_mfa")
+	startTime := time.Now()
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "service.auth.verify_mfa"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		tags["error"] = "failed validating request"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	userID, tokenID, err := a.parseMFAToken(req.MFAToken)
+	if err != nil {
+		tags["error"] = "invalid mfa token"
+		tags["actual_error"] = err.Error()
+		tags["status"] = "error"
+		return nil, domain.ErrInvalidMFAToken
+	}
+
+	tags["user_id"] = userID
+
+	// The token is spent by whoever deletes it first, before any code is consumed, so concurrent
+	// verifications of the same token cannot both burn a recovery code or a totp period.
+	pendingKey := fmt.Sprintf("mfa_pending:%s", tokenID)
+	if owner, err := a.redisRepo.GetDel(ctx, pendingKey); err != nil {
+		tags["error"] = "failed spend mfa token"
+		tags["status"] = "error"
+		return nil, err
+	} else if owner == "" {
+		tags["error"] = "mfa token already used"
+		tags["status"] = "warning"
+		return nil, domain.ErrInvalidMFAToken
+	}
+
+	attemptsKey := fmt.Sprintf("mfa_attempts:%d", userID)
+	attempts, err := a.redisRepo.Incr(ctx, attemptsKey)
+	if err != nil {
+		tags["error"] = "failed count attempts"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if attempts == 1 {
+		if err := a.redisRepo.Expire(ctx, attemptsKey, mfaTokenTTL); err != nil {
+			tags["error"] = "failed expire attempts"
+			tags["status"] = "error"
+			return nil, err
+		}
+	}
+
+	if attempts > mfaMaxAttempts {
+		tags["error"] = "too many mfa attempts"
+		tags["status"] = "warning"
+		return nil, domain.ErrMFATooManyAttempts
+	}
+
+	totp, err := a.authRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get totp"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if totp == nil || !totp.Enabled {
+		tags["error"] = "totp not enabled"
+		tags["status"] = "error"
+		return nil, domain.ErrTOTPNotEnabled
+	}
+
+	secret, err := domain.DecryptAESWithGCM(totp.Secret, a.cfg.JWT.Key)
+	if err != nil {
+		tags["error"] = "failed decrypt secret"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if counter, ok := validateTOTP(secret, req.Code, time.Now()); ok {
+		// A code stays valid for its whole period and the skew around it, it is only good once.
+		fresh, err := a.authRepo.UseTOTPCounter(ctx, userID, counter)
+		if err != nil {
+			tags["error"] = "failed use totp counter"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		if !fresh {
+			tags["error"] = "totp code already used"
+			tags["status"] = "warning"
+			return nil, domain.ErrInvalidMFACode
+		}
+	} else {
+		used, err := a.authRepo.UseRecoveryCode(ctx, userID, req.Code)
+		if err != nil {
+			tags["error"] = "failed use recovery code"
+			tags["status"] = "error"
+			return nil, err
+		}
+
+		if !used {
+			tags["error"] = "invalid mfa code"
+			tags["status"] = "warning"
+			return nil, domain.ErrInvalidMFACode
+		}
+
+		tags["recovery_code"] = true
+	}
+
+	if err := a.redisRepo.Del(ctx, attemptsKey); err != nil {
+		tags["error"] = "failed reset attempts"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	user, err := a.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get user"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if user == nil {
+		tags["error"] = "user not found"
+		tags["status"] = "error"
+		return nil, domain.ErrUserNotFound
+	}
+
+	if user.BannedAt != nil {
+		tags["error"] = "user is banned"
+		tags["status"] = "error"
+		return nil, domain.ErrUserBanned
+	}
+
+	response, err := a.issueToken(ctx, tags, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	tags["status"] = "success"
+	return response, nil
+}
+
+func (a *authServiceModule) TwoFactorStatus(ctx context.Context, userID int64) (*domain.TwoFactorStatus, error) {
+	ctx, span := tracing.Start(ctx, "service.auth.two_factor_status")
+	startTime := time.Now()
+	tags := make(log.Fields)
+	defer func() {
+		tags["name"] = "service.auth.two_factor_status"
+		tags["elapsed_time"] = time.Since(startTime).String()
+		tags["request_id"] = logging.RequestID(ctx)
+		logging.From(ctx).WithFields(tags).Debug()
+		tracing.End(span, tags)
+	}()
+
+	status, err := a.authRepo.GetTwoFactorStatus(ctx, userID)
+	if err != nil {
+		tags["error"] = "failed get two factor status"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if status == nil {
+		tags["error"] = "user not found"
+		tags["status"] = "error"
+		return nil, domain.ErrUserNotFound
+	}
+
+	tags["status"] = "success"
+	return status, nil
+}
+
+// completeLogin hands out the access token, or a pending MFA token when the user has 2FA enabled.
+func (a *authServiceModule) completeLogin(ctx context.Context, tags log.Fields, user domain.User) (*domain.AuthResponse, error) {
+	if user.BannedAt != nil {
+		tags["error"] = "user is banned"
+		tags["status"] = "error"
+		return nil, domain.ErrUserBanned
+	}
+
+	if !user.TOTPEnabled {
+		return a.issueToken(ctx, tags, user)
+	}
+
+	// The token is single use, VerifyMFA spends its ID.
+	tokenID, err := randomToken(16)
+	if err != nil {
+		tags["error"] = "failed generating mfa token id"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{
+		"jti":   tokenID,
+		"sub":   strconv.FormatInt(user.ID, 10),
+		"scope": mfaTokenScope,
+		"exp":   time.Now().Add(mfaTokenTTL * time.Second).Unix(),
+		"iat":   time.Now().Unix(),
+		"nbf":   time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(a.cfg.Key))
+	if err != nil {
+		tags["error"] = "failed generating mfa token"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	if err := a.redisRepo.Set(ctx, fmt.Sprintf("mfa_pending:%s", tokenID), user.ID, mfaTokenTTL); err != nil {
+		tags["error"] = "failed storing mfa token"
+		tags["status"] = "error"
+		return nil, err
+	}
+
+	tags["mfa_required"] = true
+	return &domain.AuthResponse{
+		MFARequired: true,
+		MFAToken:    tokenString,
+	}, nil
+}
+
+// parseMFAToken returns the user a pending MFA token was issued to and the ID of the token.
+func (a *authServiceModule) parseMFAToken(tokenString string) (int64, string, error) {
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(a.cfg.Key), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired()); err != nil {
+		return 0, "", err
+	}
+
+	if scope, _ := claims["scope"].(string); scope != mfaTokenScope {
+		return 0, "", domain.ErrInvalidMFAToken
+	}
+
+	tokenID, _ := claims["jti"].(string)
+	if tokenID == "" {
+		return 0, "", domain.ErrInvalidMFAToken
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil {
+		return 0, "", err
+	}
+
+	userID, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return userID, tokenID, nil
+}
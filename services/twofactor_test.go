@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"testing"
+	"time"
+)
+
+func Test_authServiceModule_ConfirmTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{
+		Key: "12345",
+		JWT: domain.JWT{
+			Key:        "TRTgoAnzX&NPBAhA53C6PaMB&E5*d7wx",
+			ExpireTime: 30,
+		},
+	}
+
+	secret, _ := generateTOTPSecret()
+	encryptedSecret, _ := domain.EncryptAESWithGCM(secret, config.JWT.Key)
+	code, _ := totpCode(secret, uint64(time.Now().Unix()/totpPeriod))
+
+	tests := []struct {
+		name    string
+		req     domain.TOTPConfirmRequest
+		mock    func() *authServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed validating request",
+			req:  domain.TOTPConfirmRequest{Code: "12"},
+			mock: func() *authServiceModule {
+				return &authServiceModule{cfg: config}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed not enrolled",
+			req:  domain.TOTPConfirmRequest{Code: code},
+			mock: func() *authServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetTOTP(ctx, int64(1)).Return(&domain.UserTOTP{UserID: 1}, nil)
+
+				return &authServiceModule{cfg: config, authRepo: authMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed invalid code",
+			req:  domain.TOTPConfirmRequest{Code: "000000"},
+			mock: func() *authServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetTOTP(ctx, int64(1)).
+					Return(&domain.UserTOTP{UserID: 1, Secret: encryptedSecret}, nil)
+
+				return &authServiceModule{cfg: config, authRepo: authMock}
+			},
+			wantErr: code != "000000",
+		},
+		{
+			name: "success",
+			req:  domain.TOTPConfirmRequest{Code: code},
+			mock: func() *authServiceModule {
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetTOTP(ctx, int64(1)).
+					Return(&domain.UserTOTP{UserID: 1, Secret: encryptedSecret}, nil)
+				authMock.EXPECT().EnableTOTP(ctx, int64(1), gomock.Len(recoveryCodesCount)).Return(nil)
+
+				return &authServiceModule{cfg: config, authRepo: authMock}
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			got, err := a.ConfirmTOTP(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConfirmTOTP() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && got != nil && len(got.RecoveryCodes) != recoveryCodesCount {
+				t.Errorf("ConfirmTOTP() got %d recovery codes, want %d", len(got.RecoveryCodes), recoveryCodesCount)
+			}
+		})
+	}
+}
+
+func Test_authServiceModule_VerifyMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "requestid", "test")
+
+	config := &domain.Config{
+		Key: "12345",
+		JWT: domain.JWT{
+			Key:        "TRTgoAnzX&NPBAhA53C6PaMB&E5*d7wx",
+			ExpireTime: 30,
+		},
+	}
+
+	secret, _ := generateTOTPSecret()
+	encryptedSecret, _ := domain.EncryptAESWithGCM(secret, config.JWT.Key)
+	counter := time.Now().Unix() / totpPeriod
+	code, _ := totpCode(secret, uint64(counter))
+
+	var pendingKey string
+	pendingRedisMock := NewMockRedisRepoInterface(ctrl)
+	pendingRedisMock.EXPECT().Set(ctx, gomock.Any(), int64(1), mfaTokenTTL).
+		DoAndReturn(func(ctx context.Context, key string, value interface{}, expiration int) error {
+			pendingKey = key
+			return nil
+		})
+
+	pending, err := (&authServiceModule{cfg: config, redisRepo: pendingRedisMock}).completeLogin(ctx,
+		map[string]interface{}{}, domain.User{ID: 1, TOTPEnabled: true})
+	if err != nil || !pending.MFARequired || pending.Token != "" {
+		t.Fatalf("completeLogin() got = %v, err = %v", pending, err)
+	}
+
+	enabledTOTP := &domain.UserTOTP{UserID: 1, Secret: encryptedSecret, Enabled: true}
+
+	tests := []struct {
+		name    string
+		req     domain.MFAVerifyRequest
+		mock    func() *authServiceModule
+		wantErr bool
+	}{
+		{
+			name: "failed invalid mfa token",
+			req:  domain.MFAVerifyRequest{MFAToken: "invalid", Code: code},
+			mock: func() *authServiceModule {
+				return &authServiceModule{cfg: config}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed mfa token already used",
+			req:  domain.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: code},
+			mock: func() *authServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, pendingKey).Return("", nil)
+
+				return &authServiceModule{cfg: config, redisRepo: redisMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed too many attempts",
+			req:  domain.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: code},
+			mock: func() *authServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, pendingKey).Return("1", nil)
+				redisMock.EXPECT().Incr(ctx, "mfa_attempts:1").Return(int64(mfaMaxAttempts+1), nil)
+
+				return &authServiceModule{cfg: config, redisRepo: redisMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed invalid code and recovery code",
+			req:  domain.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: "abcd-efgh"},
+			mock: func() *authServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, pendingKey).Return("1", nil)
+				redisMock.EXPECT().Incr(ctx, "mfa_attempts:1").Return(int64(2), nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetTOTP(ctx, int64(1)).Return(enabledTOTP, nil)
+				authMock.EXPECT().UseRecoveryCode(ctx, int64(1), "abcd-efgh").Return(false, nil)
+
+				return &authServiceModule{cfg: config, authRepo: authMock, redisRepo: redisMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "success with recovery code",
+			req:  domain.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: "abcd-efgh"},
+			mock: func() *authServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, pendingKey).Return("1", nil)
+				redisMock.EXPECT().Incr(ctx, "mfa_attempts:1").Return(int64(1), nil)
+				redisMock.EXPECT().Expire(ctx, "mfa_attempts:1", mfaTokenTTL).Return(nil)
+				redisMock.EXPECT().Del(ctx, "mfa_attempts:1").Return(nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetTOTP(ctx, int64(1)).Return(enabledTOTP, nil)
+				authMock.EXPECT().UseRecoveryCode(ctx, int64(1), "abcd-efgh").Return(true, nil)
+				authMock.EXPECT().GetByID(ctx, int64(1)).Return(&domain.User{ID: 1, TOTPEnabled: true}, nil)
+				authMock.EXPECT().UpdateToken(ctx, int64(1), gomock.Any()).Return(nil)
+
+				eventsMock := NewMockEventPublisherInterface(ctrl)
+				eventsMock.EXPECT().Publish(ctx, domain.UserLoggedInEvent{UserID: 1, MFA: true}).Return(nil)
+
+				return &authServiceModule{cfg: config, authRepo: authMock, redisRepo: redisMock, events: eventsMock}
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed totp code already used",
+			req:  domain.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: code},
+			mock: func() *authServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, pendingKey).Return("1", nil)
+				redisMock.EXPECT().Incr(ctx, "mfa_attempts:1").Return(int64(2), nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetTOTP(ctx, int64(1)).Return(enabledTOTP, nil)
+				authMock.EXPECT().UseTOTPCounter(ctx, int64(1), gomock.Any()).Return(false, nil)
+
+				return &authServiceModule{cfg: config, authRepo: authMock, redisRepo: redisMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed mfa token spent by a concurrent verification",
+			req:  domain.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: code},
+			mock: func() *authServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, pendingKey).Return("", nil)
+
+				// The losing verification must not consume the code.
+				authMock := NewMockAuthRepoInterface(ctrl)
+
+				return &authServiceModule{cfg: config, authRepo: authMock, redisRepo: redisMock}
+			},
+			wantErr: true,
+		},
+		{
+			name: "totp code accepted - failed update token",
+			req:  domain.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: code},
+			mock: func() *authServiceModule {
+				redisMock := NewMockRedisRepoInterface(ctrl)
+				redisMock.EXPECT().GetDel(ctx, pendingKey).Return("1", nil)
+				redisMock.EXPECT().Incr(ctx, "mfa_attempts:1").Return(int64(2), nil)
+				redisMock.EXPECT().Del(ctx, "mfa_attempts:1").Return(nil)
+
+				authMock := NewMockAuthRepoInterface(ctrl)
+				authMock.EXPECT().GetTOTP(ctx, int64(1)).Return(enabledTOTP, nil)
+				authMock.EXPECT().UseTOTPCounter(ctx, int64(1), counter).Return(true, nil)
+				authMock.EXPECT().GetByID(ctx, int64(1)).Return(&domain.User{ID: 1, TOTPEnabled: true}, nil)
+				authMock.EXPECT().UpdateToken(ctx, int64(1), gomock.Any()).Return(errors.New("test"))
+
+				return &authServiceModule{cfg: config, authRepo: authMock, redisRepo: redisMock}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := tt.mock()
+			got, err := a.VerifyMFA(ctx, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyMFA() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && (got.Token == "" || got.MFARequired) {
+				t.Errorf("VerifyMFA() got = %v, want access token", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+type gormPlugin struct{}
+
+// GormPlugin traces every query run with a context carrying a trace, see uow.Bind.
+func GormPlugin() gorm.Plugin {
+	return gormPlugin{}
+}
+
+func (gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", beforeQuery("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", afterQuery),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", beforeQuery("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", afterQuery),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", beforeQuery("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", afterQuery),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", beforeQuery("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", afterQuery),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", beforeQuery("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", afterQuery),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", beforeQuery("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", afterQuery),
+	}
+
+	return errors.Join(registrations...)
+}
+
+func beforeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := startChild(db.Statement.Context, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+		if !span.IsRecording() {
+			return
+		}
+
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func afterQuery(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+
+	span := value.(trace.Span)
+	span.SetAttributes(
+		attribute.String("db.system", db.Dialector.Name()),
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+
+	span.End()
+}
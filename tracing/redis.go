@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type redisHook struct{}
+
+// RedisHook traces every command sent with a context carrying a trace.
+func RedisHook() redis.Hook {
+	return redisHook{}
+}
+
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := startChild(ctx, "redis."+cmd.Name(), trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+
+		err := next(ctx, cmd)
+		span.SetAttributes(attribute.String("db.system", "redis"), attribute.String("db.operation", cmd.FullName()))
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := startChild(ctx, "redis.pipeline", trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+
+		err := next(ctx, cmds)
+		span.SetAttributes(attribute.String("db.system", "redis"), attribute.Int("db.redis.commands", len(cmds)))
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+// recordRedisError ignores missing keys, they are an answer rather than a failure.
+func recordRedisError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,181 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/zombozo12/tinder-dealls/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKey holds the span of a request in a context that cannot be replaced, such as the fasthttp request
+// context handlers pass to services. Start uses it as the parent when the context carries no other span.
+const SpanKey = "trace_span"
+
+const instrumentationName = "github.com/zombozo12/tinder-dealls"
+
+var (
+	enabled  atomic.Bool
+	tracer   trace.Tracer = noop.NewTracerProvider().Tracer(instrumentationName)
+	observer Observer
+)
+
+// Observer is told the duration and status of every call ended with End, such as a metrics collector.
+type Observer interface {
+	ObserveCall(name string, status string, elapsed time.Duration)
+}
+
+// SetObserver sets the observer of every call, call it before serving requests.
+func SetObserver(o Observer) {
+	observer = o
+}
+
+// Span is the span of a call started with Start. It is timed whether tracing is on or off.
+type Span struct {
+	trace.Span
+	name      string
+	startTime time.Time
+}
+
+// Setup installs the exporter of the config as the global tracer provider, the returned function flushes
+// the spans that are still buffered. Without an exporter every span is a no-op.
+func Setup(cfg *domain.Config, serviceName string) (func(context.Context) error, error) {
+	if cfg.Tracing.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Tracing.ServiceName != "" {
+		serviceName = cfg.Tracing.ServiceName
+	}
+
+	ratio := 1.0
+	if cfg.Tracing.SampleRate > 0 {
+		ratio = float64(cfg.Tracing.SampleRate) / 100
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	tracer = provider.Tracer(instrumentationName)
+	enabled.Store(true)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(cfg domain.Tracing) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// Start starts a span as a child of the span in ctx. While tracing is off ctx is returned unchanged.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, *Span) {
+	span := &Span{Span: noop.Span{}, name: name, startTime: time.Now()}
+	if !enabled.Load() {
+		return ctx, span
+	}
+
+	ctx, span.Span = tracer.Start(parent(ctx), name, opts...)
+	return ctx, span
+}
+
+// startChild only starts a span inside a trace, so clients polling in the background do not start one
+// trace per call.
+func startChild(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !enabled.Load() {
+		return ctx, noop.Span{}
+	}
+
+	ctx = parent(ctx)
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, noop.Span{}
+	}
+
+	return tracer.Start(ctx, name, opts...)
+}
+
+func parent(ctx context.Context) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	if span, ok := ctx.Value(SpanKey).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span)
+	}
+
+	return ctx
+}
+
+// End ends a span started for a call logging tags, the request ID becomes an attribute and an error status
+// marks the span failed. The observer is told how the call went.
+func End(span *Span, tags log.Fields) {
+	if observer != nil {
+		status, _ := tags["status"].(string)
+		observer.ObserveCall(span.name, status, time.Since(span.startTime))
+	}
+
+	if !span.IsRecording() {
+		span.End()
+		return
+	}
+
+	if requestID, ok := tags["request_id"].(string); ok {
+		span.SetAttributes(attribute.String("request_id", requestID))
+	}
+
+	if tags["status"] == "error" {
+		message, _ := tags["error"].(string)
+		span.SetStatus(codes.Error, message)
+	}
+
+	span.End()
+}
+
+// Inject writes the trace of ctx into carrier, such as the headers of an outgoing message.
+func Inject(ctx context.Context, carrier map[string]string) {
+	otel.GetTextMapPropagator().Inject(parent(ctx), propagation.MapCarrier(carrier))
+}
+
+// Extract continues the trace written into carrier by Inject.
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// Headers are the keys Inject writes, such as traceparent, to be copied from incoming requests.
+func Headers() []string {
+	return otel.GetTextMapPropagator().Fields()
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"testing"
+	"time"
+)
+
+func record(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	tracer = provider.Tracer(instrumentationName)
+	enabled.Store(true)
+	t.Cleanup(func() {
+		tracer = noop.NewTracerProvider().Tracer(instrumentationName)
+		enabled.Store(false)
+	})
+
+	return recorder
+}
+
+func TestStartDisabled(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "requestid", "1")
+
+	got, span := Start(ctx, "service.matcher.like")
+	if got != ctx {
+		t.Errorf("Start() changed the context while tracing is off")
+	}
+	if span.IsRecording() {
+		t.Errorf("Start() span is recording while tracing is off")
+	}
+}
+
+func TestStart(t *testing.T) {
+	recorder := record(t)
+
+	_, server := Start(context.Background(), "POST /api/matcher/like")
+	ctx := context.WithValue(context.Background(), SpanKey, server)
+
+	_, span := Start(ctx, "service.matcher.like")
+	End(span, log.Fields{"request_id": "1", "status": "error", "error": "quota exceeded"})
+	server.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	child := spans[0]
+	if child.Name() != "service.matcher.like" {
+		t.Errorf("got span %s, want service.matcher.like", child.Name())
+	}
+	if child.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("span is not a child of the span in %s", SpanKey)
+	}
+	if child.Status().Code != codes.Error || child.Status().Description != "quota exceeded" {
+		t.Errorf("got status %v, want error quota exceeded", child.Status())
+	}
+
+	var requestID string
+	for _, attr := range child.Attributes() {
+		if attr.Key == attribute.Key("request_id") {
+			requestID = attr.Value.AsString()
+		}
+	}
+	if requestID != "1" {
+		t.Errorf("got request_id %q, want 1", requestID)
+	}
+}
+
+func TestStartChild(t *testing.T) {
+	recorder := record(t)
+
+	_, span := startChild(context.Background(), "redis.get")
+	span.End()
+	if len(recorder.Ended()) != 0 {
+		t.Errorf("startChild() started a trace without a parent")
+	}
+
+	ctx, parent := Start(context.Background(), "handler.job.expire")
+	_, span = startChild(ctx, "redis.get")
+	span.End()
+	parent.End()
+	if len(recorder.Ended()) != 2 {
+		t.Errorf("startChild() did not start a span inside a trace")
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	record(t)
+
+	ctx, span := Start(context.Background(), "repo.eventbus.publish")
+	defer span.End()
+
+	carrier := make(map[string]string)
+	Inject(ctx, carrier)
+	if carrier["traceparent"] == "" {
+		t.Fatalf("Inject() wrote no traceparent")
+	}
+
+	_, child := Start(Extract(context.Background(), carrier), "repo.eventbus.handle")
+	defer child.End()
+	if child.SpanContext().TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("Extract() did not continue the trace")
+	}
+}
+
+type observed struct {
+	name   string
+	status string
+}
+
+type recordObserver []observed
+
+func (r *recordObserver) ObserveCall(name string, status string, elapsed time.Duration) {
+	*r = append(*r, observed{name: name, status: status})
+}
+
+func TestEndObserves(t *testing.T) {
+	var calls recordObserver
+	SetObserver(&calls)
+	t.Cleanup(func() {
+		SetObserver(nil)
+	})
+
+	// Calls are observed with tracing off too.
+	_, span := Start(context.Background(), "service.matcher.like")
+	End(span, log.Fields{"status": "error"})
+
+	if len(calls) != 1 || calls[0] != (observed{name: "service.matcher.like", status: "error"}) {
+		t.Errorf("End() observed %+v, want service.matcher.like with status error", calls)
+	}
+}